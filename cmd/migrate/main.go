@@ -0,0 +1,235 @@
+package main
+
+import (
+	"challenge-fravega/internal/config"
+	"challenge-fravega/internal/database"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+const usage = `Usage: migrate [settings] <command> [arguments]
+
+Applies and rolls back the migrations of the database configured as for the
+server, with the same configuration file, environment and flags.
+
+Commands:
+  up [N] [--dry-run]     apply the pending migrations, or the next N
+  down [N] [--dry-run]   roll back the last migration applied, or the last N
+  redo [--dry-run]       roll back the last migration applied and apply it again
+  status                 list the migrations and whether they were applied
+  create NAME            write the up and down files of a new migration for
+                         every driver
+  unlock                 release the lock left by a run that did not finish
+
+--dry-run prints the SQL that would run instead of running it.
+`
+
+// errUsage is returned for a command line that cannot be run.
+var errUsage = errors.New("invalid command line")
+
+func main() {
+	cfg, options, err := config.Load(os.Args[1:], os.LookupEnv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n\n%s", err, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, cfg.Database, options.Args, os.Stdout); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "%v\n\n%s", err, usage)
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command in args, writing its output to out.
+func run(ctx context.Context, cfg config.Database, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: missing command", errUsage)
+	}
+	command, args := args[0], args[1:]
+
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	dryRun := flags.Bool("dry-run", false, "print the SQL instead of running it")
+	// The count or name may come before or after --dry-run
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	if command == "create" {
+		if len(positional) != 1 {
+			return fmt.Errorf("%w: create takes the name of the migration", errUsage)
+		}
+		var dirs []string
+		for _, driver := range sortedDrivers() {
+			dirs = append(dirs, filepath.Join(cfg.MigrationsDir, driver))
+		}
+		paths, err := database.CreateMigration(positional[0], dirs...)
+		for _, path := range paths {
+			fmt.Fprintf(out, "Created %s\n", path)
+		}
+		return err
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	migrator := database.NewMigrator(db, cfg.Migrations(), cfg.MigrationLockTimeout.Duration)
+
+	var steps []database.Step
+	switch command {
+	case "up":
+		var limit int
+		if limit, err = count(positional, 0); err != nil {
+			return err
+		}
+		steps, err = migrator.Up(ctx, limit, *dryRun)
+	case "down":
+		var n int
+		if n, err = count(positional, 1); err != nil {
+			return err
+		}
+		steps, err = migrator.Down(ctx, n, *dryRun)
+	case "redo":
+		if len(positional) > 0 {
+			return fmt.Errorf("%w: redo takes no arguments", errUsage)
+		}
+		steps, err = migrator.Redo(ctx, *dryRun)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatuses(out, statuses)
+		return nil
+	case "unlock":
+		if err := migrator.Unlock(ctx); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "Unlocked migrations")
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, command)
+	}
+	// The steps taken before one failing are printed as well
+	if err == nil || len(steps) > 0 {
+		printSteps(out, steps, *dryRun)
+	}
+	return err
+}
+
+// openDatabase connects to the database of the configuration, without
+// logging the queries.
+func openDatabase(cfg config.Database) (*gorm.DB, error) {
+	driver := database.Driver(cfg.Driver)
+	if driver == database.DriverSQLite {
+		if err := os.MkdirAll(filepath.Dir(cfg.Path), os.ModePerm); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+	db, err := database.Open(driver, cfg.DataSource(), &gorm.Config{
+		Logger: logger.Discard,
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return db, nil
+}
+
+// count parses the optional count argument of up and down.
+func count(positional []string, fallback int) (int, error) {
+	switch len(positional) {
+	case 0:
+		return fallback, nil
+	case 1:
+		n, err := strconv.Atoi(positional[0])
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("%w: the count must be a positive number, got %q", errUsage, positional[0])
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("%w: too many arguments", errUsage)
+	}
+}
+
+// printSteps writes the steps taken, or their SQL on a dry run.
+func printSteps(out io.Writer, steps []database.Step, dryRun bool) {
+	if len(steps) == 0 {
+		fmt.Fprintln(out, "Nothing to do")
+		return
+	}
+	for _, step := range steps {
+		switch {
+		case dryRun:
+			fmt.Fprintf(out, "-- %s %s\n%s\n", step.Direction, step.Name, step.SQL)
+		case step.Direction == database.DirectionDown:
+			fmt.Fprintf(out, "Rolled back %s\n", step.Name)
+		default:
+			fmt.Fprintf(out, "Applied %s\n", step.Name)
+		}
+	}
+}
+
+// printStatuses writes a table of the migrations.
+func printStatuses(out io.Writer, statuses []database.MigrationStatus) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MIGRATION\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state := "pending"
+		switch {
+		case status.Missing:
+			state = "applied, file missing"
+		case status.Drifted:
+			state = "applied, file edited"
+		case status.Applied:
+			state = "applied"
+		}
+		appliedAt := ""
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status.Name, state, appliedAt)
+	}
+	w.Flush()
+}
+
+func sortedDrivers() []string {
+	var drivers []string
+	for _, driver := range database.DriverList {
+		drivers = append(drivers, driver)
+	}
+	sort.Strings(drivers)
+	return drivers
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"challenge-fravega/internal/audit"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	service audit.Service
+}
+
+func (h *AuditHandler) SetupRoutes(router *gin.Engine) {
+	router.GET("/audit", h.GetEntries)
+}
+
+func (h *AuditHandler) GetEntries(c *gin.Context) {
+	filter := &audit.Filter{}
+	if err := c.ShouldBindQuery(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.GetEntries(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, audit.ErrInvalidEntity) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// static functions
+
+func NewAuditHandler(service audit.Service) *AuditHandler {
+	return &AuditHandler{service: service}
+}
@@ -2,19 +2,34 @@ package handlers
 
 import (
 	carDriver "challenge-fravega/internal/car-driver"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+type availabilityQuery struct {
+	From string `form:"from" binding:"required"`
+	To   string `form:"to" binding:"required"`
+}
+
 type CarDriverHandler struct {
 	service carDriver.Service
 }
 
 func (h *CarDriverHandler) SetupRoutes(router *gin.Engine) {
+	router.GET("/car-drivers/available", h.GetAvailableCarDrivers)
 	router.GET("/car-drivers/:id", h.GetCarDriver)
 	router.GET("/car-drivers", h.GetCarDrivers)
+	router.GET("/car-drivers/:id/availability", h.GetAvailability)
+	router.GET("/car-drivers/:id/shifts", h.GetShifts)
+	router.POST("/car-drivers/:id/shifts", h.NewShift)
+	router.DELETE("/car-drivers/:id/shifts/:shiftId", h.DeleteShift)
+	router.GET("/car-drivers/:id/time-off", h.GetTimeOffs)
+	router.POST("/car-drivers/:id/time-off", h.NewTimeOff)
+	router.PATCH("/car-drivers/:id/time-off/:timeOffId", h.UpdateTimeOff)
 }
 
 func (h *CarDriverHandler) GetCarDriver(c *gin.Context) {
@@ -25,7 +40,7 @@ func (h *CarDriverHandler) GetCarDriver(c *gin.Context) {
 		return
 	}
 
-	driver, err := h.service.GetDriver(uuid)
+	driver, err := h.service.GetDriver(c.Request.Context(), uuid)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -35,7 +50,7 @@ func (h *CarDriverHandler) GetCarDriver(c *gin.Context) {
 }
 
 func (h *CarDriverHandler) GetCarDrivers(c *gin.Context) {
-	drivers, err := h.service.GetDrivers()
+	drivers, err := h.service.GetDrivers(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -44,8 +59,174 @@ func (h *CarDriverHandler) GetCarDrivers(c *gin.Context) {
 	c.JSON(http.StatusOK, drivers)
 }
 
+// GetAvailableCarDrivers lists the drivers that may be assigned a route on the
+// date query parameter, today when missing.
+func (h *CarDriverHandler) GetAvailableCarDrivers(c *gin.Context) {
+	drivers, err := h.service.GetAvailableDrivers(c.Request.Context(), c.Query("date"))
+	if err != nil {
+		if errors.Is(err, carDriver.ErrInvalidDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, drivers)
+}
+
+func (h *CarDriverHandler) GetAvailability(c *gin.Context) {
+	id, ok := parseDriverID(c)
+	if !ok {
+		return
+	}
+	query := &availabilityQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	calendar, err := h.service.GetAvailability(c.Request.Context(), id, query.From, query.To)
+	if err != nil {
+		driverError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, calendar)
+}
+
+func (h *CarDriverHandler) GetShifts(c *gin.Context) {
+	id, ok := parseDriverID(c)
+	if !ok {
+		return
+	}
+
+	shifts, err := h.service.GetShifts(c.Request.Context(), id)
+	if err != nil {
+		driverError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shifts)
+}
+
+func (h *CarDriverHandler) NewShift(c *gin.Context) {
+	id, ok := parseDriverID(c)
+	if !ok {
+		return
+	}
+	req := &carDriver.CreateShift{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shift, err := h.service.CreateShift(c.Request.Context(), id, req)
+	if err != nil {
+		driverError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, shift)
+}
+
+func (h *CarDriverHandler) DeleteShift(c *gin.Context) {
+	id, ok := parseDriverID(c)
+	if !ok {
+		return
+	}
+	shiftID, err := uuid.Parse(c.Param("shiftId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.DeleteShift(c.Request.Context(), id, shiftID); err != nil {
+		driverError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CarDriverHandler) GetTimeOffs(c *gin.Context) {
+	id, ok := parseDriverID(c)
+	if !ok {
+		return
+	}
+
+	timeOffs, err := h.service.GetTimeOffs(c.Request.Context(), id)
+	if err != nil {
+		driverError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, timeOffs)
+}
+
+func (h *CarDriverHandler) NewTimeOff(c *gin.Context) {
+	id, ok := parseDriverID(c)
+	if !ok {
+		return
+	}
+	req := &carDriver.CreateTimeOff{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	timeOff, err := h.service.RequestTimeOff(c.Request.Context(), id, req)
+	if err != nil {
+		driverError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, timeOff)
+}
+
+// UpdateTimeOff approves, rejects or cancels a time off request.
+func (h *CarDriverHandler) UpdateTimeOff(c *gin.Context) {
+	id, ok := parseDriverID(c)
+	if !ok {
+		return
+	}
+	timeOffID, err := uuid.Parse(c.Param("timeOffId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req := &carDriver.UpdateTimeOff{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	timeOff, err := h.service.UpdateTimeOff(c.Request.Context(), id, timeOffID, req)
+	if err != nil {
+		driverError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, timeOff)
+}
+
 // static functions
 
+func parseDriverID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func driverError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, carDriver.ErrInvalidShift), errors.Is(err, carDriver.ErrInvalidDate), errors.Is(err, carDriver.ErrInvalidDateRange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, carDriver.ErrShiftOverlap), errors.Is(err, carDriver.ErrInvalidTimeOffStatus):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func NewCarDriverHandler(service carDriver.Service) *CarDriverHandler {
 	return &CarDriverHandler{service: service}
 }
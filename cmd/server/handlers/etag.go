@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errPreconditionRequired = errors.New("If-Match header with the resource ETag is required")
+	errPreconditionFailed   = errors.New("If-Match does not match the current ETag")
+)
+
+// etag formats a resource version as a strong entity tag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag adds the ETag header and reports whether the client already has
+// this version (If-None-Match), in which case a 304 has been written.
+func setETag(c *gin.Context, version int) bool {
+	tag := etag(version)
+	c.Header("ETag", tag)
+
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion returns the version the client expects to modify, taken from
+// the If-Match header. A 428 or 412 has been written when ok is false.
+func ifMatchVersion(c *gin.Context) (version int, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": errPreconditionRequired.Error()})
+		return 0, false
+	}
+
+	// Only a single strong ETag can identify the version being modified
+	if len(header) < 2 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": errPreconditionFailed.Error()})
+		return 0, false
+	}
+	version, err := strconv.Atoi(header[1 : len(header)-1])
+	if err != nil {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": errPreconditionFailed.Error()})
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"challenge-fravega/internal/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	health *health.Health
+}
+
+func (h *HealthHandler) SetupRoutes(router *gin.Engine) {
+	router.GET("/healthz", h.Live)
+	router.GET("/readyz", h.Ready)
+}
+
+// Live answers as long as the server serves requests, whatever the state of
+// its dependencies.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusList[health.StatusUp]})
+}
+
+// Ready answers 503 Service Unavailable when a dependency is not ready or the
+// server is shutting down.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.health.Ready(c.Request.Context())
+	if !report.Ready() {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// static functions
+
+func NewHealthHandler(health *health.Health) *HealthHandler {
+	return &HealthHandler{health: health}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"challenge-fravega/internal/job"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type JobHandler struct {
+	service job.Service
+}
+
+func (h *JobHandler) SetupRoutes(router *gin.Engine) {
+	router.Group("/jobs").
+		GET("", h.GetRuns).
+		GET("/:id", h.GetRun)
+}
+
+func (h *JobHandler) GetRuns(c *gin.Context) {
+	filter := &job.Filter{}
+	if err := c.ShouldBindQuery(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.GetRuns(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, job.ErrInvalidStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *JobHandler) GetRun(c *gin.Context) {
+	res, err := h.service.GetRun(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// static functions
+
+func NewJobHandler(service job.Service) *JobHandler {
+	return &JobHandler{service: service}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"bytes"
+	"challenge-fravega/internal/manifest"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type manifestQuery struct {
+	Format string `form:"format"`
+}
+
+type ManifestHandler struct {
+	service manifest.Service
+}
+
+func (h *ManifestHandler) SetupRoutes(router *gin.Engine) {
+	router.GET("/routes/:id/manifest", h.GetManifest)
+}
+
+// GetManifest renders the route manifest as a PDF or as printable HTML, picked
+// with the format query parameter or else the Accept header.
+func (h *ManifestHandler) GetManifest(c *gin.Context) {
+	query := &manifestQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format := manifest.Format(query.Format)
+	if format == "" {
+		format = manifest.FormatPDF
+		if c.NegotiateFormat("application/pdf", "text/html") == "text/html" {
+			format = manifest.FormatHTML
+		}
+	}
+	if _, ok := manifest.FormatList[format]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": manifest.ErrInvalidFormat.Error()})
+		return
+	}
+
+	res, err := h.service.GetManifest(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var body bytes.Buffer
+	contentType := "application/pdf"
+	if format == manifest.FormatHTML {
+		contentType = "text/html; charset=utf-8"
+		err = manifest.RenderHTML(&body, res)
+	} else {
+		err = manifest.RenderPDF(&body, res)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="manifest-`+res.RouteID.String()+`.`+string(format)+`"`)
+	c.Data(http.StatusOK, contentType, body.Bytes())
+}
+
+// static functions
+
+func NewManifestHandler(service manifest.Service) *ManifestHandler {
+	return &ManifestHandler{service: service}
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"challenge-fravega/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MetricsHandler struct {
+	metrics *metrics.Metrics
+}
+
+func (h *MetricsHandler) SetupRoutes(router *gin.Engine) {
+	router.GET("/metrics", gin.WrapH(h.metrics.Handler()))
+}
+
+// static functions
+
+func NewMetricsHandler(metrics *metrics.Metrics) *MetricsHandler {
+	return &MetricsHandler{metrics: metrics}
+}
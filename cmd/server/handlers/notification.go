@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"challenge-fravega/internal/notification"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NotificationHandler struct {
+	service notification.Service
+}
+
+func (h *NotificationHandler) SetupRoutes(router *gin.Engine) {
+	router.Group("/notifications").
+		GET("", h.GetNotifications).
+		GET("/opt-outs/:purchase_order_id", h.GetOptOut).
+		PUT("/opt-outs/:purchase_order_id", h.OptOut).
+		DELETE("/opt-outs/:purchase_order_id", h.OptIn)
+}
+
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	filter := &notification.Filter{}
+	if err := c.ShouldBindQuery(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.GetNotifications(c.Request.Context(), filter)
+	if err != nil {
+		notificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *NotificationHandler) GetOptOut(c *gin.Context) {
+	res, err := h.service.GetOptOut(c.Request.Context(), c.Param("purchase_order_id"))
+	if err != nil {
+		notificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// OptOut stops the notifications about a purchase order.
+func (h *NotificationHandler) OptOut(c *gin.Context) {
+	res, err := h.service.OptOut(c.Request.Context(), c.Param("purchase_order_id"))
+	if err != nil {
+		notificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// OptIn lets the notifications about a purchase order through again.
+func (h *NotificationHandler) OptIn(c *gin.Context) {
+	if err := h.service.OptIn(c.Request.Context(), c.Param("purchase_order_id")); err != nil {
+		notificationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// static functions
+
+func NewNotificationHandler(service notification.Service) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+func notificationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, notification.ErrInvalidStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"bytes"
+	"challenge-fravega/internal/reporting"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type reportQuery struct {
+	From    string `form:"from" binding:"required"`
+	To      string `form:"to" binding:"required"`
+	GroupBy string `form:"group_by"`
+	Format  string `form:"format"`
+}
+
+type ReportHandler struct {
+	service reporting.Service
+}
+
+func (h *ReportHandler) SetupRoutes(router *gin.Engine) {
+	router.Group("/reports").
+		GET("/deliveries", h.GetDeliveryReport).
+		GET("/distance", h.GetDistanceReport).
+		GET("/drivers", h.GetDriverReport)
+}
+
+func (h *ReportHandler) GetDeliveryReport(c *gin.Context) {
+	query, format, ok := parseReportQuery(c)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetDeliveryReport(c.Request.Context(), query)
+	writeReport(c, "deliveries", query, format, res, err)
+}
+
+func (h *ReportHandler) GetDistanceReport(c *gin.Context) {
+	query, format, ok := parseReportQuery(c)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetDistanceReport(c.Request.Context(), query)
+	writeReport(c, "distance", query, format, res, err)
+}
+
+func (h *ReportHandler) GetDriverReport(c *gin.Context) {
+	query, format, ok := parseReportQuery(c)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetDriverReport(c.Request.Context(), query)
+	writeReport(c, "drivers", query, format, res, err)
+}
+
+// static functions
+
+func NewReportHandler(service reporting.Service) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+// parseReportQuery binds the report query and picks the format with the format
+// query parameter or else the Accept header, answering 400 when either is
+// invalid.
+func parseReportQuery(c *gin.Context) (reporting.Query, reporting.Format, bool) {
+	query := &reportQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return reporting.Query{}, "", false
+	}
+	format := reporting.Format(query.Format)
+	if format == "" {
+		format = reporting.FormatJSON
+		if c.NegotiateFormat("application/json", "text/csv") == "text/csv" {
+			format = reporting.FormatCSV
+		}
+	}
+	if _, ok := reporting.FormatList[format]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": reporting.ErrInvalidFormat.Error()})
+		return reporting.Query{}, "", false
+	}
+	return reporting.Query{From: query.From, To: query.To, GroupBy: reporting.GroupBy(query.GroupBy)}, format, true
+}
+
+// writeReport answers with the report rows as JSON or as a CSV download named
+// after the report and its dates.
+func writeReport[R reporting.Row](c *gin.Context, name string, query reporting.Query, format reporting.Format, rows []R, err error) {
+	if err != nil {
+		if errors.Is(err, reporting.ErrInvalidDateRange) || errors.Is(err, reporting.ErrInvalidGroupBy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if format == reporting.FormatJSON {
+		c.JSON(http.StatusOK, rows)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := reporting.WriteCSV(&body, rows); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+name+`-`+query.From+`-`+query.To+`.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", body.Bytes())
+}
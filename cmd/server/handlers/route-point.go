@@ -2,11 +2,23 @@ package handlers
 
 import (
 	routePoint "challenge-fravega/internal/route-point"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+type importQuery struct {
+	DryRun bool `form:"dry_run"`
+	Verify bool `form:"verify"`
+}
+
+type unassignedQuery struct {
+	Date string `form:"date"`
+}
+
 type RoutePointHandler struct {
 	routePointService routePoint.Service
 }
@@ -14,12 +26,15 @@ type RoutePointHandler struct {
 func (h *RoutePointHandler) SetupRoutes(router *gin.Engine) {
 	router.Group("/route-points").
 		GET("/", h.GetRoutePoints).
+		GET("/unassigned", h.GetUnassignedRoutePoints).
 		GET("/:id", h.GetRoutePoint).
-		POST("/add-purchase-order", h.CreateRoutePoint)
+		POST("/add-purchase-order", h.CreateRoutePoint).
+		POST("/import", h.ImportPurchaseOrders).
+		PATCH("/:id", h.UpdateRoutePoint)
 }
 
 func (h *RoutePointHandler) GetRoutePoints(c *gin.Context) {
-	res, err := h.routePointService.GetRoutePoints()
+	res, err := h.routePointService.GetRoutePoints(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -27,12 +42,35 @@ func (h *RoutePointHandler) GetRoutePoints(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
+// GetUnassignedRoutePoints lists the unassigned pool, optionally only the
+// route points due by a date.
+func (h *RoutePointHandler) GetUnassignedRoutePoints(c *gin.Context) {
+	query := &unassignedQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.routePointService.GetUnassignedRoutePoints(c.Request.Context(), query.Date)
+	if err != nil {
+		if errors.Is(err, routePoint.ErrInvalidPoolDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
 func (h *RoutePointHandler) GetRoutePoint(c *gin.Context) {
-	res, err := h.routePointService.GetRoutePoint(c.Param("id"))
+	res, err := h.routePointService.GetRoutePoint(c.Request.Context(), c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	if setETag(c, res.Version) {
+		return
+	}
 	c.JSON(http.StatusOK, res)
 }
 
@@ -42,14 +80,127 @@ func (h *RoutePointHandler) CreateRoutePoint(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	res, err := h.routePointService.CreateRoutePoint(req)
+	res, err := h.routePointService.CreateRoutePoint(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, routePoint.ErrInvalidCoordinates), errors.Is(err, routePoint.ErrInvalidDeliveryWindow),
+			errors.Is(err, routePoint.ErrLocationRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, routePoint.ErrAddressNotFound), errors.Is(err, routePoint.ErrCoordinatesNotFound),
+			errors.Is(err, routePoint.ErrOutsideZone):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, routePoint.ErrGeocodingFailed):
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
+	c.Header("ETag", etag(res.Version))
 	c.JSON(http.StatusOK, res)
 }
 
+func (h *RoutePointHandler) UpdateRoutePoint(c *gin.Context) {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	req := &routePoint.UpdateRoutePoint{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.routePointService.UpdateRoutePoint(c.Request.Context(), c.Param("id"), version, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, routePoint.ErrVersionConflict):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		case errors.Is(err, routePoint.ErrInvalidStatus), errors.Is(err, routePoint.ErrInvalidCoordinates),
+			errors.Is(err, routePoint.ErrInvalidDeliveryWindow), errors.Is(err, routePoint.ErrRouteNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, routePoint.ErrInvalidStatusTransition), errors.Is(err, routePoint.ErrUnassigned):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, routePoint.ErrOutsideZone):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.Header("ETag", etag(res.Version))
+	c.JSON(http.StatusOK, res)
+}
+
+// ImportPurchaseOrders adds a batch of purchase orders sent either as a CSV
+// (text/csv) or as a JSON array of AddPurchaseOrder.
+func (h *RoutePointHandler) ImportPurchaseOrders(c *gin.Context) {
+	query := importQuery{}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, routePoint.MaxImportBytes)
+	var rows []routePoint.ImportRow
+	switch c.ContentType() {
+	case "text/csv":
+		parsed, err := routePoint.ParseCSV(c.Request.Body)
+		if err != nil {
+			importBodyError(c, err)
+			return
+		}
+		rows = parsed
+	case "application/json":
+		var req []routePoint.AddPurchaseOrder
+		if err := c.ShouldBindJSON(&req); err != nil {
+			importBodyError(c, err)
+			return
+		}
+		rows = routePoint.NewImportRows(req)
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "content type must be text/csv or application/json"})
+		return
+	}
+
+	res, err := h.routePointService.ImportPurchaseOrders(c.Request.Context(), rows, routePoint.ImportOptions{
+		DryRun: query.DryRun,
+		Verify: query.Verify,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, routePoint.ErrInvalidImport):
+			c.JSON(http.StatusUnprocessableEntity, res)
+		case errors.Is(err, routePoint.ErrImportEmpty), errors.Is(err, routePoint.ErrImportTooLarge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, routePoint.ErrVerificationFailed), errors.Is(err, routePoint.ErrGeocodingFailed):
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		case errors.Is(err, routePoint.ErrVerificationUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if query.DryRun {
+		c.JSON(http.StatusOK, res)
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// importBodyError answers 413 Request Entity Too Large to imports over
+// MaxImportBytes, and 400 Bad Request to those that do not parse.
+func importBodyError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("import exceeds %d bytes", tooLarge.Limit)})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
 // static functions
 
 func NewRoutePointHandler(routePointService routePoint.Service) *RoutePointHandler {
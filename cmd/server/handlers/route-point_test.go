@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	routePoint "challenge-fravega/internal/route-point"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRoutePointRouter(repository *routePoint.FakeRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewRoutePointHandler(routePoint.NewService(repository, nil, nil, nil, nil)).SetupRoutes(router)
+	return router
+}
+
+func TestCreateRoutePointOutOfRangeLatitude(t *testing.T) {
+	// Arrange
+	repository := routePoint.NewFakeRepository()
+	router := newTestRoutePointRouter(repository)
+	body := `{"route_id":"6f1f2f5e-7a1b-4c3d-9e8f-0a1b2c3d4e5f","purchase_order_id":"PO-1",
+		"latitude":91,"longitude":-58.4,"address":"Av. Corrientes 1234, Buenos Aires"}`
+	req := httptest.NewRequest(http.MethodPost, "/route-points/add-purchase-order", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(res, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Contains(t, res.Body.String(), routePoint.ErrInvalidCoordinates.Error())
+	routePoints, _ := repository.GetRoutePoints(req.Context())
+	assert.Empty(t, routePoints)
+}
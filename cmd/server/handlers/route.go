@@ -1,13 +1,28 @@
 package handlers
 
 import (
+	"bytes"
+	carDriver "challenge-fravega/internal/car-driver"
+	geoExport "challenge-fravega/internal/geo-export"
 	"challenge-fravega/internal/route"
+	"challenge-fravega/internal/vehicle"
+	"challenge-fravega/internal/zone"
+	"errors"
+	"strings"
 
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// GeoJSON media type (RFC 7946), kept by c.JSON as it is set beforehand
+const geoJSONContentType = "application/geo+json"
+
+type routesByDateQuery struct {
+	Date string `form:"date" binding:"required"`
+}
+
 type RouteHandler struct {
 	service route.Service
 }
@@ -16,11 +31,13 @@ func (h *RouteHandler) SetupRoutes(router *gin.Engine) {
 	router.Group("/routes").
 		GET("/", h.GetRoutes).
 		GET("/:id", h.GetRoute).
-		POST("/", h.NewRoute)
+		POST("/", h.NewRoute).
+		PATCH("/:id", h.UpdateRoute)
+	router.GET("/routes.geojson", h.GetRoutesGeoJSON)
 }
 
 func (h *RouteHandler) GetRoutes(c *gin.Context) {
-	res, err := h.service.GetRoutes()
+	res, err := h.service.GetRoutes(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -28,12 +45,45 @@ func (h *RouteHandler) GetRoutes(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
+// GetRoute returns the route as JSON, or exported as GeoJSON or GPX when the
+// id has a .geojson or .gpx extension.
 func (h *RouteHandler) GetRoute(c *gin.Context) {
-	res, err := h.service.GetRoute(c.Param("id"))
+	id := c.Param("id")
+	extension := ""
+	if i := strings.LastIndexByte(id, '.'); i >= 0 {
+		id, extension = id[:i], id[i+1:]
+	}
+	if extension != "" && extension != "geojson" && extension != "gpx" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown route format " + extension})
+		return
+	}
+
+	res, err := h.service.GetRoute(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+
+	switch extension {
+	case "geojson":
+		c.Header("Content-Type", geoJSONContentType)
+		c.Header("Content-Disposition", `inline; filename="route-`+id+`.geojson"`)
+		c.JSON(http.StatusOK, geoExport.NewFeatureCollection(*res))
+		return
+	case "gpx":
+		var body bytes.Buffer
+		if err := geoExport.WriteGPX(&body, res); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="route-`+id+`.gpx"`)
+		c.Data(http.StatusOK, "application/gpx+xml", body.Bytes())
+		return
+	}
+
+	if setETag(c, res.Version) {
+		return
+	}
 	c.JSON(http.StatusOK, res)
 }
 
@@ -43,14 +93,81 @@ func (h *RouteHandler) NewRoute(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	res, err := h.service.CreateRoute(req)
+	res, err := h.service.CreateRoute(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, route.ErrInvalidScheduledDate), errors.Is(err, zone.ErrDepotNotInZone):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, zone.ErrNoDepot),
+			errors.Is(err, carDriver.ErrDriverUnavailable), errors.Is(err, vehicle.ErrVehicleInMaintenance):
+			// The zone or depot does not exist, or the zone has no depot
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
+	c.Header("ETag", etag(res.Version))
 	c.JSON(http.StatusCreated, res)
 }
 
+func (h *RouteHandler) UpdateRoute(c *gin.Context) {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	req := &route.UpdateRoute{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.service.UpdateRoute(c.Request.Context(), c.Param("id"), version, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, route.ErrVersionConflict):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		case errors.Is(err, route.ErrInvalidStatus), errors.Is(err, route.ErrInvalidScheduledDate),
+			errors.Is(err, route.ErrOdometerWithoutStatus), errors.Is(err, vehicle.ErrInvalidOdometer):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, route.ErrInvalidStatusTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, vehicle.ErrOdometerDecreased):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, carDriver.ErrDriverUnavailable), errors.Is(err, vehicle.ErrVehicleInMaintenance):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.Header("ETag", etag(res.Version))
+	c.JSON(http.StatusOK, res)
+}
+
+// GetRoutesGeoJSON exports every route scheduled for a day as a single
+// GeoJSON FeatureCollection.
+func (h *RouteHandler) GetRoutesGeoJSON(c *gin.Context) {
+	query := &routesByDateQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.service.GetRoutesByDate(c.Request.Context(), query.Date)
+	if err != nil {
+		if errors.Is(err, route.ErrInvalidScheduledDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", geoJSONContentType)
+	c.Header("Content-Disposition", `inline; filename="routes-`+query.Date+`.geojson"`)
+	c.JSON(http.StatusOK, geoExport.NewFeatureCollection(res...))
+}
+
 // static functions
 
 func NewRouteHandler(routeService route.Service) *RouteHandler {
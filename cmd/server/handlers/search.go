@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"challenge-fravega/internal/search"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	service search.Service
+}
+
+func (h *SearchHandler) SetupRoutes(router *gin.Engine) {
+	router.GET("/search", h.Search)
+}
+
+func (h *SearchHandler) Search(c *gin.Context) {
+	query := &search.Query{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.Search(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, search.ErrQueryTooShort) || errors.Is(err, search.ErrInvalidEntityType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// static functions
+
+func NewSearchHandler(service search.Service) *SearchHandler {
+	return &SearchHandler{service: service}
+}
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"challenge-fravega/internal/vehicle"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type VehicleHandler struct {
@@ -13,8 +15,14 @@ type VehicleHandler struct {
 }
 
 func (h *VehicleHandler) SetupRoutes(router *gin.Engine) {
+	router.GET("/vehicles/maintenance-alerts", h.GetMaintenanceAlerts)
 	router.GET("/vehicles/:id", h.GetVehicle)
 	router.GET("/vehicles", h.GetVehicles)
+	router.GET("/vehicles/:id/maintenance", h.GetMaintenances)
+	router.POST("/vehicles/:id/maintenance", h.NewMaintenance)
+	router.PATCH("/vehicles/:id/maintenance/:maintenanceId", h.UpdateMaintenance)
+	router.GET("/vehicles/:id/odometer", h.GetOdometerReadings)
+	router.POST("/vehicles/:id/odometer", h.NewOdometerReading)
 }
 
 func (h *VehicleHandler) GetVehicle(c *gin.Context) {
@@ -25,7 +33,7 @@ func (h *VehicleHandler) GetVehicle(c *gin.Context) {
 		return
 	}
 
-	vehicle, err := h.service.GetVehicle(uuid)
+	vehicle, err := h.service.GetVehicle(c.Request.Context(), uuid)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -35,7 +43,7 @@ func (h *VehicleHandler) GetVehicle(c *gin.Context) {
 }
 
 func (h *VehicleHandler) GetVehicles(c *gin.Context) {
-	vehicles, err := h.service.GetVehicles()
+	vehicles, err := h.service.GetVehicles(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -44,8 +52,132 @@ func (h *VehicleHandler) GetVehicles(c *gin.Context) {
 	c.JSON(http.StatusOK, vehicles)
 }
 
+// GetMaintenanceAlerts lists the vehicles due for maintenance by date or distance.
+func (h *VehicleHandler) GetMaintenanceAlerts(c *gin.Context) {
+	alerts, err := h.service.GetMaintenanceAlerts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+func (h *VehicleHandler) GetMaintenances(c *gin.Context) {
+	id, ok := parseVehicleID(c)
+	if !ok {
+		return
+	}
+
+	maintenances, err := h.service.GetMaintenances(c.Request.Context(), id)
+	if err != nil {
+		vehicleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, maintenances)
+}
+
+func (h *VehicleHandler) NewMaintenance(c *gin.Context) {
+	id, ok := parseVehicleID(c)
+	if !ok {
+		return
+	}
+	req := &vehicle.CreateMaintenance{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	maintenance, err := h.service.ScheduleMaintenance(c.Request.Context(), id, req)
+	if err != nil {
+		vehicleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, maintenance)
+}
+
+// UpdateMaintenance reschedules, completes or cancels a scheduled maintenance.
+func (h *VehicleHandler) UpdateMaintenance(c *gin.Context) {
+	id, ok := parseVehicleID(c)
+	if !ok {
+		return
+	}
+	maintenanceID, err := uuid.Parse(c.Param("maintenanceId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req := &vehicle.UpdateMaintenance{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	maintenance, err := h.service.UpdateMaintenance(c.Request.Context(), id, maintenanceID, req)
+	if err != nil {
+		vehicleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, maintenance)
+}
+
+func (h *VehicleHandler) GetOdometerReadings(c *gin.Context) {
+	id, ok := parseVehicleID(c)
+	if !ok {
+		return
+	}
+
+	readings, err := h.service.GetOdometerReadings(c.Request.Context(), id)
+	if err != nil {
+		vehicleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, readings)
+}
+
+func (h *VehicleHandler) NewOdometerReading(c *gin.Context) {
+	id, ok := parseVehicleID(c)
+	if !ok {
+		return
+	}
+	req := &vehicle.RecordOdometer{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reading, err := h.service.RecordOdometer(c.Request.Context(), id, req)
+	if err != nil {
+		vehicleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, reading)
+}
+
 // static functions
 
+func parseVehicleID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func vehicleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, vehicle.ErrInvalidMaintenance), errors.Is(err, vehicle.ErrInvalidDate), errors.Is(err, vehicle.ErrInvalidOdometer):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, vehicle.ErrInvalidMaintenanceTransition), errors.Is(err, vehicle.ErrOdometerDecreased):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func NewVehicleHandler(service vehicle.Service) *VehicleHandler {
 	return &VehicleHandler{service: service}
 }
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"challenge-fravega/internal/webhook"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	service webhook.Service
+}
+
+func (h *WebhookHandler) SetupRoutes(router *gin.Engine) {
+	router.Group("/webhooks").
+		GET("", h.GetSubscriptions).
+		POST("", h.NewSubscription).
+		GET("/deliveries", h.GetDeliveries).
+		POST("/deliveries/:id/replay", h.ReplayDelivery).
+		GET("/:id", h.GetSubscription).
+		PATCH("/:id", h.UpdateSubscription).
+		DELETE("/:id", h.DeleteSubscription)
+}
+
+func (h *WebhookHandler) GetSubscriptions(c *gin.Context) {
+	res, err := h.service.GetSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	res, err := h.service.GetSubscription(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *WebhookHandler) NewSubscription(c *gin.Context) {
+	create := &webhook.CreateSubscription{}
+	if err := c.ShouldBindJSON(create); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.CreateSubscription(c.Request.Context(), create)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	update := &webhook.UpdateSubscription{}
+	if err := c.ShouldBindJSON(update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.UpdateSubscription(c.Request.Context(), c.Param("id"), update)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	if err := h.service.DeleteSubscription(c.Request.Context(), c.Param("id")); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	filter := &webhook.DeliveryFilter{}
+	if err := c.ShouldBindQuery(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.GetDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// ReplayDelivery queues a delivered or dead-lettered event to be sent again.
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	res, err := h.service.ReplayDelivery(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, res)
+}
+
+// static functions
+
+func NewWebhookHandler(service webhook.Service) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+func webhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, webhook.ErrInvalidSubscription), errors.Is(err, webhook.ErrInvalidDeliveryStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, webhook.ErrDeliveryPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
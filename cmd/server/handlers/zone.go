@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"challenge-fravega/internal/zone"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ZoneHandler struct {
+	service zone.Service
+}
+
+func (h *ZoneHandler) SetupRoutes(router *gin.Engine) {
+	router.GET("/zones", h.GetZones)
+	router.GET("/zones/:id", h.GetZone)
+	router.POST("/zones/import", h.ImportZones)
+	router.GET("/depots", h.GetDepots)
+	router.GET("/depots/:id", h.GetDepot)
+	router.POST("/depots", h.NewDepot)
+}
+
+func (h *ZoneHandler) GetZones(c *gin.Context) {
+	zones, err := h.service.GetZones(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, zones)
+}
+
+func (h *ZoneHandler) GetZone(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	zone, err := h.service.GetZone(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, zone)
+}
+
+// ImportZones creates a zone for every feature of a GeoJSON document, named
+// after the feature's name property.
+func (h *ZoneHandler) ImportZones(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	zones, err := h.service.ImportZones(c.Request.Context(), body)
+	if err != nil {
+		if errors.Is(err, zone.ErrInvalidGeometry) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, zones)
+}
+
+func (h *ZoneHandler) GetDepots(c *gin.Context) {
+	depots, err := h.service.GetDepots(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, depots)
+}
+
+func (h *ZoneHandler) GetDepot(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	depot, err := h.service.GetDepot(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, depot)
+}
+
+func (h *ZoneHandler) NewDepot(c *gin.Context) {
+	req := &zone.CreateDepot{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	depot, err := h.service.CreateDepot(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, zone.ErrInvalidDepot), errors.Is(err, zone.ErrInvalidOperatingHours):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, zone.ErrDepotOutsideZone):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, depot)
+}
+
+// static functions
+
+func NewZoneHandler(service zone.Service) *ZoneHandler {
+	return &ZoneHandler{service: service}
+}
@@ -2,73 +2,273 @@ package main
 
 import (
 	"challenge-fravega/cmd/server/handlers"
-	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/cmd/server/middleware"
+	"challenge-fravega/internal/audit"
+	"challenge-fravega/internal/config"
+	"challenge-fravega/internal/container"
 	"challenge-fravega/internal/database"
-	"challenge-fravega/internal/route"
-	routePoint "challenge-fravega/internal/route-point"
-	"challenge-fravega/internal/vehicle"
+	"challenge-fravega/internal/health"
+	"challenge-fravega/internal/metrics"
+	"challenge-fravega/internal/request"
+	"challenge-fravega/internal/tracing"
+	"context"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	// The alpine image has no time zone database
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 )
 
 func main() {
+	// Configuration, from the defaults, a file, the environment and flags
+	cfg, options, err := config.Load(os.Args[1:], os.LookupEnv)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if options.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalf("Failed to print configuration: %v", err)
+		}
+		return
+	}
+
+	// Structured logs, with the request ID of the records logged within a
+	// request. The log package writes through them as well.
+	slog.SetDefault(newLogger(cfg.Log))
+	if _, ok := os.LookupEnv(gin.EnvGinMode); !ok && cfg.Log.LogLevel() > slog.LevelDebug {
+		// Gin only prints its routes and warnings, as plain text, in debug mode
+		gin.SetMode(gin.ReleaseMode)
+	}
+	if options.File != "" {
+		slog.Info("Loaded configuration", "file", options.File)
+	}
+
+	// Traces, exported through OpenTelemetry and propagated with W3C trace
+	// context
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    tracing.Exporter(cfg.Tracing.Exporter),
+		Endpoint:    cfg.Tracing.Endpoint,
+		File:        cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
 	// Dependencies
-	db := openConnectionDb()
+	db := openConnectionDb(cfg.Database)
+	if err := db.Use(tracing.NewGormPlugin(cfg.Database.Driver)); err != nil {
+		log.Fatalf("Failed to register tracing callbacks: %v", err)
+	}
 
-	// Run migrations
-	migrationsDir := getEnv("MIGRATIONS_DIR", "./db/migrations")
-	if err := database.MigrateDB(db, migrationsDir); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// Metrics of the requests, the queries, the order system and the domain
+	serverMetrics := metrics.NewMetrics()
+	if err := db.Use(serverMetrics.GormPlugin()); err != nil {
+		log.Fatalf("Failed to register metrics callbacks: %v", err)
 	}
+	serverMetrics.Register(metrics.NewDomainCollector(db))
+
+	// Run migrations, when not left to the migrate command
+	if cfg.Database.AutoMigrate {
+		if err := database.MigrateDB(db, cfg.Database.Migrations(), cfg.Database.MigrationLockTimeout.Duration); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+	} else if pending, err := database.PendingMigrations(db, cfg.Database.Migrations()); err != nil {
+		log.Fatalf("Failed to check migrations: %v", err)
+	} else if len(pending) > 0 {
+		slog.Warn("Pending migrations, not ready until applied with the migrate command", "migrations", pending)
+	}
+
+	// Readiness of the server and its dependencies
+	readiness := health.NewHealth(cfg.HTTP.ReadinessTimeout.Duration)
+	readiness.Add("database", health.Database(db))
+	readiness.Add("migrations", health.Migrations(db, cfg.Database.Migrations()))
 
-	// Repositories
-	routeRepository := route.NewRepository(db)
-	routePointRepository := routePoint.NewRepository(db)
-	carDriverRepository := carDriver.NewRepository(db)
-	vehicleRepository := vehicle.NewRepository(db)
+	// Audit every change to the domain tables
+	if err := audit.Register(db,
+		audit.EntityList[audit.EntityRoute],
+		audit.EntityList[audit.EntityRoutePoint],
+		audit.EntityList[audit.EntityVehicle],
+		audit.EntityList[audit.EntityDriver],
+	); err != nil {
+		log.Fatalf("Failed to register audit callbacks: %v", err)
+	}
 
-	// Services
-	carDriverService := carDriver.NewService(carDriverRepository)
-	vehicleService := vehicle.NewService(vehicleRepository)
-	routePointService := routePoint.NewService(routePointRepository)
-	routeService := route.NewService(routeRepository)
+	// Repositories, clients and services
+	deps, err := container.New(cfg, db, serverMetrics)
+	if err != nil {
+		log.Fatalf("Failed to create services: %v", err)
+	}
+	if deps.PurchaseOrders != nil {
+		readiness.Add("purchase_order", health.Upstream(deps.PurchaseOrders))
+	}
+	services := deps.Services
 
 	// Handlers
-	routeHandler := handlers.NewRouteHandler(routeService)
-	routePointHandler := handlers.NewRoutePointHandler(routePointService)
-	carDriverHandler := handlers.NewCarDriverHandler(carDriverService)
-	vehicleHandler := handlers.NewVehicleHandler(vehicleService)
+	routeHandler := handlers.NewRouteHandler(services.Route)
+	routePointHandler := handlers.NewRoutePointHandler(services.RoutePoint)
+	carDriverHandler := handlers.NewCarDriverHandler(services.CarDriver)
+	vehicleHandler := handlers.NewVehicleHandler(services.Vehicle)
+	searchHandler := handlers.NewSearchHandler(services.Search)
+	auditHandler := handlers.NewAuditHandler(services.Audit)
+	manifestHandler := handlers.NewManifestHandler(services.Manifest)
+	zoneHandler := handlers.NewZoneHandler(services.Zone)
+	reportHandler := handlers.NewReportHandler(services.Reporting)
+	jobHandler := handlers.NewJobHandler(services.Job)
+	webhookHandler := handlers.NewWebhookHandler(services.Webhook)
+	notificationHandler := handlers.NewNotificationHandler(services.Notification)
+	healthHandler := handlers.NewHealthHandler(readiness)
+	metricsHandler := handlers.NewMetricsHandler(serverMetrics)
 
-	app := gin.Default()
+	app := gin.New()
+	app.Use(middleware.Tracing())
+	app.Use(middleware.RequestContext())
+	app.Use(middleware.Logger(slog.Default()))
+	// Metrics wraps Recovery so that the requests that panic count as 500s
+	app.Use(middleware.Metrics(serverMetrics))
+	app.Use(middleware.Recovery(slog.Default()))
+	if len(cfg.HTTP.CORS.AllowedOrigins) > 0 {
+		app.Use(middleware.CORS(cfg.HTTP.CORS))
+	}
+	routeTimeouts, _ := cfg.HTTP.Timeouts()
+	app.Use(middleware.Timeout(cfg.HTTP.RequestTimeout.Duration, routeTimeouts))
+	if cfg.Features.Idempotency {
+		app.Use(middleware.Idempotency(services.Idempotency))
+	}
 
 	// Routes
 	routeHandler.SetupRoutes(app)
 	routePointHandler.SetupRoutes(app)
 	carDriverHandler.SetupRoutes(app)
 	vehicleHandler.SetupRoutes(app)
+	searchHandler.SetupRoutes(app)
+	auditHandler.SetupRoutes(app)
+	manifestHandler.SetupRoutes(app)
+	zoneHandler.SetupRoutes(app)
+	reportHandler.SetupRoutes(app)
+	jobHandler.SetupRoutes(app)
+	if cfg.Features.Webhooks {
+		webhookHandler.SetupRoutes(app)
+	}
+	notificationHandler.SetupRoutes(app)
+	healthHandler.SetupRoutes(app)
+	metricsHandler.SetupRoutes(app)
+
+	// Background workers stop once the server is shut down, finishing the work
+	// in flight
+	workers := &sync.WaitGroup{}
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	if cfg.Features.Workers {
+		deps.StartWorkers(workersCtx, workers)
+	}
+
+	// Stop on SIGINT or SIGTERM, draining the requests in flight and then the
+	// background workers before closing the database
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	port := getEnv("PORT", "8080")
-	if err := app.Run(":" + port); err != nil {
+	server := &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.HTTP.Port),
+		Handler:           app,
+		ReadTimeout:       cfg.HTTP.ReadTimeout.Duration,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout.Duration,
+		WriteTimeout:      cfg.HTTP.WriteTimeout.Duration,
+		IdleTimeout:       cfg.HTTP.IdleTimeout.Duration,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		if cfg.HTTP.TLS.CertFile != "" {
+			slog.Info("Listening", "addr", server.Addr, "tls", true)
+			serverErr <- server.ListenAndServeTLS(cfg.HTTP.TLS.CertFile, cfg.HTTP.TLS.KeyFile)
+		} else {
+			slog.Info("Listening", "addr", server.Addr, "tls", false)
+			serverErr <- server.ListenAndServe()
+		}
+	}()
+	select {
+	case err := <-serverErr:
 		log.Fatalf("Failed to start server: %v", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	// Keep serving while not ready, for load balancers to stop sending
+	// requests before connections are refused
+	slog.Info("Draining", "delay", cfg.HTTP.DrainDelay.String())
+	readiness.Drain()
+	time.Sleep(cfg.HTTP.DrainDelay.Duration)
+
+	slog.Info("Shutting down", "timeout", cfg.HTTP.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout.Duration)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to drain requests", "error", err)
+	}
+	stopWorkers()
+	if err := waitWorkers(shutdownCtx, workers); err != nil {
+		// Workers still running keep using the database until the process
+		// exits, so it is left open
+		slog.Error("Failed to drain background workers, leaving the database open", "error", err)
+	} else {
+		closeConnectionDb(db)
 	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	slog.Info("Server stopped")
 }
 
-func openConnectionDb() *gorm.DB {
-	dbPath := getEnv("DB_PATH", "./db/data.sqlite")
+// waitWorkers waits for the background workers until ctx is done.
+func waitWorkers(ctx context.Context, workers *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
-	// Ensure directory exists
-	dbDir := filepath.Dir(dbPath)
-	if err := os.MkdirAll(dbDir, os.ModePerm); err != nil {
-		log.Fatalf("Failed to create database directory: %v", err)
+func closeConnectionDb(db *gorm.DB) {
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.Close()
+	}
+	if err != nil {
+		slog.Error("Failed to close database", "error", err)
+		return
+	}
+	slog.Info("Closed database")
+}
+
+func openConnectionDb(cfg config.Database) *gorm.DB {
+	driver := database.Driver(cfg.Driver)
+	if driver == database.DriverSQLite {
+		// Ensure directory exists
+		dbDir := filepath.Dir(cfg.Path)
+		if err := os.MkdirAll(dbDir, os.ModePerm); err != nil {
+			log.Fatalf("Failed to create database directory: %v", err)
+		}
 	}
 
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+	db, err := database.Open(driver, cfg.DataSource(), &gorm.Config{
+		Logger: database.NewLogger(slog.Default(), cfg.SlowQueryThreshold.Duration),
 		NamingStrategy: schema.NamingStrategy{
 			SingularTable: true,
 		},
@@ -77,13 +277,21 @@ func openConnectionDb() *gorm.DB {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	log.Printf("Connected to database at %s", dbPath)
+	if driver == database.DriverSQLite {
+		slog.Info("Connected to database", "driver", cfg.Driver, "path", cfg.Path)
+	} else {
+		slog.Info("Connected to database", "driver", cfg.Driver)
+	}
 	return db
 }
 
-func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// newLogger creates the logger of the server, writing JSON or text records to
+// stdout.
+func newLogger(cfg config.Log) *slog.Logger {
+	options := &slog.HandlerOptions{Level: cfg.LogLevel()}
+	var handler slog.Handler = slog.NewJSONHandler(os.Stdout, options)
+	if cfg.Format == "text" {
+		handler = slog.NewTextHandler(os.Stdout, options)
 	}
-	return fallback
+	return slog.New(request.NewLogHandler(handler))
 }
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"challenge-fravega/internal/idempotency"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// Response headers that are stored and replayed along with the body
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POST requests carrying an Idempotency-Key header safe to
+// retry: the first response is stored and replayed for later requests with
+// the same key and payload. Server errors, timeouts among them, release the
+// key so the request can be retried. Requests completed past their deadline
+// keep their response, as their changes were saved.
+func Idempotency(service idempotency.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+
+		record, err := service.Begin(c.Request.Context(), key, hex.EncodeToString(hash.Sum(nil)))
+		switch {
+		case errors.Is(err, idempotency.ErrInvalidKey):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, idempotency.ErrKeyReused):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, idempotency.ErrRequestInProgress):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if record != nil {
+			for name, value := range record.ResponseHeaders {
+				c.Header(name, value)
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Status(record.ResponseStatus)
+			c.Writer.Write(record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		// The outcome must be stored even if the client has gone away
+		ctx := context.WithoutCancel(c.Request.Context())
+		release := func() {
+			if err := service.Release(ctx, key); err != nil {
+				slog.ErrorContext(ctx, "Failed to release idempotency key", "key", key, "error", err)
+			}
+		}
+		// A panic is recovered further out, after this middleware returns, so
+		// the key is released on the way
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				release()
+				panic(recovered)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			release()
+			return
+		}
+
+		headers := idempotency.Headers{}
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		err = service.Complete(ctx, key, &idempotency.Response{
+			Status:  recorder.Status(),
+			Headers: headers,
+			Body:    recorder.body.Bytes(),
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to store response for idempotency key", "key", key, "error", err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"challenge-fravega/internal/metrics"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels the requests to no route, keeping the label bounded
+const unmatchedRoute = "unmatched"
+
+// Metrics measures every request under its route template.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
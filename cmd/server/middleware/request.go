@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"challenge-fravega/internal/request"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	ActorHeader     = "X-Actor"
+	RequestIDHeader = "X-Request-ID"
+)
+
+// RequestContext stores the caller identity and a request ID in the request
+// context so services and repositories can attribute their changes. The
+// request ID is taken from the client when present and echoed back.
+func RequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, id)
+
+		ctx := request.WithID(c.Request.Context(), id)
+		if actor := c.GetHeader(ActorHeader); actor != "" {
+			ctx = request.WithActor(ctx, actor)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
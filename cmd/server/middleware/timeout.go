@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout sets a deadline on the context of every request, the timeout of its
+// route keyed as "METHOD /route/template" or else the default one. Requests
+// whose handler fails past it, with a server error such as that of a query
+// cut short, get a 504 instead, and whatever their handler writes after it is
+// discarded. Handlers that finish past the deadline all the same, having
+// saved their changes, keep their own response. Routes without a timeout have
+// no deadline.
+func Timeout(timeout time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := timeout
+		if routeTimeout, ok := routes[c.Request.Method+" "+c.FullPath()]; ok {
+			d = routeTimeout
+		}
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		writer := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx, timeout: d, header: c.Writer.Header().Clone()}
+		c.Writer = writer
+
+		c.Next()
+
+		// Handlers that fail past the deadline without writing time out all
+		// the same
+		writer.expired()
+		c.Writer = writer.ResponseWriter
+	}
+}
+
+// timeoutWriter writes the 504 in place of the error of a handler that fails
+// past the deadline of its request.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	timeout  time.Duration
+	header   http.Header
+	timedOut bool
+}
+
+// WriteHeader only sets the status, written along with the body, when it is
+// known whether the request timed out.
+func (w *timeoutWriter) WriteHeader(code int) {
+	if !w.timedOut {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	if !w.expired() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.expired() {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.expired() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// expired tells whether the request timed out, that is the handler is
+// failing with a server error past the deadline, writing the 504 the first
+// time. The headers set by the handler, such as ETag or Location, are dropped.
+func (w *timeoutWriter) expired() bool {
+	if w.timedOut {
+		return true
+	}
+	if w.ResponseWriter.Written() || w.ResponseWriter.Status() < http.StatusInternalServerError ||
+		!errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		return false
+	}
+	w.timedOut = true
+
+	header := w.ResponseWriter.Header()
+	for key := range header {
+		header.Del(key)
+	}
+	for key, values := range w.header {
+		header[key] = values
+	}
+	body, _ := json.Marshal(gin.H{"error": fmt.Sprintf("request timed out after %s", w.timeout)})
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	w.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+	w.ResponseWriter.Write(body)
+	return true
+}
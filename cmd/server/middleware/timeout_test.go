@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"challenge-fravega/internal/idempotency"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const testTimeout = 20 * time.Millisecond
+
+// newTestTimeoutRouter serves POST /slow with the given handler, run past the
+// deadline of the request
+func newTestTimeoutRouter(handler gin.HandlerFunc, middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Timeout(testTimeout, nil))
+	router.Use(middlewares...)
+	router.POST("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		handler(c)
+	})
+	return router
+}
+
+func TestTimeoutHandlerFailing(t *testing.T) {
+	// Arrange
+	router := newTestTimeoutRouter(func(c *gin.Context) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": c.Request.Context().Err().Error()})
+	})
+	res := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/slow", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusGatewayTimeout, res.Code)
+	assert.JSONEq(t, `{"error":"request timed out after 20ms"}`, res.Body.String())
+}
+
+func TestTimeoutHandlerCompleted(t *testing.T) {
+	// Arrange
+	router := newTestTimeoutRouter(func(c *gin.Context) {
+		c.Header("Location", "/slow/1")
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+	res := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/slow", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, "/slow/1", res.Header().Get("Location"))
+	assert.JSONEq(t, `{"id":"1"}`, res.Body.String())
+}
+
+func TestTimeoutHandlerCompletedKeepsIdempotencyKey(t *testing.T) {
+	// Arrange
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&idempotency.Record{}))
+	calls := 0
+	router := newTestTimeoutRouter(func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	}, Idempotency(idempotency.NewService(idempotency.NewRepository(db), time.Hour)))
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/slow", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	// Act
+	first := send()
+	retry := send()
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,7 @@
+-- Migration: 001_initial_schema (down)
+-- Drop the tables of the initial schema
+
+DROP TABLE IF EXISTS route_point;
+DROP TABLE IF EXISTS route;
+DROP TABLE IF EXISTS vehicle;
+DROP TABLE IF EXISTS driver;
@@ -0,0 +1,66 @@
+-- Migration: 001_initial_schema
+-- Create tables for the initial schema
+
+-- Create Driver table
+CREATE TABLE IF NOT EXISTS driver (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    phone_number VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    address VARCHAR(255) NOT NULL,
+    identification VARCHAR(255) NOT NULL,
+    license_number VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create Vehicle table
+CREATE TABLE IF NOT EXISTS vehicle (
+    id UUID PRIMARY KEY,
+    plate_number VARCHAR(255) UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create Route table
+CREATE TABLE IF NOT EXISTS route (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description VARCHAR(255),
+    status VARCHAR(255) NOT NULL CHECK (status IN ('pending', 'started', 'completed')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id),
+    driver_id UUID NOT NULL REFERENCES driver(id)
+);
+
+-- Create RoutePoint table
+CREATE TABLE IF NOT EXISTS route_point (
+    id UUID PRIMARY KEY,
+    purchase_order_id VARCHAR(255) NOT NULL,
+    status VARCHAR(255) NOT NULL CONSTRAINT route_point_status_check CHECK (status IN ('pending', 'in_route', 'completed')),
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    address VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    route_id UUID NOT NULL REFERENCES route(id)
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_route_status ON route(status);
+CREATE INDEX idx_route_point_route_id ON route_point(route_id);
+CREATE INDEX idx_route_point_status ON route_point(status);
+
+-- With PostGIS, the coordinates of route points are also kept as a geography
+-- point, derived from latitude and longitude, for spatial queries
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'postgis') THEN
+        CREATE EXTENSION IF NOT EXISTS postgis;
+        EXECUTE 'ALTER TABLE route_point ADD COLUMN location geography(Point, 4326)
+            GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography) STORED';
+        EXECUTE 'CREATE INDEX idx_route_point_location ON route_point USING GIST (location)';
+    END IF;
+END
+$$;
@@ -0,0 +1,12 @@
+-- Migration: 003_search_index (down)
+-- The pg_trgm and unaccent extensions are kept, as other schemas of the
+-- database may use them.
+
+DROP TRIGGER IF EXISTS route_search ON route;
+DROP TRIGGER IF EXISTS route_point_search ON route_point;
+DROP TRIGGER IF EXISTS driver_search ON driver;
+DROP TRIGGER IF EXISTS vehicle_search ON vehicle;
+DROP FUNCTION IF EXISTS search_index_sync();
+
+DROP TABLE IF EXISTS search_index;
+DROP FUNCTION IF EXISTS search_fold(TEXT);
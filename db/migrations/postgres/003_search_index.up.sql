@@ -0,0 +1,73 @@
+-- Migration: 003_search_index
+-- Full-text search index over routes, route points, drivers and vehicles.
+
+-- Terms match arbitrary fragments of addresses, plate numbers and names,
+-- ignoring case and diacritics, through a trigram index on the folded text.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent is only stable, as its dictionary could change, which is not the
+-- case here, so it is wrapped to be usable in an index
+CREATE OR REPLACE FUNCTION search_fold(value TEXT) RETURNS TEXT AS $$
+    SELECT lower(unaccent('unaccent'::regdictionary, value))
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE STRICT;
+
+CREATE TABLE IF NOT EXISTS search_index (
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id UUID NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    PRIMARY KEY (entity_type, entity_id)
+);
+
+CREATE INDEX idx_search_index_document ON search_index
+    USING GIN (search_fold(title || ' ' || body) gin_trgm_ops);
+
+-- Keeps the entry of a row up to date. The entity type and the columns of the
+-- title and body are the arguments of the trigger.
+CREATE OR REPLACE FUNCTION search_index_sync() RETURNS TRIGGER AS $$
+DECLARE
+    title TEXT;
+    body TEXT;
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        DELETE FROM search_index WHERE entity_type = TG_ARGV[0] AND entity_id = OLD.id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        title := COALESCE(to_jsonb(NEW) ->> TG_ARGV[1], '');
+        body := CASE WHEN TG_NARGS > 2 THEN COALESCE(to_jsonb(NEW) ->> TG_ARGV[2], '') ELSE '' END;
+        INSERT INTO search_index (entity_type, entity_id, title, body)
+        VALUES (TG_ARGV[0], NEW.id, title, body);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Route: name / description
+CREATE TRIGGER route_search AFTER INSERT OR DELETE OR UPDATE OF id, name, description ON route
+    FOR EACH ROW EXECUTE FUNCTION search_index_sync('route', 'name', 'description');
+
+-- RoutePoint: address / purchase_order_id
+CREATE TRIGGER route_point_search AFTER INSERT OR DELETE OR UPDATE OF id, address, purchase_order_id ON route_point
+    FOR EACH ROW EXECUTE FUNCTION search_index_sync('route_point', 'address', 'purchase_order_id');
+
+-- Driver: name / email
+CREATE TRIGGER driver_search AFTER INSERT OR DELETE OR UPDATE OF id, name, email ON driver
+    FOR EACH ROW EXECUTE FUNCTION search_index_sync('driver', 'name', 'email');
+
+-- Vehicle: plate_number
+CREATE TRIGGER vehicle_search AFTER INSERT OR DELETE OR UPDATE OF id, plate_number ON vehicle
+    FOR EACH ROW EXECUTE FUNCTION search_index_sync('vehicle', 'plate_number');
+
+-- Index the rows that already exist
+INSERT INTO search_index (entity_type, entity_id, title, body)
+SELECT 'route', id, name, COALESCE(description, '') FROM route;
+
+INSERT INTO search_index (entity_type, entity_id, title, body)
+SELECT 'route_point', id, COALESCE(address, ''), purchase_order_id FROM route_point;
+
+INSERT INTO search_index (entity_type, entity_id, title, body)
+SELECT 'driver', id, name, email FROM driver;
+
+INSERT INTO search_index (entity_type, entity_id, title, body)
+SELECT 'vehicle', id, plate_number, '' FROM vehicle;
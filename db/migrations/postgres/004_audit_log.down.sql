@@ -0,0 +1,4 @@
+-- Migration: 004_audit_log (down)
+
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
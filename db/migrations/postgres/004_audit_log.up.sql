@@ -0,0 +1,30 @@
+-- Migration: 004_audit_log
+-- Append-only log of every change to routes, route points, vehicles and drivers
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255),
+    entity VARCHAR(255) NOT NULL,
+    entity_id TEXT NOT NULL,
+    action VARCHAR(255) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    before_snapshot TEXT,
+    after_snapshot TEXT,
+    changes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log(entity, entity_id);
+
+-- Entries can never be modified or removed
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,7 @@
+-- Migration: 005_versioning (down)
+
+DROP TRIGGER IF EXISTS route_point_route_version ON route_point;
+DROP FUNCTION IF EXISTS route_point_route_version();
+
+ALTER TABLE route_point DROP COLUMN version;
+ALTER TABLE route DROP COLUMN version;
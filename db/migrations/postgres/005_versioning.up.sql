@@ -0,0 +1,23 @@
+-- Migration: 005_versioning
+-- Version columns for optimistic concurrency control (exposed as ETags)
+
+ALTER TABLE route ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE route_point ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- A route is returned together with its route points, so any change to its
+-- points is also a new version of the route
+CREATE OR REPLACE FUNCTION route_point_route_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE route SET version = version + 1 WHERE id = NEW.route_id;
+    ELSIF TG_OP = 'UPDATE' THEN
+        UPDATE route SET version = version + 1 WHERE id IN (OLD.route_id, NEW.route_id);
+    ELSE
+        UPDATE route SET version = version + 1 WHERE id = OLD.route_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER route_point_route_version AFTER INSERT OR UPDATE OR DELETE ON route_point
+    FOR EACH ROW EXECUTE FUNCTION route_point_route_version();
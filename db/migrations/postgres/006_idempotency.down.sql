@@ -0,0 +1,3 @@
+-- Migration: 006_idempotency (down)
+
+DROP TABLE IF EXISTS idempotency_record;
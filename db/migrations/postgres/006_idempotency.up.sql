@@ -0,0 +1,15 @@
+-- Migration: 006_idempotency
+-- Responses stored per Idempotency-Key so retried create requests are replayed
+
+CREATE TABLE IF NOT EXISTS idempotency_record (
+    idempotency_key VARCHAR(255) PRIMARY KEY,
+    request_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(255) NOT NULL CHECK (status IN ('processing', 'completed')),
+    response_status INTEGER,
+    response_headers TEXT,
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_idempotency_record_expires_at ON idempotency_record(expires_at);
@@ -0,0 +1,5 @@
+-- Migration: 007_delivery_window
+-- Time window agreed with the customer for each delivery
+
+ALTER TABLE route_point ADD COLUMN delivery_window_start TIMESTAMPTZ;
+ALTER TABLE route_point ADD COLUMN delivery_window_end TIMESTAMPTZ;
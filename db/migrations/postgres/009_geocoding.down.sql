@@ -0,0 +1,6 @@
+-- Migration: 009_geocoding (down)
+
+ALTER TABLE route_point DROP COLUMN location_mismatch;
+ALTER TABLE route_point DROP COLUMN geocode_distance;
+
+DROP TABLE IF EXISTS geocode_cache;
@@ -0,0 +1,15 @@
+-- Migration: 009_geocoding
+-- Cache of geocoded addresses, and the result of checking that the address
+-- and coordinates of each route point agree
+
+CREATE TABLE IF NOT EXISTS geocode_cache (
+    normalized_address TEXT PRIMARY KEY,
+    address TEXT NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Distance in meters between the given coordinates and the geocoded address
+ALTER TABLE route_point ADD COLUMN geocode_distance DOUBLE PRECISION;
+ALTER TABLE route_point ADD COLUMN location_mismatch BOOLEAN NOT NULL DEFAULT FALSE;
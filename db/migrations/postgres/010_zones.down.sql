@@ -0,0 +1,8 @@
+-- Migration: 010_zones (down)
+
+ALTER TABLE route_point DROP COLUMN outside_zone;
+ALTER TABLE route DROP COLUMN depot_id;
+ALTER TABLE route DROP COLUMN zone_id;
+
+DROP TABLE IF EXISTS depot;
+DROP TABLE IF EXISTS zone;
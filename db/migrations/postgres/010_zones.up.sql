@@ -0,0 +1,38 @@
+-- Migration: 010_zones
+-- Service zones, the depots routes start from, and whether route points lie
+-- within the zone of their route
+
+CREATE TABLE IF NOT EXISTS zone (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    -- GeoJSON Polygon or MultiPolygon
+    geometry TEXT NOT NULL,
+    -- Bounding box of the geometry, to rule out most locations cheaply
+    min_latitude DOUBLE PRECISION NOT NULL,
+    min_longitude DOUBLE PRECISION NOT NULL,
+    max_latitude DOUBLE PRECISION NOT NULL,
+    max_longitude DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS depot (
+    id UUID PRIMARY KEY,
+    zone_id UUID NOT NULL REFERENCES zone(id),
+    name VARCHAR(255) NOT NULL,
+    address VARCHAR(255),
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    -- Daily operating hours as HH:MM
+    opens_at VARCHAR(5) NOT NULL,
+    closes_at VARCHAR(5) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_depot_zone_id ON depot(zone_id);
+
+ALTER TABLE route ADD COLUMN zone_id UUID REFERENCES zone(id);
+ALTER TABLE route ADD COLUMN depot_id UUID REFERENCES depot(id);
+
+ALTER TABLE route_point ADD COLUMN outside_zone BOOLEAN NOT NULL DEFAULT FALSE;
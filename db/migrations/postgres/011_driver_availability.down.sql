@@ -0,0 +1,8 @@
+-- Migration: 011_driver_availability (down)
+
+DROP INDEX IF EXISTS idx_route_driver_started_at;
+ALTER TABLE route DROP COLUMN completed_at;
+ALTER TABLE route DROP COLUMN started_at;
+
+DROP TABLE IF EXISTS driver_time_off;
+DROP TABLE IF EXISTS driver_shift;
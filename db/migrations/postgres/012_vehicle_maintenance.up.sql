@@ -0,0 +1,35 @@
+-- Migration: 012_vehicle_maintenance
+-- Workshop visits of vehicles and their odometer readings, to keep vehicles
+-- in maintenance off routes and to tell when they are due for service
+
+ALTER TABLE vehicle ADD COLUMN odometer INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS vehicle_maintenance (
+    id UUID PRIMARY KEY,
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id),
+    -- oil_change, tires, brakes, inspection or repair
+    type VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+    -- Days as YYYY-MM-DD
+    scheduled_date VARCHAR(10) NOT NULL,
+    completed_date VARCHAR(10),
+    -- Kilometres on the odometer when completed
+    odometer INTEGER,
+    notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_vehicle_maintenance_vehicle_id ON vehicle_maintenance(vehicle_id, scheduled_date);
+
+CREATE TABLE IF NOT EXISTS odometer_reading (
+    id UUID PRIMARY KEY,
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id),
+    route_id UUID REFERENCES route(id),
+    kilometers INTEGER NOT NULL,
+    -- route_started, route_completed, maintenance or manual
+    event VARCHAR(20) NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_odometer_reading_vehicle_id ON odometer_reading(vehicle_id, recorded_at);
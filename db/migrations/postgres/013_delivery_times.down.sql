@@ -0,0 +1,10 @@
+-- Migration: 013_delivery_times (down)
+-- Route points arrived or failed fail the rollback, as the status constraint
+-- no longer allows them.
+
+ALTER TABLE route_point DROP COLUMN completed_at;
+ALTER TABLE route_point DROP COLUMN arrived_at;
+
+ALTER TABLE route_point DROP CONSTRAINT route_point_status_check;
+ALTER TABLE route_point ADD CONSTRAINT route_point_status_check
+    CHECK (status IN ('pending', 'in_route', 'completed'));
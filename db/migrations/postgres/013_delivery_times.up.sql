@@ -0,0 +1,11 @@
+-- Migration: 013_delivery_times
+-- When the driver arrived at each stop and when the delivery was completed or
+-- failed, for on-time and dwell time reporting, along with the arrived and
+-- failed statuses.
+
+ALTER TABLE route_point DROP CONSTRAINT route_point_status_check;
+ALTER TABLE route_point ADD CONSTRAINT route_point_status_check
+    CHECK (status IN ('pending', 'in_route', 'arrived', 'completed', 'failed'));
+
+ALTER TABLE route_point ADD COLUMN arrived_at TIMESTAMPTZ;
+ALTER TABLE route_point ADD COLUMN completed_at TIMESTAMPTZ;
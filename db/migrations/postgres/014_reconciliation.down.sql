@@ -0,0 +1,11 @@
+-- Migration: 014_reconciliation (down)
+-- Route points left in the unassigned pool fail the rollback, as route_id is
+-- required again.
+
+DROP INDEX IF EXISTS idx_route_point_pool_date;
+ALTER TABLE route_point DROP COLUMN pool_date;
+ALTER TABLE route_point ALTER COLUMN route_id SET NOT NULL;
+
+DROP TABLE IF EXISTS job_run;
+
+ALTER TABLE route DROP COLUMN needs_review;
@@ -0,0 +1,23 @@
+-- Migration: 014_reconciliation
+-- End of day reconciliation: route points left undelivered go back to an
+-- unassigned pool (no route) for the next day, routes left open are flagged
+-- for review, and every job run is recorded.
+
+ALTER TABLE route ADD COLUMN needs_review BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS job_run (
+    id UUID PRIMARY KEY,
+    job VARCHAR(255) NOT NULL,
+    status VARCHAR(255) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    summary TEXT,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_job_run_job_started_at ON job_run(job, started_at);
+
+ALTER TABLE route_point ALTER COLUMN route_id DROP NOT NULL;
+ALTER TABLE route_point ADD COLUMN pool_date VARCHAR(10);
+
+CREATE INDEX idx_route_point_pool_date ON route_point(pool_date) WHERE route_id IS NULL;
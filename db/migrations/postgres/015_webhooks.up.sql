@@ -0,0 +1,32 @@
+-- Migration: 015_webhooks
+-- Outbound webhooks: subscriptions to route and delivery events, and the
+-- queue of deliveries retried with backoff until accepted or dead-lettered.
+
+CREATE TABLE IF NOT EXISTS webhook_subscription (
+    id UUID PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    id UUID PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscription(id),
+    event_id UUID NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(255) NOT NULL CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_status_code INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX idx_webhook_delivery_status_next_attempt_at ON webhook_delivery(status, next_attempt_at);
+CREATE INDEX idx_webhook_delivery_subscription_id ON webhook_delivery(subscription_id);
@@ -0,0 +1,4 @@
+-- Migration: 017_notifications (down)
+
+DROP TABLE IF EXISTS notification_opt_out;
+DROP TABLE IF EXISTS notification;
@@ -0,0 +1,6 @@
+-- Migration: 018_outbox_failed_events (down)
+
+DROP INDEX IF EXISTS idx_outbox_event_unpublished;
+CREATE INDEX idx_outbox_event_unpublished ON outbox_event(position) WHERE published_at IS NULL;
+
+ALTER TABLE outbox_event DROP COLUMN failed_at;
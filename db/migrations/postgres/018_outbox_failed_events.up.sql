@@ -0,0 +1,8 @@
+-- Migration: 018_outbox_failed_events
+-- Events that failed to publish every attempt are given up on, so that they
+-- no longer hold back the later events of their aggregate.
+
+ALTER TABLE outbox_event ADD COLUMN failed_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS idx_outbox_event_unpublished;
+CREATE INDEX idx_outbox_event_unpublished ON outbox_event(position) WHERE published_at IS NULL AND failed_at IS NULL;
@@ -0,0 +1,8 @@
+-- Migration: 022_worker_leases (down)
+
+DROP INDEX IF EXISTS idx_job_run_scheduled_for;
+ALTER TABLE job_run DROP COLUMN scheduled_for;
+
+ALTER TABLE notification DROP COLUMN locked_until;
+ALTER TABLE webhook_delivery DROP COLUMN locked_until;
+ALTER TABLE outbox_event DROP COLUMN locked_until;
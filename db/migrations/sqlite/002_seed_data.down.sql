@@ -0,0 +1,12 @@
+-- Migration: 002_seed_data (down)
+-- Remove the seed data
+
+DELETE FROM route_point WHERE id IN (
+    '9b7a41f2-4061-44e9-b772-30845c3f9e93',
+    '0c266de7-0a76-4d56-8828-2ac6e485d59a',
+    '53af236a-eccc-4966-a802-3abea337ed4c',
+    '34a58f9e-1860-45ad-9572-cbe6fc8edc3c'
+);
+DELETE FROM route WHERE id IN ('3e609a33-9bf6-4bce-9ed5-a3b1c55e34c7', '356764f7-f984-437f-923d-b673d167d73b');
+DELETE FROM vehicle WHERE id IN ('171f1ef5-1b5b-4fed-a4b4-9b3d2845893c', '98fe6948-7adf-41b4-b096-2250e2ecb8db');
+DELETE FROM driver WHERE id IN ('e3b57a7a-fb4f-45bb-8fa6-81a406c1c596', '609549eb-8c70-40ea-9dc2-d7bb5bcee63e');
@@ -0,0 +1,16 @@
+-- Migration: 003_search_index (down)
+
+DROP TRIGGER IF EXISTS route_search_insert;
+DROP TRIGGER IF EXISTS route_search_update;
+DROP TRIGGER IF EXISTS route_search_delete;
+DROP TRIGGER IF EXISTS route_point_search_insert;
+DROP TRIGGER IF EXISTS route_point_search_update;
+DROP TRIGGER IF EXISTS route_point_search_delete;
+DROP TRIGGER IF EXISTS driver_search_insert;
+DROP TRIGGER IF EXISTS driver_search_update;
+DROP TRIGGER IF EXISTS driver_search_delete;
+DROP TRIGGER IF EXISTS vehicle_search_insert;
+DROP TRIGGER IF EXISTS vehicle_search_update;
+DROP TRIGGER IF EXISTS vehicle_search_delete;
+
+DROP TABLE IF EXISTS search_index;
@@ -0,0 +1,90 @@
+-- Migration: 003_search_index
+-- Full-text search index over routes, route points, drivers and vehicles.
+-- Requires SQLite built with FTS5 (go build -tags sqlite_fts5).
+
+-- The trigram tokenizer matches arbitrary fragments (at least 3 characters)
+-- of addresses, plate numbers and names, ignoring case and diacritics.
+CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+    entity_type UNINDEXED,
+    entity_id UNINDEXED,
+    title,
+    body,
+    tokenize = 'trigram remove_diacritics 1'
+);
+
+-- Route: name / description
+CREATE TRIGGER IF NOT EXISTS route_search_insert AFTER INSERT ON route BEGIN
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route', NEW.id, NEW.name, COALESCE(NEW.description, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_search_update AFTER UPDATE OF id, name, description ON route BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route' AND entity_id = OLD.id;
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route', NEW.id, NEW.name, COALESCE(NEW.description, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_search_delete AFTER DELETE ON route BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route' AND entity_id = OLD.id;
+END;
+
+-- RoutePoint: address / purchase_order_id
+CREATE TRIGGER IF NOT EXISTS route_point_search_insert AFTER INSERT ON route_point BEGIN
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route_point', NEW.id, COALESCE(NEW.address, ''), NEW.purchase_order_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_search_update AFTER UPDATE OF id, address, purchase_order_id ON route_point BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route_point' AND entity_id = OLD.id;
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route_point', NEW.id, COALESCE(NEW.address, ''), NEW.purchase_order_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_search_delete AFTER DELETE ON route_point BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route_point' AND entity_id = OLD.id;
+END;
+
+-- Driver: name / email
+CREATE TRIGGER IF NOT EXISTS driver_search_insert AFTER INSERT ON driver BEGIN
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('driver', NEW.id, NEW.name, NEW.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS driver_search_update AFTER UPDATE OF id, name, email ON driver BEGIN
+    DELETE FROM search_index WHERE entity_type = 'driver' AND entity_id = OLD.id;
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('driver', NEW.id, NEW.name, NEW.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS driver_search_delete AFTER DELETE ON driver BEGIN
+    DELETE FROM search_index WHERE entity_type = 'driver' AND entity_id = OLD.id;
+END;
+
+-- Vehicle: plate_number
+CREATE TRIGGER IF NOT EXISTS vehicle_search_insert AFTER INSERT ON vehicle BEGIN
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('vehicle', NEW.id, NEW.plate_number, '');
+END;
+
+CREATE TRIGGER IF NOT EXISTS vehicle_search_update AFTER UPDATE OF id, plate_number ON vehicle BEGIN
+    DELETE FROM search_index WHERE entity_type = 'vehicle' AND entity_id = OLD.id;
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('vehicle', NEW.id, NEW.plate_number, '');
+END;
+
+CREATE TRIGGER IF NOT EXISTS vehicle_search_delete AFTER DELETE ON vehicle BEGIN
+    DELETE FROM search_index WHERE entity_type = 'vehicle' AND entity_id = OLD.id;
+END;
+
+-- Index the rows that already exist
+INSERT INTO search_index (entity_type, entity_id, title, body)
+SELECT 'route', id, name, COALESCE(description, '') FROM route;
+
+INSERT INTO search_index (entity_type, entity_id, title, body)
+SELECT 'route_point', id, COALESCE(address, ''), purchase_order_id FROM route_point;
+
+INSERT INTO search_index (entity_type, entity_id, title, body)
+SELECT 'driver', id, name, email FROM driver;
+
+INSERT INTO search_index (entity_type, entity_id, title, body)
+SELECT 'vehicle', id, plate_number, '' FROM vehicle;
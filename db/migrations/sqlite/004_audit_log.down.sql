@@ -0,0 +1,5 @@
+-- Migration: 004_audit_log (down)
+
+DROP TRIGGER IF EXISTS audit_log_no_update;
+DROP TRIGGER IF EXISTS audit_log_no_delete;
+DROP TABLE IF EXISTS audit_log;
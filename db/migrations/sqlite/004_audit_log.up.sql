@@ -0,0 +1,26 @@
+-- Migration: 004_audit_log
+-- Append-only log of every change to routes, route points, vehicles and drivers
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255),
+    entity VARCHAR(255) NOT NULL,
+    entity_id TEXT NOT NULL,
+    action VARCHAR(255) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    before_snapshot TEXT,
+    after_snapshot TEXT,
+    changes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log(entity, entity_id);
+
+-- Entries can never be modified or removed
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
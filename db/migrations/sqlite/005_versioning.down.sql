@@ -0,0 +1,8 @@
+-- Migration: 005_versioning (down)
+
+DROP TRIGGER IF EXISTS route_point_route_version_insert;
+DROP TRIGGER IF EXISTS route_point_route_version_update;
+DROP TRIGGER IF EXISTS route_point_route_version_delete;
+
+ALTER TABLE route_point DROP COLUMN version;
+ALTER TABLE route DROP COLUMN version;
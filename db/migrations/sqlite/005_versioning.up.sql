@@ -0,0 +1,19 @@
+-- Migration: 005_versioning
+-- Version columns for optimistic concurrency control (exposed as ETags)
+
+ALTER TABLE route ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE route_point ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- A route is returned together with its route points, so any change to its
+-- points is also a new version of the route
+CREATE TRIGGER IF NOT EXISTS route_point_route_version_insert AFTER INSERT ON route_point BEGIN
+    UPDATE route SET version = version + 1 WHERE id = NEW.route_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_route_version_update AFTER UPDATE ON route_point BEGIN
+    UPDATE route SET version = version + 1 WHERE id IN (OLD.route_id, NEW.route_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_route_version_delete AFTER DELETE ON route_point BEGIN
+    UPDATE route SET version = version + 1 WHERE id = OLD.route_id;
+END;
@@ -0,0 +1,4 @@
+-- Migration: 007_delivery_window (down)
+
+ALTER TABLE route_point DROP COLUMN delivery_window_end;
+ALTER TABLE route_point DROP COLUMN delivery_window_start;
@@ -0,0 +1,5 @@
+-- Migration: 007_delivery_window
+-- Time window agreed with the customer for each delivery
+
+ALTER TABLE route_point ADD COLUMN delivery_window_start DATETIME;
+ALTER TABLE route_point ADD COLUMN delivery_window_end DATETIME;
@@ -0,0 +1,4 @@
+-- Migration: 008_route_schedule (down)
+
+DROP INDEX IF EXISTS idx_route_scheduled_date;
+ALTER TABLE route DROP COLUMN scheduled_date;
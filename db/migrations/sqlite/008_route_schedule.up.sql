@@ -0,0 +1,6 @@
+-- Migration: 008_route_schedule
+-- Day each route is planned for, as YYYY-MM-DD
+
+ALTER TABLE route ADD COLUMN scheduled_date TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_route_scheduled_date ON route(scheduled_date);
@@ -0,0 +1,15 @@
+-- Migration: 009_geocoding
+-- Cache of geocoded addresses, and the result of checking that the address
+-- and coordinates of each route point agree
+
+CREATE TABLE IF NOT EXISTS geocode_cache (
+    normalized_address TEXT PRIMARY KEY,
+    address TEXT NOT NULL,
+    latitude REAL NOT NULL,
+    longitude REAL NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Distance in meters between the given coordinates and the geocoded address
+ALTER TABLE route_point ADD COLUMN geocode_distance REAL;
+ALTER TABLE route_point ADD COLUMN location_mismatch BOOLEAN NOT NULL DEFAULT 0;
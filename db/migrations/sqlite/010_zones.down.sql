@@ -0,0 +1,65 @@
+-- Migration: 010_zones (down)
+-- SQLite cannot drop the zone_id and depot_id columns in place as they are
+-- foreign keys, so route is rebuilt without them and its triggers, and those
+-- of route_point updating it, recreated.
+
+ALTER TABLE route_point DROP COLUMN outside_zone;
+
+DROP TRIGGER IF EXISTS route_point_route_version_insert;
+DROP TRIGGER IF EXISTS route_point_route_version_update;
+DROP TRIGGER IF EXISTS route_point_route_version_delete;
+
+CREATE TABLE route_new (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description VARCHAR(255),
+    status VARCHAR(255) NOT NULL CHECK (status IN ('pending', 'started', 'completed')),
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime('now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (datetime('now')),
+    vehicle_id TEXT NOT NULL,
+    driver_id TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    scheduled_date TEXT,
+    FOREIGN KEY (vehicle_id) REFERENCES vehicle(id),
+    FOREIGN KEY (driver_id) REFERENCES driver(id)
+);
+
+INSERT INTO route_new (id, name, description, status, created_at, updated_at, vehicle_id, driver_id, version, scheduled_date)
+SELECT id, name, description, status, created_at, updated_at, vehicle_id, driver_id, version, scheduled_date
+FROM route;
+
+DROP TABLE route;
+ALTER TABLE route_new RENAME TO route;
+
+CREATE INDEX idx_route_status ON route(status);
+CREATE INDEX idx_route_scheduled_date ON route(scheduled_date);
+
+CREATE TRIGGER IF NOT EXISTS route_search_insert AFTER INSERT ON route BEGIN
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route', NEW.id, NEW.name, COALESCE(NEW.description, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_search_update AFTER UPDATE OF id, name, description ON route BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route' AND entity_id = OLD.id;
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route', NEW.id, NEW.name, COALESCE(NEW.description, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_search_delete AFTER DELETE ON route BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route' AND entity_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_route_version_insert AFTER INSERT ON route_point BEGIN
+    UPDATE route SET version = version + 1 WHERE id = NEW.route_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_route_version_update AFTER UPDATE ON route_point BEGIN
+    UPDATE route SET version = version + 1 WHERE id IN (OLD.route_id, NEW.route_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_route_version_delete AFTER DELETE ON route_point BEGIN
+    UPDATE route SET version = version + 1 WHERE id = OLD.route_id;
+END;
+
+DROP TABLE IF EXISTS depot;
+DROP TABLE IF EXISTS zone;
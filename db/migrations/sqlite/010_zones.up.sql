@@ -0,0 +1,39 @@
+-- Migration: 010_zones
+-- Service zones, the depots routes start from, and whether route points lie
+-- within the zone of their route
+
+CREATE TABLE IF NOT EXISTS zone (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    -- GeoJSON Polygon or MultiPolygon
+    geometry TEXT NOT NULL,
+    -- Bounding box of the geometry, to rule out most locations cheaply
+    min_latitude REAL NOT NULL,
+    min_longitude REAL NOT NULL,
+    max_latitude REAL NOT NULL,
+    max_longitude REAL NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime('now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS depot (
+    id TEXT PRIMARY KEY,
+    zone_id TEXT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    address VARCHAR(255),
+    latitude REAL NOT NULL,
+    longitude REAL NOT NULL,
+    -- Daily operating hours as HH:MM
+    opens_at VARCHAR(5) NOT NULL,
+    closes_at VARCHAR(5) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime('now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (zone_id) REFERENCES zone(id)
+);
+
+CREATE INDEX idx_depot_zone_id ON depot(zone_id);
+
+ALTER TABLE route ADD COLUMN zone_id TEXT REFERENCES zone(id);
+ALTER TABLE route ADD COLUMN depot_id TEXT REFERENCES depot(id);
+
+ALTER TABLE route_point ADD COLUMN outside_zone BOOLEAN NOT NULL DEFAULT 0;
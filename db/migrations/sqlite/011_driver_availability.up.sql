@@ -0,0 +1,38 @@
+-- Migration: 011_driver_availability
+-- Weekly shifts and time off of drivers, and when routes were driven, to
+-- check drivers are available and within their driving limits
+
+CREATE TABLE IF NOT EXISTS driver_shift (
+    id TEXT PRIMARY KEY,
+    driver_id TEXT NOT NULL,
+    -- 0 (Sunday) to 6 (Saturday)
+    weekday INTEGER NOT NULL,
+    -- Hours as HH:MM
+    starts_at VARCHAR(5) NOT NULL,
+    ends_at VARCHAR(5) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime('now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (driver_id) REFERENCES driver(id)
+);
+
+CREATE INDEX idx_driver_shift_driver_id ON driver_shift(driver_id);
+
+CREATE TABLE IF NOT EXISTS driver_time_off (
+    id TEXT PRIMARY KEY,
+    driver_id TEXT NOT NULL,
+    -- Days as YYYY-MM-DD, both included
+    start_date VARCHAR(10) NOT NULL,
+    end_date VARCHAR(10) NOT NULL,
+    reason TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested',
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime('now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (driver_id) REFERENCES driver(id)
+);
+
+CREATE INDEX idx_driver_time_off_driver_id ON driver_time_off(driver_id, start_date);
+
+ALTER TABLE route ADD COLUMN started_at TIMESTAMP;
+ALTER TABLE route ADD COLUMN completed_at TIMESTAMP;
+
+CREATE INDEX idx_route_driver_started_at ON route(driver_id, started_at);
@@ -0,0 +1,6 @@
+-- Migration: 012_vehicle_maintenance (down)
+
+DROP TABLE IF EXISTS odometer_reading;
+DROP TABLE IF EXISTS vehicle_maintenance;
+
+ALTER TABLE vehicle DROP COLUMN odometer;
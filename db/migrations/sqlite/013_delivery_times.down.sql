@@ -0,0 +1,68 @@
+-- Migration: 013_delivery_times (down)
+-- route_point is rebuilt without the arrived and failed statuses, so route
+-- points in either fail the rollback.
+
+DROP TRIGGER IF EXISTS route_point_search_insert;
+DROP TRIGGER IF EXISTS route_point_search_update;
+DROP TRIGGER IF EXISTS route_point_search_delete;
+DROP TRIGGER IF EXISTS route_point_route_version_insert;
+DROP TRIGGER IF EXISTS route_point_route_version_update;
+DROP TRIGGER IF EXISTS route_point_route_version_delete;
+
+CREATE TABLE route_point_new (
+    id TEXT PRIMARY KEY,
+    purchase_order_id VARCHAR(255) NOT NULL,
+    status VARCHAR(255) NOT NULL CHECK (status IN ('pending', 'in_route', 'completed')),
+    latitude REAL NOT NULL,
+    longitude REAL NOT NULL,
+    address VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT (datetime('now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (datetime('now')),
+    route_id TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    delivery_window_start DATETIME,
+    delivery_window_end DATETIME,
+    geocode_distance REAL,
+    location_mismatch BOOLEAN NOT NULL DEFAULT 0,
+    outside_zone BOOLEAN NOT NULL DEFAULT 0,
+    FOREIGN KEY (route_id) REFERENCES route(id)
+);
+
+INSERT INTO route_point_new (id, purchase_order_id, status, latitude, longitude, address, created_at, updated_at,
+    route_id, version, delivery_window_start, delivery_window_end, geocode_distance, location_mismatch, outside_zone)
+SELECT id, purchase_order_id, status, latitude, longitude, address, created_at, updated_at,
+    route_id, version, delivery_window_start, delivery_window_end, geocode_distance, location_mismatch, outside_zone
+FROM route_point;
+
+DROP TABLE route_point;
+ALTER TABLE route_point_new RENAME TO route_point;
+
+CREATE INDEX idx_route_point_route_id ON route_point(route_id);
+CREATE INDEX idx_route_point_status ON route_point(status);
+
+CREATE TRIGGER IF NOT EXISTS route_point_search_insert AFTER INSERT ON route_point BEGIN
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route_point', NEW.id, COALESCE(NEW.address, ''), NEW.purchase_order_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_search_update AFTER UPDATE OF id, address, purchase_order_id ON route_point BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route_point' AND entity_id = OLD.id;
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route_point', NEW.id, COALESCE(NEW.address, ''), NEW.purchase_order_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_search_delete AFTER DELETE ON route_point BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route_point' AND entity_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_route_version_insert AFTER INSERT ON route_point BEGIN
+    UPDATE route SET version = version + 1 WHERE id = NEW.route_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_route_version_update AFTER UPDATE ON route_point BEGIN
+    UPDATE route SET version = version + 1 WHERE id IN (OLD.route_id, NEW.route_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_route_version_delete AFTER DELETE ON route_point BEGIN
+    UPDATE route SET version = version + 1 WHERE id = OLD.route_id;
+END;
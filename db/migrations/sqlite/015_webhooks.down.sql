@@ -0,0 +1,4 @@
+-- Migration: 015_webhooks (down)
+
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook_subscription;
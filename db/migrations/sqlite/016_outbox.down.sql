@@ -0,0 +1,3 @@
+-- Migration: 016_outbox (down)
+
+DROP TABLE IF EXISTS outbox_event;
@@ -0,0 +1,21 @@
+-- Migration: 016_outbox
+-- Domain events of routes and route points, recorded in the same transaction
+-- as the change they describe and published in the background. position
+-- orders the events as they were recorded.
+
+CREATE TABLE IF NOT EXISTS outbox_event (
+    position INTEGER PRIMARY KEY AUTOINCREMENT,
+    id TEXT NOT NULL UNIQUE,
+    aggregate_type VARCHAR(255) NOT NULL CHECK (aggregate_type IN ('route', 'route_point')),
+    aggregate_id TEXT NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_event_unpublished ON outbox_event(position) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_event_aggregate ON outbox_event(aggregate_type, aggregate_id);
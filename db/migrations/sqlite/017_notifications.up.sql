@@ -0,0 +1,33 @@
+-- Migration: 017_notifications
+-- Customer notifications: messages queued as route points change status,
+-- sent in the background and retried until taken or out of attempts, and the
+-- purchase orders whose customers opted out of them.
+
+CREATE TABLE IF NOT EXISTS notification (
+    id TEXT PRIMARY KEY,
+    event_id TEXT NOT NULL,
+    route_point_id TEXT NOT NULL,
+    purchase_order_id VARCHAR(255) NOT NULL,
+    template VARCHAR(255) NOT NULL CHECK (template IN ('out_for_delivery', 'next_stop', 'delivered', 'failed_attempt')),
+    channel VARCHAR(255) NOT NULL CHECK (channel IN ('email', 'sms')),
+    recipient VARCHAR(255) NOT NULL,
+    locale VARCHAR(8) NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    status VARCHAR(255) NOT NULL CHECK (status IN ('pending', 'sent', 'failed', 'cancelled')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notification_status_next_attempt_at ON notification(status, next_attempt_at);
+CREATE INDEX idx_notification_event_id ON notification(event_id);
+CREATE INDEX idx_notification_purchase_order_id ON notification(purchase_order_id);
+
+CREATE TABLE IF NOT EXISTS notification_opt_out (
+    purchase_order_id VARCHAR(255) PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
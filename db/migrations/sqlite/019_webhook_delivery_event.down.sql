@@ -0,0 +1,3 @@
+-- Migration: 019_webhook_delivery_event (down)
+
+DROP INDEX IF EXISTS idx_webhook_delivery_event;
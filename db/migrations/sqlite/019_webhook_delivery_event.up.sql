@@ -0,0 +1,5 @@
+-- Migration: 019_webhook_delivery_event
+-- The outbox looks up the deliveries of every event it publishes, to fan it
+-- out to the subscribers only once.
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_event ON webhook_delivery(event_id);
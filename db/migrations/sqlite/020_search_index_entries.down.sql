@@ -0,0 +1,80 @@
+-- Migration: 020_search_index_entries (down)
+
+DROP TRIGGER IF EXISTS route_search_insert;
+DROP TRIGGER IF EXISTS route_search_update;
+DROP TRIGGER IF EXISTS route_search_delete;
+DROP TRIGGER IF EXISTS route_point_search_insert;
+DROP TRIGGER IF EXISTS route_point_search_update;
+DROP TRIGGER IF EXISTS route_point_search_delete;
+DROP TRIGGER IF EXISTS driver_search_insert;
+DROP TRIGGER IF EXISTS driver_search_update;
+DROP TRIGGER IF EXISTS driver_search_delete;
+DROP TRIGGER IF EXISTS vehicle_search_insert;
+DROP TRIGGER IF EXISTS vehicle_search_update;
+DROP TRIGGER IF EXISTS vehicle_search_delete;
+
+DROP TABLE IF EXISTS search_index_entry;
+
+-- Route: name / description
+CREATE TRIGGER IF NOT EXISTS route_search_insert AFTER INSERT ON route BEGIN
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route', NEW.id, NEW.name, COALESCE(NEW.description, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_search_update AFTER UPDATE OF id, name, description ON route BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route' AND entity_id = OLD.id;
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route', NEW.id, NEW.name, COALESCE(NEW.description, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_search_delete AFTER DELETE ON route BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route' AND entity_id = OLD.id;
+END;
+
+-- RoutePoint: address / purchase_order_id
+CREATE TRIGGER IF NOT EXISTS route_point_search_insert AFTER INSERT ON route_point BEGIN
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route_point', NEW.id, COALESCE(NEW.address, ''), NEW.purchase_order_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_search_update AFTER UPDATE OF id, address, purchase_order_id ON route_point BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route_point' AND entity_id = OLD.id;
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('route_point', NEW.id, COALESCE(NEW.address, ''), NEW.purchase_order_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS route_point_search_delete AFTER DELETE ON route_point BEGIN
+    DELETE FROM search_index WHERE entity_type = 'route_point' AND entity_id = OLD.id;
+END;
+
+-- Driver: name / email
+CREATE TRIGGER IF NOT EXISTS driver_search_insert AFTER INSERT ON driver BEGIN
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('driver', NEW.id, NEW.name, NEW.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS driver_search_update AFTER UPDATE OF id, name, email ON driver BEGIN
+    DELETE FROM search_index WHERE entity_type = 'driver' AND entity_id = OLD.id;
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('driver', NEW.id, NEW.name, NEW.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS driver_search_delete AFTER DELETE ON driver BEGIN
+    DELETE FROM search_index WHERE entity_type = 'driver' AND entity_id = OLD.id;
+END;
+
+-- Vehicle: plate_number
+CREATE TRIGGER IF NOT EXISTS vehicle_search_insert AFTER INSERT ON vehicle BEGIN
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('vehicle', NEW.id, NEW.plate_number, '');
+END;
+
+CREATE TRIGGER IF NOT EXISTS vehicle_search_update AFTER UPDATE OF id, plate_number ON vehicle BEGIN
+    DELETE FROM search_index WHERE entity_type = 'vehicle' AND entity_id = OLD.id;
+    INSERT INTO search_index (entity_type, entity_id, title, body)
+    VALUES ('vehicle', NEW.id, NEW.plate_number, '');
+END;
+
+CREATE TRIGGER IF NOT EXISTS vehicle_search_delete AFTER DELETE ON vehicle BEGIN
+    DELETE FROM search_index WHERE entity_type = 'vehicle' AND entity_id = OLD.id;
+END;
@@ -0,0 +1,120 @@
+-- Migration: 020_search_index_entries
+-- Keys the search index by rowid. Its entity_type and entity_id columns are
+-- UNINDEXED, so the triggers deleting entries by them scanned the whole index
+-- on every write. search_index_entry maps each entity to the rowid of its
+-- entry, which the triggers now update and delete by.
+
+DROP TRIGGER IF EXISTS route_search_insert;
+DROP TRIGGER IF EXISTS route_search_update;
+DROP TRIGGER IF EXISTS route_search_delete;
+DROP TRIGGER IF EXISTS route_point_search_insert;
+DROP TRIGGER IF EXISTS route_point_search_update;
+DROP TRIGGER IF EXISTS route_point_search_delete;
+DROP TRIGGER IF EXISTS driver_search_insert;
+DROP TRIGGER IF EXISTS driver_search_update;
+DROP TRIGGER IF EXISTS driver_search_delete;
+DROP TRIGGER IF EXISTS vehicle_search_insert;
+DROP TRIGGER IF EXISTS vehicle_search_update;
+DROP TRIGGER IF EXISTS vehicle_search_delete;
+
+CREATE TABLE search_index_entry (
+    id INTEGER PRIMARY KEY,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    UNIQUE (entity_type, entity_id)
+);
+
+-- Route: name / description
+CREATE TRIGGER route_search_insert AFTER INSERT ON route BEGIN
+    INSERT INTO search_index_entry (entity_type, entity_id) VALUES ('route', NEW.id);
+    INSERT INTO search_index (rowid, entity_type, entity_id, title, body)
+    VALUES ((SELECT id FROM search_index_entry WHERE entity_type = 'route' AND entity_id = NEW.id), 'route', NEW.id, NEW.name, COALESCE(NEW.description, ''));
+END;
+
+CREATE TRIGGER route_search_update AFTER UPDATE OF id, name, description ON route BEGIN
+    UPDATE search_index_entry SET entity_id = NEW.id WHERE entity_type = 'route' AND entity_id = OLD.id;
+    UPDATE search_index SET entity_id = NEW.id, title = NEW.name, body = COALESCE(NEW.description, '')
+    WHERE rowid = (SELECT id FROM search_index_entry WHERE entity_type = 'route' AND entity_id = NEW.id);
+END;
+
+CREATE TRIGGER route_search_delete AFTER DELETE ON route BEGIN
+    DELETE FROM search_index WHERE rowid = (SELECT id FROM search_index_entry WHERE entity_type = 'route' AND entity_id = OLD.id);
+    DELETE FROM search_index_entry WHERE entity_type = 'route' AND entity_id = OLD.id;
+END;
+
+-- RoutePoint: address / purchase_order_id
+CREATE TRIGGER route_point_search_insert AFTER INSERT ON route_point BEGIN
+    INSERT INTO search_index_entry (entity_type, entity_id) VALUES ('route_point', NEW.id);
+    INSERT INTO search_index (rowid, entity_type, entity_id, title, body)
+    VALUES ((SELECT id FROM search_index_entry WHERE entity_type = 'route_point' AND entity_id = NEW.id), 'route_point', NEW.id, COALESCE(NEW.address, ''), NEW.purchase_order_id);
+END;
+
+CREATE TRIGGER route_point_search_update AFTER UPDATE OF id, address, purchase_order_id ON route_point BEGIN
+    UPDATE search_index_entry SET entity_id = NEW.id WHERE entity_type = 'route_point' AND entity_id = OLD.id;
+    UPDATE search_index SET entity_id = NEW.id, title = COALESCE(NEW.address, ''), body = NEW.purchase_order_id
+    WHERE rowid = (SELECT id FROM search_index_entry WHERE entity_type = 'route_point' AND entity_id = NEW.id);
+END;
+
+CREATE TRIGGER route_point_search_delete AFTER DELETE ON route_point BEGIN
+    DELETE FROM search_index WHERE rowid = (SELECT id FROM search_index_entry WHERE entity_type = 'route_point' AND entity_id = OLD.id);
+    DELETE FROM search_index_entry WHERE entity_type = 'route_point' AND entity_id = OLD.id;
+END;
+
+-- Driver: name / email
+CREATE TRIGGER driver_search_insert AFTER INSERT ON driver BEGIN
+    INSERT INTO search_index_entry (entity_type, entity_id) VALUES ('driver', NEW.id);
+    INSERT INTO search_index (rowid, entity_type, entity_id, title, body)
+    VALUES ((SELECT id FROM search_index_entry WHERE entity_type = 'driver' AND entity_id = NEW.id), 'driver', NEW.id, NEW.name, NEW.email);
+END;
+
+CREATE TRIGGER driver_search_update AFTER UPDATE OF id, name, email ON driver BEGIN
+    UPDATE search_index_entry SET entity_id = NEW.id WHERE entity_type = 'driver' AND entity_id = OLD.id;
+    UPDATE search_index SET entity_id = NEW.id, title = NEW.name, body = NEW.email
+    WHERE rowid = (SELECT id FROM search_index_entry WHERE entity_type = 'driver' AND entity_id = NEW.id);
+END;
+
+CREATE TRIGGER driver_search_delete AFTER DELETE ON driver BEGIN
+    DELETE FROM search_index WHERE rowid = (SELECT id FROM search_index_entry WHERE entity_type = 'driver' AND entity_id = OLD.id);
+    DELETE FROM search_index_entry WHERE entity_type = 'driver' AND entity_id = OLD.id;
+END;
+
+-- Vehicle: plate_number
+CREATE TRIGGER vehicle_search_insert AFTER INSERT ON vehicle BEGIN
+    INSERT INTO search_index_entry (entity_type, entity_id) VALUES ('vehicle', NEW.id);
+    INSERT INTO search_index (rowid, entity_type, entity_id, title, body)
+    VALUES ((SELECT id FROM search_index_entry WHERE entity_type = 'vehicle' AND entity_id = NEW.id), 'vehicle', NEW.id, NEW.plate_number, '');
+END;
+
+CREATE TRIGGER vehicle_search_update AFTER UPDATE OF id, plate_number ON vehicle BEGIN
+    UPDATE search_index_entry SET entity_id = NEW.id WHERE entity_type = 'vehicle' AND entity_id = OLD.id;
+    UPDATE search_index SET entity_id = NEW.id, title = NEW.plate_number, body = ''
+    WHERE rowid = (SELECT id FROM search_index_entry WHERE entity_type = 'vehicle' AND entity_id = NEW.id);
+END;
+
+CREATE TRIGGER vehicle_search_delete AFTER DELETE ON vehicle BEGIN
+    DELETE FROM search_index WHERE rowid = (SELECT id FROM search_index_entry WHERE entity_type = 'vehicle' AND entity_id = OLD.id);
+    DELETE FROM search_index_entry WHERE entity_type = 'vehicle' AND entity_id = OLD.id;
+END;
+
+-- Index the rows that already exist again, under the rowid of their entity
+DELETE FROM search_index;
+
+INSERT INTO search_index_entry (entity_type, entity_id) SELECT 'route', id FROM route;
+INSERT INTO search_index (rowid, entity_type, entity_id, title, body)
+SELECT e.id, 'route', t.id, t.name, COALESCE(t.description, '')
+FROM route t JOIN search_index_entry e ON e.entity_type = 'route' AND e.entity_id = t.id;
+
+INSERT INTO search_index_entry (entity_type, entity_id) SELECT 'route_point', id FROM route_point;
+INSERT INTO search_index (rowid, entity_type, entity_id, title, body)
+SELECT e.id, 'route_point', t.id, COALESCE(t.address, ''), t.purchase_order_id
+FROM route_point t JOIN search_index_entry e ON e.entity_type = 'route_point' AND e.entity_id = t.id;
+
+INSERT INTO search_index_entry (entity_type, entity_id) SELECT 'driver', id FROM driver;
+INSERT INTO search_index (rowid, entity_type, entity_id, title, body)
+SELECT e.id, 'driver', t.id, t.name, t.email
+FROM driver t JOIN search_index_entry e ON e.entity_type = 'driver' AND e.entity_id = t.id;
+
+INSERT INTO search_index_entry (entity_type, entity_id) SELECT 'vehicle', id FROM vehicle;
+INSERT INTO search_index (rowid, entity_type, entity_id, title, body)
+SELECT e.id, 'vehicle', t.id, t.plate_number, ''
+FROM vehicle t JOIN search_index_entry e ON e.entity_type = 'vehicle' AND e.entity_id = t.id;
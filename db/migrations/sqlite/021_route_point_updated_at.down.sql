@@ -0,0 +1,3 @@
+-- Migration: 021_route_point_updated_at (down)
+
+DROP INDEX IF EXISTS idx_route_point_updated_at;
@@ -0,0 +1,5 @@
+-- Migration: 021_route_point_updated_at
+-- The purchase order drift report pages through the route points changed
+-- within its window.
+
+CREATE INDEX IF NOT EXISTS idx_route_point_updated_at ON route_point(updated_at, id);
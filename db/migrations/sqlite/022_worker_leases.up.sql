@@ -0,0 +1,14 @@
+-- Migration: 022_worker_leases
+-- Every instance of the server runs the background workers. An instance
+-- leases the outbox events, webhook deliveries and notifications it is
+-- sending until locked_until, so the others leave them alone, and takes them
+-- over once the lease runs out, if it stopped halfway. Scheduled job runs are
+-- recorded once per job and time they were due, by whichever instance gets
+-- there first.
+
+ALTER TABLE outbox_event ADD COLUMN locked_until TIMESTAMP;
+ALTER TABLE webhook_delivery ADD COLUMN locked_until TIMESTAMP;
+ALTER TABLE notification ADD COLUMN locked_until TIMESTAMP;
+
+ALTER TABLE job_run ADD COLUMN scheduled_for TIMESTAMP;
+CREATE UNIQUE INDEX idx_job_run_scheduled_for ON job_run(job, scheduled_for);
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Entry struct {
+	ID        uint      `gorm:"column:id" json:"id"`
+	Actor     string    `gorm:"column:actor" json:"actor"`
+	RequestID string    `gorm:"column:request_id" json:"request_id"`
+	Entity    string    `gorm:"column:entity" json:"entity"`
+	EntityID  string    `gorm:"column:entity_id" json:"entity_id"`
+	Action    string    `gorm:"column:action" json:"action"`
+	Before    Snapshot  `gorm:"column:before_snapshot" json:"before"`
+	After     Snapshot  `gorm:"column:after_snapshot" json:"after"`
+	Changes   Changes   `gorm:"column:changes" json:"changes"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (Entry) TableName() string {
+	return "audit_log"
+}
+
+type Filter struct {
+	Entity string `form:"entity"`
+	ID     string `form:"id"`
+	Limit  int    `form:"limit"`
+}
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+var ActionList = map[Action]string{
+	ActionCreate: "create",
+	ActionUpdate: "update",
+	ActionDelete: "delete",
+}
+
+type Entity string
+
+const (
+	EntityRoute      Entity = "route"
+	EntityRoutePoint Entity = "route_point"
+	EntityVehicle    Entity = "vehicle"
+	EntityDriver     Entity = "driver"
+)
+
+var EntityList = map[Entity]string{
+	EntityRoute:      "route",
+	EntityRoutePoint: "route_point",
+	EntityVehicle:    "vehicle",
+	EntityDriver:     "driver",
+}
+
+var ErrInvalidEntity = errors.New("invalid entity")
+
+// Snapshot is a row as read from the database, keyed by column name.
+type Snapshot map[string]interface{}
+
+func (s Snapshot) GormDataType() string {
+	return "text"
+}
+
+func (s Snapshot) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+func (s *Snapshot) Scan(value interface{}) error {
+	return scanJSON(value, s)
+}
+
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Changes holds the columns that differ between two snapshots.
+type Changes map[string]Change
+
+func (c Changes) GormDataType() string {
+	return "text"
+}
+
+func (c Changes) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+func (c *Changes) Scan(value interface{}) error {
+	return scanJSON(value, c)
+}
+
+func scanJSON(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return fmt.Errorf("unsupported audit value type %T", value)
+	}
+}
@@ -0,0 +1,263 @@
+package audit
+
+import (
+	"challenge-fravega/internal/request"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const beforeSnapshotKey = "audit:before_snapshot"
+
+// Columns that change on every write and would only add noise to a diff
+var ignoredColumns = map[string]bool{
+	"updated_at": true,
+}
+
+type auditor struct {
+	tables map[string]bool
+}
+
+// Register hooks the audit log into the GORM create, update and delete
+// callbacks for the given tables. Entries are written in the same transaction
+// as the change, so a failure to audit rolls the change back. Statements run
+// through db.Exec bypass these callbacks and are not audited.
+func Register(db *gorm.DB, tables ...string) error {
+	a := &auditor{tables: map[string]bool{}}
+	for _, table := range tables {
+		a.tables[table] = true
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("audit:after_create", a.afterCreate); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("audit:before_update", a.captureBefore); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("audit:after_update", a.afterUpdate); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("audit:before_delete", a.captureBefore); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Register("audit:after_delete", a.afterDelete)
+}
+
+func (a *auditor) audited(db *gorm.DB) bool {
+	return db.Error == nil && !db.DryRun && a.tables[db.Statement.Table]
+}
+
+func (a *auditor) afterCreate(db *gorm.DB) {
+	if !a.audited(db) {
+		return
+	}
+
+	ids := primaryKeys(db)
+	if len(ids) == 0 {
+		return
+	}
+
+	after, err := load(db, ids, false)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	record(db, ActionCreate, nil, after)
+}
+
+func (a *auditor) captureBefore(db *gorm.DB) {
+	if !a.audited(db) {
+		return
+	}
+
+	before, err := load(db, primaryKeys(db), true)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(beforeSnapshotKey, before)
+}
+
+func (a *auditor) afterUpdate(db *gorm.DB) {
+	if !a.audited(db) {
+		return
+	}
+
+	before := capturedBefore(db)
+	if len(before) == 0 {
+		return
+	}
+
+	ids := make([]interface{}, 0, len(before))
+	for id := range before {
+		ids = append(ids, id)
+	}
+
+	after, err := load(db, ids, false)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	record(db, ActionUpdate, before, after)
+}
+
+func (a *auditor) afterDelete(db *gorm.DB) {
+	if !a.audited(db) {
+		return
+	}
+
+	if before := capturedBefore(db); len(before) > 0 {
+		record(db, ActionDelete, before, nil)
+	}
+}
+
+func capturedBefore(db *gorm.DB) map[string]Snapshot {
+	value, ok := db.InstanceGet(beforeSnapshotKey)
+	if !ok {
+		return nil
+	}
+	before, _ := value.(map[string]Snapshot)
+	return before
+}
+
+// record writes one entry per affected row. Updates that didn't change any
+// column are skipped.
+func record(db *gorm.DB, action Action, before, after map[string]Snapshot) {
+	ids := map[string]bool{}
+	for id := range before {
+		ids[id] = true
+	}
+	for id := range after {
+		ids[id] = true
+	}
+
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	ctx := db.Statement.Context
+	var entries []Entry
+	for _, id := range sorted {
+		changes := diff(before[id], after[id])
+		if action == ActionUpdate && len(changes) == 0 {
+			continue
+		}
+		entries = append(entries, Entry{
+			Actor:     request.Actor(ctx),
+			RequestID: request.ID(ctx),
+			Entity:    db.Statement.Table,
+			EntityID:  id,
+			Action:    ActionList[action],
+			Before:    before[id],
+			After:     after[id],
+			Changes:   changes,
+		})
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	// A new session on the same connection pool keeps the write inside the
+	// transaction of the audited statement
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&entries).Error; err != nil {
+		db.AddError(fmt.Errorf("failed to record audit entry: %w", err))
+	}
+}
+
+// load reads the current rows affected by the statement, either by primary
+// key, by the statement's WHERE clause, or both.
+func load(db *gorm.DB, ids []interface{}, scoped bool) (map[string]Snapshot, error) {
+	stmt := db.Statement
+	pk := primaryKeyColumn(db)
+
+	var exprs []clause.Expression
+	if len(ids) > 0 {
+		exprs = append(exprs, clause.IN{Column: clause.Column{Name: pk}, Values: ids})
+	}
+	if scoped {
+		if c, ok := stmt.Clauses["WHERE"]; ok {
+			if where, ok := c.Expression.(clause.Where); ok {
+				exprs = append(exprs, where.Exprs...)
+			}
+		}
+	}
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+
+	var rows []map[string]interface{}
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table(stmt.Table).
+		Clauses(clause.Where{Exprs: exprs}).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load audit snapshot: %w", err)
+	}
+
+	snapshots := make(map[string]Snapshot, len(rows))
+	for _, row := range rows {
+		for column, value := range row {
+			if b, ok := value.([]byte); ok {
+				row[column] = string(b)
+			}
+		}
+		snapshots[fmt.Sprint(row[pk])] = Snapshot(row)
+	}
+	return snapshots, nil
+}
+
+func primaryKeyColumn(db *gorm.DB) string {
+	if db.Statement.Schema != nil && db.Statement.Schema.PrioritizedPrimaryField != nil {
+		return db.Statement.Schema.PrioritizedPrimaryField.DBName
+	}
+	return "id"
+}
+
+// primaryKeys returns the non-zero primary keys of the statement's model.
+func primaryKeys(db *gorm.DB) []interface{} {
+	stmt := db.Statement
+	if stmt.Schema == nil || stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+
+	var ids []interface{}
+	collect := func(value reflect.Value) {
+		if id, zero := field.ValueOf(stmt.Context, value); !zero {
+			ids = append(ids, id)
+		}
+	}
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			collect(reflect.Indirect(stmt.ReflectValue.Index(i)))
+		}
+	case reflect.Struct:
+		collect(stmt.ReflectValue)
+	}
+	return ids
+}
+
+func diff(before, after Snapshot) Changes {
+	changes := Changes{}
+	for column, value := range after {
+		if ignoredColumns[column] || reflect.DeepEqual(before[column], value) {
+			continue
+		}
+		changes[column] = Change{Old: before[column], New: value}
+	}
+	for column, value := range before {
+		if _, ok := after[column]; ok || ignoredColumns[column] || value == nil {
+			continue
+		}
+		changes[column] = Change{Old: value, New: nil}
+	}
+	return changes
+}
@@ -0,0 +1,167 @@
+package audit
+
+import (
+	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/request"
+	"challenge-fravega/internal/vehicle"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type CallbacksTestSuite struct {
+	suite.Suite
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func (suite *CallbacksTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	err = db.AutoMigrate(&Entry{}, &vehicle.Vehicle{}, &carDriver.Driver{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	// Only vehicles are audited, drivers are used to check the table filter
+	if err := Register(db, "vehicles"); err != nil {
+		suite.T().Fatal(err)
+	}
+
+	suite.db = db
+	suite.ctx = request.WithID(request.WithActor(context.Background(), "dispatcher@example.com"), "req-1")
+}
+
+func (suite *CallbacksTestSuite) entries() []Entry {
+	var entries []Entry
+	suite.db.Order("id").Find(&entries)
+	return entries
+}
+
+func (suite *CallbacksTestSuite) TestCreateIsAudited() {
+	// Arrange
+	v := &vehicle.Vehicle{ID: uuid.New(), PlateNumber: "ABC123"}
+
+	// Act
+	err := suite.db.WithContext(suite.ctx).Create(v).Error
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	entries := suite.entries()
+	assert.Len(suite.T(), entries, 1)
+	assert.Equal(suite.T(), "dispatcher@example.com", entries[0].Actor)
+	assert.Equal(suite.T(), "req-1", entries[0].RequestID)
+	assert.Equal(suite.T(), "vehicles", entries[0].Entity)
+	assert.Equal(suite.T(), v.ID.String(), entries[0].EntityID)
+	assert.Equal(suite.T(), ActionList[ActionCreate], entries[0].Action)
+	assert.Nil(suite.T(), entries[0].Before)
+	assert.Equal(suite.T(), "ABC123", entries[0].After["plate_number"])
+	assert.Equal(suite.T(), Change{Old: nil, New: "ABC123"}, entries[0].Changes["plate_number"])
+}
+
+func (suite *CallbacksTestSuite) TestUpdateRecordsDiff() {
+	// Arrange
+	v := &vehicle.Vehicle{ID: uuid.New(), PlateNumber: "OLD123"}
+	suite.db.Create(v)
+	v.PlateNumber = "NEW456"
+
+	// Act
+	err := suite.db.WithContext(suite.ctx).Save(v).Error
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	entries := suite.entries()
+	assert.Len(suite.T(), entries, 2)
+	assert.Equal(suite.T(), request.SystemActor, entries[0].Actor)
+	update := entries[1]
+	assert.Equal(suite.T(), ActionList[ActionUpdate], update.Action)
+	assert.Equal(suite.T(), "OLD123", update.Before["plate_number"])
+	assert.Equal(suite.T(), "NEW456", update.After["plate_number"])
+	assert.Equal(suite.T(), Changes{"plate_number": {Old: "OLD123", New: "NEW456"}}, update.Changes)
+}
+
+func (suite *CallbacksTestSuite) TestUpdateByConditionIsAudited() {
+	// Arrange
+	v := &vehicle.Vehicle{ID: uuid.New(), PlateNumber: "OLD123"}
+	suite.db.Create(v)
+
+	// Act
+	err := suite.db.WithContext(suite.ctx).Model(&vehicle.Vehicle{}).
+		Where("plate_number = ?", "OLD123").
+		Update("plate_number", "NEW456").Error
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	entries := suite.entries()
+	assert.Len(suite.T(), entries, 2)
+	assert.Equal(suite.T(), v.ID.String(), entries[1].EntityID)
+	assert.Equal(suite.T(), Changes{"plate_number": {Old: "OLD123", New: "NEW456"}}, entries[1].Changes)
+}
+
+func (suite *CallbacksTestSuite) TestUnchangedUpdateIsSkipped() {
+	// Arrange
+	v := &vehicle.Vehicle{ID: uuid.New(), PlateNumber: "SAME123"}
+	suite.db.Create(v)
+
+	// Act
+	err := suite.db.WithContext(suite.ctx).Save(v).Error
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), suite.entries(), 1)
+}
+
+func (suite *CallbacksTestSuite) TestDeleteIsAudited() {
+	// Arrange
+	v := &vehicle.Vehicle{ID: uuid.New(), PlateNumber: "DEL123"}
+	suite.db.Create(v)
+
+	// Act
+	err := suite.db.WithContext(suite.ctx).Delete(&vehicle.Vehicle{}, "id = ?", v.ID).Error
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	entries := suite.entries()
+	assert.Len(suite.T(), entries, 2)
+	assert.Equal(suite.T(), ActionList[ActionDelete], entries[1].Action)
+	assert.Equal(suite.T(), "DEL123", entries[1].Before["plate_number"])
+	assert.Nil(suite.T(), entries[1].After)
+}
+
+func (suite *CallbacksTestSuite) TestUnregisteredTableIsIgnored() {
+	// Act
+	err := suite.db.WithContext(suite.ctx).Create(&carDriver.Driver{ID: uuid.New(), Name: "John Doe"}).Error
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), suite.entries())
+}
+
+func (suite *CallbacksTestSuite) TestAuditRunsInChangeTransaction() {
+	// Arrange
+	v := &vehicle.Vehicle{ID: uuid.New(), PlateNumber: "TX123"}
+
+	// Act
+	suite.db.WithContext(suite.ctx).Transaction(func(tx *gorm.DB) error {
+		tx.Create(v)
+		return assert.AnError
+	})
+
+	// Assert
+	var count int64
+	suite.db.Model(&vehicle.Vehicle{}).Count(&count)
+	assert.Zero(suite.T(), count)
+	assert.Empty(suite.T(), suite.entries())
+}
+
+func TestCallbacksSuite(t *testing.T) {
+	suite.Run(t, new(CallbacksTestSuite))
+}
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repository reads the audit log.
+type Repository interface {
+	GetEntries(ctx context.Context, entity string, entityID string, limit int) ([]Entry, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func (r *repository) GetEntries(ctx context.Context, entity string, entityID string, limit int) ([]Entry, error) {
+	var entries []Entry
+	query := r.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	if entityID != "" {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	return entries, query.Find(&entries).Error
+}
+
+// static functions
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
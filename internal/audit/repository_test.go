@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type RepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	repository Repository
+}
+
+func (suite *RepositoryTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	err = db.AutoMigrate(&Entry{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	suite.db = db
+	suite.repository = NewRepository(db)
+
+	suite.db.Create(&[]Entry{
+		{Actor: "alice", Entity: "route", EntityID: "route-1", Action: "create"},
+		{Actor: "bob", Entity: "route_point", EntityID: "point-1", Action: "create"},
+		{Actor: "bob", Entity: "route", EntityID: "route-1", Action: "update",
+			Changes: Changes{"driver_id": {Old: "driver-1", New: "driver-2"}}},
+	})
+}
+
+func (suite *RepositoryTestSuite) TestGetEntriesByEntity() {
+	// Act
+	results, err := suite.repository.GetEntries(context.Background(), "route", "route-1", 10)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 2)
+	assert.Equal(suite.T(), "update", results[0].Action)
+	assert.Equal(suite.T(), "bob", results[0].Actor)
+	assert.Equal(suite.T(), Change{Old: "driver-1", New: "driver-2"}, results[0].Changes["driver_id"])
+	assert.Equal(suite.T(), "create", results[1].Action)
+}
+
+func (suite *RepositoryTestSuite) TestGetEntriesLimit() {
+	// Act
+	results, err := suite.repository.GetEntries(context.Background(), "", "", 1)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 1)
+	assert.Equal(suite.T(), "update", results[0].Action)
+}
+
+func TestRepositorySuite(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
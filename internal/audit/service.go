@@ -0,0 +1,41 @@
+package audit
+
+import "context"
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+type Service interface {
+	GetEntries(ctx context.Context, filter *Filter) ([]Entry, error)
+}
+
+type service struct {
+	repository Repository
+}
+
+// GetEntries returns the most recent audit entries first.
+func (s *service) GetEntries(ctx context.Context, filter *Filter) ([]Entry, error) {
+	if filter.Entity != "" {
+		if _, ok := EntityList[Entity(filter.Entity)]; !ok {
+			return nil, ErrInvalidEntity
+		}
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	return s.repository.GetEntries(ctx, filter.Entity, filter.ID, limit)
+}
+
+// static functions
+
+func NewService(repository Repository) *service {
+	return &service{repository: repository}
+}
@@ -0,0 +1,226 @@
+package carDriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DateLayout is the format of the days of time off and of the calendar
+	DateLayout = time.DateOnly
+	// HourLayout is the format of the start and end of shifts
+	HourLayout = "15:04"
+	// MaxCalendarDays bounds the days of a single availability calendar
+	MaxCalendarDays = 62
+)
+
+// DefaultDrivingLimits are the statutory maximum driving hours of a driver
+var DefaultDrivingLimits = DrivingLimits{Daily: 9 * time.Hour, Weekly: 56 * time.Hour}
+
+var (
+	ErrInvalidShift         = errors.New("invalid shift, weekday must be 0 (Sunday) to 6 and hours formatted as HH:MM, ending after they start")
+	ErrShiftOverlap         = errors.New("shift overlaps another shift of the driver")
+	ErrInvalidDate          = errors.New("invalid date, must be formatted as YYYY-MM-DD")
+	ErrInvalidDateRange     = fmt.Errorf("invalid date range, must end after it starts and span at most %d days", MaxCalendarDays)
+	ErrInvalidTimeOffStatus = errors.New("invalid time off status transition")
+	ErrDriverUnavailable    = errors.New("driver is not available")
+)
+
+// Shift is a weekly recurring period a driver works, on Weekday from StartsAt
+// to EndsAt formatted as HourLayout.
+type Shift struct {
+	ID        uuid.UUID    `gorm:"column:id" json:"id"`
+	DriverID  uuid.UUID    `gorm:"column:driver_id" json:"driver_id"`
+	Weekday   time.Weekday `gorm:"column:weekday" json:"weekday"`
+	StartsAt  string       `gorm:"column:starts_at" json:"starts_at"`
+	EndsAt    string       `gorm:"column:ends_at" json:"ends_at"`
+	CreatedAt time.Time    `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time    `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (Shift) TableName() string {
+	return "driver_shift"
+}
+
+// TimeOff is a request of a driver to be off from StartDate to EndDate, both
+// included. Only approved time off makes the driver unavailable.
+type TimeOff struct {
+	ID        uuid.UUID `gorm:"column:id" json:"id"`
+	DriverID  uuid.UUID `gorm:"column:driver_id" json:"driver_id"`
+	StartDate string    `gorm:"column:start_date" json:"start_date"`
+	EndDate   string    `gorm:"column:end_date" json:"end_date"`
+	Reason    string    `gorm:"column:reason" json:"reason"`
+	Status    string    `gorm:"column:status" json:"status"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (TimeOff) TableName() string {
+	return "driver_time_off"
+}
+
+type TimeOffStatus string
+
+const (
+	TimeOffStatusRequested TimeOffStatus = "requested"
+	TimeOffStatusApproved  TimeOffStatus = "approved"
+	TimeOffStatusRejected  TimeOffStatus = "rejected"
+	TimeOffStatusCancelled TimeOffStatus = "cancelled"
+)
+
+var TimeOffStatusList = map[TimeOffStatus]string{
+	TimeOffStatusRequested: "requested",
+	TimeOffStatusApproved:  "approved",
+	TimeOffStatusRejected:  "rejected",
+	TimeOffStatusCancelled: "cancelled",
+}
+
+// TimeOffStatusTransitions lists the statuses time off can move to from each status
+var TimeOffStatusTransitions = map[TimeOffStatus][]TimeOffStatus{
+	TimeOffStatusRequested: {TimeOffStatusApproved, TimeOffStatusRejected, TimeOffStatusCancelled},
+	TimeOffStatusApproved:  {TimeOffStatusCancelled},
+}
+
+// DrivingLimits are the most a driver may drive in a day and in a week,
+// weeks starting on Monday.
+type DrivingLimits struct {
+	Daily  time.Duration
+	Weekly time.Duration
+}
+
+// DrivingLog reports how long a driver drove within a period, from the routes
+// they started.
+type DrivingLog interface {
+	GetDrivingTime(ctx context.Context, driverID uuid.UUID, from time.Time, to time.Time) (time.Duration, error)
+}
+
+// Availability is a day of the availability calendar of a driver. Reason
+// explains why the driver is not available.
+type Availability struct {
+	Date               string   `json:"date"`
+	Available          bool     `json:"available"`
+	Reason             string   `json:"reason,omitempty"`
+	Shifts             []Shift  `json:"shifts"`
+	TimeOff            *TimeOff `json:"time_off"`
+	DrivingHours       float64  `json:"driving_hours"`
+	WeeklyDrivingHours float64  `json:"weekly_driving_hours"`
+}
+
+func CanTransitionTimeOff(from TimeOffStatus, to TimeOffStatus) bool {
+	for _, status := range TimeOffStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// static functions
+
+// ValidShift tells whether the weekday exists and the shift ends after it
+// starts on the same day.
+func ValidShift(weekday time.Weekday, startsAt string, endsAt string) bool {
+	if weekday < time.Sunday || weekday > time.Saturday {
+		return false
+	}
+	starts, err := time.Parse(HourLayout, startsAt)
+	if err != nil {
+		return false
+	}
+	ends, err := time.Parse(HourLayout, endsAt)
+	if err != nil {
+		return false
+	}
+	return ends.After(starts)
+}
+
+// normalizeHour formats an hour as HourLayout, zero padded, leaving hours
+// that do not parse as they are.
+func normalizeHour(hour string) string {
+	parsed, err := time.Parse(HourLayout, hour)
+	if err != nil {
+		return hour
+	}
+	return parsed.Format(HourLayout)
+}
+
+// NewAvailability works out whether a driver is available on a day, given
+// their shifts, their approved time off and how long they drove that day and
+// week. Drivers without any shift have no schedule yet and may work any day.
+func NewAvailability(day time.Time, shifts []Shift, timeOffs []TimeOff, drivenDay time.Duration, drivenWeek time.Duration, limits DrivingLimits) Availability {
+	date := day.Format(DateLayout)
+	availability := Availability{
+		Date:               date,
+		Shifts:             []Shift{},
+		DrivingHours:       drivenDay.Hours(),
+		WeeklyDrivingHours: drivenWeek.Hours(),
+	}
+	for _, shift := range shifts {
+		if shift.Weekday == day.Weekday() {
+			availability.Shifts = append(availability.Shifts, shift)
+		}
+	}
+	for i, timeOff := range timeOffs {
+		if timeOff.Status == TimeOffStatusList[TimeOffStatusApproved] && timeOff.StartDate <= date && date <= timeOff.EndDate {
+			availability.TimeOff = &timeOffs[i]
+			break
+		}
+	}
+
+	switch {
+	case availability.TimeOff != nil:
+		availability.Reason = "driver is on time off"
+	case len(shifts) > 0 && len(availability.Shifts) == 0:
+		availability.Reason = "driver has no shift on " + day.Weekday().String()
+	case drivenDay >= limits.Daily:
+		availability.Reason = "driver reached the daily driving limit"
+	case drivenWeek >= limits.Weekly:
+		availability.Reason = "driver reached the weekly driving limit"
+	default:
+		availability.Available = true
+	}
+	return availability
+}
+
+// startOfWeek returns the Monday midnight starting the week of a day.
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// parseDay parses a date as midnight in location, today when empty.
+func parseDay(date string, location *time.Location) (time.Time, error) {
+	if date == "" {
+		now := time.Now().In(location)
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location), nil
+	}
+	day, err := time.ParseInLocation(DateLayout, date, location)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return day, nil
+}
+
+// parseDateRange parses the days from and to, both included, spanning at
+// most maxDays when positive.
+func parseDateRange(from string, to string, maxDays int, location *time.Location) (time.Time, time.Time, error) {
+	if from == "" || to == "" {
+		return time.Time{}, time.Time{}, ErrInvalidDate
+	}
+	start, err := parseDay(from, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseDay(to, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if end.Before(start) || (maxDays > 0 && !end.Before(start.AddDate(0, 0, maxDays))) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	return start, end, nil
+}
@@ -0,0 +1,45 @@
+package carDriver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidShift(t *testing.T) {
+	assert.True(t, ValidShift(time.Monday, "08:00", "16:00"))
+	assert.False(t, ValidShift(time.Monday, "16:00", "08:00"))
+	assert.False(t, ValidShift(time.Monday, "8am", "16:00"))
+	assert.False(t, ValidShift(time.Weekday(7), "08:00", "16:00"))
+}
+
+func TestNewAvailability(t *testing.T) {
+	wednesday := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+	shifts := []Shift{
+		{Weekday: time.Wednesday, StartsAt: "08:00", EndsAt: "12:00"},
+		{Weekday: time.Thursday, StartsAt: "08:00", EndsAt: "12:00"},
+	}
+	requested := []TimeOff{{StartDate: "2025-03-12", EndDate: "2025-03-12", Status: "requested"}}
+	approved := []TimeOff{{StartDate: "2025-03-10", EndDate: "2025-03-12", Status: "approved"}}
+
+	available := NewAvailability(wednesday, shifts, requested, 2*time.Hour, 20*time.Hour, DefaultDrivingLimits)
+	assert.True(t, available.Available)
+	assert.Equal(t, "2025-03-12", available.Date)
+	assert.Len(t, available.Shifts, 1)
+	assert.Equal(t, 2.0, available.DrivingHours)
+	assert.Nil(t, available.TimeOff)
+
+	assert.Equal(t, "driver is on time off", NewAvailability(wednesday, shifts, approved, 0, 0, DefaultDrivingLimits).Reason)
+	assert.Equal(t, "driver has no shift on Tuesday", NewAvailability(wednesday.AddDate(0, 0, -1), shifts, nil, 0, 0, DefaultDrivingLimits).Reason)
+	assert.Equal(t, "driver reached the daily driving limit", NewAvailability(wednesday, shifts, nil, 9*time.Hour, 9*time.Hour, DefaultDrivingLimits).Reason)
+	assert.True(t, NewAvailability(wednesday.AddDate(0, 0, -1), nil, nil, 0, 0, DefaultDrivingLimits).Available)
+}
+
+func TestStartOfWeek(t *testing.T) {
+	monday := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, monday, startOfWeek(monday))
+	assert.Equal(t, monday, startOfWeek(time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, monday, startOfWeek(time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,9 @@
+package carDriver
+
+import "time"
+
+type CreateShift struct {
+	Weekday  time.Weekday `json:"weekday"`
+	StartsAt string       `json:"starts_at"`
+	EndsAt   string       `json:"ends_at"`
+}
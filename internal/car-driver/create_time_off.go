@@ -0,0 +1,11 @@
+package carDriver
+
+type CreateTimeOff struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Reason    string `json:"reason"`
+}
+
+type UpdateTimeOff struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,165 @@
+package carDriver
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FakeRepository is an in-memory Repository for the tests of the services
+// built on drivers, their shifts and their time off.
+type FakeRepository struct {
+	mu       sync.Mutex
+	drivers  []Driver
+	shifts   []Shift
+	timeOffs []TimeOff
+}
+
+func (f *FakeRepository) CreateDriver(ctx context.Context, driver *Driver) (*Driver, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if driver.ID == uuid.Nil {
+		driver.ID = uuid.New()
+	}
+	f.drivers = append(f.drivers, *driver)
+	return driver, nil
+}
+
+func (f *FakeRepository) GetDriver(ctx context.Context, id uuid.UUID) (*Driver, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, driver := range f.drivers {
+		if driver.ID == id {
+			return &driver, nil
+		}
+	}
+	return &Driver{}, gorm.ErrRecordNotFound
+}
+
+func (f *FakeRepository) GetDrivers(ctx context.Context) ([]Driver, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Driver{}, f.drivers...), nil
+}
+
+func (f *FakeRepository) UpdateDriver(ctx context.Context, driver *Driver) (*Driver, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.drivers {
+		if f.drivers[i].ID == driver.ID {
+			f.drivers[i] = *driver
+			return driver, nil
+		}
+	}
+	f.drivers = append(f.drivers, *driver)
+	return driver, nil
+}
+
+func (f *FakeRepository) GetShifts(ctx context.Context, driverID uuid.UUID) ([]Shift, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	shifts := []Shift{}
+	for _, shift := range f.shifts {
+		if shift.DriverID == driverID {
+			shifts = append(shifts, shift)
+		}
+	}
+	sort.SliceStable(shifts, func(i, j int) bool {
+		if shifts[i].Weekday != shifts[j].Weekday {
+			return shifts[i].Weekday < shifts[j].Weekday
+		}
+		return shifts[i].StartsAt < shifts[j].StartsAt
+	})
+	return shifts, nil
+}
+
+func (f *FakeRepository) CreateShift(ctx context.Context, shift *Shift) (*Shift, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if shift.ID == uuid.Nil {
+		shift.ID = uuid.New()
+	}
+	f.shifts = append(f.shifts, *shift)
+	return shift, nil
+}
+
+func (f *FakeRepository) DeleteShift(ctx context.Context, driverID uuid.UUID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, shift := range f.shifts {
+		if shift.DriverID == driverID && shift.ID == id {
+			f.shifts = append(f.shifts[:i], f.shifts[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (f *FakeRepository) GetTimeOffs(ctx context.Context, driverID uuid.UUID) ([]TimeOff, error) {
+	return f.findTimeOffs(func(timeOff TimeOff) bool { return timeOff.DriverID == driverID }), nil
+}
+
+func (f *FakeRepository) GetApprovedTimeOffs(ctx context.Context, driverID uuid.UUID, from string, to string) ([]TimeOff, error) {
+	return f.findTimeOffs(func(timeOff TimeOff) bool {
+		return timeOff.DriverID == driverID && timeOff.Status == TimeOffStatusList[TimeOffStatusApproved] &&
+			timeOff.StartDate <= to && timeOff.EndDate >= from
+	}), nil
+}
+
+func (f *FakeRepository) GetTimeOff(ctx context.Context, driverID uuid.UUID, id uuid.UUID) (*TimeOff, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, timeOff := range f.timeOffs {
+		if timeOff.DriverID == driverID && timeOff.ID == id {
+			return &timeOff, nil
+		}
+	}
+	return &TimeOff{}, gorm.ErrRecordNotFound
+}
+
+func (f *FakeRepository) CreateTimeOff(ctx context.Context, timeOff *TimeOff) (*TimeOff, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if timeOff.ID == uuid.Nil {
+		timeOff.ID = uuid.New()
+	}
+	f.timeOffs = append(f.timeOffs, *timeOff)
+	return timeOff, nil
+}
+
+func (f *FakeRepository) UpdateTimeOff(ctx context.Context, timeOff *TimeOff) (*TimeOff, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.timeOffs {
+		if f.timeOffs[i].ID == timeOff.ID {
+			f.timeOffs[i] = *timeOff
+			return timeOff, nil
+		}
+	}
+	f.timeOffs = append(f.timeOffs, *timeOff)
+	return timeOff, nil
+}
+
+// findTimeOffs returns the time off that matches, by start date.
+func (f *FakeRepository) findTimeOffs(match func(TimeOff) bool) []TimeOff {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	timeOffs := []TimeOff{}
+	for _, timeOff := range f.timeOffs {
+		if match(timeOff) {
+			timeOffs = append(timeOffs, timeOff)
+		}
+	}
+	sort.SliceStable(timeOffs, func(i, j int) bool { return timeOffs[i].StartDate < timeOffs[j].StartDate })
+	return timeOffs
+}
+
+// static functions
+
+// NewFakeRepository creates an in-memory repository holding the drivers.
+func NewFakeRepository(drivers ...Driver) *FakeRepository {
+	return &FakeRepository{drivers: append([]Driver{}, drivers...)}
+}
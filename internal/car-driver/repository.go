@@ -1,37 +1,111 @@
 package carDriver
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type Repository struct {
+// Repository stores the drivers, their shifts and their time off.
+type Repository interface {
+	CreateDriver(ctx context.Context, driver *Driver) (*Driver, error)
+	GetDriver(ctx context.Context, id uuid.UUID) (*Driver, error)
+	GetDrivers(ctx context.Context) ([]Driver, error)
+	UpdateDriver(ctx context.Context, driver *Driver) (*Driver, error)
+	GetShifts(ctx context.Context, driverID uuid.UUID) ([]Shift, error)
+	CreateShift(ctx context.Context, shift *Shift) (*Shift, error)
+	DeleteShift(ctx context.Context, driverID uuid.UUID, id uuid.UUID) error
+	GetTimeOffs(ctx context.Context, driverID uuid.UUID) ([]TimeOff, error)
+	GetApprovedTimeOffs(ctx context.Context, driverID uuid.UUID, from string, to string) ([]TimeOff, error)
+	GetTimeOff(ctx context.Context, driverID uuid.UUID, id uuid.UUID) (*TimeOff, error)
+	CreateTimeOff(ctx context.Context, timeOff *TimeOff) (*TimeOff, error)
+	UpdateTimeOff(ctx context.Context, timeOff *TimeOff) (*TimeOff, error)
+}
+
+type repository struct {
 	db *gorm.DB
 }
 
-func (r *Repository) CreateDriver(driver *Driver) (*Driver, error) {
+func (r *repository) CreateDriver(ctx context.Context, driver *Driver) (*Driver, error) {
 	if driver.ID == uuid.Nil {
 		driver.ID = uuid.New()
 	}
-	return driver, r.db.Create(driver).Error
+	return driver, r.db.WithContext(ctx).Create(driver).Error
 }
 
-func (r *Repository) GetDriver(id uuid.UUID) (*Driver, error) {
+func (r *repository) GetDriver(ctx context.Context, id uuid.UUID) (*Driver, error) {
 	var driver Driver
-	return &driver, r.db.First(&driver, "id = ?", id).Error
+	return &driver, r.db.WithContext(ctx).First(&driver, "id = ?", id).Error
 }
 
-func (r *Repository) GetDrivers() ([]Driver, error) {
+func (r *repository) GetDrivers(ctx context.Context) ([]Driver, error) {
 	var drivers []Driver
-	return drivers, r.db.Find(&drivers).Error
+	return drivers, r.db.WithContext(ctx).Find(&drivers).Error
+}
+
+func (r *repository) UpdateDriver(ctx context.Context, driver *Driver) (*Driver, error) {
+	return driver, r.db.WithContext(ctx).Save(driver).Error
+}
+
+func (r *repository) GetShifts(ctx context.Context, driverID uuid.UUID) ([]Shift, error) {
+	shifts := []Shift{}
+	err := r.db.WithContext(ctx).Where("driver_id = ?", driverID).Order("weekday, starts_at").Find(&shifts).Error
+	return shifts, err
+}
+
+func (r *repository) CreateShift(ctx context.Context, shift *Shift) (*Shift, error) {
+	if shift.ID == uuid.Nil {
+		shift.ID = uuid.New()
+	}
+	return shift, r.db.WithContext(ctx).Create(shift).Error
+}
+
+func (r *repository) DeleteShift(ctx context.Context, driverID uuid.UUID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("driver_id = ?", driverID).Delete(&Shift{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *repository) GetTimeOffs(ctx context.Context, driverID uuid.UUID) ([]TimeOff, error) {
+	timeOffs := []TimeOff{}
+	err := r.db.WithContext(ctx).Where("driver_id = ?", driverID).Order("start_date").Find(&timeOffs).Error
+	return timeOffs, err
+}
+
+// GetApprovedTimeOffs returns the approved time off of a driver overlapping
+// the days from and to, both included.
+func (r *repository) GetApprovedTimeOffs(ctx context.Context, driverID uuid.UUID, from string, to string) ([]TimeOff, error) {
+	timeOffs := []TimeOff{}
+	err := r.db.WithContext(ctx).Where("driver_id = ? AND status = ? AND start_date <= ? AND end_date >= ?",
+		driverID, TimeOffStatusList[TimeOffStatusApproved], to, from).
+		Order("start_date").Find(&timeOffs).Error
+	return timeOffs, err
+}
+
+func (r *repository) GetTimeOff(ctx context.Context, driverID uuid.UUID, id uuid.UUID) (*TimeOff, error) {
+	var timeOff TimeOff
+	return &timeOff, r.db.WithContext(ctx).First(&timeOff, "driver_id = ? AND id = ?", driverID, id).Error
+}
+
+func (r *repository) CreateTimeOff(ctx context.Context, timeOff *TimeOff) (*TimeOff, error) {
+	if timeOff.ID == uuid.Nil {
+		timeOff.ID = uuid.New()
+	}
+	return timeOff, r.db.WithContext(ctx).Create(timeOff).Error
 }
 
-func (r *Repository) UpdateDriver(driver *Driver) (*Driver, error) {
-	return driver, r.db.Save(driver).Error
+func (r *repository) UpdateTimeOff(ctx context.Context, timeOff *TimeOff) (*TimeOff, error) {
+	return timeOff, r.db.WithContext(ctx).Save(timeOff).Error
 }
 
 // static functions
 
-func NewRepository(db *gorm.DB) *Repository {
-	return &Repository{db: db}
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
 }
@@ -1,6 +1,7 @@
 package carDriver
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -14,7 +15,7 @@ import (
 type RepositoryTestSuite struct {
 	suite.Suite
 	db         *gorm.DB
-	repository *Repository
+	repository Repository
 }
 
 func (suite *RepositoryTestSuite) SetupTest() {
@@ -23,7 +24,7 @@ func (suite *RepositoryTestSuite) SetupTest() {
 		suite.T().Fatal(err)
 	}
 
-	err = db.AutoMigrate(&Driver{})
+	err = db.AutoMigrate(&Driver{}, &Shift{}, &TimeOff{})
 	if err != nil {
 		suite.T().Fatal(err)
 	}
@@ -44,7 +45,7 @@ func (suite *RepositoryTestSuite) TestCreateDriver() {
 	}
 
 	// Act
-	result, err := suite.repository.CreateDriver(driver)
+	result, err := suite.repository.CreateDriver(context.Background(), driver)
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -76,7 +77,7 @@ func (suite *RepositoryTestSuite) TestGetDriver() {
 	suite.db.Create(driver)
 
 	// Act
-	result, err := suite.repository.GetDriver(driverID)
+	result, err := suite.repository.GetDriver(context.Background(), driverID)
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -113,7 +114,7 @@ func (suite *RepositoryTestSuite) TestGetDrivers() {
 	suite.db.Create(driver2)
 
 	// Act
-	results, err := suite.repository.GetDrivers()
+	results, err := suite.repository.GetDrivers(context.Background())
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -140,7 +141,7 @@ func (suite *RepositoryTestSuite) TestUpdateDriver() {
 	driver.PhoneNumber = "9999999999"
 
 	// Act
-	result, err := suite.repository.UpdateDriver(driver)
+	result, err := suite.repository.UpdateDriver(context.Background(), driver)
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -154,6 +155,47 @@ func (suite *RepositoryTestSuite) TestUpdateDriver() {
 	assert.Equal(suite.T(), "9999999999", updatedDriver.PhoneNumber)
 }
 
+func (suite *RepositoryTestSuite) TestCreateAndDeleteShift() {
+	// Arrange
+	driverID := uuid.New()
+	shift, err := suite.repository.CreateShift(context.Background(), &Shift{DriverID: driverID, Weekday: time.Tuesday, StartsAt: "08:00", EndsAt: "16:00"})
+	assert.NoError(suite.T(), err)
+	suite.repository.CreateShift(context.Background(), &Shift{DriverID: driverID, Weekday: time.Monday, StartsAt: "08:00", EndsAt: "16:00"})
+
+	// Act
+	shifts, err := suite.repository.GetShifts(context.Background(), driverID)
+	deleteErr := suite.repository.DeleteShift(context.Background(), driverID, shift.ID)
+	missingErr := suite.repository.DeleteShift(context.Background(), uuid.New(), shift.ID)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), shifts, 2)
+	assert.Equal(suite.T(), time.Monday, shifts[0].Weekday)
+	assert.NoError(suite.T(), deleteErr)
+	assert.ErrorIs(suite.T(), missingErr, gorm.ErrRecordNotFound)
+}
+
+func (suite *RepositoryTestSuite) TestGetApprovedTimeOffs() {
+	// Arrange
+	driverID := uuid.New()
+	for _, timeOff := range []TimeOff{
+		{DriverID: driverID, StartDate: "2025-03-01", EndDate: "2025-03-09", Status: "approved"},
+		{DriverID: driverID, StartDate: "2025-03-10", EndDate: "2025-03-14", Status: "approved"},
+		{DriverID: driverID, StartDate: "2025-03-12", EndDate: "2025-03-12", Status: "requested"},
+		{DriverID: uuid.New(), StartDate: "2025-03-10", EndDate: "2025-03-14", Status: "approved"},
+	} {
+		suite.repository.CreateTimeOff(context.Background(), &timeOff)
+	}
+
+	// Act
+	result, err := suite.repository.GetApprovedTimeOffs(context.Background(), driverID, "2025-03-12", "2025-03-20")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), "2025-03-10", result[0].StartDate)
+}
+
 func TestRepositorySuite(t *testing.T) {
 	suite.Run(t, new(RepositoryTestSuite))
 }
@@ -1,31 +1,215 @@
 package carDriver
 
-import "github.com/google/uuid"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Service interface {
-	CreateDriver(driver *Driver) (*Driver, error)
-	GetDriver(id uuid.UUID) (*Driver, error)
-	GetDrivers() ([]Driver, error)
+	CreateDriver(ctx context.Context, driver *Driver) (*Driver, error)
+	GetDriver(ctx context.Context, id uuid.UUID) (*Driver, error)
+	GetDrivers(ctx context.Context) ([]Driver, error)
+	GetShifts(ctx context.Context, driverID uuid.UUID) ([]Shift, error)
+	CreateShift(ctx context.Context, driverID uuid.UUID, createShift *CreateShift) (*Shift, error)
+	DeleteShift(ctx context.Context, driverID uuid.UUID, id uuid.UUID) error
+	GetTimeOffs(ctx context.Context, driverID uuid.UUID) ([]TimeOff, error)
+	RequestTimeOff(ctx context.Context, driverID uuid.UUID, createTimeOff *CreateTimeOff) (*TimeOff, error)
+	UpdateTimeOff(ctx context.Context, driverID uuid.UUID, id uuid.UUID, update *UpdateTimeOff) (*TimeOff, error)
+	GetAvailability(ctx context.Context, driverID uuid.UUID, from string, to string) ([]Availability, error)
+	GetAvailableDrivers(ctx context.Context, date string) ([]Driver, error)
+	CheckAvailability(ctx context.Context, driverID uuid.UUID, date string) error
 }
 
 type service struct {
-	repository *Repository
+	repository Repository
+	drivingLog DrivingLog
+	limits     DrivingLimits
+	location   *time.Location
+}
+
+func (s *service) CreateDriver(ctx context.Context, driver *Driver) (*Driver, error) {
+	return s.repository.CreateDriver(ctx, driver)
+}
+
+func (s *service) GetDriver(ctx context.Context, id uuid.UUID) (*Driver, error) {
+	return s.repository.GetDriver(ctx, id)
+}
+
+func (s *service) GetDrivers(ctx context.Context) ([]Driver, error) {
+	return s.repository.GetDrivers(ctx)
+}
+
+func (s *service) GetShifts(ctx context.Context, driverID uuid.UUID) ([]Shift, error) {
+	if _, err := s.repository.GetDriver(ctx, driverID); err != nil {
+		return nil, err
+	}
+	return s.repository.GetShifts(ctx, driverID)
+}
+
+func (s *service) CreateShift(ctx context.Context, driverID uuid.UUID, createShift *CreateShift) (*Shift, error) {
+	if !ValidShift(createShift.Weekday, createShift.StartsAt, createShift.EndsAt) {
+		return nil, ErrInvalidShift
+	}
+	// Hours are stored zero padded, such as 09:00, so they compare and sort
+	// as strings
+	startsAt, endsAt := normalizeHour(createShift.StartsAt), normalizeHour(createShift.EndsAt)
+	shifts, err := s.GetShifts(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	for _, shift := range shifts {
+		if shift.Weekday == createShift.Weekday && startsAt < normalizeHour(shift.EndsAt) && normalizeHour(shift.StartsAt) < endsAt {
+			return nil, ErrShiftOverlap
+		}
+	}
+
+	return s.repository.CreateShift(ctx, &Shift{
+		DriverID: driverID,
+		Weekday:  createShift.Weekday,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+	})
+}
+
+func (s *service) DeleteShift(ctx context.Context, driverID uuid.UUID, id uuid.UUID) error {
+	return s.repository.DeleteShift(ctx, driverID, id)
 }
 
-func (s *service) CreateDriver(driver *Driver) (*Driver, error) {
-	return s.repository.CreateDriver(driver)
+func (s *service) GetTimeOffs(ctx context.Context, driverID uuid.UUID) ([]TimeOff, error) {
+	if _, err := s.repository.GetDriver(ctx, driverID); err != nil {
+		return nil, err
+	}
+	return s.repository.GetTimeOffs(ctx, driverID)
 }
 
-func (s *service) GetDriver(id uuid.UUID) (*Driver, error) {
-	return s.repository.GetDriver(id)
+func (s *service) RequestTimeOff(ctx context.Context, driverID uuid.UUID, createTimeOff *CreateTimeOff) (*TimeOff, error) {
+	if _, _, err := parseDateRange(createTimeOff.StartDate, createTimeOff.EndDate, 0, s.location); err != nil {
+		return nil, err
+	}
+	if _, err := s.repository.GetDriver(ctx, driverID); err != nil {
+		return nil, err
+	}
+
+	return s.repository.CreateTimeOff(ctx, &TimeOff{
+		DriverID:  driverID,
+		StartDate: createTimeOff.StartDate,
+		EndDate:   createTimeOff.EndDate,
+		Reason:    createTimeOff.Reason,
+		Status:    TimeOffStatusList[TimeOffStatusRequested],
+	})
+}
+
+func (s *service) UpdateTimeOff(ctx context.Context, driverID uuid.UUID, id uuid.UUID, update *UpdateTimeOff) (*TimeOff, error) {
+	timeOff, err := s.repository.GetTimeOff(ctx, driverID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !CanTransitionTimeOff(TimeOffStatus(timeOff.Status), TimeOffStatus(update.Status)) {
+		return nil, fmt.Errorf("%w: from %s to %s", ErrInvalidTimeOffStatus, timeOff.Status, update.Status)
+	}
+
+	timeOff.Status = update.Status
+	return s.repository.UpdateTimeOff(ctx, timeOff)
+}
+
+func (s *service) GetAvailability(ctx context.Context, driverID uuid.UUID, from string, to string) ([]Availability, error) {
+	start, end, err := parseDateRange(from, to, MaxCalendarDays, s.location)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := s.GetShifts(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	timeOffs, err := s.repository.GetApprovedTimeOffs(ctx, driverID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := []Availability{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		availability, err := s.availability(ctx, driverID, day, shifts, timeOffs)
+		if err != nil {
+			return nil, err
+		}
+		calendar = append(calendar, availability)
+	}
+	return calendar, nil
+}
+
+func (s *service) GetAvailableDrivers(ctx context.Context, date string) ([]Driver, error) {
+	drivers, err := s.repository.GetDrivers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	available := []Driver{}
+	for _, driver := range drivers {
+		err := s.CheckAvailability(ctx, driver.ID, date)
+		if errors.Is(err, ErrDriverUnavailable) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		available = append(available, driver)
+	}
+	return available, nil
+}
+
+// CheckAvailability returns ErrDriverUnavailable, with the reason, when the
+// driver may not be assigned a route on the date. An empty date means today.
+func (s *service) CheckAvailability(ctx context.Context, driverID uuid.UUID, date string) error {
+	day, err := parseDay(date, s.location)
+	if err != nil {
+		return err
+	}
+	shifts, err := s.GetShifts(ctx, driverID)
+	if err != nil {
+		return err
+	}
+	date = day.Format(DateLayout)
+	timeOffs, err := s.repository.GetApprovedTimeOffs(ctx, driverID, date, date)
+	if err != nil {
+		return err
+	}
+
+	availability, err := s.availability(ctx, driverID, day, shifts, timeOffs)
+	if err != nil {
+		return err
+	}
+	if !availability.Available {
+		return fmt.Errorf("%w on %s: %s", ErrDriverUnavailable, availability.Date, availability.Reason)
+	}
+	return nil
 }
 
-func (s *service) GetDrivers() ([]Driver, error) {
-	return s.repository.GetDrivers()
+func (s *service) availability(ctx context.Context, driverID uuid.UUID, day time.Time, shifts []Shift, timeOffs []TimeOff) (Availability, error) {
+	var drivenDay, drivenWeek time.Duration
+	if s.drivingLog != nil {
+		var err error
+		next := day.AddDate(0, 0, 1)
+		if drivenDay, err = s.drivingLog.GetDrivingTime(ctx, driverID, day, next); err != nil {
+			return Availability{}, err
+		}
+		if drivenWeek, err = s.drivingLog.GetDrivingTime(ctx, driverID, startOfWeek(day), next); err != nil {
+			return Availability{}, err
+		}
+	}
+	return NewAvailability(day, shifts, timeOffs, drivenDay, drivenWeek, s.limits), nil
 }
 
 // static functions
 
-func NewService(repository *Repository) *service {
-	return &service{repository: repository}
+// NewService creates the driver service. Driving time is not limited without
+// a driving log, and days are taken in location, UTC if nil.
+func NewService(repository Repository, drivingLog DrivingLog, limits DrivingLimits, location *time.Location) *service {
+	if location == nil {
+		location = time.UTC
+	}
+	return &service{repository: repository, drivingLog: drivingLog, limits: limits, location: location}
 }
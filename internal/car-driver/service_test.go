@@ -1,33 +1,27 @@
 package carDriver
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-// Define a repository interface that our mock can implement
-type RepositoryInterface interface {
-	CreateDriver(driver *Driver) (*Driver, error)
-	GetDriver(id uuid.UUID) (*Driver, error)
-	GetDrivers() ([]Driver, error)
-	UpdateDriver(driver *Driver) (*Driver, error)
-}
-
 // Define a mock repository for testing the service
 type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) CreateDriver(driver *Driver) (*Driver, error) {
-	args := m.Called(driver)
+func (m *MockRepository) CreateDriver(ctx context.Context, driver *Driver) (*Driver, error) {
+	args := m.Called(ctx, driver)
 	return args.Get(0).(*Driver), args.Error(1)
 }
 
-func (m *MockRepository) GetDriver(id uuid.UUID) (*Driver, error) {
+func (m *MockRepository) GetDriver(ctx context.Context, id uuid.UUID) (*Driver, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -35,35 +29,75 @@ func (m *MockRepository) GetDriver(id uuid.UUID) (*Driver, error) {
 	return args.Get(0).(*Driver), args.Error(1)
 }
 
-func (m *MockRepository) GetDrivers() ([]Driver, error) {
+func (m *MockRepository) GetDrivers(ctx context.Context) ([]Driver, error) {
 	args := m.Called()
 	return args.Get(0).([]Driver), args.Error(1)
 }
 
-func (m *MockRepository) UpdateDriver(driver *Driver) (*Driver, error) {
-	args := m.Called(driver)
+func (m *MockRepository) UpdateDriver(ctx context.Context, driver *Driver) (*Driver, error) {
+	args := m.Called(ctx, driver)
 	return args.Get(0).(*Driver), args.Error(1)
 }
 
-// Create a custom service for testing
-type testService struct {
-	repo RepositoryInterface
+func (m *MockRepository) GetShifts(ctx context.Context, driverID uuid.UUID) ([]Shift, error) {
+	args := m.Called(driverID)
+	return args.Get(0).([]Shift), args.Error(1)
+}
+
+func (m *MockRepository) CreateShift(ctx context.Context, shift *Shift) (*Shift, error) {
+	args := m.Called(ctx, shift)
+	return args.Get(0).(*Shift), args.Error(1)
+}
+
+func (m *MockRepository) DeleteShift(ctx context.Context, driverID uuid.UUID, id uuid.UUID) error {
+	args := m.Called(ctx, driverID, id)
+	return args.Error(0)
 }
 
-func (s *testService) CreateDriver(driver *Driver) (*Driver, error) {
-	return s.repo.CreateDriver(driver)
+func (m *MockRepository) GetTimeOffs(ctx context.Context, driverID uuid.UUID) ([]TimeOff, error) {
+	args := m.Called(driverID)
+	return args.Get(0).([]TimeOff), args.Error(1)
 }
 
-func (s *testService) GetDriver(id uuid.UUID) (*Driver, error) {
-	return s.repo.GetDriver(id)
+func (m *MockRepository) GetApprovedTimeOffs(ctx context.Context, driverID uuid.UUID, from string, to string) ([]TimeOff, error) {
+	args := m.Called(driverID, from, to)
+	return args.Get(0).([]TimeOff), args.Error(1)
 }
 
-func (s *testService) GetDrivers() ([]Driver, error) {
-	return s.repo.GetDrivers()
+func (m *MockRepository) GetTimeOff(ctx context.Context, driverID uuid.UUID, id uuid.UUID) (*TimeOff, error) {
+	args := m.Called(driverID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*TimeOff), args.Error(1)
+}
+
+func (m *MockRepository) CreateTimeOff(ctx context.Context, timeOff *TimeOff) (*TimeOff, error) {
+	args := m.Called(ctx, timeOff)
+	return args.Get(0).(*TimeOff), args.Error(1)
+}
+
+func (m *MockRepository) UpdateTimeOff(ctx context.Context, timeOff *TimeOff) (*TimeOff, error) {
+	args := m.Called(ctx, timeOff)
+	return args.Get(0).(*TimeOff), args.Error(1)
+}
+
+// Define a driving log that reports the same driving time for every day,
+// and for every week
+type fakeDrivingLog struct {
+	day  time.Duration
+	week time.Duration
+}
+
+func (f fakeDrivingLog) GetDrivingTime(ctx context.Context, driverID uuid.UUID, from time.Time, to time.Time) (time.Duration, error) {
+	if to.Sub(from) > 24*time.Hour {
+		return f.week, nil
+	}
+	return f.day, nil
 }
 
 func createTestService(mockRepo *MockRepository) Service {
-	return &testService{repo: mockRepo}
+	return NewService(mockRepo, nil, DefaultDrivingLimits, time.UTC)
 }
 
 func TestCreateDriver(t *testing.T) {
@@ -91,10 +125,10 @@ func TestCreateDriver(t *testing.T) {
 		LicenseNumber:  driver.LicenseNumber,
 	}
 
-	mockRepo.On("CreateDriver", driver).Return(expectedDriver, nil)
+	mockRepo.On("CreateDriver", mock.Anything, driver).Return(expectedDriver, nil)
 
 	// Act
-	result, err := service.CreateDriver(driver)
+	result, err := service.CreateDriver(context.Background(), driver)
 
 	// Assert
 	assert.NoError(t, err)
@@ -122,7 +156,7 @@ func TestGetDriver(t *testing.T) {
 	mockRepo.On("GetDriver", driverID).Return(expectedDriver, nil)
 
 	// Act
-	result, err := service.GetDriver(driverID)
+	result, err := service.GetDriver(context.Background(), driverID)
 
 	// Assert
 	assert.NoError(t, err)
@@ -142,7 +176,7 @@ func TestGetDriverNotFound(t *testing.T) {
 	mockRepo.On("GetDriver", driverID).Return((*Driver)(nil), expectedError)
 
 	// Act
-	_, err := service.GetDriver(driverID)
+	_, err := service.GetDriver(context.Background(), driverID)
 
 	// Assert
 	assert.Error(t, err)
@@ -177,7 +211,7 @@ func TestGetDrivers(t *testing.T) {
 	mockRepo.On("GetDrivers").Return(expectedDrivers, nil)
 
 	// Act
-	results, err := service.GetDrivers()
+	results, err := service.GetDrivers(context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -186,3 +220,233 @@ func TestGetDrivers(t *testing.T) {
 	assert.Equal(t, expectedDrivers[1].Name, results[1].Name)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestCreateShift(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	driverID := uuid.New()
+	mockRepo.On("GetDriver", driverID).Return(&Driver{ID: driverID}, nil)
+	mockRepo.On("GetShifts", driverID).Return([]Shift{
+		{DriverID: driverID, Weekday: time.Monday, StartsAt: "08:00", EndsAt: "12:00"},
+	}, nil)
+	mockRepo.On("CreateShift", mock.Anything, mock.Anything).Return(&Shift{ID: uuid.New(), DriverID: driverID, Weekday: time.Monday, StartsAt: "13:00", EndsAt: "17:00"}, nil)
+
+	// Act
+	result, err := service.CreateShift(context.Background(), driverID, &CreateShift{Weekday: time.Monday, StartsAt: "13:00", EndsAt: "17:00"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "13:00", result.StartsAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateShiftOverlap(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	driverID := uuid.New()
+	mockRepo.On("GetDriver", driverID).Return(&Driver{ID: driverID}, nil)
+	mockRepo.On("GetShifts", driverID).Return([]Shift{
+		{DriverID: driverID, Weekday: time.Monday, StartsAt: "08:00", EndsAt: "12:00"},
+	}, nil)
+
+	// Act
+	_, err := service.CreateShift(context.Background(), driverID, &CreateShift{Weekday: time.Monday, StartsAt: "11:00", EndsAt: "15:00"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrShiftOverlap)
+	mockRepo.AssertNotCalled(t, "CreateShift", mock.Anything, mock.Anything)
+}
+
+func TestCreateShiftUnpaddedHours(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	driverID := uuid.New()
+	mockRepo.On("GetDriver", driverID).Return(&Driver{ID: driverID}, nil)
+	mockRepo.On("GetShifts", driverID).Return([]Shift{
+		{DriverID: driverID, Weekday: time.Monday, StartsAt: "08:00", EndsAt: "12:00"},
+	}, nil)
+	mockRepo.On("CreateShift", mock.Anything, mock.MatchedBy(func(shift *Shift) bool {
+		return shift.StartsAt == "07:30" && shift.EndsAt == "08:00"
+	})).Return(&Shift{ID: uuid.New(), DriverID: driverID, Weekday: time.Monday, StartsAt: "07:30", EndsAt: "08:00"}, nil)
+
+	// Act
+	_, overlapErr := service.CreateShift(context.Background(), driverID, &CreateShift{Weekday: time.Monday, StartsAt: "9:00", EndsAt: "10:00"})
+	_, err := service.CreateShift(context.Background(), driverID, &CreateShift{Weekday: time.Monday, StartsAt: "7:30", EndsAt: "8:00"})
+
+	// Assert
+	assert.ErrorIs(t, overlapErr, ErrShiftOverlap)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateShiftInvalid(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	// Act
+	_, err := service.CreateShift(context.Background(), uuid.New(), &CreateShift{Weekday: time.Monday, StartsAt: "17:00", EndsAt: "09:00"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidShift)
+}
+
+func TestRequestTimeOffInvalidRange(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	// Act
+	_, err := service.RequestTimeOff(context.Background(), uuid.New(), &CreateTimeOff{StartDate: "2025-03-14", EndDate: "2025-03-10"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+}
+
+func TestUpdateTimeOff(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	driverID, id := uuid.New(), uuid.New()
+	mockRepo.On("GetTimeOff", driverID, id).Return(&TimeOff{ID: id, DriverID: driverID, Status: "requested"}, nil)
+	mockRepo.On("UpdateTimeOff", mock.Anything, mock.Anything).Return(&TimeOff{ID: id, DriverID: driverID, Status: "approved"}, nil)
+
+	// Act
+	result, err := service.UpdateTimeOff(context.Background(), driverID, id, &UpdateTimeOff{Status: "approved"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "approved", result.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateTimeOffInvalidTransition(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	driverID, id := uuid.New(), uuid.New()
+	mockRepo.On("GetTimeOff", driverID, id).Return(&TimeOff{ID: id, DriverID: driverID, Status: "rejected"}, nil)
+
+	// Act
+	_, err := service.UpdateTimeOff(context.Background(), driverID, id, &UpdateTimeOff{Status: "approved"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidTimeOffStatus)
+	mockRepo.AssertNotCalled(t, "UpdateTimeOff", mock.Anything, mock.Anything)
+}
+
+func TestCheckAvailabilityOnTimeOff(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	driverID := uuid.New()
+	mockRepo.On("GetDriver", driverID).Return(&Driver{ID: driverID}, nil)
+	mockRepo.On("GetShifts", driverID).Return([]Shift{}, nil)
+	mockRepo.On("GetApprovedTimeOffs", driverID, "2025-03-12", "2025-03-12").Return([]TimeOff{
+		{DriverID: driverID, StartDate: "2025-03-10", EndDate: "2025-03-14", Status: "approved"},
+	}, nil)
+
+	// Act
+	err := service.CheckAvailability(context.Background(), driverID, "2025-03-12")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrDriverUnavailable)
+	assert.ErrorContains(t, err, "time off")
+}
+
+func TestCheckAvailabilityDrivingLimit(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	driverID := uuid.New()
+	mockRepo.On("GetDriver", driverID).Return(&Driver{ID: driverID}, nil)
+	mockRepo.On("GetShifts", driverID).Return([]Shift{}, nil)
+	mockRepo.On("GetApprovedTimeOffs", driverID, "2025-03-12", "2025-03-12").Return([]TimeOff{}, nil)
+	service := NewService(mockRepo, fakeDrivingLog{day: 2 * time.Hour, week: 56 * time.Hour}, DefaultDrivingLimits, time.UTC)
+
+	// Act
+	err := service.CheckAvailability(context.Background(), driverID, "2025-03-12")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrDriverUnavailable)
+	assert.ErrorContains(t, err, "weekly driving limit")
+}
+
+func TestGetAvailableDrivers(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	onShift, offShift := Driver{ID: uuid.New(), Name: "On shift"}, Driver{ID: uuid.New(), Name: "Off shift"}
+	mockRepo.On("GetDrivers").Return([]Driver{onShift, offShift}, nil)
+	mockRepo.On("GetDriver", mock.Anything).Return(&Driver{}, nil)
+	mockRepo.On("GetShifts", onShift.ID).Return([]Shift{{Weekday: time.Wednesday, StartsAt: "08:00", EndsAt: "16:00"}}, nil)
+	mockRepo.On("GetShifts", offShift.ID).Return([]Shift{{Weekday: time.Thursday, StartsAt: "08:00", EndsAt: "16:00"}}, nil)
+	mockRepo.On("GetApprovedTimeOffs", mock.Anything, "2025-03-12", "2025-03-12").Return([]TimeOff{}, nil)
+
+	// Act
+	results, err := service.GetAvailableDrivers(context.Background(), "2025-03-12")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Driver{onShift}, results)
+}
+
+func TestGetAvailability(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	driverID := uuid.New()
+	mockRepo.On("GetDriver", driverID).Return(&Driver{ID: driverID}, nil)
+	mockRepo.On("GetShifts", driverID).Return([]Shift{{Weekday: time.Monday, StartsAt: "08:00", EndsAt: "16:00"}}, nil)
+	mockRepo.On("GetApprovedTimeOffs", driverID, "2025-03-09", "2025-03-17").Return([]TimeOff{
+		{StartDate: "2025-03-17", EndDate: "2025-03-21", Status: "approved"},
+	}, nil)
+
+	// Act
+	calendar, err := service.GetAvailability(context.Background(), driverID, "2025-03-09", "2025-03-17")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, calendar, 9)
+	assert.False(t, calendar[0].Available)
+	assert.True(t, calendar[1].Available)
+	assert.Equal(t, "2025-03-10", calendar[1].Date)
+	assert.False(t, calendar[8].Available)
+	assert.NotNil(t, calendar[8].TimeOff)
+}
+
+func TestGetAvailabilityTooLong(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	// Act
+	_, err := service.GetAvailability(context.Background(), uuid.New(), "2025-01-01", "2025-12-31")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+}
+
+func TestTimeOffLifecycle(t *testing.T) {
+	// Arrange
+	driverID := uuid.New()
+	service := NewService(NewFakeRepository(Driver{ID: driverID, Name: "Juan"}), nil, DefaultDrivingLimits, time.UTC)
+	_, err := service.CreateShift(context.Background(), driverID, &CreateShift{Weekday: time.Monday, StartsAt: "08:00", EndsAt: "16:00"})
+	assert.NoError(t, err)
+	timeOff, err := service.RequestTimeOff(context.Background(), driverID, &CreateTimeOff{StartDate: "2030-01-07", EndDate: "2030-01-08", Reason: "Medical"})
+	assert.NoError(t, err)
+
+	// Act
+	requestedErr := service.CheckAvailability(context.Background(), driverID, "2030-01-07")
+	_, approveErr := service.UpdateTimeOff(context.Background(), driverID, timeOff.ID, &UpdateTimeOff{Status: "approved"})
+	approvedErr := service.CheckAvailability(context.Background(), driverID, "2030-01-07")
+	_, transitionErr := service.UpdateTimeOff(context.Background(), driverID, timeOff.ID, &UpdateTimeOff{Status: "requested"})
+
+	// Assert
+	assert.NoError(t, requestedErr)
+	assert.NoError(t, approveErr)
+	assert.ErrorIs(t, approvedErr, ErrDriverUnavailable)
+	assert.ErrorIs(t, transitionErr, ErrInvalidTimeOffStatus)
+}
@@ -0,0 +1,571 @@
+package config
+
+import (
+	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/database"
+	"challenge-fravega/internal/geocoder"
+	"challenge-fravega/internal/health"
+	"challenge-fravega/internal/idempotency"
+	"challenge-fravega/internal/job"
+	"challenge-fravega/internal/notification"
+	orderSync "challenge-fravega/internal/order-sync"
+	"challenge-fravega/internal/outbox"
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	"challenge-fravega/internal/reconciliation"
+	routePoint "challenge-fravega/internal/route-point"
+	"challenge-fravega/internal/tracing"
+	"challenge-fravega/internal/vehicle"
+	"challenge-fravega/internal/webhook"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Config is the configuration of the server. Every setting can be given in
+// the configuration file under its yaml (or toml) key, as the environment
+// variable in its env tag, or as a flag named after the variable, such as
+// --db-path for DB_PATH, each overriding the former.
+type Config struct {
+	Database       Database       `yaml:"database" toml:"database"`
+	HTTP           HTTP           `yaml:"http" toml:"http"`
+	Log            Log            `yaml:"log" toml:"log"`
+	Tracing        Tracing        `yaml:"tracing" toml:"tracing"`
+	PurchaseOrder  PurchaseOrder  `yaml:"purchase_order" toml:"purchase_order"`
+	Geocoder       Geocoder       `yaml:"geocoder" toml:"geocoder"`
+	Zones          Zones          `yaml:"zones" toml:"zones"`
+	Drivers        Drivers        `yaml:"drivers" toml:"drivers"`
+	Maintenance    Maintenance    `yaml:"maintenance" toml:"maintenance"`
+	Reconciliation Reconciliation `yaml:"reconciliation" toml:"reconciliation"`
+	Idempotency    Idempotency    `yaml:"idempotency" toml:"idempotency"`
+	Outbox         Outbox         `yaml:"outbox" toml:"outbox"`
+	Webhooks       Webhooks       `yaml:"webhooks" toml:"webhooks"`
+	Notifications  Notifications  `yaml:"notifications" toml:"notifications"`
+	Features       Features       `yaml:"features" toml:"features"`
+	// Timezone is where the days of the calendar, such as those of driver
+	// availability, are counted, and ManifestTimezone that of the times
+	// printed on manifests, the same by default
+	Timezone         string `yaml:"timezone" toml:"timezone" env:"TIMEZONE"`
+	ManifestTimezone string `yaml:"manifest_timezone" toml:"manifest_timezone" env:"MANIFEST_TIMEZONE"`
+}
+
+// Database is where the server stores its data: a SQLite file at Path, the
+// default, or the PostgreSQL database at URL. The migrations of the driver
+// are in its own directory under MigrationsDir, applied by the migrate
+// command, or at startup with AutoMigrate.
+type Database struct {
+	Driver        string `yaml:"driver" toml:"driver" env:"DB_DRIVER"`
+	Path          string `yaml:"path" toml:"path" env:"DB_PATH"`
+	URL           Secret `yaml:"url" toml:"url" env:"DATABASE_URL"`
+	URLFile       string `yaml:"url_file" toml:"url_file" env:"DATABASE_URL_FILE"`
+	MigrationsDir string `yaml:"migrations_dir" toml:"migrations_dir" env:"MIGRATIONS_DIR"`
+	AutoMigrate   bool   `yaml:"auto_migrate" toml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+	// MigrationLockTimeout is how long the lock of the migrations goes
+	// without being refreshed before it is taken over, or never when 0
+	MigrationLockTimeout Duration `yaml:"migration_lock_timeout" toml:"migration_lock_timeout" env:"DB_MIGRATION_LOCK_TIMEOUT"`
+	// SlowQueryThreshold is the duration past which queries are logged
+	SlowQueryThreshold Duration `yaml:"slow_query_threshold" toml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD"`
+}
+
+// Tracing exports the spans of the server through OpenTelemetry, sampling
+// SampleRatio of the traces started here.
+type Tracing struct {
+	ServiceName string  `yaml:"service_name" toml:"service_name" env:"OTEL_SERVICE_NAME"`
+	Exporter    string  `yaml:"exporter" toml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint" env:"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"`
+	File        string  `yaml:"file" toml:"file" env:"OTEL_TRACES_FILE"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG"`
+}
+
+// Log is the structured logging of the server, as JSON or text records at
+// or above the level.
+type Log struct {
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"`
+}
+
+type HTTP struct {
+	Port              int      `yaml:"port" toml:"port" env:"PORT"`
+	ReadTimeout       Duration `yaml:"read_timeout" toml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" toml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      Duration `yaml:"write_timeout" toml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	// ShutdownTimeout bounds the wait for the requests in flight and the
+	// background workers on shutdown, and ReadinessTimeout the checks of
+	// /readyz. DrainDelay is how long the server keeps serving once it stops
+	// being ready, for load balancers to notice before connections are refused.
+	ShutdownTimeout  Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+	ReadinessTimeout Duration `yaml:"readiness_timeout" toml:"readiness_timeout" env:"HTTP_READINESS_TIMEOUT"`
+	DrainDelay       Duration `yaml:"drain_delay" toml:"drain_delay" env:"HTTP_DRAIN_DELAY"`
+	// RequestTimeout is the deadline of the requests, 504 past it, unless
+	// RouteTimeouts has one for their route, as "METHOD /route=duration".
+	// Requests have no deadline when zero.
+	RequestTimeout Duration `yaml:"request_timeout" toml:"request_timeout" env:"HTTP_REQUEST_TIMEOUT"`
+	RouteTimeouts  []string `yaml:"route_timeouts" toml:"route_timeouts" env:"HTTP_ROUTE_TIMEOUTS"`
+	CORS           CORS     `yaml:"cors" toml:"cors"`
+	TLS            TLS      `yaml:"tls" toml:"tls"`
+}
+
+// CORS lets browsers on the allowed origins call the API. Cross-origin
+// requests are not allowed when there are none, and from anywhere with "*".
+type CORS struct {
+	AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods []string `yaml:"allowed_methods" toml:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders []string `yaml:"allowed_headers" toml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders []string `yaml:"exposed_headers" toml:"exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+	MaxAge         Duration `yaml:"max_age" toml:"max_age" env:"CORS_MAX_AGE"`
+}
+
+// TLS serves HTTPS with the certificate when both files are given.
+type TLS struct {
+	CertFile string `yaml:"cert_file" toml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" toml:"key_file" env:"TLS_KEY_FILE"`
+}
+
+// PurchaseOrder is the client of the order system, disabled without a URL.
+type PurchaseOrder struct {
+	URL        string   `yaml:"url" toml:"url" env:"PURCHASE_ORDER_URL"`
+	APIKey     Secret   `yaml:"api_key" toml:"api_key" env:"PURCHASE_ORDER_API_KEY"`
+	APIKeyFile string   `yaml:"api_key_file" toml:"api_key_file" env:"PURCHASE_ORDER_API_KEY_FILE"`
+	StatusPath string   `yaml:"status_path" toml:"status_path" env:"PURCHASE_ORDER_STATUS_PATH"`
+	Timeout    Duration `yaml:"timeout" toml:"timeout" env:"PURCHASE_ORDER_TIMEOUT"`
+	// SyncTime is when the daily drift report runs, never when empty, and
+	// SyncWindow how far back it looks for route points changed
+	SyncTime   string   `yaml:"sync_time" toml:"sync_time" env:"PURCHASE_ORDER_SYNC_TIME"`
+	SyncWindow Duration `yaml:"sync_window" toml:"sync_window" env:"PURCHASE_ORDER_SYNC_WINDOW"`
+}
+
+// Geocoder is the geocoding provider, disabled when empty.
+type Geocoder struct {
+	Provider          string   `yaml:"provider" toml:"provider" env:"GEOCODER"`
+	URL               string   `yaml:"url" toml:"url" env:"GEOCODER_URL"`
+	UserAgent         string   `yaml:"user_agent" toml:"user_agent" env:"GEOCODER_USER_AGENT"`
+	Timeout           Duration `yaml:"timeout" toml:"timeout" env:"GEOCODER_TIMEOUT"`
+	File              string   `yaml:"file" toml:"file" env:"GEOCODER_FILE"`
+	MismatchThreshold float64  `yaml:"mismatch_threshold" toml:"mismatch_threshold" env:"GEOCODE_MISMATCH_THRESHOLD"`
+}
+
+type Zones struct {
+	Policy string `yaml:"policy" toml:"policy" env:"ZONE_POLICY"`
+}
+
+type Drivers struct {
+	MaxDailyDriving  Duration `yaml:"max_daily_driving" toml:"max_daily_driving" env:"DRIVER_MAX_DAILY_DRIVING"`
+	MaxWeeklyDriving Duration `yaml:"max_weekly_driving" toml:"max_weekly_driving" env:"DRIVER_MAX_WEEKLY_DRIVING"`
+}
+
+type Maintenance struct {
+	IntervalKm   int `yaml:"interval_km" toml:"interval_km" env:"MAINTENANCE_INTERVAL_KM"`
+	IntervalDays int `yaml:"interval_days" toml:"interval_days" env:"MAINTENANCE_INTERVAL_DAYS"`
+	WarningKm    int `yaml:"warning_km" toml:"warning_km" env:"MAINTENANCE_WARNING_KM"`
+	WarningDays  int `yaml:"warning_days" toml:"warning_days" env:"MAINTENANCE_WARNING_DAYS"`
+}
+
+// Reconciliation is the end of day job, which never runs when Time is empty.
+type Reconciliation struct {
+	Time   string `yaml:"time" toml:"time" env:"RECONCILIATION_TIME"`
+	Policy string `yaml:"policy" toml:"policy" env:"RECONCILIATION_POLICY"`
+}
+
+type Idempotency struct {
+	TTL Duration `yaml:"ttl" toml:"ttl" env:"IDEMPOTENCY_TTL"`
+}
+
+type Outbox struct {
+	// File receives every event as a line of JSON when set
+	File         string   `yaml:"file" toml:"file" env:"OUTBOX_FILE"`
+	PollInterval Duration `yaml:"poll_interval" toml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	MaxAttempts  int      `yaml:"max_attempts" toml:"max_attempts" env:"OUTBOX_MAX_ATTEMPTS"`
+}
+
+type Webhooks struct {
+	Timeout      Duration `yaml:"timeout" toml:"timeout" env:"WEBHOOK_TIMEOUT"`
+	PollInterval Duration `yaml:"poll_interval" toml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL"`
+	BackoffBase  Duration `yaml:"backoff_base" toml:"backoff_base" env:"WEBHOOK_BACKOFF_BASE"`
+	BackoffMax   Duration `yaml:"backoff_max" toml:"backoff_max" env:"WEBHOOK_BACKOFF_MAX"`
+	MaxAttempts  int      `yaml:"max_attempts" toml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	// AllowPrivateHosts lets subscriptions point to loopback, link-local and
+	// private addresses, such as receivers running next to the server
+	AllowPrivateHosts bool `yaml:"allow_private_hosts" toml:"allow_private_hosts" env:"WEBHOOK_ALLOW_PRIVATE_HOSTS"`
+}
+
+type Notifications struct {
+	Locale string `yaml:"locale" toml:"locale" env:"NOTIFICATION_LOCALE"`
+	// File receives the messages of the channels not configured, for local
+	// testing
+	File         string   `yaml:"file" toml:"file" env:"NOTIFICATION_FILE"`
+	PollInterval Duration `yaml:"poll_interval" toml:"poll_interval" env:"NOTIFICATION_POLL_INTERVAL"`
+	BackoffBase  Duration `yaml:"backoff_base" toml:"backoff_base" env:"NOTIFICATION_BACKOFF_BASE"`
+	BackoffMax   Duration `yaml:"backoff_max" toml:"backoff_max" env:"NOTIFICATION_BACKOFF_MAX"`
+	MaxAttempts  int      `yaml:"max_attempts" toml:"max_attempts" env:"NOTIFICATION_MAX_ATTEMPTS"`
+	SMTP         SMTP     `yaml:"smtp" toml:"smtp"`
+	SMS          SMS      `yaml:"sms" toml:"sms"`
+}
+
+// SMTP is the email channel, disabled without a host.
+type SMTP struct {
+	Host         string   `yaml:"host" toml:"host" env:"SMTP_HOST"`
+	Port         int      `yaml:"port" toml:"port" env:"SMTP_PORT"`
+	Username     string   `yaml:"username" toml:"username" env:"SMTP_USERNAME"`
+	Password     Secret   `yaml:"password" toml:"password" env:"SMTP_PASSWORD"`
+	PasswordFile string   `yaml:"password_file" toml:"password_file" env:"SMTP_PASSWORD_FILE"`
+	From         string   `yaml:"from" toml:"from" env:"SMTP_FROM"`
+	Timeout      Duration `yaml:"timeout" toml:"timeout" env:"SMTP_TIMEOUT"`
+}
+
+// SMS is the text message channel, disabled without a URL.
+type SMS struct {
+	URL        string   `yaml:"url" toml:"url" env:"SMS_URL"`
+	APIKey     Secret   `yaml:"api_key" toml:"api_key" env:"SMS_API_KEY"`
+	APIKeyFile string   `yaml:"api_key_file" toml:"api_key_file" env:"SMS_API_KEY_FILE"`
+	From       string   `yaml:"from" toml:"from" env:"SMS_FROM"`
+	Timeout    Duration `yaml:"timeout" toml:"timeout" env:"SMS_TIMEOUT"`
+}
+
+// Features switch the optional parts of the service on or off. Workers runs
+// the background workers, the scheduler, the outbox and the dispatchers.
+type Features struct {
+	Idempotency       bool `yaml:"idempotency" toml:"idempotency" env:"FEATURE_IDEMPOTENCY"`
+	Webhooks          bool `yaml:"webhooks" toml:"webhooks" env:"FEATURE_WEBHOOKS"`
+	Notifications     bool `yaml:"notifications" toml:"notifications" env:"FEATURE_NOTIFICATIONS"`
+	PurchaseOrderSync bool `yaml:"purchase_order_sync" toml:"purchase_order_sync" env:"FEATURE_PURCHASE_ORDER_SYNC"`
+	Reconciliation    bool `yaml:"reconciliation" toml:"reconciliation" env:"FEATURE_RECONCILIATION"`
+	Workers           bool `yaml:"workers" toml:"workers" env:"FEATURE_WORKERS"`
+}
+
+// Duration is a time.Duration written as a string, such as "1m30s".
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+// Secret is a setting never printed, such as a password.
+type Secret string
+
+const redacted = "[REDACTED]"
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Validate returns ErrInvalidConfig along with every invalid setting.
+func (c *Config) Validate() error {
+	var problems []string
+	invalid := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch database.Driver(c.Database.Driver) {
+	case database.DriverSQLite:
+		if c.Database.Path == "" {
+			invalid("database.path is required by sqlite")
+		}
+	case database.DriverPostgres:
+		if c.Database.URL == "" {
+			invalid("database.url is required by postgres")
+		}
+	default:
+		invalid("database.driver must be sqlite or postgres, got %q", c.Database.Driver)
+	}
+	if c.Database.MigrationsDir == "" {
+		invalid("database.migrations_dir is required")
+	}
+
+	if c.Database.SlowQueryThreshold.Duration < 0 {
+		invalid("database.slow_query_threshold must not be negative")
+	}
+	if c.Database.MigrationLockTimeout.Duration < 0 {
+		invalid("database.migration_lock_timeout must not be negative")
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		invalid("log.level must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		invalid("log.format must be json or text, got %q", c.Log.Format)
+	}
+
+	if _, ok := tracing.ExporterList[tracing.Exporter(c.Tracing.Exporter)]; !ok {
+		invalid("tracing.exporter must be none, otlp, stdout or file, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.Endpoint != "" && !absoluteURL(c.Tracing.Endpoint) {
+		invalid("tracing.endpoint must be an absolute http or https URL")
+	}
+	if c.Tracing.Exporter == tracing.ExporterList[tracing.ExporterFile] && c.Tracing.File == "" {
+		invalid("tracing.file is required by the file exporter")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		invalid("tracing.sample_ratio must be between 0 and 1")
+	}
+
+	if c.HTTP.Port < 1 || c.HTTP.Port > 65535 {
+		invalid("http.port must be between 1 and 65535")
+	}
+	for name, d := range map[string]Duration{
+		"http.read_timeout":        c.HTTP.ReadTimeout,
+		"http.read_header_timeout": c.HTTP.ReadHeaderTimeout,
+		"http.write_timeout":       c.HTTP.WriteTimeout,
+		"http.idle_timeout":        c.HTTP.IdleTimeout,
+		"http.shutdown_timeout":    c.HTTP.ShutdownTimeout,
+		"http.drain_delay":         c.HTTP.DrainDelay,
+		"http.request_timeout":     c.HTTP.RequestTimeout,
+		"http.cors.max_age":        c.HTTP.CORS.MaxAge,
+	} {
+		if d.Duration < 0 {
+			invalid("%s must not be negative", name)
+		}
+	}
+	for _, origin := range c.HTTP.CORS.AllowedOrigins {
+		if origin != "*" && !absoluteURL(origin) {
+			invalid("http.cors.allowed_origins must be * or absolute URLs, got %q", origin)
+		}
+	}
+	if c.HTTP.ReadinessTimeout.Duration <= 0 {
+		invalid("http.readiness_timeout must be positive")
+	}
+	// Requests past the write timeout are cut off before their 504 is written
+	if write := c.HTTP.WriteTimeout.Duration; write > 0 && c.HTTP.RequestTimeout.Duration > write {
+		invalid("http.request_timeout must not exceed http.write_timeout")
+	}
+	if routeTimeouts, err := c.HTTP.Timeouts(); err != nil {
+		invalid("http.route_timeouts %v", err)
+	} else {
+		for route, timeout := range routeTimeouts {
+			if write := c.HTTP.WriteTimeout.Duration; write > 0 && timeout > write {
+				invalid("http.route_timeouts of %s must not exceed http.write_timeout", route)
+			}
+		}
+	}
+	if (c.HTTP.TLS.CertFile == "") != (c.HTTP.TLS.KeyFile == "") {
+		invalid("http.tls.cert_file and http.tls.key_file must be given together")
+	}
+
+	for name, zone := range map[string]string{"timezone": c.Timezone, "manifest_timezone": c.ManifestTimezone} {
+		if _, err := time.LoadLocation(zone); err != nil {
+			invalid("%s is not a known time zone: %q", name, zone)
+		}
+	}
+	for name, at := range map[string]string{"purchase_order.sync_time": c.PurchaseOrder.SyncTime, "reconciliation.time": c.Reconciliation.Time} {
+		if _, err := job.ParseDaily(at, time.UTC); at != "" && err != nil {
+			invalid("%s must be formatted as HH:MM", name)
+		}
+	}
+
+	if c.PurchaseOrder.URL != "" && !absoluteURL(c.PurchaseOrder.URL) {
+		invalid("purchase_order.url must be an absolute http or https URL")
+	}
+	if !strings.Contains(c.PurchaseOrder.StatusPath, "{id}") {
+		invalid("purchase_order.status_path must contain {id}")
+	}
+	if c.PurchaseOrder.Timeout.Duration <= 0 {
+		invalid("purchase_order.timeout must be positive")
+	}
+	if c.PurchaseOrder.SyncWindow.Duration < 0 {
+		invalid("purchase_order.sync_window must not be negative")
+	}
+
+	if _, ok := geocoder.ProviderList[geocoder.Provider(c.Geocoder.Provider)]; c.Geocoder.Provider != "" && !ok {
+		invalid("geocoder.provider must be nominatim, file or empty, got %q", c.Geocoder.Provider)
+	}
+	if c.Geocoder.Provider == geocoder.ProviderList[geocoder.ProviderFile] && c.Geocoder.File == "" {
+		invalid("geocoder.file is required by the file provider")
+	}
+	if _, ok := routePoint.ZonePolicyList[routePoint.ZonePolicy(c.Zones.Policy)]; !ok {
+		invalid("zones.policy must be warn or reject, got %q", c.Zones.Policy)
+	}
+	if _, ok := reconciliation.PolicyList[reconciliation.Policy(c.Reconciliation.Policy)]; !ok {
+		invalid("reconciliation.policy must be close or flag, got %q", c.Reconciliation.Policy)
+	}
+	if _, ok := notification.LocaleList[notification.Locale(c.Notifications.Locale)]; !ok {
+		invalid("notifications.locale must be es or en, got %q", c.Notifications.Locale)
+	}
+
+	if c.Outbox.MaxAttempts < 1 {
+		invalid("outbox.max_attempts must be at least 1")
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		invalid("webhooks.max_attempts must be at least 1")
+	}
+	if c.Notifications.MaxAttempts < 1 {
+		invalid("notifications.max_attempts must be at least 1")
+	}
+	if c.Notifications.SMTP.Host != "" && (c.Notifications.SMTP.Port < 1 || c.Notifications.SMTP.Port > 65535) {
+		invalid("notifications.smtp.port must be between 1 and 65535")
+	}
+	if c.Notifications.SMS.URL != "" && !absoluteURL(c.Notifications.SMS.URL) {
+		invalid("notifications.sms.url must be an absolute http or https URL")
+	}
+
+	if problems != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// LogLevel is the level of the log, already validated.
+func (l Log) LogLevel() slog.Level {
+	var level slog.Level
+	level.UnmarshalText([]byte(l.Level))
+	return level
+}
+
+// DataSource returns what the driver connects to: the path of the SQLite file
+// or the PostgreSQL URL.
+func (d Database) DataSource() string {
+	if database.Driver(d.Driver) == database.DriverPostgres {
+		return string(d.URL)
+	}
+	return d.Path
+}
+
+// Migrations returns the directory of the migrations of the driver.
+func (d Database) Migrations() string {
+	return filepath.Join(d.MigrationsDir, d.Driver)
+}
+
+// Timeouts returns the RouteTimeouts keyed by method and route template, such
+// as "POST /route-points/import".
+func (h HTTP) Timeouts() (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration, len(h.RouteTimeouts))
+	for _, entry := range h.RouteTimeouts {
+		route, rawTimeout, ok := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !hasPath || !strings.HasPrefix(strings.TrimSpace(path), "/") {
+			return nil, fmt.Errorf("must be formatted as \"METHOD /route=duration\", got %q", entry)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(rawTimeout))
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("must have a positive duration, got %q", entry)
+		}
+		timeouts[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = timeout
+	}
+	return timeouts, nil
+}
+
+// static functions
+
+// Default returns the configuration used for every setting not given.
+func Default() *Config {
+	return &Config{
+		Database: Database{
+			Driver:               database.DriverList[database.DriverSQLite],
+			Path:                 "./db/data.sqlite",
+			MigrationsDir:        "./db/migrations",
+			SlowQueryThreshold:   Duration{database.DefaultSlowThreshold},
+			MigrationLockTimeout: Duration{database.DefaultLockTimeout},
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: Tracing{
+			ServiceName: "challenge-fravega",
+			Exporter:    tracing.ExporterList[tracing.ExporterNone],
+			File:        "./traces.json",
+			SampleRatio: 1,
+		},
+		HTTP: HTTP{
+			Port:              8080,
+			ReadTimeout:       Duration{15 * time.Second},
+			ReadHeaderTimeout: Duration{5 * time.Second},
+			WriteTimeout:      Duration{30 * time.Second},
+			IdleTimeout:       Duration{time.Minute},
+			ShutdownTimeout:   Duration{30 * time.Second},
+			ReadinessTimeout:  Duration{health.DefaultTimeout},
+			DrainDelay:        Duration{5 * time.Second},
+			RequestTimeout:    Duration{10 * time.Second},
+			RouteTimeouts:     []string{"POST /route-points/import=25s", "POST /zones/import=25s"},
+			CORS: CORS{
+				AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+				AllowedHeaders: []string{"Content-Type", "Authorization", "If-Match", "Idempotency-Key", "X-Actor", "X-Request-ID"},
+				ExposedHeaders: []string{"ETag", "Location", "Idempotent-Replayed", "X-Request-ID"},
+				MaxAge:         Duration{12 * time.Hour},
+			},
+		},
+		PurchaseOrder: PurchaseOrder{
+			URL:        "http://localhost:8083",
+			StatusPath: purchaseOrder.DefaultStatusPath,
+			Timeout:    Duration{purchaseOrder.DefaultTimeout},
+			SyncTime:   "01:00",
+			SyncWindow: Duration{orderSync.DefaultWindow},
+		},
+		Geocoder: Geocoder{
+			URL:               geocoder.DefaultNominatimURL,
+			UserAgent:         "challenge-fravega",
+			Timeout:           Duration{geocoder.DefaultTimeout},
+			File:              "./resources/geocoder/locations.json",
+			MismatchThreshold: routePoint.DefaultMismatchThreshold,
+		},
+		Zones: Zones{Policy: routePoint.ZonePolicyList[routePoint.ZonePolicyWarn]},
+		Drivers: Drivers{
+			MaxDailyDriving:  Duration{carDriver.DefaultDrivingLimits.Daily},
+			MaxWeeklyDriving: Duration{carDriver.DefaultDrivingLimits.Weekly},
+		},
+		Maintenance: Maintenance{
+			IntervalKm:   vehicle.DefaultMaintenancePolicy.IntervalKm,
+			IntervalDays: vehicle.DefaultMaintenancePolicy.IntervalDays,
+			WarningKm:    vehicle.DefaultMaintenancePolicy.WarningKm,
+			WarningDays:  vehicle.DefaultMaintenancePolicy.WarningDays,
+		},
+		Reconciliation: Reconciliation{
+			Time:   "23:00",
+			Policy: reconciliation.PolicyList[reconciliation.PolicyFlag],
+		},
+		Idempotency: Idempotency{TTL: Duration{idempotency.DefaultTTL}},
+		Outbox:      Outbox{PollInterval: Duration{outbox.DefaultPollInterval}, MaxAttempts: outbox.DefaultMaxAttempts},
+		Webhooks: Webhooks{
+			Timeout:      Duration{webhook.DefaultTimeout},
+			PollInterval: Duration{webhook.DefaultPollInterval},
+			BackoffBase:  Duration{webhook.DefaultBackoff.Base},
+			BackoffMax:   Duration{webhook.DefaultBackoff.Max},
+			MaxAttempts:  webhook.DefaultBackoff.MaxAttempts,
+		},
+		Notifications: Notifications{
+			Locale:       notification.LocaleList[notification.LocaleEs],
+			PollInterval: Duration{notification.DefaultPollInterval},
+			BackoffBase:  Duration{notification.DefaultRetry.Base},
+			BackoffMax:   Duration{notification.DefaultRetry.Max},
+			MaxAttempts:  notification.DefaultRetry.MaxAttempts,
+			SMTP:         SMTP{Port: 587, From: "entregas@example.com", Timeout: Duration{notification.DefaultTimeout}},
+			SMS:          SMS{Timeout: Duration{notification.DefaultTimeout}},
+		},
+		Features: Features{
+			Idempotency:       true,
+			Webhooks:          true,
+			Notifications:     true,
+			PurchaseOrderSync: true,
+			Reconciliation:    true,
+			Workers:           true,
+		},
+		Timezone: "America/Argentina/Buenos_Aires",
+	}
+}
+
+func absoluteURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the configuration file when --config is not given
+const FileEnv = "CONFIG_FILE"
+
+// LookupEnv returns the value of an environment variable, as os.LookupEnv.
+type LookupEnv func(key string) (string, bool)
+
+// Options are the command line options other than the settings.
+type Options struct {
+	// File is the configuration file read, if any
+	File string
+	// PrintConfig asks to print the configuration and exit
+	PrintConfig bool
+	// Args are the arguments after the flags, such as the command of the
+	// migrate tool
+	Args []string
+}
+
+// setting is a field of the configuration with an environment variable.
+type setting struct {
+	env   string
+	value reflect.Value
+}
+
+// flagValue records the value of a flag, applied once the file and the
+// environment are.
+type flagValue struct {
+	setting setting
+	values  *[]assignment
+}
+
+type assignment struct {
+	setting setting
+	raw     string
+	name    string
+}
+
+func (f *flagValue) String() string {
+	return ""
+}
+
+// IsBoolFlag lets boolean settings be given as a bare flag, such as
+// --feature-webhooks.
+func (f *flagValue) IsBoolFlag() bool {
+	return f.setting.value.Kind() == reflect.Bool
+}
+
+func (f *flagValue) Set(raw string) error {
+	*f.values = append(*f.values, assignment{setting: f.setting, raw: raw, name: "--" + flagName(f.setting.env)})
+	return nil
+}
+
+// Print writes the configuration as YAML, with its secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// static functions
+
+// Load builds the configuration from the defaults, the configuration file
+// (YAML or TOML, after its extension), the environment and the flags in args,
+// each overriding the former, then reads the secrets given as files and
+// validates the result.
+func Load(args []string, lookupEnv LookupEnv) (*Config, *Options, error) {
+	config := Default()
+	settings := settingsOf(reflect.ValueOf(config).Elem())
+
+	options := &Options{}
+	var flagged []assignment
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&options.File, "config", "", "configuration file, YAML or TOML")
+	flags.BoolVar(&options.PrintConfig, "print-config", false, "print the configuration, secrets redacted, and exit")
+	for _, s := range settings {
+		flags.Var(&flagValue{setting: s, values: &flagged}, flagName(s.env), "sets "+s.env)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	options.Args = flags.Args()
+
+	if options.File == "" {
+		options.File, _ = lookupEnv(FileEnv)
+	}
+	if options.File != "" {
+		if err := readFile(options.File, config); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, s := range settings {
+		if raw, ok := lookupEnv(s.env); ok {
+			if err := set(s.value, raw); err != nil {
+				return nil, nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, s.env, err)
+			}
+		}
+	}
+	for _, a := range flagged {
+		if err := set(a.setting.value, a.raw); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, a.name, err)
+		}
+	}
+
+	if err := config.readSecrets(); err != nil {
+		return nil, nil, err
+	}
+	if config.ManifestTimezone == "" {
+		config.ManifestTimezone = config.Timezone
+	}
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return config, options, nil
+}
+
+// readSecrets replaces the secrets given as a file with its content.
+func (c *Config) readSecrets() error {
+	for _, s := range []struct {
+		file   string
+		secret *Secret
+	}{
+		{c.Database.URLFile, &c.Database.URL},
+		{c.PurchaseOrder.APIKeyFile, &c.PurchaseOrder.APIKey},
+		{c.Notifications.SMTP.PasswordFile, &c.Notifications.SMTP.Password},
+		{c.Notifications.SMS.APIKeyFile, &c.Notifications.SMS.APIKey},
+	} {
+		if s.file == "" {
+			continue
+		}
+		content, err := os.ReadFile(s.file)
+		if err != nil {
+			return fmt.Errorf("%w: failed to read secret: %v", ErrInvalidConfig, err)
+		}
+		*s.secret = Secret(strings.TrimSpace(string(content)))
+	}
+	return nil
+}
+
+func readFile(path string, config *Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(config)
+		if err == io.EOF {
+			err = nil
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(config)
+	default:
+		return fmt.Errorf("%w: %s must be a .yaml, .yml or .toml file", ErrInvalidConfig, path)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+	return nil
+}
+
+// settingsOf lists the fields with an environment variable of the struct and
+// of the structs within it.
+func settingsOf(v reflect.Value) []setting {
+	var settings []setting
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		if env := field.Tag.Get("env"); env != "" {
+			settings = append(settings, setting{env: env, value: value})
+		} else if value.Kind() == reflect.Struct {
+			settings = append(settings, settingsOf(value)...)
+		}
+	}
+	return settings
+}
+
+// set parses raw into the field after its type. Lists are comma separated.
+func set(value reflect.Value, raw string) error {
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		value.SetInt(int64(number))
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		value.SetFloat(number)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		value.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", value.Type())
+	}
+	return nil
+}
+
+// flagName is the flag of an environment variable, --db-path for DB_PATH.
+func flagName(env string) string {
+	return strings.ToLower(strings.ReplaceAll(env, "_", "-"))
+}
@@ -0,0 +1,286 @@
+package container
+
+import (
+	"challenge-fravega/internal/audit"
+	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/config"
+	"challenge-fravega/internal/geocoder"
+	"challenge-fravega/internal/idempotency"
+	"challenge-fravega/internal/job"
+	"challenge-fravega/internal/manifest"
+	"challenge-fravega/internal/metrics"
+	"challenge-fravega/internal/notification"
+	orderSync "challenge-fravega/internal/order-sync"
+	"challenge-fravega/internal/outbox"
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	"challenge-fravega/internal/reconciliation"
+	"challenge-fravega/internal/reporting"
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"challenge-fravega/internal/search"
+	"challenge-fravega/internal/vehicle"
+	"challenge-fravega/internal/webhook"
+	"challenge-fravega/internal/zone"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repositories holds the repositories of every package, all on the same
+// database.
+type Repositories struct {
+	Route          route.Repository
+	RoutePoint     routePoint.Repository
+	CarDriver      carDriver.Repository
+	Vehicle        vehicle.Repository
+	Search         search.Repository
+	Audit          audit.Repository
+	Idempotency    idempotency.Repository
+	Geocoder       geocoder.Repository
+	Zone           zone.Repository
+	Reporting      reporting.Repository
+	Job            job.Repository
+	Reconciliation reconciliation.Repository
+	Webhook        webhook.Repository
+	Notification   notification.Repository
+	Outbox         outbox.Repository
+}
+
+// Services holds the services the handlers are built on.
+type Services struct {
+	Route        route.Service
+	RoutePoint   routePoint.Service
+	CarDriver    carDriver.Service
+	Vehicle      vehicle.Service
+	Search       search.Service
+	Audit        audit.Service
+	Manifest     manifest.Service
+	Zone         zone.Service
+	Reporting    reporting.Service
+	Job          job.Service
+	Webhook      webhook.Service
+	Notification notification.Service
+	Idempotency  idempotency.Service
+}
+
+// Container wires the repositories, clients and services of the server from
+// its configuration, and starts its background workers.
+type Container struct {
+	Repositories Repositories
+	Services     Services
+	// PurchaseOrders is the client of the order system, nil when it is not
+	// configured
+	PurchaseOrders metrics.PurchaseOrderClient
+	// Location is the time zone of the days of the calendar
+	Location *time.Location
+
+	config               *config.Config
+	notificationChannels []notification.Channel
+}
+
+// StartWorkers starts the scheduled jobs and the dispatchers of events,
+// webhooks and notifications, which stop once ctx is done and are tracked in
+// workers.
+func (c *Container) StartWorkers(ctx context.Context, workers *sync.WaitGroup) {
+	cfg := c.config
+
+	// Background jobs
+	scheduler := job.NewScheduler(c.Repositories.Job)
+	if cfg.Features.Reconciliation && cfg.Reconciliation.Time != "" {
+		schedule, _ := job.ParseDaily(cfg.Reconciliation.Time, c.Location)
+		policy := reconciliation.Policy(cfg.Reconciliation.Policy)
+		scheduler.Add(reconciliation.NewJob(c.Repositories.Reconciliation, policy, c.Location), schedule)
+	}
+	if cfg.Features.PurchaseOrderSync && cfg.PurchaseOrder.SyncTime != "" && c.PurchaseOrders != nil {
+		schedule, _ := job.ParseDaily(cfg.PurchaseOrder.SyncTime, c.Location)
+		scheduler.Add(orderSync.NewJob(c.Repositories.RoutePoint, c.PurchaseOrders, cfg.PurchaseOrder.SyncWindow.Duration), schedule)
+	}
+	scheduler.Start(ctx, workers)
+
+	// Events recorded along with the changes to routes and route points are
+	// published in the background, in process and to webhook subscribers
+	eventBus := outbox.NewBus()
+	if cfg.Features.PurchaseOrderSync && c.PurchaseOrders != nil {
+		// Purchase orders follow the status of their route points
+		eventBus.Subscribe(orderSync.NewSyncer(c.PurchaseOrders).Handle, outbox.EventRoutePointStatusChanged)
+	}
+	// Customers are told about their deliveries, reached through the contact
+	// details of their purchase order
+	if c.PurchaseOrders != nil && len(c.notificationChannels) > 0 {
+		channelNames := make([]string, len(c.notificationChannels))
+		for i, channel := range c.notificationChannels {
+			channelNames[i] = channel.Name()
+		}
+		notifier := notification.NewNotifier(c.Repositories.Notification, c.PurchaseOrders, c.Services.Route,
+			channelNames, notification.Locale(cfg.Notifications.Locale), c.Location)
+		eventBus.Subscribe(notifier.Handle, outbox.EventRoutePointStatusChanged)
+	}
+	sinks := []outbox.Sink{eventBus}
+	if cfg.Features.Webhooks {
+		sinks = append(sinks, webhook.NewSink(c.Services.Webhook))
+	}
+	if cfg.Outbox.File != "" {
+		sinks = append(sinks, outbox.NewFileSink(cfg.Outbox.File))
+	}
+	outbox.NewDispatcher(c.Repositories.Outbox, cfg.Outbox.PollInterval.Duration, cfg.Outbox.MaxAttempts, sinks...).Start(ctx, workers)
+
+	// Webhook deliveries are sent and retried in the background
+	if cfg.Features.Webhooks {
+		webhook.NewDispatcher(c.Repositories.Webhook, webhook.Backoff{
+			Base:        cfg.Webhooks.BackoffBase.Duration,
+			Max:         cfg.Webhooks.BackoffMax.Duration,
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+		}, cfg.Webhooks.Timeout.Duration, cfg.Webhooks.PollInterval.Duration, cfg.Webhooks.AllowPrivateHosts).Start(ctx, workers)
+	}
+
+	// Notifications are sent and retried in the background
+	if cfg.Features.Notifications {
+		notification.NewDispatcher(c.Repositories.Notification, notification.Retry{
+			Base:        cfg.Notifications.BackoffBase.Duration,
+			Max:         cfg.Notifications.BackoffMax.Duration,
+			MaxAttempts: cfg.Notifications.MaxAttempts,
+		}, cfg.Notifications.PollInterval.Duration, c.notificationChannels...).Start(ctx, workers)
+	}
+}
+
+// static functions
+
+// New creates the repositories on db and the clients and services on top of
+// them, as configured. Requests to the order system are measured in
+// serverMetrics.
+func New(cfg *config.Config, db *gorm.DB, serverMetrics *metrics.Metrics) (*Container, error) {
+	c := &Container{config: cfg}
+
+	// Repositories
+	c.Repositories = Repositories{
+		Route:          route.NewRepository(db),
+		RoutePoint:     routePoint.NewRepository(db),
+		CarDriver:      carDriver.NewRepository(db),
+		Vehicle:        vehicle.NewRepository(db),
+		Search:         search.NewRepository(db),
+		Audit:          audit.NewRepository(db),
+		Idempotency:    idempotency.NewRepository(db),
+		Geocoder:       geocoder.NewRepository(db),
+		Zone:           zone.NewRepository(db),
+		Reporting:      reporting.NewRepository(db),
+		Job:            job.NewRepository(db),
+		Reconciliation: reconciliation.NewRepository(db),
+		Webhook:        webhook.NewRepository(db),
+		Notification:   notification.NewRepository(db),
+		Outbox:         outbox.NewRepository(db),
+	}
+
+	// Clients
+	var purchaseOrderClient purchaseOrder.Client
+	if cfg.PurchaseOrder.URL != "" {
+		c.PurchaseOrders = serverMetrics.NewPurchaseOrderClient(purchaseOrder.NewClient(cfg.PurchaseOrder.URL,
+			string(cfg.PurchaseOrder.APIKey), cfg.PurchaseOrder.StatusPath, cfg.PurchaseOrder.Timeout.Duration))
+		purchaseOrderClient = c.PurchaseOrders
+	}
+
+	// Geocoding fills in the coordinates or the address of new route points
+	var locator *routePoint.Locator
+	provider, err := newGeocoder(cfg.Geocoder)
+	if err != nil {
+		return nil, err
+	}
+	if provider != nil {
+		locator = routePoint.NewLocator(geocoder.NewCachedGeocoder(c.Repositories.Geocoder, provider),
+			cfg.Geocoder.MismatchThreshold)
+	}
+
+	// Days of the calendar, such as those of driver availability, are local
+	if c.Location, err = loadLocation(cfg.Timezone); err != nil {
+		return nil, err
+	}
+	manifestLocation, err := loadLocation(cfg.ManifestTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Features.Notifications {
+		c.notificationChannels = newNotificationChannels(cfg.Notifications)
+	}
+
+	// Services
+	carDriverService := carDriver.NewService(c.Repositories.CarDriver, c.Repositories.Route, carDriver.DrivingLimits{
+		Daily:  cfg.Drivers.MaxDailyDriving.Duration,
+		Weekly: cfg.Drivers.MaxWeeklyDriving.Duration,
+	}, c.Location)
+	vehicleService := vehicle.NewService(c.Repositories.Vehicle, vehicle.MaintenancePolicy{
+		IntervalKm:   cfg.Maintenance.IntervalKm,
+		IntervalDays: cfg.Maintenance.IntervalDays,
+		WarningKm:    cfg.Maintenance.WarningKm,
+		WarningDays:  cfg.Maintenance.WarningDays,
+	}, c.Location)
+	zoneService := zone.NewService(c.Repositories.Zone)
+	routeService := route.NewService(c.Repositories.Route, zoneService, carDriverService, vehicleService)
+	zoneCheck := routePoint.NewZoneCheck(routeService, routePoint.ZonePolicy(cfg.Zones.Policy))
+	c.Services = Services{
+		Route:        routeService,
+		RoutePoint:   routePoint.NewService(c.Repositories.RoutePoint, purchaseOrderClient, locator, zoneCheck, routeService),
+		CarDriver:    carDriverService,
+		Vehicle:      vehicleService,
+		Search:       search.NewService(c.Repositories.Search),
+		Audit:        audit.NewService(c.Repositories.Audit),
+		Manifest:     manifest.NewService(routeService, purchaseOrderClient, manifestLocation),
+		Zone:         zoneService,
+		Reporting:    reporting.NewService(c.Repositories.Reporting),
+		Job:          job.NewService(c.Repositories.Job),
+		Webhook:      webhook.NewService(c.Repositories.Webhook, cfg.Webhooks.AllowPrivateHosts),
+		Notification: notification.NewService(c.Repositories.Notification),
+		Idempotency:  idempotency.NewService(c.Repositories.Idempotency, cfg.Idempotency.TTL.Duration),
+	}
+	return c, nil
+}
+
+// newGeocoder creates the configured geocoding provider, or nil when
+// geocoding is disabled.
+func newGeocoder(cfg config.Geocoder) (geocoder.Geocoder, error) {
+	switch geocoder.Provider(cfg.Provider) {
+	case "":
+		return nil, nil
+	case geocoder.ProviderNominatim:
+		return geocoder.NewNominatimProvider(cfg.URL, cfg.UserAgent, cfg.Timeout.Duration), nil
+	case geocoder.ProviderFile:
+		fileProvider, err := geocoder.LoadFileProvider(cfg.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load geocoder file: %w", err)
+		}
+		return fileProvider, nil
+	default:
+		return nil, fmt.Errorf("unknown geocoder %q", cfg.Provider)
+	}
+}
+
+// newNotificationChannels creates the channels customers are notified on: SMTP
+// email with a host and HTTP SMS with a URL. With a notification file, the
+// channels not configured write to that file instead.
+func newNotificationChannels(cfg config.Notifications) []notification.Channel {
+	var channels []notification.Channel
+	if cfg.SMTP.Host != "" {
+		channels = append(channels, notification.NewSMTPChannel(cfg.SMTP.Host, cfg.SMTP.Port,
+			cfg.SMTP.Username, string(cfg.SMTP.Password), cfg.SMTP.From, cfg.SMTP.Timeout.Duration))
+	} else if cfg.File != "" {
+		channels = append(channels, notification.NewFileChannel(notification.ChannelEmail, cfg.File))
+	}
+	if cfg.SMS.URL != "" {
+		channels = append(channels, notification.NewSMSChannel(cfg.SMS.URL, string(cfg.SMS.APIKey), cfg.SMS.From,
+			cfg.SMS.Timeout.Duration))
+	} else if cfg.File != "" {
+		channels = append(channels, notification.NewFileChannel(notification.ChannelSMS, cfg.File))
+	}
+	return channels
+}
+
+// loadLocation loads a time zone, already validated with the configuration.
+func loadLocation(name string) (*time.Location, error) {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", name, err)
+	}
+	return location, nil
+}
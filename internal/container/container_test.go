@@ -0,0 +1,99 @@
+//go:build sqlite_fts5
+
+package container
+
+import (
+	"challenge-fravega/internal/audit"
+	"challenge-fravega/internal/config"
+	"challenge-fravega/internal/database"
+	"challenge-fravega/internal/job"
+	"challenge-fravega/internal/metrics"
+	"context"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// newTestContainer creates the container of the default configuration on a
+// new SQLite database with the migrations applied. The database is a
+// temporary file rather than :memory:, where every connection of the pool,
+// such as those of the workers, would get a database of its own.
+func newTestContainer(t *testing.T) *Container {
+	cfg := config.Default()
+	cfg.Database.Path = filepath.Join(t.TempDir(), "test.sqlite")
+	db, err := database.Open(database.DriverSQLite, cfg.Database.Path, &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	require.NoError(t, err)
+	require.NoError(t, database.MigrateDB(db, "../../db/migrations/sqlite", database.DefaultLockTimeout))
+
+	c, err := New(cfg, db, metrics.NewMetrics())
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew(t *testing.T) {
+	// Act
+	c := newTestContainer(t)
+
+	// Assert
+	for _, set := range []any{c.Repositories, c.Services} {
+		value := reflect.ValueOf(set)
+		for i := 0; i < value.NumField(); i++ {
+			assert.False(t, value.Field(i).IsNil(), "%s.%s", value.Type().Name(), value.Type().Field(i).Name)
+		}
+	}
+	assert.NotNil(t, c.Location)
+}
+
+func TestNewMatchesMigrations(t *testing.T) {
+	// Arrange
+	c := newTestContainer(t)
+	ctx := context.Background()
+
+	// Act
+	_, routesErr := c.Services.Route.GetRoutes(ctx)
+	_, routePointsErr := c.Services.RoutePoint.GetRoutePoints(ctx)
+	_, zonesErr := c.Services.Zone.GetZones(ctx)
+	_, subscriptionsErr := c.Services.Webhook.GetSubscriptions(ctx)
+	_, runsErr := c.Services.Job.GetRuns(ctx, &job.Filter{})
+	_, entriesErr := c.Services.Audit.GetEntries(ctx, &audit.Filter{})
+
+	// Assert
+	assert.NoError(t, routesErr)
+	assert.NoError(t, routePointsErr)
+	assert.NoError(t, zonesErr)
+	assert.NoError(t, subscriptionsErr)
+	assert.NoError(t, runsErr)
+	assert.NoError(t, entriesErr)
+}
+
+func TestStartWorkersStopsWithContext(t *testing.T) {
+	// Arrange
+	c := newTestContainer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	workers := &sync.WaitGroup{}
+	c.StartWorkers(ctx, workers)
+
+	// Act
+	cancel()
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+
+	// Assert
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("workers did not stop")
+	}
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DefaultSlowThreshold is the duration past which queries are logged as slow
+const DefaultSlowThreshold = 200 * time.Millisecond
+
+// slogLogger logs the GORM errors and slow queries through slog, with the
+// request ID of their context. Records not found are not errors worth
+// logging, as the repositories turn them into 404s.
+type slogLogger struct {
+	logger        *slog.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func (l *slogLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *slogLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		l.logger.InfoContext(ctx, msg, "args", args)
+	}
+}
+
+func (l *slogLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		l.logger.WarnContext(ctx, msg, "args", args)
+	}
+}
+
+func (l *slogLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		l.logger.ErrorContext(ctx, msg, "args", args)
+	}
+}
+
+func (l *slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		sql, rows := fc()
+		l.logger.ErrorContext(ctx, "Query failed", "sql", sql, "rows", rows, "duration", elapsed, "error", err)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.logger.WarnContext(ctx, "Slow query", "sql", sql, "rows", rows, "duration", elapsed)
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		l.logger.DebugContext(ctx, "Query", "sql", sql, "rows", rows, "duration", elapsed)
+	}
+}
+
+// static functions
+
+// NewLogger logs the GORM errors and the queries slower than slowThreshold
+// to the logger, and every query when it logs at debug level.
+func NewLogger(l *slog.Logger, slowThreshold time.Duration) logger.Interface {
+	level := logger.Warn
+	if l.Enabled(context.Background(), slog.LevelDebug) {
+		level = logger.Info
+	}
+	return &slogLogger{logger: l, level: level, slowThreshold: slowThreshold}
+}
@@ -1,109 +1,625 @@
 package database
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-// MigrateDB runs all migrations from the specified directory
-func MigrateDB(db *gorm.DB, migrationsDir string) error {
-	// Create migration table if it doesn't exist
-	err := db.Exec(`CREATE TABLE IF NOT EXISTS migrations (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	)`).Error
+var (
+	// ErrMigrationsLocked is returned while another run holds the lock of the
+	// migrations
+	ErrMigrationsLocked = errors.New("migrations are locked")
+	// ErrMigrationDrift is returned when applied migrations were edited or
+	// removed since
+	ErrMigrationDrift = errors.New("applied migrations changed")
+	// ErrNoDownMigration is returned when rolling back a migration without a
+	// down file
+	ErrNoDownMigration = errors.New("migration has no down file")
+)
+
+// DefaultLockTimeout is how long the lock of the migrations goes without
+// being refreshed before another run takes it over.
+const DefaultLockTimeout = time.Minute
+
+// Direction tells whether a step applies or rolls back a migration.
+type Direction string
+
+const (
+	DirectionUp   Direction = "up"
+	DirectionDown Direction = "down"
+)
+
+var DirectionList = map[Direction]string{
+	DirectionUp:   "up",
+	DirectionDown: "down",
+}
+
+// Migration is a pair of SQL files sharing a name, such as
+// 001_initial_schema.up.sql and 001_initial_schema.down.sql. A plain .sql file
+// is an up file without a down one.
+type Migration struct {
+	Name     string
+	UpFile   string
+	DownFile string
+	// Checksum is the SHA-256 of the up file, recorded when it is applied to
+	// tell when it is edited afterwards
+	Checksum string
+}
+
+// MigrationStatus tells whether a migration was applied, and whether its up
+// file changed (Drifted) or is gone (Missing) since.
+type MigrationStatus struct {
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Drifted   bool
+	Missing   bool
+}
+
+// Step is a migration applied or rolled back, or to be on a dry run.
+type Step struct {
+	Name      string
+	Direction Direction
+	SQL       string
+}
+
+// appliedMigration is a row of the migrations table.
+type appliedMigration struct {
+	ID        int64
+	Name      string
+	Checksum  *string
+	AppliedAt *time.Time
+}
+
+// migrationLock is the row of the lock table.
+type migrationLock struct {
+	Owner    string
+	LockedAt time.Time
+}
+
+// Migrator applies and rolls back the migrations of a directory, recording
+// them in the migrations table. Runs that change the database hold a lock, so
+// that servers starting together or an operator running the CLI meanwhile
+// fail with ErrMigrationsLocked instead of applying the same migration twice.
+// The run refreshes the lock as it goes, and a lock left without refreshing
+// for lockTimeout, by a run killed midway, is taken over.
+type Migrator struct {
+	db          *gorm.DB
+	dir         string
+	owner       string
+	lockTimeout time.Duration
+}
+
+// Status returns every migration, in the directory or applied, by name.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := readMigrations(m.dir)
 	if err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get list of applied migrations
-	var appliedMigrations []string
-	if err := db.Raw("SELECT name FROM migrations ORDER BY id").Scan(&appliedMigrations).Error; err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
+	appliedMap := make(map[string]appliedMigration)
+	for _, a := range applied {
+		appliedMap[a.Name] = a
 	}
+	var statuses []MigrationStatus
+	for name, migration := range migrations {
+		status := MigrationStatus{Name: name}
+		if a, ok := appliedMap[name]; ok {
+			status.Applied = true
+			status.AppliedAt = a.AppliedAt
+			status.Drifted = a.Checksum != nil && *a.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+	for _, a := range applied {
+		if _, ok := migrations[a.Name]; !ok {
+			statuses = append(statuses, MigrationStatus{Name: a.Name, Applied: true, AppliedAt: a.AppliedAt, Missing: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// Up applies the pending migrations by name, at most limit of them unless it
+// is 0.
+func (m *Migrator) Up(ctx context.Context, limit int, dryRun bool) ([]Step, error) {
+	return m.run(ctx, dryRun, func(migrations map[string]Migration, applied []appliedMigration) ([]Step, error) {
+		appliedMap := make(map[string]bool)
+		for _, a := range applied {
+			appliedMap[a.Name] = true
+		}
+		var steps []Step
+		for _, name := range sortedNames(migrations) {
+			if appliedMap[name] {
+				continue
+			}
+			if limit > 0 && len(steps) == limit {
+				break
+			}
+			step, err := newStep(migrations[name], DirectionUp)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+		}
+		return steps, nil
+	})
+}
+
+// Down rolls back the last count migrations applied, the latest first.
+func (m *Migrator) Down(ctx context.Context, count int, dryRun bool) ([]Step, error) {
+	return m.run(ctx, dryRun, func(migrations map[string]Migration, applied []appliedMigration) ([]Step, error) {
+		var steps []Step
+		for i := len(applied) - 1; i >= 0 && len(steps) < count; i-- {
+			step, err := newStep(migrations[applied[i].Name], DirectionDown)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+		}
+		return steps, nil
+	})
+}
+
+// Redo rolls back the last migration applied and applies it again, to try
+// out the migration being written.
+func (m *Migrator) Redo(ctx context.Context, dryRun bool) ([]Step, error) {
+	return m.run(ctx, dryRun, func(migrations map[string]Migration, applied []appliedMigration) ([]Step, error) {
+		if len(applied) == 0 {
+			return nil, nil
+		}
+		migration := migrations[applied[len(applied)-1].Name]
+		down, err := newStep(migration, DirectionDown)
+		if err != nil {
+			return nil, err
+		}
+		up, err := newStep(migration, DirectionUp)
+		if err != nil {
+			return nil, err
+		}
+		return []Step{down, up}, nil
+	})
+}
 
-	appliedMap := make(map[string]bool)
-	for _, m := range appliedMigrations {
-		appliedMap[m] = true
+// Unlock releases the lock left behind by a run that did not finish, such as
+// one killed midway.
+func (m *Migrator) Unlock(ctx context.Context) error {
+	if err := m.createTables(ctx); err != nil {
+		return err
 	}
+	return m.db.WithContext(ctx).Exec("DELETE FROM migration_lock").Error
+}
 
-	// Get migration files
-	files, err := getMigrationFiles(migrationsDir)
+// run plans the steps from the migrations in the directory and those applied,
+// once they are checked to agree, and takes them unless on a dry run. Each
+// step runs in a transaction of its own; the steps taken before one failing
+// are returned along with its error.
+func (m *Migrator) run(ctx context.Context, dryRun bool, plan func(map[string]Migration, []appliedMigration) ([]Step, error)) ([]Step, error) {
+	migrations, err := readMigrations(m.dir)
 	if err != nil {
-		return fmt.Errorf("failed to get migration files: %w", err)
+		return nil, err
+	}
+	if !dryRun {
+		if err := m.createTables(ctx); err != nil {
+			return nil, err
+		}
+		if err := m.lock(ctx); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := m.unlock(context.WithoutCancel(ctx)); err != nil {
+				slog.Error("Failed to unlock migrations", "error", err)
+			}
+		}()
+		defer m.keepLocked(ctx)()
+		if err := m.adopt(ctx, migrations); err != nil {
+			return nil, err
+		}
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDrift(migrations, applied); err != nil {
+		return nil, err
+	}
+	steps, err := plan(migrations, applied)
+	if err != nil || dryRun {
+		return steps, err
 	}
 
-	// Run pending migrations
-	for _, file := range files {
-		migrationName := filepath.Base(file)
-
-		// Skip if already applied
-		if appliedMap[migrationName] {
-			fmt.Printf("Migration already applied: %s\n", migrationName)
-			continue
+	for i, step := range steps {
+		if err := m.take(ctx, step, migrations[step.Name]); err != nil {
+			return steps[:i], err
 		}
+	}
+	return steps, nil
+}
 
-		// Read migration file
-		content, err := os.ReadFile(file)
+// take runs the SQL of a step and records it in the same transaction.
+func (m *Migrator) take(ctx context.Context, step Step, migration Migration) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(step.SQL).Error; err != nil {
+			return fmt.Errorf("failed to %s migration %s: %w", verbOf(step.Direction), step.Name, err)
+		}
+		var err error
+		if step.Direction == DirectionUp {
+			err = tx.Exec("INSERT INTO migrations (name, checksum) VALUES (?, ?)", step.Name, migration.Checksum).Error
+		} else {
+			err = tx.Exec("DELETE FROM migrations WHERE name = ?", step.Name).Error
+		}
 		if err != nil {
-			return fmt.Errorf("failed to read migration file %s: %w", file, err)
+			return fmt.Errorf("failed to record migration %s: %w", step.Name, err)
+		}
+		return nil
+	})
+}
+
+// createTables creates the migrations table and the lock, and adds the
+// checksum column to migrations tables older than it.
+func (m *Migrator) createTables(ctx context.Context) error {
+	db := m.db.WithContext(ctx)
+	createTable := `CREATE TABLE IF NOT EXISTS migrations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		checksum TEXT,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+	createLock := `CREATE TABLE IF NOT EXISTS migration_lock (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		owner TEXT NOT NULL,
+		locked_at TIMESTAMP NOT NULL
+	)`
+	if IsPostgres(db) {
+		createTable = `CREATE TABLE IF NOT EXISTS migrations (
+		id BIGSERIAL PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT,
+		applied_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	)`
+		createLock = `CREATE TABLE IF NOT EXISTS migration_lock (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		owner TEXT NOT NULL,
+		locked_at TIMESTAMPTZ NOT NULL
+	)`
+	}
+	if err := db.Exec(createTable).Error; err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	if !db.Migrator().HasColumn("migrations", "checksum") {
+		if err := db.Exec("ALTER TABLE migrations ADD COLUMN checksum TEXT").Error; err != nil {
+			return fmt.Errorf("failed to add checksum to migrations table: %w", err)
 		}
+	}
+	if err := db.Exec(createLock).Error; err != nil {
+		return fmt.Errorf("failed to create migration lock table: %w", err)
+	}
+	return nil
+}
 
-		// Execute migration within a transaction
-		tx := db.Begin()
-		if err := tx.Exec(string(content)).Error; err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to apply migration %s: %w", migrationName, err)
+// lock takes the lock of the migrations, failing with ErrMigrationsLocked
+// while another run holds it. A lock not refreshed for the lock timeout is
+// taken over.
+func (m *Migrator) lock(ctx context.Context) error {
+	db := m.db.WithContext(ctx)
+	err := m.insertLock(db)
+	if err == nil {
+		return nil
+	}
+	holder, found := m.holder(db)
+	if found && m.lockTimeout > 0 && time.Since(holder.LockedAt) > m.lockTimeout {
+		// Only the stale lock is deleted, in case another run took it over first
+		result := db.Exec("DELETE FROM migration_lock WHERE id = 1 AND owner = ? AND locked_at < ?",
+			holder.Owner, time.Now().UTC().Add(-m.lockTimeout))
+		if result.Error == nil && result.RowsAffected == 1 {
+			slog.Warn("Took over a stale migration lock", "owner", holder.Owner, "locked_at", holder.LockedAt)
+			if err = m.insertLock(db); err == nil {
+				return nil
+			}
+			holder, found = m.holder(db)
 		}
+	}
+	if found {
+		return fmt.Errorf("%w by %s since %s", ErrMigrationsLocked, holder.Owner, holder.LockedAt.Format(time.RFC3339))
+	}
+	return fmt.Errorf("failed to lock migrations: %w", err)
+}
+
+// holder returns the run holding the lock of the migrations, if any.
+func (m *Migrator) holder(db *gorm.DB) (migrationLock, bool) {
+	var holder migrationLock
+	err := db.Raw("SELECT owner, locked_at FROM migration_lock WHERE id = 1").Scan(&holder).Error
+	return holder, err == nil && holder.Owner != ""
+}
 
-		// Record migration
-		if err := tx.Exec("INSERT INTO migrations (name) VALUES (?)", migrationName).Error; err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to record migration %s: %w", migrationName, err)
+func (m *Migrator) insertLock(db *gorm.DB) error {
+	// Failing while another run holds it is expected, and not logged
+	return db.Session(&gorm.Session{Logger: logger.Discard}).
+		Exec("INSERT INTO migration_lock (id, owner, locked_at) VALUES (1, ?, ?)", m.owner, time.Now().UTC()).Error
+}
+
+// keepLocked refreshes the lock every third of the lock timeout until the
+// function returned is called, so that a run longer than the timeout is not
+// taken over.
+func (m *Migrator) keepLocked(ctx context.Context) func() {
+	if m.lockTimeout <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(m.lockTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			result := m.db.WithContext(ctx).Exec("UPDATE migration_lock SET locked_at = ? WHERE id = 1 AND owner = ?",
+				time.Now().UTC(), m.owner)
+			if ctx.Err() != nil {
+				return
+			}
+			if result.Error != nil {
+				slog.Warn("Failed to refresh the migration lock", "error", result.Error)
+			} else if result.RowsAffected == 0 {
+				slog.Error("Migration lock was taken over by another run")
+			}
 		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
 
-		if err := tx.Commit().Error; err != nil {
-			return fmt.Errorf("failed to commit migration %s: %w", migrationName, err)
+func (m *Migrator) unlock(ctx context.Context) error {
+	return m.db.WithContext(ctx).Exec("DELETE FROM migration_lock WHERE id = 1 AND owner = ?", m.owner).Error
+}
+
+// adopt records the checksum of the migrations applied before checksums
+// were, trusting the files as they are, and drops the .sql suffix their names
+// were recorded with.
+func (m *Migrator) adopt(ctx context.Context, migrations map[string]Migration) error {
+	var rows []appliedMigration
+	if err := m.db.WithContext(ctx).Raw("SELECT id, name, checksum FROM migrations WHERE checksum IS NULL").Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	for _, row := range rows {
+		name := strings.TrimSuffix(row.Name, ".sql")
+		migration, ok := migrations[name]
+		if !ok {
+			continue
 		}
+		if err := m.db.WithContext(ctx).Exec("UPDATE migrations SET name = ?, checksum = ? WHERE id = ?",
+			name, migration.Checksum, row.ID).Error; err != nil {
+			return fmt.Errorf("failed to record checksum of migration %s: %w", name, err)
+		}
+	}
+	return nil
+}
 
-		fmt.Printf("Applied migration: %s\n", migrationName)
+// applied returns the migrations applied, in the order they were. There are
+// none before the migrations table is created.
+func (m *Migrator) applied(ctx context.Context) ([]appliedMigration, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable("migrations") {
+		return nil, nil
+	}
+	query := "SELECT id, name, checksum, applied_at FROM migrations ORDER BY id"
+	if !db.Migrator().HasColumn("migrations", "checksum") {
+		query = "SELECT id, name, NULL AS checksum, applied_at FROM migrations ORDER BY id"
 	}
+	var applied []appliedMigration
+	if err := db.Raw(query).Scan(&applied).Error; err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	for i := range applied {
+		applied[i].Name = strings.TrimSuffix(applied[i].Name, ".sql")
+	}
+	return applied, nil
+}
 
-	return nil
+// static functions
+
+// NewMigrator creates the migrator of the migrations in migrationsDir, taking
+// over locks not refreshed for lockTimeout, or never when it is 0.
+func NewMigrator(db *gorm.DB, migrationsDir string, lockTimeout time.Duration) *Migrator {
+	host, _ := os.Hostname()
+	return &Migrator{db: db, dir: migrationsDir, owner: fmt.Sprintf("%s:%d", host, os.Getpid()), lockTimeout: lockTimeout}
+}
+
+// MigrateDB runs all migrations from the specified directory
+func MigrateDB(db *gorm.DB, migrationsDir string, lockTimeout time.Duration) error {
+	steps, err := NewMigrator(db, migrationsDir, lockTimeout).Up(context.Background(), 0, false)
+	for _, step := range steps {
+		slog.Info("Applied migration", "migration", step.Name)
+	}
+	return err
 }
 
-// getMigrationFiles returns a sorted list of SQL migration files
-func getMigrationFiles(migrationsDir string) ([]string, error) {
-	// Check if directory exists
-	if _, err := os.Stat(migrationsDir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("migrations directory does not exist: %s", migrationsDir)
+// PendingMigrations returns the names of the migrations from the specified
+// directory not applied yet
+func PendingMigrations(db *gorm.DB, migrationsDir string) ([]string, error) {
+	statuses, err := NewMigrator(db, migrationsDir, 0).Status(db.Statement.Context)
+	if err != nil {
+		return nil, err
 	}
+	var pending []string
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status.Name)
+		}
+	}
+	return pending, nil
+}
 
-	var files []string
-	err := filepath.Walk(migrationsDir, func(path string, info os.FileInfo, err error) error {
+// CreateMigration writes the empty up and down files of a new migration in
+// each directory, numbered after the last migration in any of them, and
+// returns their paths.
+func CreateMigration(name string, dirs ...string) ([]string, error) {
+	slug := strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return nil, fmt.Errorf("invalid migration name %q", name)
+	}
+	last := 0
+	for _, dir := range dirs {
+		migrations, err := readMigrations(dir)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		for name := range migrations {
+			prefix, _, _ := strings.Cut(name, "_")
+			if number, err := strconv.Atoi(prefix); err == nil && number > last {
+				last = number
+			}
 		}
+	}
+	name = fmt.Sprintf("%03d_%s", last+1, slug)
 
-		if !info.IsDir() && strings.HasSuffix(info.Name(), ".sql") {
-			files = append(files, path)
+	var paths []string
+	for _, dir := range dirs {
+		for _, direction := range []Direction{DirectionUp, DirectionDown} {
+			path := filepath.Join(dir, name+"."+DirectionList[direction]+".sql")
+			header := "-- Migration: " + name + "\n\n"
+			if direction == DirectionDown {
+				header = "-- Migration: " + name + " (down)\n\n"
+			}
+			file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+			if err != nil {
+				return paths, err
+			}
+			_, err = file.WriteString(header)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return paths, err
+			}
+			paths = append(paths, path)
 		}
+	}
+	return paths, nil
+}
 
-		return nil
-	})
+// nonWord matches what cannot be part of the name of a migration file.
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
 
+// readMigrations returns the migrations of the directory by name.
+func readMigrations(migrationsDir string) (map[string]Migration, error) {
+	entries, err := os.ReadDir(migrationsDir)
 	if err != nil {
-		return nil, err
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("migrations directory does not exist: %s", migrationsDir)
+		}
+		return nil, fmt.Errorf("failed to get migration files: %w", err)
 	}
 
-	// Sort files by name to ensure correct order
-	sort.Strings(files)
+	migrations := make(map[string]Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		path := filepath.Join(migrationsDir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		direction := DirectionUp
+		if base, ok := strings.CutSuffix(name, ".down"); ok {
+			name, direction = base, DirectionDown
+		} else {
+			name = strings.TrimSuffix(name, ".up")
+		}
 
-	return files, nil
+		migration := migrations[name]
+		migration.Name = name
+		if direction == DirectionDown {
+			migration.DownFile = path
+		} else {
+			if migration.UpFile != "" {
+				return nil, fmt.Errorf("migration %s has two up files", name)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read migration file %s: %w", path, err)
+			}
+			sum := sha256.Sum256(content)
+			migration.UpFile, migration.Checksum = path, hex.EncodeToString(sum[:])
+		}
+		migrations[name] = migration
+	}
+	for name, migration := range migrations {
+		if migration.UpFile == "" {
+			return nil, fmt.Errorf("migration %s has no up file", name)
+		}
+	}
+	return migrations, nil
+}
+
+// checkDrift fails with ErrMigrationDrift when applied migrations were edited
+// or removed since. Those applied before checksums were recorded are trusted.
+func checkDrift(migrations map[string]Migration, applied []appliedMigration) error {
+	var changed []string
+	for _, a := range applied {
+		migration, ok := migrations[a.Name]
+		if !ok {
+			changed = append(changed, a.Name+" (missing)")
+		} else if a.Checksum != nil && *a.Checksum != migration.Checksum {
+			changed = append(changed, a.Name+" (edited)")
+		}
+	}
+	if len(changed) > 0 {
+		return fmt.Errorf("%w: %s", ErrMigrationDrift, strings.Join(changed, ", "))
+	}
+	return nil
+}
+
+// newStep reads the SQL of a migration in a direction.
+func newStep(migration Migration, direction Direction) (Step, error) {
+	path := migration.UpFile
+	if direction == DirectionDown {
+		if migration.DownFile == "" {
+			return Step{}, fmt.Errorf("%w: %s", ErrNoDownMigration, migration.Name)
+		}
+		path = migration.DownFile
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Step{}, fmt.Errorf("failed to read migration file %s: %w", path, err)
+	}
+	return Step{Name: migration.Name, Direction: direction, SQL: string(content)}, nil
+}
+
+func sortedNames(migrations map[string]Migration) []string {
+	names := make([]string, 0, len(migrations))
+	for name := range migrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func verbOf(direction Direction) string {
+	if direction == DirectionDown {
+		return "roll back"
+	}
+	return "apply"
 }
@@ -0,0 +1,270 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// newTestMigrator creates a migrator of a directory with the given files, on
+// a new SQLite database.
+func newTestMigrator(t *testing.T, files map[string]string) (*Migrator, *gorm.DB, string) {
+	dir := t.TempDir()
+	migrationsDir := filepath.Join(dir, "migrations")
+	require.NoError(t, os.Mkdir(migrationsDir, 0o755))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(migrationsDir, name), []byte(content), 0o644))
+	}
+	db, err := Open(DriverSQLite, filepath.Join(dir, "test.sqlite"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	require.NoError(t, err)
+	return NewMigrator(db, migrationsDir, DefaultLockTimeout), db, migrationsDir
+}
+
+var testMigrations = map[string]string{
+	"001_widget.up.sql":      "CREATE TABLE widget (id INTEGER PRIMARY KEY);",
+	"001_widget.down.sql":    "DROP TABLE widget;",
+	"002_gadget.up.sql":      "CREATE TABLE gadget (id INTEGER PRIMARY KEY);",
+	"002_gadget.down.sql":    "DROP TABLE gadget;",
+	"003_doohickey.up.sql":   "CREATE TABLE doohickey (id INTEGER PRIMARY KEY);",
+	"003_doohickey.down.sql": "DROP TABLE doohickey;",
+}
+
+func stepNames(steps []Step) []string {
+	var names []string
+	for _, step := range steps {
+		names = append(names, string(step.Direction)+" "+step.Name)
+	}
+	return names
+}
+
+func TestMigratorUpAndDown(t *testing.T) {
+	// Arrange
+	migrator, db, _ := newTestMigrator(t, testMigrations)
+	ctx := context.Background()
+
+	// Act
+	up, upErr := migrator.Up(ctx, 0, false)
+	down, downErr := migrator.Down(ctx, 2, false)
+
+	// Assert
+	require.NoError(t, upErr)
+	require.NoError(t, downErr)
+	assert.Equal(t, []string{"up 001_widget", "up 002_gadget", "up 003_doohickey"}, stepNames(up))
+	assert.Equal(t, []string{"down 003_doohickey", "down 002_gadget"}, stepNames(down))
+	assert.True(t, db.Migrator().HasTable("widget"))
+	assert.False(t, db.Migrator().HasTable("gadget"))
+	pending, err := PendingMigrations(db, migrator.dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"002_gadget", "003_doohickey"}, pending)
+}
+
+func TestMigratorUpLimit(t *testing.T) {
+	// Arrange
+	migrator, _, _ := newTestMigrator(t, testMigrations)
+
+	// Act
+	steps, err := migrator.Up(context.Background(), 1, false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"up 001_widget"}, stepNames(steps))
+}
+
+func TestMigratorDryRun(t *testing.T) {
+	// Arrange
+	migrator, db, _ := newTestMigrator(t, testMigrations)
+
+	// Act
+	steps, err := migrator.Up(context.Background(), 0, true)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, steps, 3)
+	assert.Equal(t, "CREATE TABLE widget (id INTEGER PRIMARY KEY);", steps[0].SQL)
+	assert.False(t, db.Migrator().HasTable("widget"))
+	assert.False(t, db.Migrator().HasTable("migrations"))
+}
+
+func TestMigratorRedo(t *testing.T) {
+	// Arrange
+	migrator, db, _ := newTestMigrator(t, testMigrations)
+	ctx := context.Background()
+	_, err := migrator.Up(ctx, 0, false)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("INSERT INTO doohickey (id) VALUES (1)").Error)
+
+	// Act
+	steps, err := migrator.Redo(ctx, false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"down 003_doohickey", "up 003_doohickey"}, stepNames(steps))
+	var count int64
+	assert.NoError(t, db.Table("doohickey").Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestMigratorDetectsDrift(t *testing.T) {
+	// Arrange
+	migrator, _, dir := newTestMigrator(t, testMigrations)
+	ctx := context.Background()
+	_, err := migrator.Up(ctx, 2, false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "001_widget.up.sql"),
+		[]byte("CREATE TABLE widget (id INTEGER PRIMARY KEY, name TEXT);"), 0o644))
+
+	// Act
+	_, err = migrator.Up(ctx, 0, false)
+	statuses, statusErr := migrator.Status(ctx)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrMigrationDrift)
+	assert.ErrorContains(t, err, "001_widget (edited)")
+	require.NoError(t, statusErr)
+	require.Len(t, statuses, 3)
+	assert.True(t, statuses[0].Drifted)
+	assert.False(t, statuses[1].Drifted)
+	assert.False(t, statuses[2].Applied)
+}
+
+func TestMigratorDownWithoutDownFile(t *testing.T) {
+	// Arrange
+	migrator, _, _ := newTestMigrator(t, map[string]string{
+		"001_widget.sql": "CREATE TABLE widget (id INTEGER PRIMARY KEY);",
+	})
+	ctx := context.Background()
+	_, err := migrator.Up(ctx, 0, false)
+	require.NoError(t, err)
+
+	// Act
+	_, err = migrator.Down(ctx, 1, false)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNoDownMigration)
+}
+
+func TestMigratorLocked(t *testing.T) {
+	// Arrange
+	migrator, db, dir := newTestMigrator(t, testMigrations)
+	ctx := context.Background()
+	other := NewMigrator(db, dir, DefaultLockTimeout)
+	other.owner = "other-host:1"
+	require.NoError(t, other.createTables(ctx))
+	require.NoError(t, other.lock(ctx))
+
+	// Act
+	_, err := migrator.Up(ctx, 0, false)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrMigrationsLocked)
+	assert.ErrorContains(t, err, "other-host:1")
+	assert.NoError(t, migrator.Unlock(ctx))
+	_, err = migrator.Up(ctx, 0, false)
+	assert.NoError(t, err)
+}
+
+func TestMigratorTakesOverStaleLock(t *testing.T) {
+	// Arrange
+	migrator, db, _ := newTestMigrator(t, testMigrations)
+	ctx := context.Background()
+	require.NoError(t, migrator.createTables(ctx))
+	require.NoError(t, db.Exec("INSERT INTO migration_lock (id, owner, locked_at) VALUES (1, ?, ?)",
+		"killed-host:1", time.Now().UTC().Add(-2*DefaultLockTimeout)).Error)
+
+	// Act
+	steps, err := migrator.Up(ctx, 0, false)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, steps, 3)
+	var count int64
+	require.NoError(t, db.Raw("SELECT COUNT(*) FROM migration_lock").Scan(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestMigratorKeepsFreshLock(t *testing.T) {
+	// Arrange
+	migrator, db, _ := newTestMigrator(t, testMigrations)
+	ctx := context.Background()
+	require.NoError(t, migrator.createTables(ctx))
+	require.NoError(t, db.Exec("INSERT INTO migration_lock (id, owner, locked_at) VALUES (1, ?, ?)",
+		"other-host:1", time.Now().UTC().Add(-DefaultLockTimeout/2)).Error)
+
+	// Act
+	_, err := migrator.Up(ctx, 0, false)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrMigrationsLocked)
+	assert.ErrorContains(t, err, "other-host:1")
+}
+
+func TestMigratorRefreshesLock(t *testing.T) {
+	// Arrange
+	migrator, db, _ := newTestMigrator(t, testMigrations)
+	migrator.lockTimeout = 30 * time.Millisecond
+	ctx := context.Background()
+	require.NoError(t, migrator.createTables(ctx))
+	require.NoError(t, migrator.lock(ctx))
+	stop := migrator.keepLocked(ctx)
+
+	// Act
+	time.Sleep(3 * migrator.lockTimeout)
+	other := NewMigrator(db, migrator.dir, migrator.lockTimeout)
+	other.owner = "other-host:1"
+	err := other.lock(ctx)
+	stop()
+
+	// Assert
+	assert.ErrorIs(t, err, ErrMigrationsLocked)
+}
+
+func TestMigratorAdoptsLegacyMigrations(t *testing.T) {
+	// Arrange
+	migrator, db, _ := newTestMigrator(t, testMigrations)
+	ctx := context.Background()
+	require.NoError(t, db.Exec(`CREATE TABLE migrations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`).Error)
+	require.NoError(t, db.Exec("CREATE TABLE widget (id INTEGER PRIMARY KEY)").Error)
+	require.NoError(t, db.Exec("INSERT INTO migrations (name) VALUES ('001_widget.sql')").Error)
+
+	// Act
+	steps, err := migrator.Up(ctx, 0, false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"up 002_gadget", "up 003_doohickey"}, stepNames(steps))
+	var checksums []string
+	assert.NoError(t, db.Raw("SELECT checksum FROM migrations WHERE name = '001_widget'").Scan(&checksums).Error)
+	assert.Len(t, checksums, 1)
+	assert.NotEmpty(t, checksums[0])
+}
+
+func TestCreateMigration(t *testing.T) {
+	// Arrange
+	sqliteDir, postgresDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sqliteDir, "007_widget.up.sql"), nil, 0o644))
+
+	// Act
+	paths, err := CreateMigration("Add gadget colour", sqliteDir, postgresDir)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(sqliteDir, "008_add_gadget_colour.up.sql"),
+		filepath.Join(sqliteDir, "008_add_gadget_colour.down.sql"),
+		filepath.Join(postgresDir, "008_add_gadget_colour.up.sql"),
+		filepath.Join(postgresDir, "008_add_gadget_colour.down.sql"),
+	}, paths)
+}
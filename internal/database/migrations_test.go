@@ -0,0 +1,49 @@
+//go:build sqlite_fts5
+
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// schemaOf returns the SQL of the tables, indexes and triggers of the
+// database, other than those of the migrations and SQLite itself.
+func schemaOf(t *testing.T, db *gorm.DB) []string {
+	var statements []string
+	require.NoError(t, db.Raw(`SELECT sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND name NOT IN ('migrations', 'migration_lock', 'sqlite_sequence') ORDER BY type, name`).
+		Scan(&statements).Error)
+	return statements
+}
+
+func TestSQLiteMigrationsRollBack(t *testing.T) {
+	// Arrange
+	db, err := Open(DriverSQLite, filepath.Join(t.TempDir(), "test.sqlite"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	require.NoError(t, err)
+	migrator := NewMigrator(db, "../../db/migrations/sqlite", DefaultLockTimeout)
+	ctx := context.Background()
+	applied, err := migrator.Up(ctx, 0, false)
+	require.NoError(t, err)
+	migrated := schemaOf(t, db)
+
+	// Act
+	rolledBack, downErr := migrator.Down(ctx, len(applied), false)
+	empty := schemaOf(t, db)
+	_, upErr := migrator.Up(ctx, 0, false)
+
+	// Assert
+	require.NoError(t, downErr)
+	require.NoError(t, upErr)
+	assert.Len(t, rolledBack, len(applied))
+	assert.Empty(t, empty)
+	assert.Equal(t, migrated, schemaOf(t, db))
+}
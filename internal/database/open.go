@@ -0,0 +1,46 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Driver is the storage backend of the server. SQLite keeps everything in a
+// single file and suits a single instance, PostgreSQL is shared by as many
+// instances as needed.
+type Driver string
+
+const (
+	DriverSQLite   Driver = "sqlite"
+	DriverPostgres Driver = "postgres"
+)
+
+var DriverList = map[Driver]string{
+	DriverSQLite:   "sqlite",
+	DriverPostgres: "postgres",
+}
+
+// static functions
+
+// Open connects to the database of the driver. The data source is the path
+// of the file for SQLite, and a postgres:// URL or a key=value connection
+// string for PostgreSQL.
+func Open(driver Driver, dataSource string, config *gorm.Config) (*gorm.DB, error) {
+	switch driver {
+	case DriverSQLite:
+		return gorm.Open(sqlite.Open(dataSource), config)
+	case DriverPostgres:
+		return gorm.Open(postgres.Open(dataSource), config)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", driver)
+	}
+}
+
+// IsPostgres tells whether db is a PostgreSQL database, for the few queries
+// written differently for it.
+func IsPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == DriverList[DriverPostgres]
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// openTestPostgres connects to the server of POSTGRES_TEST_URL within a schema
+// of its own, dropped when the test ends, and skips the test when the variable
+// is not set.
+func openTestPostgres(t *testing.T) *gorm.DB {
+	dataSource := os.Getenv("POSTGRES_TEST_URL")
+	if dataSource == "" {
+		t.Skip("POSTGRES_TEST_URL is not set")
+	}
+	config := &gorm.Config{NamingStrategy: schema.NamingStrategy{SingularTable: true}}
+	admin, err := Open(DriverPostgres, dataSource, config)
+	require.NoError(t, err)
+
+	name := "test_" + uuid.NewString()[:8]
+	require.NoError(t, admin.Exec(fmt.Sprintf("CREATE SCHEMA %s", name)).Error)
+	t.Cleanup(func() {
+		admin.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", name))
+	})
+
+	source, err := url.Parse(dataSource)
+	require.NoError(t, err)
+	query := source.Query()
+	query.Set("search_path", name+",public")
+	source.RawQuery = query.Encode()
+	db, err := Open(DriverPostgres, source.String(), config)
+	require.NoError(t, err)
+	return db
+}
+
+func TestPostgresMigrations(t *testing.T) {
+	// Arrange
+	db := openTestPostgres(t)
+
+	// Act
+	err := MigrateDB(db, "../../db/migrations/postgres", DefaultLockTimeout)
+
+	// Assert
+	require.NoError(t, err)
+	pending, err := PendingMigrations(db, "../../db/migrations/postgres")
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.NoError(t, MigrateDB(db, "../../db/migrations/postgres", DefaultLockTimeout))
+}
+
+func TestPostgresMigrationsRollBack(t *testing.T) {
+	// Arrange
+	db := openTestPostgres(t)
+	migrator := NewMigrator(db, "../../db/migrations/postgres", DefaultLockTimeout)
+	ctx := context.Background()
+	up, err := migrator.Up(ctx, 0, false)
+	require.NoError(t, err)
+
+	// Act
+	down, downErr := migrator.Down(ctx, len(up), false)
+	_, upErr := migrator.Up(ctx, 0, false)
+
+	// Assert
+	require.NoError(t, downErr)
+	assert.Len(t, down, len(up))
+	assert.NoError(t, upErr)
+}
+
+func TestPostgresRoutePointVersion(t *testing.T) {
+	// Arrange
+	db := openTestPostgres(t)
+	require.NoError(t, MigrateDB(db, "../../db/migrations/postgres", DefaultLockTimeout))
+	routeID := "cf3e1d1b-5a3a-4b4e-8d8a-6d0a9b1d2c31"
+	require.NoError(t, db.Exec(`INSERT INTO route (id, name, status, vehicle_id, driver_id)
+		SELECT ?, 'Test route', 'pending', vehicle.id, driver.id FROM vehicle, driver LIMIT 1`, routeID).Error)
+	var version int
+	require.NoError(t, db.Raw("SELECT version FROM route WHERE id = ?", routeID).Scan(&version).Error)
+
+	// Act
+	err := db.Exec(`INSERT INTO route_point (id, route_id, purchase_order_id, latitude, longitude, address, status)
+		VALUES (?, ?, 'PO-1', -34.6, -58.4, 'Av. Corrientes 1234', 'pending')`, uuid.New(), routeID).Error
+
+	// Assert
+	require.NoError(t, err)
+	var bumped int
+	require.NoError(t, db.Raw("SELECT version FROM route WHERE id = ?", routeID).Scan(&bumped).Error)
+	assert.Equal(t, version+1, bumped)
+}
+
+func TestPostgresRoutePointLocation(t *testing.T) {
+	// Arrange
+	db := openTestPostgres(t)
+	require.NoError(t, MigrateDB(db, "../../db/migrations/postgres", DefaultLockTimeout))
+	var postgis bool
+	require.NoError(t, db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')").Scan(&postgis).Error)
+	if !postgis {
+		t.Skip("PostGIS is not available")
+	}
+
+	// Act
+	var outside int
+	err := db.Raw(`SELECT COUNT(*) FROM route_point
+		WHERE ST_DWithin(location, ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography, 1) IS NOT TRUE`).
+		Scan(&outside).Error
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Zero(t, outside)
+}
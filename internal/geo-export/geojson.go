@@ -0,0 +1,110 @@
+package geoExport
+
+import (
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"time"
+)
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type Geometry struct {
+	Type string `json:"type"`
+	// Coordinates are [longitude, latitude] positions, as GeoJSON orders them
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type FeatureKind string
+
+const (
+	FeatureKindStop        FeatureKind = "stop"
+	FeatureKindPlannedPath FeatureKind = "planned_path"
+)
+
+var FeatureKindList = map[FeatureKind]string{
+	FeatureKindStop:        "stop",
+	FeatureKindPlannedPath: "planned_path",
+}
+
+// RouteFeatures returns the planned path of a route as a LineString through
+// its stops in delivery order, followed by every stop as a Point. Routes with
+// fewer than two stops have no path. Actual breadcrumbs are not included as
+// vehicle positions are not tracked.
+func RouteFeatures(r *route.Route) []Feature {
+	routePoints := deliveryOrder(r)
+
+	features := make([]Feature, 0, len(routePoints)+1)
+	if len(routePoints) >= 2 {
+		path := make([][2]float64, len(routePoints))
+		for i, point := range routePoints {
+			path[i] = [2]float64{point.Longitude, point.Latitude}
+		}
+		features = append(features, Feature{
+			Type:     "Feature",
+			Geometry: Geometry{Type: "LineString", Coordinates: path},
+			Properties: map[string]interface{}{
+				"kind":           FeatureKindList[FeatureKindPlannedPath],
+				"route_id":       r.ID,
+				"route_name":     r.Name,
+				"route_status":   r.Status,
+				"scheduled_date": r.ScheduledDate,
+				"driver":         r.Driver.Name,
+				"vehicle_plate":  r.Vehicle.PlateNumber,
+				"stops":          len(routePoints),
+			},
+		})
+	}
+
+	for i, point := range routePoints {
+		features = append(features, Feature{
+			Type:     "Feature",
+			Geometry: Geometry{Type: "Point", Coordinates: [2]float64{point.Longitude, point.Latitude}},
+			Properties: map[string]interface{}{
+				"kind":                  FeatureKindList[FeatureKindStop],
+				"route_id":              r.ID,
+				"route_point_id":        point.ID,
+				"sequence":              i + 1,
+				"purchase_order_id":     point.PurchaseOrderID,
+				"address":               point.Address,
+				"status":                point.Status,
+				"delivery_window_start": formatTime(point.DeliveryWindowStart),
+				"delivery_window_end":   formatTime(point.DeliveryWindowEnd),
+			},
+		})
+	}
+	return features
+}
+
+// static functions
+
+func NewFeatureCollection(routes ...route.Route) *FeatureCollection {
+	collection := &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+	for i := range routes {
+		collection.Features = append(collection.Features, RouteFeatures(&routes[i])...)
+	}
+	return collection
+}
+
+// deliveryOrder returns the route points of a route in delivery order,
+// leaving the route untouched.
+func deliveryOrder(r *route.Route) []routePoint.RoutePoint {
+	routePoints := append([]routePoint.RoutePoint(nil), r.RoutePoints...)
+	routePoint.SortForDelivery(routePoints)
+	return routePoints
+}
+
+func formatTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
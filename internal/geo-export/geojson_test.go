@@ -0,0 +1,84 @@
+package geoExport
+
+import (
+	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"challenge-fravega/internal/vehicle"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRoute() route.Route {
+	created := time.Date(2025, 3, 9, 10, 0, 0, 0, time.UTC)
+	window := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	return route.Route{
+		ID:            uuid.New(),
+		Name:          "Zona Norte",
+		Status:        "started",
+		ScheduledDate: "2025-03-10",
+		Driver:        carDriver.Driver{Name: "John Doe"},
+		Vehicle:       vehicle.Vehicle{PlateNumber: "AB123CD"},
+		RoutePoints: []routePoint.RoutePoint{
+			{ID: uuid.New(), PurchaseOrderID: "PO2", Latitude: -34.52, Longitude: -58.49, Address: "Second", Status: "pending", CreatedAt: created},
+			{ID: uuid.New(), PurchaseOrderID: "PO1", Latitude: -34.51, Longitude: -58.48, Address: "First", Status: "completed",
+				CreatedAt: created.Add(time.Hour), DeliveryWindowStart: &window},
+		},
+	}
+}
+
+func TestRouteFeatures(t *testing.T) {
+	// Arrange
+	r := testRoute()
+
+	// Act
+	features := RouteFeatures(&r)
+
+	// Assert
+	assert.Len(t, features, 3)
+	assert.Equal(t, "LineString", features[0].Geometry.Type)
+	assert.Equal(t, [][2]float64{{-58.48, -34.51}, {-58.49, -34.52}}, features[0].Geometry.Coordinates)
+	assert.Equal(t, "planned_path", features[0].Properties["kind"])
+	assert.Equal(t, "AB123CD", features[0].Properties["vehicle_plate"])
+
+	assert.Equal(t, "Point", features[1].Geometry.Type)
+	assert.Equal(t, [2]float64{-58.48, -34.51}, features[1].Geometry.Coordinates)
+	assert.Equal(t, 1, features[1].Properties["sequence"])
+	assert.Equal(t, "PO1", features[1].Properties["purchase_order_id"])
+	assert.Equal(t, "completed", features[1].Properties["status"])
+	assert.Equal(t, "2025-03-10T12:00:00Z", features[1].Properties["delivery_window_start"])
+	assert.Nil(t, features[1].Properties["delivery_window_end"])
+	assert.Equal(t, "PO2", features[2].Properties["purchase_order_id"])
+
+	// The route itself is left in its original order
+	assert.Equal(t, "PO2", r.RoutePoints[0].PurchaseOrderID)
+}
+
+func TestRouteFeaturesSingleStopHasNoPath(t *testing.T) {
+	// Arrange
+	r := testRoute()
+	r.RoutePoints = r.RoutePoints[:1]
+
+	// Act
+	features := RouteFeatures(&r)
+
+	// Assert
+	assert.Len(t, features, 1)
+	assert.Equal(t, "Point", features[0].Geometry.Type)
+}
+
+func TestNewFeatureCollection(t *testing.T) {
+	// Act
+	empty, err := json.Marshal(NewFeatureCollection())
+	assert.NoError(t, err)
+	collection := NewFeatureCollection(testRoute(), testRoute())
+
+	// Assert
+	assert.JSONEq(t, `{"type":"FeatureCollection","features":[]}`, string(empty))
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	assert.Len(t, collection.Features, 6)
+}
@@ -0,0 +1,96 @@
+package geoExport
+
+import (
+	"challenge-fravega/internal/route"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type gpx struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Xmlns     string        `xml:"xmlns,attr"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Metadata  gpxMetadata   `xml:"metadata"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+	Routes    []gpxRoute    `xml:"rte"`
+}
+
+type gpxMetadata struct {
+	Name string `xml:"name"`
+	Desc string `xml:"desc,omitempty"`
+	Time string `xml:"time"`
+}
+
+type gpxWaypoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Name string  `xml:"name"`
+	Desc string  `xml:"desc,omitempty"`
+	Type string  `xml:"type,omitempty"`
+}
+
+type gpxRoute struct {
+	Name   string        `xml:"name"`
+	Number int           `xml:"number"`
+	Points []gpxWaypoint `xml:"rtept"`
+}
+
+// WriteGPX writes a route as GPX 1.1, with its stops in delivery order both as
+// waypoints and as the points of a GPX route for navigation devices.
+func WriteGPX(w io.Writer, r *route.Route) error {
+	routePoints := deliveryOrder(r)
+
+	waypoints := make([]gpxWaypoint, len(routePoints))
+	for i, point := range routePoints {
+		desc := point.Address
+		if point.DeliveryWindowStart != nil || point.DeliveryWindowEnd != nil {
+			desc += " (" + formatWindow(point.DeliveryWindowStart, point.DeliveryWindowEnd) + ")"
+		}
+		waypoints[i] = gpxWaypoint{
+			Lat:  point.Latitude,
+			Lon:  point.Longitude,
+			Name: fmt.Sprintf("%d. %s", i+1, point.PurchaseOrderID),
+			Desc: desc,
+			Type: point.Status,
+		}
+	}
+
+	doc := gpx{
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+		Version: "1.1",
+		Creator: "challenge-fravega",
+		Metadata: gpxMetadata{
+			Name: r.Name,
+			Desc: r.Description,
+			Time: time.Now().UTC().Format(time.RFC3339),
+		},
+		Waypoints: waypoints,
+		Routes:    []gpxRoute{{Name: r.Name, Number: 1, Points: waypoints}},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// static functions
+
+func formatWindow(start *time.Time, end *time.Time) string {
+	switch {
+	case start != nil && end != nil:
+		return start.Format(time.RFC3339) + " - " + end.Format(time.RFC3339)
+	case start != nil:
+		return "from " + start.Format(time.RFC3339)
+	}
+	return "until " + end.Format(time.RFC3339)
+}
@@ -0,0 +1,38 @@
+package geoExport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteGPX(t *testing.T) {
+	// Arrange
+	r := testRoute()
+	r.Name = "Zona Norte & Sur"
+	var out bytes.Buffer
+
+	// Act
+	err := WriteGPX(&out, &r)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.String(), `<?xml version="1.0" encoding="UTF-8"?>`))
+	assert.Contains(t, out.String(), `<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1"`)
+
+	var doc gpx
+	assert.NoError(t, xml.Unmarshal(out.Bytes(), &doc))
+	assert.Equal(t, "Zona Norte & Sur", doc.Metadata.Name)
+	assert.Len(t, doc.Waypoints, 2)
+	assert.Equal(t, "1. PO1", doc.Waypoints[0].Name)
+	assert.Equal(t, -34.51, doc.Waypoints[0].Lat)
+	assert.Equal(t, -58.48, doc.Waypoints[0].Lon)
+	assert.Equal(t, "First (from 2025-03-10T12:00:00Z)", doc.Waypoints[0].Desc)
+	assert.Equal(t, "completed", doc.Waypoints[0].Type)
+	assert.Equal(t, "2. PO2", doc.Waypoints[1].Name)
+	assert.Len(t, doc.Routes, 1)
+	assert.Equal(t, doc.Waypoints, doc.Routes[0].Points)
+}
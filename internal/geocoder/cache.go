@@ -0,0 +1,54 @@
+package geocoder
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// cachedGeocoder keeps the addresses geocoded by a provider in the database,
+// keyed by normalized address, so each address is only looked up once.
+// Reverse geocoding is not cached.
+type cachedGeocoder struct {
+	repository Repository
+	provider   Geocoder
+}
+
+func (g *cachedGeocoder) Geocode(ctx context.Context, address string) (*Location, error) {
+	key := NormalizeAddress(address)
+	cached, err := g.repository.GetCachedLocation(ctx, key)
+	if err == nil {
+		return &Location{Latitude: cached.Latitude, Longitude: cached.Longitude, Address: cached.Address}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	location, err := g.provider.Geocode(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	err = g.repository.SaveCachedLocation(ctx, &CachedLocation{
+		Key:       key,
+		Address:   location.Address,
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+	})
+	if err != nil {
+		// The location is still good even if it could not be cached
+		slog.WarnContext(ctx, "Failed to cache geocoded address", "address", address, "error", err)
+	}
+	return location, nil
+}
+
+func (g *cachedGeocoder) ReverseGeocode(ctx context.Context, latitude float64, longitude float64) (*Location, error) {
+	return g.provider.ReverseGeocode(ctx, latitude, longitude)
+}
+
+// static functions
+
+func NewCachedGeocoder(repository Repository, provider Geocoder) *cachedGeocoder {
+	return &cachedGeocoder{repository: repository, provider: provider}
+}
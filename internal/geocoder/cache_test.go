@@ -0,0 +1,49 @@
+package geocoder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Define a provider that counts the addresses it geocodes
+type countingProvider struct {
+	Geocoder
+	calls int
+}
+
+func (p *countingProvider) Geocode(ctx context.Context, address string) (*Location, error) {
+	p.calls++
+	return p.Geocoder.Geocode(ctx, address)
+}
+
+func TestCachedGeocoder(t *testing.T) {
+	// Arrange
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&CachedLocation{}); err != nil {
+		t.Fatal(err)
+	}
+	provider := &countingProvider{Geocoder: NewFileProvider([]Location{{Address: "Florida 100, CABA", Latitude: -34.6070, Longitude: -58.3745}})}
+	cached := NewCachedGeocoder(NewRepository(db), provider)
+
+	// Act
+	first, err := cached.Geocode(context.Background(), "Florida 100, CABA")
+	assert.NoError(t, err)
+	second, err := cached.Geocode(context.Background(), "florida 100 caba")
+	assert.NoError(t, err)
+	_, notFoundErr := cached.Geocode(context.Background(), "Nowhere 1")
+
+	// Assert
+	assert.Equal(t, first, second)
+	assert.Equal(t, 2, provider.calls)
+	assert.ErrorIs(t, notFoundErr, ErrNotFound)
+	var count int64
+	db.Model(&CachedLocation{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
@@ -0,0 +1,72 @@
+package geocoder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// DefaultReverseRadius is how far from a known address the file provider
+// still reverse geocodes coordinates to it, in meters
+const DefaultReverseRadius = 100
+
+// fileProvider geocodes from a fixed list of locations, for tests and offline
+// use.
+type fileProvider struct {
+	locations     []Location
+	byAddress     map[string]Location
+	reverseRadius float64
+}
+
+func (p *fileProvider) Geocode(ctx context.Context, address string) (*Location, error) {
+	location, ok := p.byAddress[NormalizeAddress(address)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &location, nil
+}
+
+// ReverseGeocode returns the nearest known location within the reverse radius.
+func (p *fileProvider) ReverseGeocode(ctx context.Context, latitude float64, longitude float64) (*Location, error) {
+	var nearest *Location
+	nearestDistance := p.reverseRadius
+	for i, location := range p.locations {
+		if distance := Distance(latitude, longitude, location.Latitude, location.Longitude); distance <= nearestDistance {
+			nearest, nearestDistance = &p.locations[i], distance
+		}
+	}
+	if nearest == nil {
+		return nil, ErrNotFound
+	}
+	location := *nearest
+	return &location, nil
+}
+
+// static functions
+
+func NewFileProvider(locations []Location) *fileProvider {
+	provider := &fileProvider{
+		locations:     locations,
+		byAddress:     make(map[string]Location, len(locations)),
+		reverseRadius: DefaultReverseRadius,
+	}
+	for _, location := range locations {
+		provider.byAddress[NormalizeAddress(location.Address)] = location
+	}
+	return provider
+}
+
+// LoadFileProvider reads the locations of a file provider from a JSON array of
+// {"address", "latitude", "longitude"} objects.
+func LoadFileProvider(path string) (*fileProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var locations []Location
+	if err := json.Unmarshal(data, &locations); err != nil {
+		return nil, fmt.Errorf("invalid geocoder file %s: %w", path, err)
+	}
+	return NewFileProvider(locations), nil
+}
@@ -0,0 +1,80 @@
+package geocoder
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Address   string  `json:"address"`
+}
+
+// Geocoder converts addresses to coordinates and back.
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (*Location, error)
+	ReverseGeocode(ctx context.Context, latitude float64, longitude float64) (*Location, error)
+}
+
+type Provider string
+
+const (
+	ProviderNominatim Provider = "nominatim"
+	ProviderFile      Provider = "file"
+)
+
+var ProviderList = map[Provider]string{
+	ProviderNominatim: "nominatim",
+	ProviderFile:      "file",
+}
+
+var ErrNotFound = errors.New("location not found")
+
+const earthRadiusMeters = 6371000
+
+// CachedLocation is a geocoded address stored in the local cache.
+type CachedLocation struct {
+	Key       string    `gorm:"column:normalized_address;primaryKey" json:"normalized_address"`
+	Address   string    `gorm:"column:address" json:"address"`
+	Latitude  float64   `gorm:"column:latitude" json:"latitude"`
+	Longitude float64   `gorm:"column:longitude" json:"longitude"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (CachedLocation) TableName() string {
+	return "geocode_cache"
+}
+
+// static functions
+
+// NormalizeAddress reduces an address to a canonical form, so that spellings
+// differing only in case, accents, punctuation or spacing match.
+func NormalizeAddress(address string) string {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), address)
+	if err != nil {
+		stripped = address
+	}
+	fields := strings.FieldsFunc(strings.ToLower(stripped), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Distance returns the great-circle distance in meters between two points.
+func Distance(latitude1 float64, longitude1 float64, latitude2 float64, longitude2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLatitude := toRadians(latitude2 - latitude1)
+	dLongitude := toRadians(longitude2 - longitude1)
+	a := math.Sin(dLatitude/2)*math.Sin(dLatitude/2) +
+		math.Cos(toRadians(latitude1))*math.Cos(toRadians(latitude2))*math.Sin(dLongitude/2)*math.Sin(dLongitude/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
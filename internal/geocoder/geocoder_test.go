@@ -0,0 +1,74 @@
+package geocoder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	// Act & Assert
+	assert.Equal(t, "av cordoba 1234 caba", NormalizeAddress("  Av. Córdoba 1234,  CABA "))
+	assert.Equal(t, NormalizeAddress("AV CORDOBA 1234 - caba"), NormalizeAddress("av. córdoba 1234, CABA"))
+	assert.Equal(t, "", NormalizeAddress(" , . "))
+}
+
+func TestDistance(t *testing.T) {
+	// Act
+	// Obelisco to Plaza de Mayo, about 1.1 km
+	distance := Distance(-34.6037, -58.3816, -34.6083, -58.3712)
+
+	// Assert
+	assert.InDelta(t, 1070, distance, 50)
+	assert.Zero(t, Distance(-34.6037, -58.3816, -34.6037, -58.3816))
+}
+
+func TestFileProviderGeocode(t *testing.T) {
+	// Arrange
+	provider := NewFileProvider([]Location{{Address: "Av. Córdoba 1234, CABA", Latitude: -34.5990, Longitude: -58.3856}})
+
+	// Act
+	location, err := provider.Geocode(context.Background(), "av cordoba 1234 caba")
+	_, notFoundErr := provider.Geocode(context.Background(), "Florida 100")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, -34.5990, location.Latitude)
+	assert.Equal(t, "Av. Córdoba 1234, CABA", location.Address)
+	assert.ErrorIs(t, notFoundErr, ErrNotFound)
+}
+
+func TestFileProviderReverseGeocode(t *testing.T) {
+	// Arrange
+	provider := NewFileProvider([]Location{
+		{Address: "Av. Córdoba 1234, CABA", Latitude: -34.5990, Longitude: -58.3856},
+		{Address: "Av. Córdoba 1300, CABA", Latitude: -34.5989, Longitude: -58.3866},
+	})
+
+	// Act
+	location, err := provider.ReverseGeocode(context.Background(), -34.5989, -58.3864)
+	_, notFoundErr := provider.ReverseGeocode(context.Background(), -34.6200, -58.3856)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Av. Córdoba 1300, CABA", location.Address)
+	assert.ErrorIs(t, notFoundErr, ErrNotFound)
+}
+
+func TestLoadFileProvider(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "locations.json")
+	os.WriteFile(path, []byte(`[{"address":"Florida 100, CABA","latitude":-34.6070,"longitude":-58.3745}]`), 0o644)
+
+	// Act
+	provider, err := LoadFileProvider(path)
+
+	// Assert
+	assert.NoError(t, err)
+	location, err := provider.Geocode(context.Background(), "Florida 100, CABA")
+	assert.NoError(t, err)
+	assert.Equal(t, -58.3745, location.Longitude)
+}
@@ -0,0 +1,113 @@
+package geocoder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultNominatimURL = "https://nominatim.openstreetmap.org"
+	DefaultTimeout      = 5 * time.Second
+)
+
+// nominatimProvider geocodes with a Nominatim compatible server.
+type nominatimProvider struct {
+	baseURL    string
+	userAgent  string
+	httpClient *http.Client
+}
+
+// nominatimPlace is the part of a Nominatim place used by the provider
+type nominatimPlace struct {
+	Latitude    string `json:"lat"`
+	Longitude   string `json:"lon"`
+	DisplayName string `json:"display_name"`
+	Error       string `json:"error"`
+}
+
+func (p *nominatimProvider) Geocode(ctx context.Context, address string) (*Location, error) {
+	var places []nominatimPlace
+	err := p.get(ctx, "/search", url.Values{
+		"q":      {address},
+		"format": {"jsonv2"},
+		"limit":  {"1"},
+	}, &places)
+	if err != nil {
+		return nil, err
+	}
+	if len(places) == 0 {
+		return nil, ErrNotFound
+	}
+	return places[0].location()
+}
+
+func (p *nominatimProvider) ReverseGeocode(ctx context.Context, latitude float64, longitude float64) (*Location, error) {
+	var place nominatimPlace
+	err := p.get(ctx, "/reverse", url.Values{
+		"lat":    {strconv.FormatFloat(latitude, 'f', -1, 64)},
+		"lon":    {strconv.FormatFloat(longitude, 'f', -1, 64)},
+		"format": {"jsonv2"},
+	}, &place)
+	if err != nil {
+		return nil, err
+	}
+	// Nominatim answers 200 with an error when nothing is found
+	if place.Error != "" {
+		return nil, ErrNotFound
+	}
+	return place.location()
+}
+
+func (p *nominatimProvider) get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	// Nominatim's usage policy requires identifying the application
+	req.Header.Set("User-Agent", p.userAgent)
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("geocoding request failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("geocoding request failed: unexpected status %d", res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode geocoding response: %w", err)
+	}
+	return nil
+}
+
+func (p nominatimPlace) location() (*Location, error) {
+	latitude, err := strconv.ParseFloat(p.Latitude, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latitude in geocoding response: %w", err)
+	}
+	longitude, err := strconv.ParseFloat(p.Longitude, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid longitude in geocoding response: %w", err)
+	}
+	return &Location{Latitude: latitude, Longitude: longitude, Address: p.DisplayName}, nil
+}
+
+// static functions
+
+func NewNominatimProvider(baseURL string, userAgent string, timeout time.Duration) *nominatimProvider {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &nominatimProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		userAgent:  userAgent,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
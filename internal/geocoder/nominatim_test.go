@@ -0,0 +1,92 @@
+package geocoder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "challenge-fravega-test", r.Header.Get("User-Agent"))
+		assert.Equal(t, "jsonv2", r.URL.Query().Get("format"))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/search" && r.URL.Query().Get("q") == "Florida 100, CABA":
+			w.Write([]byte(`[{"lat":"-34.6070","lon":"-58.3745","display_name":"100, Florida, San Nicolás, Buenos Aires"}]`))
+		case r.URL.Path == "/search" && r.URL.Query().Get("q") == "broken":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/search":
+			w.Write([]byte(`[]`))
+		case r.URL.Path == "/reverse" && r.URL.Query().Get("lat") == "-34.607":
+			w.Write([]byte(`{"lat":"-34.6070","lon":"-58.3745","display_name":"100, Florida, San Nicolás, Buenos Aires"}`))
+		case r.URL.Path == "/reverse":
+			w.Write([]byte(`{"error":"Unable to geocode"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestNominatimGeocode(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	defer server.Close()
+	provider := NewNominatimProvider(server.URL+"/", "challenge-fravega-test", time.Second)
+
+	// Act
+	location, err := provider.Geocode(context.Background(), "Florida 100, CABA")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, -34.6070, location.Latitude)
+	assert.Equal(t, -58.3745, location.Longitude)
+	assert.Equal(t, "100, Florida, San Nicolás, Buenos Aires", location.Address)
+}
+
+func TestNominatimGeocodeNotFound(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	defer server.Close()
+	provider := NewNominatimProvider(server.URL, "challenge-fravega-test", time.Second)
+
+	// Act
+	_, err := provider.Geocode(context.Background(), "Nowhere 1")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNominatimGeocodeUnavailable(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	defer server.Close()
+	provider := NewNominatimProvider(server.URL, "challenge-fravega-test", time.Second)
+
+	// Act
+	_, err := provider.Geocode(context.Background(), "broken")
+
+	// Assert
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestNominatimReverseGeocode(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	defer server.Close()
+	provider := NewNominatimProvider(server.URL, "challenge-fravega-test", time.Second)
+
+	// Act
+	location, err := provider.ReverseGeocode(context.Background(), -34.607, -58.3745)
+	_, notFoundErr := provider.ReverseGeocode(context.Background(), 0, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "100, Florida, San Nicolás, Buenos Aires", location.Address)
+	assert.ErrorIs(t, notFoundErr, ErrNotFound)
+}
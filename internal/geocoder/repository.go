@@ -0,0 +1,36 @@
+package geocoder
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository stores the cached geocoded locations.
+type Repository interface {
+	GetCachedLocation(ctx context.Context, key string) (*CachedLocation, error)
+	SaveCachedLocation(ctx context.Context, location *CachedLocation) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func (r *repository) GetCachedLocation(ctx context.Context, key string) (*CachedLocation, error) {
+	var location CachedLocation
+	err := r.db.WithContext(ctx).First(&location, "normalized_address = ?", key).Error
+	return &location, err
+}
+
+// SaveCachedLocation stores a location, replacing any previous one for the
+// same key.
+func (r *repository) SaveCachedLocation(ctx context.Context, location *CachedLocation) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(location).Error
+}
+
+// static functions
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
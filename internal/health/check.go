@@ -0,0 +1,61 @@
+package health
+
+import (
+	"challenge-fravega/internal/database"
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Pinger is a dependency reached over the network, such as the order system.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// static functions
+
+// Database checks the connection to the database.
+func Database(db *gorm.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// Migrations checks every migration in the directory was applied, and none
+// of those applied was edited or removed since.
+func Migrations(db *gorm.DB, migrationsDir string) Check {
+	migrator := database.NewMigrator(db, migrationsDir, 0)
+	return func(ctx context.Context) error {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		var pending, changed []string
+		for _, status := range statuses {
+			switch {
+			case !status.Applied:
+				pending = append(pending, status.Name)
+			case status.Drifted, status.Missing:
+				changed = append(changed, status.Name)
+			}
+		}
+		if len(changed) > 0 {
+			return fmt.Errorf("applied migrations changed: %s", strings.Join(changed, ", "))
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+		}
+		return nil
+	}
+}
+
+// Upstream checks a dependency can be reached.
+func Upstream(pinger Pinger) Check {
+	return pinger.Ping
+}
@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout bounds every readiness check
+const DefaultTimeout = 2 * time.Second
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+var StatusList = map[Status]string{
+	StatusUp:   "up",
+	StatusDown: "down",
+}
+
+// Check reports whether a dependency is ready, returning why when it is not.
+type Check func(ctx context.Context) error
+
+type named struct {
+	name  string
+	check Check
+}
+
+// CheckResult is the outcome of a readiness check.
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the readiness of the server, ready only when every check is up
+// and it is not shutting down.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Ready tells whether the report is up.
+func (r *Report) Ready() bool {
+	return r.Status == StatusList[StatusUp]
+}
+
+// Health runs the readiness checks of the server. Once draining, the server is
+// no longer ready, so load balancers stop sending it requests while those in
+// flight finish.
+type Health struct {
+	checks   []named
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// Add registers a readiness check under a name. Checks must be added before
+// the server starts.
+func (h *Health) Add(name string, check Check) {
+	h.checks = append(h.checks, named{name: name, check: check})
+}
+
+// Drain marks the server as shutting down.
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// Ready runs every check concurrently, each bounded by the timeout.
+func (h *Health) Ready(ctx context.Context) *Report {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = CheckResult{Status: StatusList[StatusUp]}
+			if err := c.check(ctx); err != nil {
+				results[i] = CheckResult{Status: StatusList[StatusDown], Error: err.Error()}
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusList[StatusUp], Checks: map[string]CheckResult{}}
+	for i, c := range h.checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusList[StatusUp] {
+			report.Status = StatusList[StatusDown]
+		}
+	}
+	if h.draining.Load() {
+		report.Status = StatusList[StatusDown]
+		report.Checks["shutdown"] = CheckResult{Status: StatusList[StatusDown], Error: "shutting down"}
+	}
+	return report
+}
+
+// static functions
+
+// NewHealth bounds every check by timeout, DefaultTimeout when not positive.
+func NewHealth(timeout time.Duration) *Health {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Health{timeout: timeout}
+}
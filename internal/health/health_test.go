@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestReady(t *testing.T) {
+	// Arrange
+	health := NewHealth(time.Second)
+	health.Add("database", Database(setupDB(t)))
+	health.Add("purchase_order", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	// Act
+	report := health.Ready(context.Background())
+
+	// Assert
+	assert.False(t, report.Ready())
+	assert.Equal(t, CheckResult{Status: "up"}, report.Checks["database"])
+	assert.Equal(t, CheckResult{Status: "down", Error: "connection refused"}, report.Checks["purchase_order"])
+}
+
+func TestReadyDraining(t *testing.T) {
+	// Arrange
+	health := NewHealth(0)
+	health.Add("database", Database(setupDB(t)))
+
+	// Act
+	before := health.Ready(context.Background())
+	health.Drain()
+	after := health.Ready(context.Background())
+
+	// Assert
+	assert.True(t, before.Ready())
+	assert.False(t, after.Ready())
+	assert.Equal(t, "down", after.Checks["shutdown"].Status)
+}
+
+func TestReadyTimeout(t *testing.T) {
+	// Arrange
+	health := NewHealth(10 * time.Millisecond)
+	health.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	// Act
+	report := health.Ready(context.Background())
+
+	// Assert
+	assert.False(t, report.Ready())
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestMigrations(t *testing.T) {
+	// Arrange
+	db := setupDB(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "001_init.sql"), []byte("CREATE TABLE t (id INTEGER);"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, db.Exec("CREATE TABLE migrations (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, applied_at TIMESTAMP)").Error)
+	check := Migrations(db, dir)
+
+	// Act
+	pendingErr := check(context.Background())
+	assert.NoError(t, db.Exec("INSERT INTO migrations (name) VALUES ('001_init.sql')").Error)
+	err := check(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, pendingErr, "001_init")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,77 @@
+package idempotency
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Record struct {
+	Key             string    `gorm:"column:idempotency_key;primaryKey" json:"idempotency_key"`
+	RequestHash     string    `gorm:"column:request_hash" json:"request_hash"`
+	Status          string    `gorm:"column:status" json:"status"`
+	ResponseStatus  int       `gorm:"column:response_status" json:"response_status"`
+	ResponseHeaders Headers   `gorm:"column:response_headers" json:"response_headers"`
+	ResponseBody    []byte    `gorm:"column:response_body" json:"response_body"`
+	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
+	ExpiresAt       time.Time `gorm:"column:expires_at" json:"expires_at"`
+}
+
+func (Record) TableName() string {
+	return "idempotency_record"
+}
+
+// Response is what gets replayed to a client retrying with the same key.
+type Response struct {
+	Status  int
+	Headers Headers
+	Body    []byte
+}
+
+type RecordStatus string
+
+const (
+	RecordStatusProcessing RecordStatus = "processing"
+	RecordStatusCompleted  RecordStatus = "completed"
+)
+
+var RecordStatusList = map[RecordStatus]string{
+	RecordStatusProcessing: "processing",
+	RecordStatusCompleted:  "completed",
+}
+
+var (
+	ErrInvalidKey        = errors.New("Idempotency-Key must be between 1 and 255 characters")
+	ErrKeyReused         = errors.New("Idempotency-Key was already used with a different request")
+	ErrRequestInProgress = errors.New("a request with this Idempotency-Key is still being processed")
+)
+
+// Headers are the response headers replayed along with the body.
+type Headers map[string]string
+
+func (h Headers) GormDataType() string {
+	return "text"
+}
+
+func (h Headers) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(h)
+	return string(b), err
+}
+
+func (h *Headers) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, h)
+	case string:
+		return json.Unmarshal([]byte(v), h)
+	default:
+		return fmt.Errorf("unsupported headers type %T", value)
+	}
+}
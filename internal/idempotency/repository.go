@@ -0,0 +1,59 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository stores the idempotency keys and their responses.
+type Repository interface {
+	CreateRecord(ctx context.Context, record *Record) (bool, error)
+	GetRecord(ctx context.Context, key string) (*Record, error)
+	CompleteRecord(ctx context.Context, key string, response *Response) error
+	DeleteRecord(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// CreateRecord inserts the record unless one with the same key exists, and
+// reports whether it was inserted.
+func (r *repository) CreateRecord(ctx context.Context, record *Record) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *repository) GetRecord(ctx context.Context, key string) (*Record, error) {
+	var record Record
+	return &record, r.db.WithContext(ctx).First(&record, "idempotency_key = ?", key).Error
+}
+
+func (r *repository) CompleteRecord(ctx context.Context, key string, response *Response) error {
+	return r.db.WithContext(ctx).Model(&Record{}).
+		Where("idempotency_key = ?", key).
+		Updates(map[string]interface{}{
+			"status":           RecordStatusList[RecordStatusCompleted],
+			"response_status":  response.Status,
+			"response_headers": response.Headers,
+			"response_body":    response.Body,
+		}).Error
+}
+
+func (r *repository) DeleteRecord(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Delete(&Record{}, "idempotency_key = ?", key).Error
+}
+
+func (r *repository) DeleteExpired(ctx context.Context, now time.Time) error {
+	return r.db.WithContext(ctx).Delete(&Record{}, "expires_at < ?", now).Error
+}
+
+// static functions
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
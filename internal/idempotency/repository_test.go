@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type RepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	repository Repository
+}
+
+func (suite *RepositoryTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	err = db.AutoMigrate(&Record{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	suite.db = db
+	suite.repository = NewRepository(db)
+}
+
+func (suite *RepositoryTestSuite) TestCreateRecordOnlyOnce() {
+	// Arrange
+	now := time.Now().UTC()
+	first := &Record{Key: "key-1", RequestHash: "hash-1", Status: "processing", ExpiresAt: now.Add(time.Hour)}
+	second := &Record{Key: "key-1", RequestHash: "hash-2", Status: "processing", ExpiresAt: now.Add(time.Hour)}
+
+	// Act
+	createdFirst, errFirst := suite.repository.CreateRecord(context.Background(), first)
+	createdSecond, errSecond := suite.repository.CreateRecord(context.Background(), second)
+
+	// Assert
+	assert.NoError(suite.T(), errFirst)
+	assert.NoError(suite.T(), errSecond)
+	assert.True(suite.T(), createdFirst)
+	assert.False(suite.T(), createdSecond)
+
+	stored, err := suite.repository.GetRecord(context.Background(), "key-1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "hash-1", stored.RequestHash)
+}
+
+func (suite *RepositoryTestSuite) TestCompleteRecord() {
+	// Arrange
+	suite.repository.CreateRecord(context.Background(), &Record{
+		Key: "key-1", RequestHash: "hash-1", Status: "processing", ExpiresAt: time.Now().UTC().Add(time.Hour),
+	})
+
+	// Act
+	err := suite.repository.CompleteRecord(context.Background(), "key-1", &Response{
+		Status:  201,
+		Headers: Headers{"Content-Type": "application/json"},
+		Body:    []byte(`{"id":"1"}`),
+	})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	stored, _ := suite.repository.GetRecord(context.Background(), "key-1")
+	assert.Equal(suite.T(), RecordStatusList[RecordStatusCompleted], stored.Status)
+	assert.Equal(suite.T(), 201, stored.ResponseStatus)
+	assert.Equal(suite.T(), "application/json", stored.ResponseHeaders["Content-Type"])
+	assert.Equal(suite.T(), `{"id":"1"}`, string(stored.ResponseBody))
+}
+
+func (suite *RepositoryTestSuite) TestDeleteExpired() {
+	// Arrange
+	now := time.Now().UTC()
+	suite.repository.CreateRecord(context.Background(), &Record{Key: "expired", RequestHash: "h", Status: "processing", ExpiresAt: now.Add(-time.Minute)})
+	suite.repository.CreateRecord(context.Background(), &Record{Key: "live", RequestHash: "h", Status: "processing", ExpiresAt: now.Add(time.Minute)})
+
+	// Act
+	err := suite.repository.DeleteExpired(context.Background(), now)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	var keys []string
+	suite.db.Model(&Record{}).Pluck("idempotency_key", &keys)
+	assert.Equal(suite.T(), []string{"live"}, keys)
+}
+
+func TestRepositorySuite(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
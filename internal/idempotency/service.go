@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultTTL   = 24 * time.Hour
+	maxKeyLength = 255
+)
+
+type Service interface {
+	Begin(ctx context.Context, key string, requestHash string) (*Record, error)
+	Complete(ctx context.Context, key string, response *Response) error
+	Release(ctx context.Context, key string) error
+}
+
+type service struct {
+	repository Repository
+	ttl        time.Duration
+}
+
+// Begin claims the key for a request. It returns a nil record when the
+// request should be processed, or the completed record whose response must be
+// replayed. ErrKeyReused is returned when the key was used for a different
+// request, and ErrRequestInProgress while the first request hasn't finished.
+func (s *service) Begin(ctx context.Context, key string, requestHash string) (*Record, error) {
+	if key == "" || len(key) > maxKeyLength {
+		return nil, ErrInvalidKey
+	}
+
+	now := time.Now().UTC()
+	if err := s.repository.DeleteExpired(ctx, now); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repository.CreateRecord(ctx, &Record{
+		Key:         key,
+		RequestHash: requestHash,
+		Status:      RecordStatusList[RecordStatusProcessing],
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		return nil, nil
+	}
+
+	record, err := s.repository.GetRecord(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if record.RequestHash != requestHash {
+		return nil, ErrKeyReused
+	}
+	if record.Status != RecordStatusList[RecordStatusCompleted] {
+		return nil, ErrRequestInProgress
+	}
+	return record, nil
+}
+
+// Complete stores the response to replay on retries.
+func (s *service) Complete(ctx context.Context, key string, response *Response) error {
+	return s.repository.CompleteRecord(ctx, key, response)
+}
+
+// Release frees the key so the request can be retried, e.g. after a server
+// error.
+func (s *service) Release(ctx context.Context, key string) error {
+	return s.repository.DeleteRecord(ctx, key)
+}
+
+// static functions
+
+func NewService(repository Repository, ttl time.Duration) *service {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &service{repository: repository, ttl: ttl}
+}
@@ -0,0 +1,125 @@
+package idempotency
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type ServiceTestSuite struct {
+	suite.Suite
+	repository Repository
+	service    Service
+}
+
+func (suite *ServiceTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	err = db.AutoMigrate(&Record{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	suite.repository = NewRepository(db)
+	suite.service = NewService(suite.repository, time.Hour)
+}
+
+func (suite *ServiceTestSuite) TestBeginNewKey() {
+	// Act
+	record, err := suite.service.Begin(context.Background(), "key-1", "hash-1")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), record)
+}
+
+func (suite *ServiceTestSuite) TestBeginReplaysCompletedResponse() {
+	// Arrange
+	suite.service.Begin(context.Background(), "key-1", "hash-1")
+	suite.service.Complete(context.Background(), "key-1", &Response{Status: 201, Body: []byte(`{"id":"1"}`)})
+
+	// Act
+	record, err := suite.service.Begin(context.Background(), "key-1", "hash-1")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), record)
+	assert.Equal(suite.T(), 201, record.ResponseStatus)
+	assert.Equal(suite.T(), `{"id":"1"}`, string(record.ResponseBody))
+}
+
+func (suite *ServiceTestSuite) TestBeginRejectsDifferentPayload() {
+	// Arrange
+	suite.service.Begin(context.Background(), "key-1", "hash-1")
+	suite.service.Complete(context.Background(), "key-1", &Response{Status: 201})
+
+	// Act
+	_, err := suite.service.Begin(context.Background(), "key-1", "hash-2")
+
+	// Assert
+	assert.ErrorIs(suite.T(), err, ErrKeyReused)
+}
+
+func (suite *ServiceTestSuite) TestBeginWhileInProgress() {
+	// Arrange
+	suite.service.Begin(context.Background(), "key-1", "hash-1")
+
+	// Act
+	_, err := suite.service.Begin(context.Background(), "key-1", "hash-1")
+
+	// Assert
+	assert.ErrorIs(suite.T(), err, ErrRequestInProgress)
+}
+
+func (suite *ServiceTestSuite) TestReleaseAllowsRetry() {
+	// Arrange
+	suite.service.Begin(context.Background(), "key-1", "hash-1")
+	suite.service.Release(context.Background(), "key-1")
+
+	// Act
+	record, err := suite.service.Begin(context.Background(), "key-1", "hash-1")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), record)
+}
+
+func (suite *ServiceTestSuite) TestBeginAfterExpiry() {
+	// Arrange
+	suite.repository.CreateRecord(context.Background(), &Record{
+		Key:         "key-1",
+		RequestHash: "hash-1",
+		Status:      RecordStatusList[RecordStatusCompleted],
+		ExpiresAt:   time.Now().UTC().Add(-time.Second),
+	})
+
+	// Act
+	record, err := suite.service.Begin(context.Background(), "key-1", "hash-2")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), record)
+}
+
+func (suite *ServiceTestSuite) TestBeginInvalidKey() {
+	// Act
+	_, errEmpty := suite.service.Begin(context.Background(), "", "hash-1")
+	_, errLong := suite.service.Begin(context.Background(), strings.Repeat("k", 256), "hash-1")
+
+	// Assert
+	assert.ErrorIs(suite.T(), errEmpty, ErrInvalidKey)
+	assert.ErrorIs(suite.T(), errLong, ErrInvalidKey)
+}
+
+func TestServiceSuite(t *testing.T) {
+	suite.Run(t, new(ServiceTestSuite))
+}
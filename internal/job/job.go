@@ -0,0 +1,131 @@
+package job
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+	// TimeLayout is the format of the time of day daily jobs run at
+	TimeLayout = "15:04"
+)
+
+var (
+	ErrInvalidStatus = errors.New("invalid job run status")
+	ErrInvalidTime   = errors.New("invalid job time, must be formatted as HH:MM")
+)
+
+// Job is a task run in the background. The summary it returns is stored as
+// JSON with the run.
+type Job interface {
+	Name() string
+	Run(ctx context.Context) (interface{}, error)
+}
+
+// Run records an execution of a job and its outcome. ScheduledFor is when the
+// run was due, for those on the schedule of the job, recorded only once.
+type Run struct {
+	ID           uuid.UUID  `gorm:"column:id" json:"id"`
+	Job          string     `gorm:"column:job" json:"job"`
+	Status       string     `gorm:"column:status" json:"status"`
+	Summary      Summary    `gorm:"column:summary" json:"summary"`
+	Error        string     `gorm:"column:error" json:"error,omitempty"`
+	ScheduledFor *time.Time `gorm:"column:scheduled_for" json:"scheduled_for,omitempty"`
+	StartedAt    time.Time  `gorm:"column:started_at" json:"started_at"`
+	FinishedAt   *time.Time `gorm:"column:finished_at" json:"finished_at"`
+}
+
+func (Run) TableName() string {
+	return "job_run"
+}
+
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "running"
+	RunStatusSucceeded RunStatus = "succeeded"
+	RunStatusFailed    RunStatus = "failed"
+)
+
+var RunStatusList = map[RunStatus]string{
+	RunStatusRunning:   "running",
+	RunStatusSucceeded: "succeeded",
+	RunStatusFailed:    "failed",
+}
+
+type Filter struct {
+	Job    string `form:"job"`
+	Status string `form:"status"`
+	Limit  int    `form:"limit"`
+}
+
+// Summary is the outcome reported by a job, kept as JSON.
+type Summary json.RawMessage
+
+func (s Summary) GormDataType() string {
+	return "text"
+}
+
+func (s Summary) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return string(s), nil
+}
+
+func (s *Summary) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+	case []byte:
+		*s = append(Summary(nil), v...)
+	case string:
+		*s = Summary(v)
+	default:
+		return fmt.Errorf("unsupported job summary type %T", value)
+	}
+	return nil
+}
+
+func (s Summary) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+	return s, nil
+}
+
+// Daily is the time of day a job runs at every day, in Location.
+type Daily struct {
+	Hour     int
+	Minute   int
+	Location *time.Location
+}
+
+// Next returns the first time the job is due strictly after t.
+func (d Daily) Next(t time.Time) time.Time {
+	t = t.In(d.Location)
+	next := time.Date(t.Year(), t.Month(), t.Day(), d.Hour, d.Minute, 0, 0, d.Location)
+	if !next.After(t) {
+		next = time.Date(t.Year(), t.Month(), t.Day()+1, d.Hour, d.Minute, 0, 0, d.Location)
+	}
+	return next
+}
+
+// static functions
+
+// ParseDaily reads a time of day formatted as HH:MM.
+func ParseDaily(value string, location *time.Location) (Daily, error) {
+	t, err := time.Parse(TimeLayout, value)
+	if err != nil {
+		return Daily{}, ErrInvalidTime
+	}
+	return Daily{Hour: t.Hour(), Minute: t.Minute(), Location: location}, nil
+}
@@ -0,0 +1,46 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyNext(t *testing.T) {
+	// Arrange
+	location, _ := time.LoadLocation("America/Argentina/Buenos_Aires")
+	daily, err := ParseDaily("23:30", location)
+	assert.NoError(t, err)
+
+	// Act
+	before := daily.Next(time.Date(2025, 3, 10, 12, 0, 0, 0, location))
+	exactly := daily.Next(time.Date(2025, 3, 10, 23, 30, 0, 0, location))
+	utc := daily.Next(time.Date(2025, 3, 11, 1, 0, 0, 0, time.UTC))
+
+	// Assert
+	assert.Equal(t, time.Date(2025, 3, 10, 23, 30, 0, 0, location), before)
+	assert.Equal(t, time.Date(2025, 3, 11, 23, 30, 0, 0, location), exactly)
+	// 01:00 UTC is still the 10th in Buenos Aires
+	assert.Equal(t, time.Date(2025, 3, 10, 23, 30, 0, 0, location), utc)
+}
+
+func TestParseDailyInvalid(t *testing.T) {
+	_, err := ParseDaily("25:00", time.UTC)
+	assert.ErrorIs(t, err, ErrInvalidTime)
+
+	_, err = ParseDaily("tonight", time.UTC)
+	assert.ErrorIs(t, err, ErrInvalidTime)
+}
+
+func TestSummaryScan(t *testing.T) {
+	var summary Summary
+	assert.NoError(t, summary.Scan(`{"closed":1}`))
+	b, err := summary.MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"closed":1}`, string(b))
+
+	assert.NoError(t, summary.Scan(nil))
+	b, _ = summary.MarshalJSON()
+	assert.Equal(t, "null", string(b))
+}
@@ -0,0 +1,81 @@
+package job
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository stores the runs of the background jobs.
+type Repository interface {
+	CreateRun(ctx context.Context, run *Run) (*Run, error)
+	CreateScheduledRun(ctx context.Context, run *Run) (bool, error)
+	FinishRun(ctx context.Context, run *Run) error
+	GetRuns(ctx context.Context, job string, status string, limit int) ([]Run, error)
+	GetRun(ctx context.Context, id string) (*Run, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func (r *repository) CreateRun(ctx context.Context, run *Run) (*Run, error) {
+	if run.ID == uuid.Nil {
+		run.ID = uuid.New()
+	}
+	err := r.db.WithContext(ctx).Create(run).Error
+	return run, err
+}
+
+// CreateScheduledRun inserts the run unless one of the same job due at the
+// same time exists, and reports whether it was inserted.
+func (r *repository) CreateScheduledRun(ctx context.Context, run *Run) (bool, error) {
+	if run.ID == uuid.Nil {
+		run.ID = uuid.New()
+	}
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(run)
+	return result.RowsAffected == 1, result.Error
+}
+
+// FinishRun saves the outcome of a run.
+func (r *repository) FinishRun(ctx context.Context, run *Run) error {
+	return r.db.WithContext(ctx).Model(&Run{}).Where("id = ?", run.ID).
+		Updates(map[string]interface{}{
+			"status":      run.Status,
+			"summary":     run.Summary,
+			"error":       run.Error,
+			"finished_at": run.FinishedAt,
+		}).Error
+}
+
+// GetRuns returns the most recent runs first, optionally only those of a job
+// or with a status.
+func (r *repository) GetRuns(ctx context.Context, job string, status string, limit int) ([]Run, error) {
+	var runs []Run
+	query := r.db.WithContext(ctx).Order("started_at DESC").Limit(limit)
+	if job != "" {
+		query = query.Where("job = ?", job)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&runs).Error
+	return runs, err
+}
+
+func (r *repository) GetRun(ctx context.Context, id string) (*Run, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return &Run{}, gorm.ErrRecordNotFound
+	}
+	var run Run
+	err := r.db.WithContext(ctx).First(&run, "id = ?", id).Error
+	return &run, err
+}
+
+// static functions
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
@@ -0,0 +1,130 @@
+package job
+
+import (
+	"challenge-fravega/internal/request"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	job      Job
+	schedule Daily
+}
+
+// Scheduler runs jobs in process on their daily schedule and records every
+// run. Runs of the same job never overlap. Every instance of the server may
+// run one: each scheduled run is recorded once, by the instance that runs it.
+type Scheduler struct {
+	repository Repository
+	entries    []entry
+}
+
+// Add schedules a job. Jobs must be added before Start.
+func (s *Scheduler) Add(job Job, schedule Daily) {
+	s.entries = append(s.entries, entry{job: job, schedule: schedule})
+}
+
+// Start runs every job when due until ctx is cancelled. Runs in progress
+// then finish before wg is marked done.
+func (s *Scheduler) Start(ctx context.Context, wg *sync.WaitGroup) {
+	for _, e := range s.entries {
+		wg.Add(1)
+		go s.loop(ctx, e, wg)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		next := e.schedule.Next(time.Now())
+		slog.InfoContext(ctx, "Job scheduled", "job", e.job.Name(), "at", next.Format(time.RFC3339))
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if _, err := s.runScheduled(context.WithoutCancel(ctx), e.job, next); err != nil {
+			slog.ErrorContext(ctx, "Job failed", "job", e.job.Name(), "error", err)
+		}
+	}
+}
+
+// Run runs a job right away and records the run. Changes made by the job
+// carry the run ID as their request ID in the audit log.
+func (s *Scheduler) Run(ctx context.Context, job Job) (*Run, error) {
+	run, err := s.repository.CreateRun(ctx, &Run{
+		Job:       job.Name(),
+		Status:    RunStatusList[RunStatusRunning],
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.finish(ctx, job, run)
+}
+
+// runScheduled runs a job due at the given time, unless another instance
+// already did, returning no run then.
+func (s *Scheduler) runScheduled(ctx context.Context, job Job, due time.Time) (*Run, error) {
+	scheduledFor := due.UTC()
+	run := &Run{
+		Job:          job.Name(),
+		Status:       RunStatusList[RunStatusRunning],
+		ScheduledFor: &scheduledFor,
+		StartedAt:    time.Now(),
+	}
+	created, err := s.repository.CreateScheduledRun(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		slog.InfoContext(ctx, "Job already run by another instance", "job", job.Name(), "scheduled_for", scheduledFor.Format(time.RFC3339))
+		return nil, nil
+	}
+	return s.finish(ctx, job, run)
+}
+
+// finish runs the job of a recorded run and saves its outcome.
+func (s *Scheduler) finish(ctx context.Context, job Job, run *Run) (*Run, error) {
+	summary, runErr := runJob(request.WithID(ctx, run.ID.String()), job)
+	if runErr == nil && summary != nil {
+		var b []byte
+		if b, runErr = json.Marshal(summary); runErr == nil {
+			run.Summary = b
+		}
+	}
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = RunStatusList[RunStatusSucceeded]
+	if runErr != nil {
+		run.Status = RunStatusList[RunStatusFailed]
+		run.Error = runErr.Error()
+	}
+	if err := s.repository.FinishRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, runErr
+}
+
+// static functions
+
+func NewScheduler(repository Repository) *Scheduler {
+	return &Scheduler{repository: repository}
+}
+
+// runJob runs the job, turning a panic into an error so that it is recorded
+// and does not bring the server down.
+func runJob(ctx context.Context, job Job) (summary interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
@@ -0,0 +1,142 @@
+package job
+
+import (
+	"challenge-fravega/internal/request"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeJob struct {
+	summary   interface{}
+	err       error
+	panics    bool
+	requestID string
+}
+
+func (f *fakeJob) Name() string {
+	return "fake"
+}
+
+func (f *fakeJob) Run(ctx context.Context) (interface{}, error) {
+	f.requestID = request.ID(ctx)
+	if f.panics {
+		panic("boom")
+	}
+	return f.summary, f.err
+}
+
+type SchedulerTestSuite struct {
+	suite.Suite
+	repository Repository
+	scheduler  *Scheduler
+}
+
+func (suite *SchedulerTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	err = db.AutoMigrate(&Run{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	// As in the migrations
+	err = db.Exec("CREATE UNIQUE INDEX idx_job_run_scheduled_for ON job_run(job, scheduled_for)").Error
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	suite.repository = NewRepository(db)
+	suite.scheduler = NewScheduler(suite.repository)
+}
+
+func (suite *SchedulerTestSuite) TestRunSucceeded() {
+	// Arrange
+	job := &fakeJob{summary: map[string]int{"closed": 2}}
+
+	// Act
+	run, err := suite.scheduler.Run(context.Background(), job)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), run.ID.String(), job.requestID)
+	saved, err := suite.repository.GetRun(context.Background(), run.ID.String())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "fake", saved.Job)
+	assert.Equal(suite.T(), "succeeded", saved.Status)
+	assert.JSONEq(suite.T(), `{"closed":2}`, string(saved.Summary))
+	assert.NotNil(suite.T(), saved.FinishedAt)
+}
+
+func (suite *SchedulerTestSuite) TestRunFailed() {
+	// Act
+	run, err := suite.scheduler.Run(context.Background(), &fakeJob{err: errors.New("database is locked")})
+
+	// Assert
+	assert.EqualError(suite.T(), err, "database is locked")
+	saved, _ := suite.repository.GetRun(context.Background(), run.ID.String())
+	assert.Equal(suite.T(), "failed", saved.Status)
+	assert.Equal(suite.T(), "database is locked", saved.Error)
+	assert.Nil(suite.T(), saved.Summary)
+}
+
+func (suite *SchedulerTestSuite) TestRunPanicked() {
+	// Act
+	run, err := suite.scheduler.Run(context.Background(), &fakeJob{panics: true})
+
+	// Assert
+	assert.ErrorContains(suite.T(), err, "boom")
+	saved, _ := suite.repository.GetRun(context.Background(), run.ID.String())
+	assert.Equal(suite.T(), "failed", saved.Status)
+}
+
+func (suite *SchedulerTestSuite) TestGetRuns() {
+	// Arrange
+	suite.scheduler.Run(context.Background(), &fakeJob{})
+	suite.scheduler.Run(context.Background(), &fakeJob{err: errors.New("failed")})
+
+	// Act
+	all, err := NewService(suite.repository).GetRuns(context.Background(), &Filter{Job: "fake"})
+	failed, _ := NewService(suite.repository).GetRuns(context.Background(), &Filter{Status: "failed"})
+	_, invalidErr := NewService(suite.repository).GetRuns(context.Background(), &Filter{Status: "done"})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), all, 2)
+	assert.Len(suite.T(), failed, 1)
+	assert.ErrorIs(suite.T(), invalidErr, ErrInvalidStatus)
+}
+
+func (suite *SchedulerTestSuite) TestRunScheduledOnceAcrossInstances() {
+	// Arrange
+	first, second := &fakeJob{}, &fakeJob{}
+	other := NewScheduler(suite.repository)
+	due := time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC)
+
+	// Act
+	firstRun, firstErr := suite.scheduler.runScheduled(context.Background(), first, due)
+	secondRun, secondErr := other.runScheduled(context.Background(), second, due)
+	nextRun, nextErr := other.runScheduled(context.Background(), second, due.AddDate(0, 0, 1))
+
+	// Assert
+	assert.NoError(suite.T(), errors.Join(firstErr, secondErr, nextErr))
+	assert.NotNil(suite.T(), firstRun)
+	assert.Equal(suite.T(), firstRun.ID.String(), first.requestID)
+	assert.Nil(suite.T(), secondRun)
+	assert.NotNil(suite.T(), nextRun)
+	assert.Equal(suite.T(), nextRun.ID.String(), second.requestID)
+	runs, _ := suite.repository.GetRuns(context.Background(), "fake", "", 10)
+	assert.Len(suite.T(), runs, 2)
+}
+
+func TestSchedulerSuite(t *testing.T) {
+	suite.Run(t, new(SchedulerTestSuite))
+}
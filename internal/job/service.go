@@ -0,0 +1,41 @@
+package job
+
+import "context"
+
+type Service interface {
+	GetRuns(ctx context.Context, filter *Filter) ([]Run, error)
+	GetRun(ctx context.Context, id string) (*Run, error)
+}
+
+type service struct {
+	repository Repository
+}
+
+// GetRuns returns the most recent job runs first.
+func (s *service) GetRuns(ctx context.Context, filter *Filter) ([]Run, error) {
+	if filter.Status != "" {
+		if _, ok := RunStatusList[RunStatus(filter.Status)]; !ok {
+			return nil, ErrInvalidStatus
+		}
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	return s.repository.GetRuns(ctx, filter.Job, filter.Status, limit)
+}
+
+func (s *service) GetRun(ctx context.Context, id string) (*Run, error) {
+	return s.repository.GetRun(ctx, id)
+}
+
+// static functions
+
+func NewService(repository Repository) *service {
+	return &service{repository: repository}
+}
@@ -0,0 +1,81 @@
+package manifest
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Manifest is the paper list a driver takes along a route.
+type Manifest struct {
+	RouteID      uuid.UUID
+	RouteName    string
+	Description  string
+	Status       string
+	DriverName   string
+	DriverPhone  string
+	VehiclePlate string
+	Stops        []Stop
+	GeneratedAt  time.Time
+	// Location is the time zone times are printed in
+	Location *time.Location
+}
+
+// Stop is a route point in delivery order.
+type Stop struct {
+	Sequence        int
+	PurchaseOrderID string
+	// OrderNumber and CustomerName come from the purchase order service and
+	// are empty when it could not be reached
+	OrderNumber         string
+	CustomerName        string
+	Address             string
+	Latitude            float64
+	Longitude           float64
+	DeliveryWindowStart *time.Time
+	DeliveryWindowEnd   *time.Time
+	Status              string
+}
+
+type Format string
+
+const (
+	FormatPDF  Format = "pdf"
+	FormatHTML Format = "html"
+)
+
+var FormatList = map[Format]string{
+	FormatPDF:  "pdf",
+	FormatHTML: "html",
+}
+
+var ErrInvalidFormat = errors.New("invalid manifest format, must be pdf or html")
+
+// PurchaseOrder returns the order number if known, or else the purchase order ID.
+func (s Stop) PurchaseOrder() string {
+	if s.OrderNumber != "" {
+		return s.OrderNumber
+	}
+	return s.PurchaseOrderID
+}
+
+// DeliveryWindow formats the delivery window in the given time zone, or
+// returns an empty string if the stop has none.
+func (s Stop) DeliveryWindow(location *time.Location) string {
+	const layout = "02/01 15:04"
+	start, end := s.DeliveryWindowStart, s.DeliveryWindowEnd
+	switch {
+	case start != nil && end != nil:
+		from, to := start.In(location), end.In(location)
+		if from.YearDay() == to.YearDay() && from.Year() == to.Year() {
+			return from.Format(layout) + " - " + to.Format("15:04")
+		}
+		return from.Format(layout) + " - " + to.Format(layout)
+	case start != nil:
+		return "from " + start.In(location).Format(layout)
+	case end != nil:
+		return "until " + end.In(location).Format(layout)
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package manifest
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/go-pdf/fpdf"
+)
+
+//go:embed templates/manifest.html
+var templates embed.FS
+
+// RenderHTML writes the manifest as a print friendly HTML page.
+func RenderHTML(w io.Writer, m *Manifest) error {
+	tmpl, err := template.New("manifest.html").Funcs(template.FuncMap{
+		"window": func(s Stop) string { return s.DeliveryWindow(m.Location) },
+	}).ParseFS(templates, "templates/manifest.html")
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, m)
+}
+
+// Column widths in millimeters of the stops table on an A4 page
+var pdfColumns = []struct {
+	title string
+	width float64
+}{
+	{"#", 8},
+	{"Purchase order", 38},
+	{"Address", 56},
+	{"Delivery window", 30},
+	{"Received by (name, ID, signature)", 54},
+}
+
+const (
+	pdfMargin       = 12
+	pdfLineHeight   = 4.5
+	pdfMinRowHeight = 16
+)
+
+// RenderPDF writes the manifest as an A4 PDF, using only the standard PDF
+// fonts so no font files are needed at runtime.
+func RenderPDF(w io.Writer, m *Manifest) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.SetTitle("Manifest "+m.RouteName, true)
+	pdf.AliasNbPages("")
+	// The standard fonts are not Unicode, accents are translated to cp1252
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	_, pageHeight := pdf.GetPageSize()
+	bottom := pageHeight - pdfMargin - 8
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin - 4)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(0, 4, tr(fmt.Sprintf("%s - printed %s - page %d of {nb}",
+			m.RouteName, m.GeneratedAt.Format("02/01/2006 15:04"), pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+
+	tableHeader := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for _, column := range pdfColumns {
+			pdf.CellFormat(column.width, 7, tr(column.title), "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 8, tr(m.RouteName), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	if m.Description != "" {
+		pdf.MultiCell(0, 5, tr(m.Description), "", "L", false)
+	}
+	driver := m.DriverName
+	if m.DriverPhone != "" {
+		driver += " (" + m.DriverPhone + ")"
+	}
+	for _, line := range [][2]string{
+		{"Driver", driver},
+		{"Vehicle", m.VehiclePlate},
+		{"Stops", fmt.Sprint(len(m.Stops))},
+		{"Route", m.RouteID.String()},
+	} {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(20, 5, tr(line[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 5, tr(line[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+	tableHeader()
+
+	for _, stop := range m.Stops {
+		// Text is wrapped before translating it, as SplitText measures runes
+		pdf.SetFont("Helvetica", "", 9)
+		cells := [][]string{
+			{fmt.Sprint(stop.Sequence)},
+			pdf.SplitText(stop.PurchaseOrder(), pdfColumns[1].width-2),
+			pdf.SplitText(stop.Address, pdfColumns[2].width-2),
+			pdf.SplitText(stop.DeliveryWindow(m.Location), pdfColumns[3].width-2),
+			nil,
+		}
+		if stop.CustomerName != "" {
+			cells[1] = append(cells[1], pdf.SplitText(stop.CustomerName, pdfColumns[1].width-2)...)
+		}
+
+		height := float64(pdfMinRowHeight)
+		for _, lines := range cells {
+			height = max(height, float64(len(lines))*pdfLineHeight+2)
+		}
+		if pdf.GetY()+height > bottom {
+			pdf.AddPage()
+			tableHeader()
+		}
+
+		x, y := pdf.GetXY()
+		pdf.SetFont("Helvetica", "", 9)
+		for i, column := range pdfColumns {
+			pdf.Rect(x, y, column.width, height, "D")
+			for j, line := range cells[i] {
+				pdf.SetXY(x+1, y+1+float64(j)*pdfLineHeight)
+				pdf.CellFormat(column.width-2, pdfLineHeight, tr(line), "", 0, "L", false, 0, "")
+			}
+			x += column.width
+		}
+		pdf.SetXY(pdfMargin, y+height)
+	}
+	if len(m.Stops) == 0 {
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.CellFormat(0, 7, tr("This route has no stops."), "1", 1, "L", false, 0, "")
+	}
+
+	// Sign-off by the driver and the dispatcher
+	if pdf.GetY()+25 > bottom {
+		pdf.AddPage()
+	}
+	y := pdf.GetY() + 18
+	pdf.SetFont("Helvetica", "", 9)
+	for i, label := range []string{"Driver signature", "Dispatcher signature"} {
+		x := pdfMargin + float64(i)*98
+		pdf.Line(x, y, x+80, y)
+		pdf.SetXY(x, y+1)
+		pdf.CellFormat(80, 5, tr(label), "", 0, "L", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
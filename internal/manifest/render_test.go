@@ -0,0 +1,79 @@
+package manifest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func testManifest(stops int) *Manifest {
+	m := &Manifest{
+		RouteID:      uuid.New(),
+		RouteName:    "Zona Norte <AM>",
+		DriverName:   "José Pérez",
+		VehiclePlate: "AB123CD",
+		GeneratedAt:  time.Date(2025, 3, 10, 7, 30, 0, 0, time.UTC),
+		Location:     time.UTC,
+	}
+	for i := 1; i <= stops; i++ {
+		m.Stops = append(m.Stops, Stop{
+			Sequence:            i,
+			PurchaseOrderID:     uuid.NewString(),
+			OrderNumber:         "PO-12345",
+			CustomerName:        "Ana García",
+			Address:             "Av. del Libertador 1234, Piso 5 Depto B, Vicente López, Provincia de Buenos Aires",
+			DeliveryWindowStart: at(12),
+			DeliveryWindowEnd:   at(14),
+		})
+	}
+	return m
+}
+
+func TestRenderHTML(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+
+	// Act
+	err := RenderHTML(&out, testManifest(2))
+
+	// Assert
+	assert.NoError(t, err)
+	html := out.String()
+	assert.Contains(t, html, "Zona Norte &lt;AM&gt;")
+	assert.Contains(t, html, "José Pérez")
+	assert.Contains(t, html, "10/03 12:00 - 14:00")
+	assert.Contains(t, html, "Ana García")
+	assert.Equal(t, 2, strings.Count(html, `class="signature"`))
+}
+
+func TestRenderHTMLWithoutStops(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+
+	// Act
+	err := RenderHTML(&out, testManifest(0))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "This route has no stops.")
+}
+
+func TestRenderPDF(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+
+	// Act
+	err := RenderPDF(&out, testManifest(40))
+
+	// Assert
+	assert.NoError(t, err)
+	pdf := out.String()
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-"))
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(pdf), "%%EOF"))
+	// 40 stops do not fit on a single page
+	assert.Greater(t, strings.Count(pdf, "/Type /Page\n"), 1)
+}
@@ -0,0 +1,106 @@
+package manifest
+
+import (
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"challenge-fravega/internal/tracing"
+	"context"
+	"sync"
+	"time"
+)
+
+// MaxConcurrentLookups bounds the requests made to the purchase order service
+// while building a manifest
+const MaxConcurrentLookups = 8
+
+type Service interface {
+	GetManifest(ctx context.Context, routeID string) (*Manifest, error)
+}
+
+type service struct {
+	routeService   route.Service
+	purchaseOrders purchaseOrder.Client
+	location       *time.Location
+}
+
+// GetManifest builds the manifest of a route, with its stops in delivery order.
+func (s *service) GetManifest(ctx context.Context, routeID string) (*Manifest, error) {
+	ctx, span := tracing.Start(ctx, "manifest.GetManifest")
+	defer span.End()
+
+	r, err := s.routeService.GetRoute(ctx, routeID)
+	if err != nil {
+		return nil, err
+	}
+
+	routePoints := r.RoutePoints
+	routePoint.SortForDelivery(routePoints)
+
+	stops := make([]Stop, len(routePoints))
+	for i, point := range routePoints {
+		stops[i] = Stop{
+			Sequence:            i + 1,
+			PurchaseOrderID:     point.PurchaseOrderID,
+			Address:             point.Address,
+			Latitude:            point.Latitude,
+			Longitude:           point.Longitude,
+			DeliveryWindowStart: point.DeliveryWindowStart,
+			DeliveryWindowEnd:   point.DeliveryWindowEnd,
+			Status:              point.Status,
+		}
+	}
+	s.addPurchaseOrders(ctx, stops)
+
+	return &Manifest{
+		RouteID:      r.ID,
+		RouteName:    r.Name,
+		Description:  r.Description,
+		Status:       r.Status,
+		DriverName:   r.Driver.Name,
+		DriverPhone:  r.Driver.PhoneNumber,
+		VehiclePlate: r.Vehicle.PlateNumber,
+		Stops:        stops,
+		GeneratedAt:  time.Now().In(s.location),
+		Location:     s.location,
+	}, nil
+}
+
+// addPurchaseOrders fills in the order number and customer of every stop, at
+// most MaxConcurrentLookups at a time. A manifest is still useful without
+// them, so stops whose purchase order cannot be fetched are left as they are.
+func (s *service) addPurchaseOrders(ctx context.Context, stops []Stop) {
+	if s.purchaseOrders == nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, MaxConcurrentLookups)
+	for i := range stops {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(stop *Stop) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			order, err := s.purchaseOrders.GetPurchaseOrder(ctx, stop.PurchaseOrderID)
+			if err != nil {
+				return
+			}
+			stop.OrderNumber = order.OrderNumber
+			stop.CustomerName = order.CustomerName
+		}(&stops[i])
+	}
+	wg.Wait()
+}
+
+// static functions
+
+// NewService creates the manifest service. purchaseOrders may be nil, in which
+// case manifests only show purchase order IDs. Times are printed in location.
+func NewService(routeService route.Service, purchaseOrders purchaseOrder.Client, location *time.Location) *service {
+	if location == nil {
+		location = time.UTC
+	}
+	return &service{routeService: routeService, purchaseOrders: purchaseOrders, location: location}
+}
@@ -0,0 +1,146 @@
+package manifest
+
+import (
+	carDriver "challenge-fravega/internal/car-driver"
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"challenge-fravega/internal/vehicle"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Define a mock route service for testing the manifest service
+type MockRouteService struct {
+	mock.Mock
+}
+
+func (m *MockRouteService) GetRoutes(ctx context.Context) ([]route.Route, error) {
+	args := m.Called()
+	return args.Get(0).([]route.Route), args.Error(1)
+}
+
+func (m *MockRouteService) GetRoute(ctx context.Context, id string) (*route.Route, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*route.Route), args.Error(1)
+}
+
+func (m *MockRouteService) GetRoutesByDate(ctx context.Context, date string) ([]route.Route, error) {
+	args := m.Called(date)
+	return args.Get(0).([]route.Route), args.Error(1)
+}
+
+func (m *MockRouteService) CreateRoute(ctx context.Context, newRoute *route.CreateRoute) (*route.Route, error) {
+	args := m.Called(ctx, newRoute)
+	return args.Get(0).(*route.Route), args.Error(1)
+}
+
+func (m *MockRouteService) UpdateRoute(ctx context.Context, id string, version int, update *route.UpdateRoute) (*route.Route, error) {
+	args := m.Called(ctx, id, version, update)
+	return args.Get(0).(*route.Route), args.Error(1)
+}
+
+func (m *MockRouteService) InZone(ctx context.Context, id uuid.UUID, latitude float64, longitude float64) (bool, error) {
+	args := m.Called(id, latitude, longitude)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRouteService) RouteExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+// Define a purchase order client that knows a fixed set of purchase orders
+type fakePurchaseOrders map[string]purchaseOrder.PurchaseOrder
+
+func (f fakePurchaseOrders) GetPurchaseOrder(ctx context.Context, id string) (*purchaseOrder.PurchaseOrder, error) {
+	order, ok := f[id]
+	if !ok {
+		return nil, purchaseOrder.ErrNotFound
+	}
+	return &order, nil
+}
+
+func at(hour int) *time.Time {
+	t := time.Date(2025, 3, 10, hour, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func testRoute() *route.Route {
+	created := time.Date(2025, 3, 9, 10, 0, 0, 0, time.UTC)
+	return &route.Route{
+		ID:      uuid.New(),
+		Name:    "Zona Norte",
+		Status:  route.RouteStatusList[route.RouteStatusPending],
+		Driver:  carDriver.Driver{Name: "John Doe", PhoneNumber: "+5491112345678"},
+		Vehicle: vehicle.Vehicle{PlateNumber: "AB123CD"},
+		RoutePoints: []routePoint.RoutePoint{
+			{PurchaseOrderID: "PO3", Address: "No window 2", CreatedAt: created.Add(2 * time.Hour)},
+			{PurchaseOrderID: "PO2", Address: "Afternoon", CreatedAt: created, DeliveryWindowStart: at(17), DeliveryWindowEnd: at(19)},
+			{PurchaseOrderID: "PO4", Address: "No window 1", CreatedAt: created.Add(time.Hour)},
+			{PurchaseOrderID: "PO1", Address: "Morning", CreatedAt: created.Add(3 * time.Hour), DeliveryWindowStart: at(12)},
+		},
+	}
+}
+
+func TestGetManifest(t *testing.T) {
+	// Arrange
+	routes := new(MockRouteService)
+	r := testRoute()
+	routes.On("GetRoute", r.ID.String()).Return(r, nil)
+	orders := fakePurchaseOrders{"PO1": {OrderNumber: "PO-00001", CustomerName: "Ana García"}}
+	service := NewService(routes, orders, time.UTC)
+
+	// Act
+	result, err := service.GetManifest(context.Background(), r.ID.String())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Zona Norte", result.RouteName)
+	assert.Equal(t, "John Doe", result.DriverName)
+	assert.Equal(t, "AB123CD", result.VehiclePlate)
+	assert.Len(t, result.Stops, 4)
+	var order []string
+	for i, stop := range result.Stops {
+		assert.Equal(t, i+1, stop.Sequence)
+		order = append(order, stop.PurchaseOrderID)
+	}
+	assert.Equal(t, []string{"PO1", "PO2", "PO4", "PO3"}, order)
+	assert.Equal(t, "PO-00001", result.Stops[0].PurchaseOrder())
+	assert.Equal(t, "Ana García", result.Stops[0].CustomerName)
+	assert.Equal(t, "PO2", result.Stops[1].PurchaseOrder())
+}
+
+func TestGetManifestRouteNotFound(t *testing.T) {
+	// Arrange
+	routes := new(MockRouteService)
+	notFound := errors.New("record not found")
+	routes.On("GetRoute", "missing").Return(nil, notFound)
+	service := NewService(routes, nil, nil)
+
+	// Act
+	result, err := service.GetManifest(context.Background(), "missing")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, notFound)
+}
+
+func TestDeliveryWindow(t *testing.T) {
+	buenosAires := time.FixedZone("ART", -3*60*60)
+
+	assert.Equal(t, "10/03 14:00 - 16:00", Stop{DeliveryWindowStart: at(17), DeliveryWindowEnd: at(19)}.DeliveryWindow(buenosAires))
+	assert.Equal(t, "10/03 20:00 - 11/03 01:00", Stop{DeliveryWindowStart: at(23), DeliveryWindowEnd: at(28)}.DeliveryWindow(buenosAires))
+	assert.Equal(t, "from 10/03 09:00", Stop{DeliveryWindowStart: at(12)}.DeliveryWindow(buenosAires))
+	assert.Equal(t, "until 10/03 09:00", Stop{DeliveryWindowEnd: at(12)}.DeliveryWindow(buenosAires))
+	assert.Empty(t, Stop{}.DeliveryWindow(buenosAires))
+}
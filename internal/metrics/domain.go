@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// collectTimeout bounds the queries of the domain gauges on each scrape
+const collectTimeout = 5 * time.Second
+
+type statusCount struct {
+	Status string
+	Count  int
+}
+
+// domainCollector counts the routes and route points by status when scraped,
+// so the gauges are never stale.
+type domainCollector struct {
+	db          *gorm.DB
+	routes      *prometheus.Desc
+	routePoints *prometheus.Desc
+}
+
+func (c *domainCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.routes
+	ch <- c.routePoints
+}
+
+func (c *domainCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	routeStatuses := make([]string, 0, len(route.RouteStatusList))
+	for _, status := range route.RouteStatusList {
+		routeStatuses = append(routeStatuses, status)
+	}
+	routePointStatuses := make([]string, 0, len(routePoint.RoutePointStatusList))
+	for _, status := range routePoint.RoutePointStatusList {
+		routePointStatuses = append(routePointStatuses, status)
+	}
+	c.collect(ctx, ch, c.routes, "route", routeStatuses)
+	c.collect(ctx, ch, c.routePoints, "route_point", routePointStatuses)
+}
+
+// collect reports the count of every known status of the table, zero when
+// there are no rows with it.
+func (c *domainCollector) collect(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc, table string, statuses []string) {
+	var counts []statusCount
+	err := c.db.WithContext(ctx).Table(table).Select("status, COUNT(*) AS count").Group("status").Scan(&counts).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to count by status", "table", table, "error", err)
+		ch <- prometheus.NewInvalidMetric(desc, err)
+		return
+	}
+
+	byStatus := map[string]int{}
+	for _, status := range statuses {
+		byStatus[status] = 0
+	}
+	for _, count := range counts {
+		byStatus[count.Status] = count.Count
+	}
+	for status, count := range byStatus {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(count), status)
+	}
+}
+
+// static functions
+
+// NewDomainCollector reports the routes and the route points (stops) by
+// status, as the routes and route_points gauges.
+func NewDomainCollector(db *gorm.DB) prometheus.Collector {
+	return &domainCollector{
+		db:          db,
+		routes:      prometheus.NewDesc("routes", "Routes by status.", []string{"status"}, nil),
+		routePoints: prometheus.NewDesc("route_points", "Route points, the stops of the routes, by status.", []string{"status"}, nil),
+	}
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type fakePurchaseOrderClient struct {
+	err error
+}
+
+func (f *fakePurchaseOrderClient) GetPurchaseOrder(ctx context.Context, id string) (*purchaseOrder.PurchaseOrder, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &purchaseOrder.PurchaseOrder{ID: id}, nil
+}
+
+func (f *fakePurchaseOrderClient) UpdateStatus(ctx context.Context, id string, status purchaseOrder.Status) error {
+	return f.err
+}
+
+func (f *fakePurchaseOrderClient) Ping(ctx context.Context) error {
+	return f.err
+}
+
+func setupDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range []string{
+		"CREATE TABLE route (id TEXT PRIMARY KEY, status TEXT)",
+		"CREATE TABLE route_point (id TEXT PRIMARY KEY, status TEXT)",
+		"INSERT INTO route VALUES ('r1', 'pending'), ('r2', 'started'), ('r3', 'started')",
+		"INSERT INTO route_point VALUES ('p1', 'completed')",
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestObserveRequest(t *testing.T) {
+	// Arrange
+	metrics := NewMetrics()
+
+	// Act
+	metrics.ObserveRequest("GET", "/routes/:id", 200, 20*time.Millisecond)
+	metrics.ObserveRequest("GET", "/routes/:id", 200, 30*time.Millisecond)
+	metrics.ObserveRequest("GET", "/routes/:id", 404, time.Millisecond)
+
+	// Assert
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.httpRequests.WithLabelValues("GET", "/routes/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.httpRequests.WithLabelValues("GET", "/routes/:id", "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.httpDuration))
+}
+
+func TestGormPlugin(t *testing.T) {
+	// Arrange
+	db := setupDB(t)
+	metrics := NewMetrics()
+	assert.NoError(t, db.Use(metrics.GormPlugin()))
+
+	// Act
+	var ids []string
+	err := db.Table("route").Where("status = ?", "started").Pluck("id", &ids).Error
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, ids, 2)
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.dbDuration))
+	assert.Contains(t, gather(t, metrics), `db_query_duration_seconds_count{operation="query",table="route"} 1`)
+}
+
+func TestPurchaseOrderClient(t *testing.T) {
+	// Arrange
+	metrics := NewMetrics()
+	client := metrics.NewPurchaseOrderClient(&fakePurchaseOrderClient{})
+	failing := metrics.NewPurchaseOrderClient(&fakePurchaseOrderClient{err: purchaseOrder.ErrNotFound})
+
+	// Act
+	_, err := client.GetPurchaseOrder(context.Background(), "po-1")
+	_, notFoundErr := failing.GetPurchaseOrder(context.Background(), "po-2")
+	statusErr := failing.UpdateStatus(context.Background(), "po-2", purchaseOrder.StatusDelivered)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.ErrorIs(t, notFoundErr, purchaseOrder.ErrNotFound)
+	assert.ErrorIs(t, statusErr, purchaseOrder.ErrNotFound)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.upstreamErrors.WithLabelValues("get", "not_found")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.upstreamErrors.WithLabelValues("update_status", "not_found")))
+	assert.Contains(t, gather(t, metrics), `purchase_order_request_duration_seconds_count{operation="get",outcome="ok"} 1`)
+}
+
+func TestDomainCollector(t *testing.T) {
+	// Arrange
+	metrics := NewMetrics()
+	metrics.Register(NewDomainCollector(setupDB(t)))
+
+	// Act
+	out := gather(t, metrics)
+
+	// Assert
+	assert.Contains(t, out, `routes{status="started"} 2`)
+	assert.Contains(t, out, `routes{status="completed"} 0`)
+	assert.Contains(t, out, `route_points{status="completed"} 1`)
+	assert.Contains(t, out, `route_points{status="pending"} 0`)
+}
+
+// gather scrapes the metrics handler.
+func gather(t *testing.T, metrics *Metrics) string {
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Code != 200 {
+		t.Fatalf("unexpected status %d: %s", recorder.Code, recorder.Body.String())
+	}
+	return strings.TrimSpace(recorder.Body.String())
+}
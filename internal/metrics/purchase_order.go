@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	"context"
+	"errors"
+	"time"
+)
+
+// PurchaseOrderClient is the client of the order system, measured by
+// NewPurchaseOrderClient.
+type PurchaseOrderClient interface {
+	purchaseOrder.Client
+	purchaseOrder.StatusClient
+	Ping(ctx context.Context) error
+}
+
+type purchaseOrderClient struct {
+	next    PurchaseOrderClient
+	metrics *Metrics
+}
+
+func (c *purchaseOrderClient) GetPurchaseOrder(ctx context.Context, id string) (*purchaseOrder.PurchaseOrder, error) {
+	start := time.Now()
+	order, err := c.next.GetPurchaseOrder(ctx, id)
+	c.observe("get", start, err)
+	return order, err
+}
+
+func (c *purchaseOrderClient) UpdateStatus(ctx context.Context, id string, status purchaseOrder.Status) error {
+	start := time.Now()
+	err := c.next.UpdateStatus(ctx, id, status)
+	c.observe("update_status", start, err)
+	return err
+}
+
+func (c *purchaseOrderClient) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := c.next.Ping(ctx)
+	c.observe("ping", start, err)
+	return err
+}
+
+func (c *purchaseOrderClient) observe(operation string, start time.Time, err error) {
+	outcome := outcomeOf(err)
+	c.metrics.upstreamDuration.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+	if err != nil {
+		c.metrics.upstreamErrors.WithLabelValues(operation, outcome).Inc()
+	}
+}
+
+// NewPurchaseOrderClient measures the latency and errors of the requests to
+// the order system.
+func (m *Metrics) NewPurchaseOrderClient(next PurchaseOrderClient) PurchaseOrderClient {
+	return &purchaseOrderClient{next: next, metrics: m}
+}
+
+// static functions
+
+// outcomeOf classifies the result of a request, keeping the label bounded.
+func outcomeOf(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, purchaseOrder.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, purchaseOrder.ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, purchaseOrder.ErrRejected):
+		return "rejected"
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled):
+		return "timeout"
+	default:
+		return "error"
+	}
+}
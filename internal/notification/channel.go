@@ -0,0 +1,235 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChannelName is how a customer is reached.
+type ChannelName string
+
+const (
+	ChannelEmail ChannelName = "email"
+	ChannelSMS   ChannelName = "sms"
+)
+
+var ChannelNameList = map[ChannelName]string{
+	ChannelEmail: "email",
+	ChannelSMS:   "sms",
+}
+
+// Channel sends messages to customers. Name is the ChannelName it stands for.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, message *Message) error
+}
+
+// SMTPChannel sends emails through an SMTP server, with STARTTLS when the
+// server offers it. Each email, from dialing to quitting, is bounded by
+// timeout and by the context.
+type SMTPChannel struct {
+	addr    string
+	host    string
+	auth    smtp.Auth
+	from    string
+	timeout time.Duration
+}
+
+func (s *SMTPChannel) Name() string {
+	return ChannelNameList[ChannelEmail]
+}
+
+func (s *SMTPChannel) Send(ctx context.Context, message *Message) error {
+	// Recipients come from the order system, and a line break would let them
+	// add headers of their own
+	if message.To == "" || strings.ContainsAny(message.To, "\r\n") {
+		return fmt.Errorf("%w: %q", ErrInvalidRecipient, message.To)
+	}
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", s.from)
+	fmt.Fprintf(&body, "To: %s\r\n", message.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	body.WriteString("\r\n")
+	body.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	body.WriteString("\r\n")
+
+	if err := s.send(ctx, message.To, body.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// send delivers the email as smtp.SendMail does, on a connection that is
+// closed once the timeout passes or ctx is done, so that a server that hangs
+// does not hold up the dispatcher.
+func (s *SMTPChannel) send(ctx context.Context, to string, email []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if s.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("server %s does not support authentication", s.addr)
+		}
+		if err := client.Auth(s.auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(email); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// SMSChannel sends text messages through an HTTP gateway, POSTing
+// {"from","to","body"} as JSON with the API key as a bearer token.
+type SMSChannel struct {
+	url        string
+	apiKey     string
+	from       string
+	httpClient *http.Client
+}
+
+func (s *SMSChannel) Name() string {
+	return ChannelNameList[ChannelSMS]
+}
+
+func (s *SMSChannel) Send(ctx context.Context, message *Message) error {
+	payload, err := json.Marshal(map[string]string{"from": s.from, "to": message.To, "body": message.Body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send sms: %w", err)
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("failed to send sms: unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// FileChannel appends every message to a file as a line of JSON, standing in
+// for a real channel in local testing.
+type FileChannel struct {
+	mu   sync.Mutex
+	name string
+	path string
+}
+
+func (f *FileChannel) Name() string {
+	return f.name
+}
+
+func (f *FileChannel) Send(ctx context.Context, message *Message) error {
+	line, err := json.Marshal(struct {
+		Channel string `json:"channel"`
+		*Message
+		SentAt time.Time `json:"sent_at"`
+	}{f.name, message, time.Now()})
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+	return file.Close()
+}
+
+// static functions
+
+// NewSMTPChannel sends emails from the address through host:port,
+// authenticating when a username is given.
+func NewSMTPChannel(host string, port int, username string, password string, from string, timeout time.Duration) *SMTPChannel {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPChannel{addr: net.JoinHostPort(host, strconv.Itoa(port)), host: host, auth: auth, from: from, timeout: timeout}
+}
+
+func NewSMSChannel(url string, apiKey string, from string, timeout time.Duration) *SMSChannel {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &SMSChannel{url: url, apiKey: apiKey, from: from, httpClient: &http.Client{Timeout: timeout}}
+}
+
+// NewFileChannel writes the messages of the named channel to path.
+func NewFileChannel(name ChannelName, path string) *FileChannel {
+	return &FileChannel{name: ChannelNameList[name], path: path}
+}
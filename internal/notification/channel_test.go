@@ -0,0 +1,169 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveSMTP answers a single SMTP session on a local port, calling reply with
+// every command to get the response, and returns the host and port.
+func serveSMTP(t *testing.T, reply func(command string) string) (string, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		if greeting := reply(""); greeting != "" {
+			io.WriteString(conn, greeting+"\r\n")
+		}
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if response := reply(strings.TrimRight(line, "\r\n")); response != "" {
+				io.WriteString(conn, response+"\r\n")
+			}
+		}
+	}()
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestSMTPChannelSend(t *testing.T) {
+	// Arrange
+	var commands []string
+	inData := false
+	host, port := serveSMTP(t, func(command string) string {
+		switch {
+		case inData && command == ".":
+			inData = false
+			return "250 queued"
+		case inData:
+			return ""
+		case command == "":
+			return "220 localhost ready"
+		}
+		commands = append(commands, strings.Fields(command)[0])
+		switch {
+		case strings.HasPrefix(command, "DATA"):
+			inData = true
+			return "354 go ahead"
+		case strings.HasPrefix(command, "QUIT"):
+			return "221 bye"
+		default:
+			return "250 ok"
+		}
+	})
+	channel := NewSMTPChannel(host, port, "", "", "entregas@example.com", time.Second)
+
+	// Act
+	err := channel.Send(context.Background(), &Message{To: "ana@example.com", Subject: "Hola", Body: "Tu pedido"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"EHLO", "MAIL", "RCPT", "DATA", "QUIT"}, commands)
+}
+
+func TestSMTPChannelSendTimesOut(t *testing.T) {
+	// Arrange
+	host, port := serveSMTP(t, func(command string) string { return "" })
+	channel := NewSMTPChannel(host, port, "", "", "entregas@example.com", 100*time.Millisecond)
+
+	// Act
+	started := time.Now()
+	err := channel.Send(context.Background(), &Message{To: "ana@example.com", Subject: "Hola", Body: "Tu pedido"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+func TestSMTPChannelSendRejectsLineBreaks(t *testing.T) {
+	// Arrange
+	channel := NewSMTPChannel("127.0.0.1", 1, "", "", "entregas@example.com", time.Second)
+
+	// Act
+	err := channel.Send(context.Background(), &Message{To: "ana@example.com\r\nBcc: all@example.com", Subject: "Hola", Body: "Tu pedido"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidRecipient)
+}
+
+func TestSMSChannelSend(t *testing.T) {
+	// Arrange
+	var authorization string
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &payload)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	channel := NewSMSChannel(server.URL, "secret", "Fravega", time.Second)
+
+	// Act
+	err := channel.Send(context.Background(), &Message{To: "+5491155550000", Subject: "ignored", Body: "Tu pedido está en camino"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "sms", channel.Name())
+	assert.Equal(t, "Bearer secret", authorization)
+	assert.Equal(t, map[string]string{"from": "Fravega", "to": "+5491155550000", "body": "Tu pedido está en camino"}, payload)
+}
+
+func TestSMSChannelSendFails(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// Act
+	err := NewSMSChannel(server.URL, "", "", time.Second).Send(context.Background(), &Message{To: "+54911", Body: "hi"})
+
+	// Assert
+	assert.ErrorContains(t, err, "unexpected status 503")
+}
+
+func TestFileChannelSend(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "notifications.ndjson")
+	channel := NewFileChannel(ChannelEmail, path)
+
+	// Act
+	firstErr := channel.Send(context.Background(), &Message{To: "ana@example.com", Subject: "Hola", Body: "Tu pedido"})
+	secondErr := channel.Send(context.Background(), &Message{To: "ana@example.com", Subject: "Chau", Body: "Entregado"})
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 2)
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal(t, "email", line["channel"])
+	assert.Equal(t, "ana@example.com", line["to"])
+	assert.Equal(t, "Hola", line["subject"])
+}
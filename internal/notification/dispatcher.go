@@ -0,0 +1,118 @@
+package notification
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// batchSize bounds the notifications sent on each poll
+	batchSize = 100
+	// lease is how long an instance has to send a notification it claimed
+	// before another one takes it over
+	lease = 5 * time.Minute
+)
+
+// Dispatcher sends the pending notifications in the background through their
+// channel, retrying failed ones with exponential backoff until they run out
+// of attempts. Every instance of the server may run one: each notification is
+// claimed before it is sent.
+type Dispatcher struct {
+	repository Repository
+	channels   map[string]Channel
+	retry      Retry
+	interval   time.Duration
+}
+
+// Start polls for due notifications every interval until ctx is cancelled, then
+// finishes the batch in flight and marks wg done.
+func (d *Dispatcher) Start(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.DispatchDue(context.WithoutCancel(ctx)); err != nil {
+					slog.ErrorContext(ctx, "Failed to dispatch notifications", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// DispatchDue sends every notification due by now.
+func (d *Dispatcher) DispatchDue(ctx context.Context) error {
+	notifications, err := d.repository.GetDueNotifications(ctx, time.Now(), batchSize)
+	if err != nil {
+		return err
+	}
+	for i := range notifications {
+		notification := &notifications[i]
+		now := time.Now()
+		claimed, err := d.repository.ClaimNotification(ctx, notification, now, now.Add(lease))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		d.attempt(ctx, notification)
+		saved, err := d.repository.UpdateNotification(ctx, notification)
+		if err != nil {
+			return err
+		}
+		if !saved {
+			slog.InfoContext(ctx, "Skipped notification no longer pending", "notification_id", notification.ID,
+				"status", notification.Status)
+		}
+	}
+	return nil
+}
+
+// attempt sends the notification once and records the outcome on it.
+func (d *Dispatcher) attempt(ctx context.Context, notification *Notification) {
+	now := time.Now()
+	notification.Attempts++
+	channel, ok := d.channels[notification.Channel]
+	if !ok {
+		notification.Status = StatusList[StatusFailed]
+		notification.LastError = ErrNoChannel.Error()
+		return
+	}
+
+	err := channel.Send(ctx, &Message{To: notification.Recipient, Subject: notification.Subject, Body: notification.Body})
+	if err == nil {
+		notification.Status = StatusList[StatusSent]
+		notification.SentAt = &now
+		notification.LastError = ""
+		return
+	}
+
+	notification.LastError = err.Error()
+	if notification.Attempts >= d.retry.MaxAttempts {
+		notification.Status = StatusList[StatusFailed]
+		return
+	}
+	notification.NextAttemptAt = now.Add(d.retry.Delay(notification.Attempts))
+}
+
+// static functions
+
+func NewDispatcher(repository Repository, retry Retry, interval time.Duration, channels ...Channel) *Dispatcher {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	byName := map[string]Channel{}
+	for _, channel := range channels {
+		byName[channel.Name()] = channel
+	}
+	return &Dispatcher{repository: repository, channels: byName, retry: retry, interval: interval}
+}
@@ -0,0 +1,299 @@
+package notification
+
+import (
+	"challenge-fravega/internal/outbox"
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeOrders knows the purchase orders by ID
+type fakeOrders map[string]*purchaseOrder.PurchaseOrder
+
+func (f fakeOrders) GetPurchaseOrder(ctx context.Context, id string) (*purchaseOrder.PurchaseOrder, error) {
+	order, ok := f[id]
+	if !ok {
+		return nil, purchaseOrder.ErrNotFound
+	}
+	return order, nil
+}
+
+type fakeRoutes struct {
+	route *route.Route
+}
+
+func (f *fakeRoutes) GetRoute(ctx context.Context, id string) (*route.Route, error) {
+	return f.route, nil
+}
+
+// fakeChannel records the messages sent, failing with err when set and
+// calling onSend with every message when set
+type fakeChannel struct {
+	name   string
+	err    error
+	sent   []*Message
+	onSend func()
+}
+
+func (f *fakeChannel) Name() string {
+	return f.name
+}
+
+func (f *fakeChannel) Send(ctx context.Context, message *Message) error {
+	if f.onSend != nil {
+		f.onSend()
+	}
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, message)
+	return nil
+}
+
+type DispatcherTestSuite struct {
+	suite.Suite
+	repository *repository
+	service    *service
+	notifier   *Notifier
+	dispatcher *Dispatcher
+	email      *fakeChannel
+	sms        *fakeChannel
+	route      *route.Route
+}
+
+func (suite *DispatcherTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	err = db.AutoMigrate(&Notification{}, &OptOut{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	now := time.Now()
+	routeID := uuid.New()
+	suite.route = &route.Route{ID: routeID, RoutePoints: []routePoint.RoutePoint{
+		{ID: uuid.New(), RouteID: &routeID, PurchaseOrderID: "PO1", Address: "Florida 165", Status: "in_route", CreatedAt: now},
+		{ID: uuid.New(), RouteID: &routeID, PurchaseOrderID: "PO2", Address: "Corrientes 800", Status: "pending", CreatedAt: now.Add(time.Minute)},
+	}}
+	orders := fakeOrders{
+		"PO1": {ID: "PO1", OrderNumber: "PO-1", CustomerName: "Ana", CustomerEmail: "ana@example.com", CustomerPhone: "+5491155550001"},
+		"PO2": {ID: "PO2", OrderNumber: "PO-2", CustomerName: "Bob", CustomerEmail: "bob@example.com", CustomerLocale: "en"},
+	}
+
+	suite.email = &fakeChannel{name: "email"}
+	suite.sms = &fakeChannel{name: "sms"}
+	suite.repository = NewRepository(db)
+	suite.service = NewService(suite.repository)
+	suite.notifier = NewNotifier(suite.repository, orders, &fakeRoutes{route: suite.route}, []string{"email", "sms"}, LocaleEs, time.UTC)
+	suite.dispatcher = NewDispatcher(suite.repository, Retry{Base: time.Minute, Max: time.Hour, MaxAttempts: 2}, time.Second, suite.email, suite.sms)
+}
+
+func (suite *DispatcherTestSuite) statusChanged(rp *routePoint.RoutePoint, from string, to string) *outbox.Event {
+	rp.Status = to
+	event, err := routePoint.NewEvent(outbox.EventRoutePointStatusChanged, from, rp)
+	suite.Require().NoError(err)
+	return &event
+}
+
+func (suite *DispatcherTestSuite) notifications(status Status) []Notification {
+	notifications, err := suite.service.GetNotifications(context.Background(), &Filter{Status: StatusList[status]})
+	suite.Require().NoError(err)
+	return notifications
+}
+
+// due makes every pending notification due now, as if the backoff had elapsed
+func (suite *DispatcherTestSuite) due() {
+	err := suite.repository.db.Model(&Notification{}).Where("1 = 1").Update("next_attempt_at", time.Now().Add(-time.Second)).Error
+	suite.Require().NoError(err)
+}
+
+func (suite *DispatcherTestSuite) TestHandleQueuesOnEveryChannelWithContact() {
+	// Arrange
+	rp := suite.route.RoutePoints[0]
+
+	// Act
+	err := suite.notifier.Handle(context.Background(), suite.statusChanged(&rp, "pending", "in_route"))
+
+	// Assert
+	suite.NoError(err)
+	pending := suite.notifications(StatusPending)
+	suite.Len(pending, 2)
+	for _, notification := range pending {
+		suite.Equal("out_for_delivery", notification.Template)
+		suite.Equal("PO1", notification.PurchaseOrderID)
+		suite.Equal("es", notification.Locale)
+		if notification.Channel == "sms" {
+			suite.Equal("+5491155550001", notification.Recipient)
+			suite.Empty(notification.Subject)
+		} else {
+			suite.Equal("Tu pedido PO-1 está en camino", notification.Subject)
+		}
+	}
+}
+
+func (suite *DispatcherTestSuite) TestHandleTellsNextStop() {
+	// Arrange
+	rp := suite.route.RoutePoints[0]
+
+	// Act
+	err := suite.notifier.Handle(context.Background(), suite.statusChanged(&rp, "in_route", "completed"))
+
+	// Assert
+	suite.NoError(err)
+	delivered, _ := suite.service.GetNotifications(context.Background(), &Filter{PurchaseOrderID: "PO1"})
+	next, _ := suite.service.GetNotifications(context.Background(), &Filter{PurchaseOrderID: "PO2"})
+	suite.Len(delivered, 2)
+	suite.Equal("delivered", delivered[0].Template)
+	suite.Len(next, 1)
+	suite.Equal("next_stop", next[0].Template)
+	suite.Equal("email", next[0].Channel)
+	suite.Equal("en", next[0].Locale)
+	suite.Equal("Your order PO-2 is the next delivery", next[0].Subject)
+}
+
+func (suite *DispatcherTestSuite) TestHandleIgnoresHandledEventsAndOptedOutOrders() {
+	// Arrange
+	rp := suite.route.RoutePoints[0]
+	event := suite.statusChanged(&rp, "in_route", "failed")
+	_, err := suite.service.OptOut(context.Background(), "PO2")
+	suite.Require().NoError(err)
+
+	// Act
+	firstErr := suite.notifier.Handle(context.Background(), event)
+	againErr := suite.notifier.Handle(context.Background(), event)
+
+	// Assert
+	suite.NoError(firstErr)
+	suite.NoError(againErr)
+	pending := suite.notifications(StatusPending)
+	suite.Len(pending, 2)
+	suite.Equal("failed_attempt", pending[0].Template)
+	suite.Equal("PO1", pending[0].PurchaseOrderID)
+}
+
+func (suite *DispatcherTestSuite) TestOptOutCancelsPendingNotifications() {
+	// Arrange
+	rp := suite.route.RoutePoints[0]
+	suite.Require().NoError(suite.notifier.Handle(context.Background(), suite.statusChanged(&rp, "pending", "in_route")))
+
+	// Act
+	optOut, err := suite.service.OptOut(context.Background(), "PO1")
+	_, againErr := suite.service.OptOut(context.Background(), "PO1")
+	optInErr := suite.service.OptIn(context.Background(), "PO1")
+	missingErr := suite.service.OptIn(context.Background(), "PO1")
+
+	// Assert
+	suite.NoError(err)
+	suite.NoError(againErr)
+	suite.Equal("PO1", optOut.PurchaseOrderID)
+	suite.Len(suite.notifications(StatusCancelled), 2)
+	suite.NoError(optInErr)
+	suite.ErrorIs(missingErr, gorm.ErrRecordNotFound)
+}
+
+func (suite *DispatcherTestSuite) TestDispatchDueSends() {
+	// Arrange
+	rp := suite.route.RoutePoints[0]
+	suite.Require().NoError(suite.notifier.Handle(context.Background(), suite.statusChanged(&rp, "pending", "in_route")))
+
+	// Act
+	err := suite.dispatcher.DispatchDue(context.Background())
+
+	// Assert
+	suite.NoError(err)
+	suite.Len(suite.email.sent, 1)
+	suite.Equal("ana@example.com", suite.email.sent[0].To)
+	suite.Len(suite.sms.sent, 1)
+	suite.Equal("+5491155550001", suite.sms.sent[0].To)
+	sent := suite.notifications(StatusSent)
+	suite.Len(sent, 2)
+	suite.NotNil(sent[0].SentAt)
+	suite.Equal(1, sent[0].Attempts)
+}
+
+func (suite *DispatcherTestSuite) TestDispatchDueRetriesThenFails() {
+	// Arrange
+	rp := suite.route.RoutePoints[0]
+	suite.Require().NoError(suite.notifier.Handle(context.Background(), suite.statusChanged(&rp, "pending", "in_route")))
+	suite.sms.err = errors.New("gateway down")
+
+	// Act
+	firstErr := suite.dispatcher.DispatchDue(context.Background())
+	retrying := suite.notifications(StatusPending)
+	notDueErr := suite.dispatcher.DispatchDue(context.Background())
+	suite.due()
+	lastErr := suite.dispatcher.DispatchDue(context.Background())
+
+	// Assert
+	suite.NoError(firstErr)
+	suite.NoError(notDueErr)
+	suite.NoError(lastErr)
+	suite.Len(retrying, 1)
+	suite.Equal("gateway down", retrying[0].LastError)
+	suite.True(retrying[0].NextAttemptAt.After(time.Now()))
+	failed := suite.notifications(StatusFailed)
+	suite.Len(failed, 1)
+	suite.Equal("sms", failed[0].Channel)
+	suite.Equal(2, failed[0].Attempts)
+}
+
+func (suite *DispatcherTestSuite) TestDispatchDueSkipsClaimedUntilLeaseRunsOut() {
+	// Arrange
+	rp := suite.route.RoutePoints[0]
+	suite.Require().NoError(suite.notifier.Handle(context.Background(), suite.statusChanged(&rp, "pending", "in_route")))
+	now := time.Now()
+	for _, notification := range suite.notifications(StatusPending) {
+		claimed, err := suite.repository.ClaimNotification(context.Background(), &notification, now, now.Add(time.Minute))
+		suite.Require().NoError(err)
+		suite.Require().True(claimed)
+	}
+
+	// Act
+	claimedErr := suite.dispatcher.DispatchDue(context.Background())
+	claimedSent := len(suite.email.sent) + len(suite.sms.sent)
+	suite.Require().NoError(suite.repository.db.Model(&Notification{}).Where("1 = 1").
+		Update("locked_until", now.Add(-time.Second)).Error)
+	expiredErr := suite.dispatcher.DispatchDue(context.Background())
+
+	// Assert
+	suite.NoError(claimedErr)
+	suite.NoError(expiredErr)
+	suite.Zero(claimedSent)
+	sent := suite.notifications(StatusSent)
+	suite.Len(sent, 2)
+	suite.Nil(sent[0].LockedUntil)
+}
+
+func (suite *DispatcherTestSuite) TestDispatchDueKeepsCancellation() {
+	// Arrange
+	rp := suite.route.RoutePoints[0]
+	suite.Require().NoError(suite.notifier.Handle(context.Background(), suite.statusChanged(&rp, "pending", "in_route")))
+	suite.email.onSend = func() {
+		_, err := suite.service.OptOut(context.Background(), "PO1")
+		suite.Require().NoError(err)
+	}
+
+	// Act
+	err := suite.dispatcher.DispatchDue(context.Background())
+
+	// Assert
+	suite.NoError(err)
+	suite.Len(suite.notifications(StatusCancelled), 2)
+	suite.Empty(suite.notifications(StatusSent))
+}
+
+func TestDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(DispatcherTestSuite))
+}
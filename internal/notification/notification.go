@@ -0,0 +1,112 @@
+package notification
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultTimeout      = 10 * time.Second
+	DefaultPollInterval = 5 * time.Second
+	DefaultLimit        = 100
+	MaxLimit            = 1000
+)
+
+// DefaultRetry resends a notification after 1m, 2m, 4m... up to an hour
+// apart, and gives up after 5 attempts
+var DefaultRetry = Retry{Base: time.Minute, Max: time.Hour, MaxAttempts: 5}
+
+var (
+	ErrInvalidStatus = errors.New("invalid notification status")
+	ErrNoChannel     = errors.New("notification channel is not configured")
+	// ErrInvalidRecipient is returned for recipients that would break out of
+	// their header, such as an email address with a line break
+	ErrInvalidRecipient = errors.New("invalid notification recipient")
+)
+
+// Notification is a message queued for a customer about the delivery of
+// their purchase order, sent in the background and retried until the channel
+// takes it or it runs out of attempts. LockedUntil is set while an instance of
+// the server sends it.
+type Notification struct {
+	ID uuid.UUID `gorm:"column:id" json:"id"`
+	// EventID is the outbox event the notification was queued for
+	EventID         uuid.UUID  `gorm:"column:event_id" json:"event_id"`
+	RoutePointID    uuid.UUID  `gorm:"column:route_point_id" json:"route_point_id"`
+	PurchaseOrderID string     `gorm:"column:purchase_order_id" json:"purchase_order_id"`
+	Template        string     `gorm:"column:template" json:"template"`
+	Channel         string     `gorm:"column:channel" json:"channel"`
+	Recipient       string     `gorm:"column:recipient" json:"recipient"`
+	Locale          string     `gorm:"column:locale" json:"locale"`
+	Subject         string     `gorm:"column:subject" json:"subject"`
+	Body            string     `gorm:"column:body" json:"body"`
+	Status          string     `gorm:"column:status" json:"status"`
+	Attempts        int        `gorm:"column:attempts" json:"attempts"`
+	NextAttemptAt   time.Time  `gorm:"column:next_attempt_at" json:"next_attempt_at"`
+	LastError       string     `gorm:"column:last_error" json:"last_error,omitempty"`
+	SentAt          *time.Time `gorm:"column:sent_at" json:"sent_at"`
+	LockedUntil     *time.Time `gorm:"column:locked_until" json:"-"`
+	CreatedAt       time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (Notification) TableName() string {
+	return "notification"
+}
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusSent    Status = "sent"
+	// StatusFailed notifications ran out of attempts
+	StatusFailed Status = "failed"
+	// StatusCancelled notifications were pending when the customer opted out
+	StatusCancelled Status = "cancelled"
+)
+
+var StatusList = map[Status]string{
+	StatusPending:   "pending",
+	StatusSent:      "sent",
+	StatusFailed:    "failed",
+	StatusCancelled: "cancelled",
+}
+
+// OptOut records that the customer of a purchase order wants no
+// notifications about it.
+type OptOut struct {
+	PurchaseOrderID string    `gorm:"column:purchase_order_id;primaryKey" json:"purchase_order_id"`
+	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (OptOut) TableName() string {
+	return "notification_opt_out"
+}
+
+type Filter struct {
+	PurchaseOrderID string `form:"purchase_order_id"`
+	RoutePointID    string `form:"route_point_id"`
+	Status          string `form:"status"`
+	Limit           int    `form:"limit"`
+}
+
+// Retry spaces the attempts of a notification exponentially.
+type Retry struct {
+	Base        time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+// Delay returns how long to wait after the given number of failed attempts.
+func (r Retry) Delay(attempts int) time.Duration {
+	delay := r.Base
+	for i := 1; i < attempts && delay < r.Max; i++ {
+		delay *= 2
+	}
+	if delay > r.Max {
+		delay = r.Max
+	}
+	return delay
+}
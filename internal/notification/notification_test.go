@@ -0,0 +1,73 @@
+package notification
+
+import (
+	routePoint "challenge-fravega/internal/route-point"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	// Arrange
+	data := &Data{CustomerName: "Ana", OrderNumber: "PO-123", Address: "Florida 165", Window: "14:00-18:00"}
+
+	// Act
+	es, esErr := Render(TemplateOutForDelivery, LocaleEs, data)
+	en, enErr := Render(TemplateDelivered, LocaleEn, data)
+	fallback, _ := Render(TemplateFailedAttempt, Locale("pt"), data)
+	_, unknownErr := Render(Template("cancelled"), LocaleEs, data)
+
+	// Assert
+	assert.NoError(t, esErr)
+	assert.NoError(t, enErr)
+	assert.Equal(t, "Tu pedido PO-123 está en camino", es.Subject)
+	assert.Equal(t, "Hola Ana, tu pedido PO-123 salió para ser entregado en Florida 165 entre las 14:00-18:00.", es.Body)
+	assert.Equal(t, "Your order PO-123 was delivered", en.Subject)
+	assert.Equal(t, "No pudimos entregar tu pedido PO-123", fallback.Subject)
+	assert.Error(t, unknownErr)
+}
+
+func TestEveryTemplateRendersInEveryLocale(t *testing.T) {
+	for locale := range LocaleList {
+		for tmpl := range TemplateList {
+			// Act
+			message, err := Render(tmpl, locale, &Data{OrderNumber: "PO-1"})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Contains(t, message.Subject, "PO-1")
+			assert.NotEmpty(t, message.Body)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	// Arrange
+	retry := Retry{Base: time.Minute, Max: 5 * time.Minute, MaxAttempts: 5}
+
+	// Act & Assert
+	assert.Equal(t, time.Minute, retry.Delay(1))
+	assert.Equal(t, 2*time.Minute, retry.Delay(2))
+	assert.Equal(t, 4*time.Minute, retry.Delay(3))
+	assert.Equal(t, 5*time.Minute, retry.Delay(10))
+}
+
+func TestNextStop(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	late := now.Add(2 * time.Hour)
+	delivered := routePoint.RoutePoint{ID: uuid.New(), Status: "completed", CreatedAt: now}
+	current := routePoint.RoutePoint{ID: uuid.New(), Status: "in_route", CreatedAt: now.Add(time.Minute)}
+	later := routePoint.RoutePoint{ID: uuid.New(), Status: "pending", CreatedAt: now.Add(2 * time.Minute), DeliveryWindowStart: &late}
+	first := routePoint.RoutePoint{ID: uuid.New(), Status: "pending", CreatedAt: now.Add(3 * time.Minute), DeliveryWindowStart: &now}
+
+	// Act
+	next := nextStop([]routePoint.RoutePoint{delivered, current, later, first}, current.ID)
+	none := nextStop([]routePoint.RoutePoint{delivered, current}, current.ID)
+
+	// Assert
+	assert.Equal(t, first.ID, next.ID)
+	assert.Nil(t, none)
+}
@@ -0,0 +1,187 @@
+package notification
+
+import (
+	"challenge-fravega/internal/outbox"
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// windowLayout formats the bounds of delivery windows in messages
+const windowLayout = "15:04"
+
+// Templates maps the status a route point moves to with the message its
+// customer is sent.
+var Templates = map[routePoint.RoutePointStatus]Template{
+	routePoint.RoutePointStatusInRoute:   TemplateOutForDelivery,
+	routePoint.RoutePointStatusCompleted: TemplateDelivered,
+	routePoint.RoutePointStatusFailed:    TemplateFailedAttempt,
+}
+
+// Routes finds the route of a route point, with its route points.
+type Routes interface {
+	GetRoute(ctx context.Context, id string) (*route.Route, error)
+}
+
+// Notifier queues the notifications of the customers as their route points
+// change status, for the dispatcher to send.
+type Notifier struct {
+	repository     Repository
+	purchaseOrders purchaseOrder.Client
+	routes         Routes
+	channels       []string
+	locale         Locale
+	location       *time.Location
+}
+
+// Handle is an outbox bus handler for route point status changes. Once a
+// stop is delivered or failed, the customer of the next stop of the route is
+// told theirs is next. Customers who opted out or without contact details
+// for any of the channels are left out. Events already handled are ignored.
+func (n *Notifier) Handle(ctx context.Context, event *outbox.Event) error {
+	data := routePoint.EventData{}
+	if err := json.Unmarshal(event.Payload, &data); err != nil {
+		return fmt.Errorf("failed to decode event %s: %w", event.ID, err)
+	}
+	status := routePoint.RoutePointStatus(data.Status)
+	tmpl, ok := Templates[status]
+	if !ok || data.RouteID == nil {
+		return nil
+	}
+	queued, err := n.repository.HasNotifications(ctx, event.ID)
+	if err != nil || queued {
+		return err
+	}
+
+	r, err := n.routes.GetRoute(ctx, data.RouteID.String())
+	if err != nil {
+		return err
+	}
+	var notifications []*Notification
+	for _, rp := range r.RoutePoints {
+		if rp.ID == data.RoutePointID {
+			queue, err := n.queue(ctx, event.ID, tmpl, &rp)
+			if err != nil {
+				return err
+			}
+			notifications = append(notifications, queue...)
+		}
+	}
+	if status == routePoint.RoutePointStatusCompleted || status == routePoint.RoutePointStatusFailed {
+		if next := nextStop(r.RoutePoints, data.RoutePointID); next != nil {
+			queue, err := n.queue(ctx, event.ID, TemplateNextStop, next)
+			if err != nil {
+				return err
+			}
+			notifications = append(notifications, queue...)
+		}
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	return n.repository.CreateNotifications(ctx, notifications)
+}
+
+// queue renders the template for the customer of the route point on every
+// channel they can be reached on.
+func (n *Notifier) queue(ctx context.Context, eventID uuid.UUID, tmpl Template, rp *routePoint.RoutePoint) ([]*Notification, error) {
+	optedOut, err := n.repository.IsOptedOut(ctx, rp.PurchaseOrderID)
+	if err != nil || optedOut {
+		return nil, err
+	}
+	order, err := n.purchaseOrders.GetPurchaseOrder(ctx, rp.PurchaseOrderID)
+	if errors.Is(err, purchaseOrder.ErrNotFound) {
+		slog.WarnContext(ctx, "Skipped notification", "template", TemplateList[tmpl], "purchase_order_id", rp.PurchaseOrderID,
+			"error", err)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	locale := n.locale
+	if _, ok := LocaleList[Locale(order.CustomerLocale)]; ok {
+		locale = Locale(order.CustomerLocale)
+	}
+	message, err := Render(tmpl, locale, &Data{
+		CustomerName: order.CustomerName,
+		OrderNumber:  order.OrderNumber,
+		Address:      rp.Address,
+		Window:       n.window(rp),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var notifications []*Notification
+	for _, channel := range n.channels {
+		recipient, subject := order.CustomerEmail, message.Subject
+		if channel == ChannelNameList[ChannelSMS] {
+			recipient, subject = order.CustomerPhone, ""
+		}
+		if recipient == "" {
+			continue
+		}
+		notifications = append(notifications, &Notification{
+			EventID:         eventID,
+			RoutePointID:    rp.ID,
+			PurchaseOrderID: rp.PurchaseOrderID,
+			Template:        TemplateList[tmpl],
+			Channel:         channel,
+			Recipient:       recipient,
+			Locale:          LocaleList[locale],
+			Subject:         subject,
+			Body:            message.Body,
+			Status:          StatusList[StatusPending],
+			NextAttemptAt:   time.Now(),
+		})
+	}
+	return notifications, nil
+}
+
+// window formats the delivery window of the route point in local time, or
+// returns an empty string when it has none.
+func (n *Notifier) window(rp *routePoint.RoutePoint) string {
+	if rp.DeliveryWindowStart == nil || rp.DeliveryWindowEnd == nil {
+		return ""
+	}
+	return rp.DeliveryWindowStart.In(n.location).Format(windowLayout) + "-" +
+		rp.DeliveryWindowEnd.In(n.location).Format(windowLayout)
+}
+
+// static functions
+
+// NewNotifier queues notifications on the named channels, in the locale of
+// the customer or the given one when the purchase order has none.
+func NewNotifier(repository Repository, purchaseOrders purchaseOrder.Client, routes Routes, channels []string, locale Locale, location *time.Location) *Notifier {
+	return &Notifier{
+		repository:     repository,
+		purchaseOrders: purchaseOrders,
+		routes:         routes,
+		channels:       channels,
+		locale:         locale,
+		location:       location,
+	}
+}
+
+// nextStop returns the first stop of the route, in delivery order, still to
+// be visited after the given one, or nil when none is left.
+func nextStop(routePoints []routePoint.RoutePoint, after uuid.UUID) *routePoint.RoutePoint {
+	stops := append([]routePoint.RoutePoint(nil), routePoints...)
+	routePoint.SortForDelivery(stops)
+	for i := range stops {
+		status := routePoint.RoutePointStatus(stops[i].Status)
+		if stops[i].ID != after && (status == routePoint.RoutePointStatusPending || status == routePoint.RoutePointStatusInRoute) {
+			return &stops[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository stores the customer notifications and the opt-outs.
+type Repository interface {
+	CreateNotifications(ctx context.Context, notifications []*Notification) error
+	HasNotifications(ctx context.Context, eventID uuid.UUID) (bool, error)
+	GetDueNotifications(ctx context.Context, now time.Time, limit int) ([]Notification, error)
+	ClaimNotification(ctx context.Context, notification *Notification, now time.Time, until time.Time) (bool, error)
+	GetNotifications(ctx context.Context, filter *Filter, limit int) ([]Notification, error)
+	UpdateNotification(ctx context.Context, notification *Notification) (bool, error)
+	GetOptOut(ctx context.Context, purchaseOrderID string) (*OptOut, error)
+	IsOptedOut(ctx context.Context, purchaseOrderID string) (bool, error)
+	CreateOptOut(ctx context.Context, purchaseOrderID string) error
+	DeleteOptOut(ctx context.Context, purchaseOrderID string) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func (r *repository) CreateNotifications(ctx context.Context, notifications []*Notification) error {
+	for _, notification := range notifications {
+		if notification.ID == uuid.Nil {
+			notification.ID = uuid.New()
+		}
+	}
+	return r.db.WithContext(ctx).CreateInBatches(notifications, 100).Error
+}
+
+// HasNotifications tells whether notifications were already queued for the
+// event.
+func (r *repository) HasNotifications(ctx context.Context, eventID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Notification{}).Where("event_id = ?", eventID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetDueNotifications returns the pending notifications due by now and not
+// claimed by another instance, oldest first.
+func (r *repository) GetDueNotifications(ctx context.Context, now time.Time, limit int) ([]Notification, error) {
+	var notifications []Notification
+	err := r.db.WithContext(ctx).Where("status = ? AND next_attempt_at <= ?", StatusList[StatusPending], now).
+		Where("locked_until IS NULL OR locked_until <= ?", now).
+		Order("next_attempt_at, created_at").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+// ClaimNotification leases the notification until the given time, unless it
+// was sent, cancelled, put off or claimed by another instance since it was
+// read, and tells whether it was. UpdateNotification releases it.
+func (r *repository) ClaimNotification(ctx context.Context, notification *Notification, now time.Time, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Notification{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", notification.ID, StatusList[StatusPending], now).
+		Where("locked_until IS NULL OR locked_until <= ?", now).
+		Update("locked_until", until)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	notification.LockedUntil = &until
+	return true, nil
+}
+
+// GetNotifications returns the most recent notifications first, optionally
+// only those of a purchase order, a route point or with a status.
+func (r *repository) GetNotifications(ctx context.Context, filter *Filter, limit int) ([]Notification, error) {
+	var notifications []Notification
+	query := r.db.WithContext(ctx).Order("created_at DESC").Limit(limit)
+	if filter.PurchaseOrderID != "" {
+		query = query.Where("purchase_order_id = ?", filter.PurchaseOrderID)
+	}
+	if filter.RoutePointID != "" {
+		if _, err := uuid.Parse(filter.RoutePointID); err != nil {
+			return []Notification{}, nil
+		}
+		query = query.Where("route_point_id = ?", filter.RoutePointID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	err := query.Find(&notifications).Error
+	return notifications, err
+}
+
+// UpdateNotification saves the outcome of an attempt, unless the notification
+// is no longer pending, such as cancelled by an opt-out while it was being
+// sent. It tells whether the outcome was saved.
+func (r *repository) UpdateNotification(ctx context.Context, notification *Notification) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Notification{}).
+		Where("id = ? AND status = ?", notification.ID, StatusList[StatusPending]).
+		Updates(map[string]interface{}{
+			"status":          notification.Status,
+			"attempts":        notification.Attempts,
+			"next_attempt_at": notification.NextAttemptAt,
+			"last_error":      notification.LastError,
+			"sent_at":         notification.SentAt,
+			"locked_until":    nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *repository) GetOptOut(ctx context.Context, purchaseOrderID string) (*OptOut, error) {
+	var optOut OptOut
+	err := r.db.WithContext(ctx).First(&optOut, "purchase_order_id = ?", purchaseOrderID).Error
+	return &optOut, err
+}
+
+func (r *repository) IsOptedOut(ctx context.Context, purchaseOrderID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&OptOut{}).Where("purchase_order_id = ?", purchaseOrderID).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateOptOut records the opt-out, unless already there, and cancels the
+// notifications of the purchase order not sent yet.
+func (r *repository) CreateOptOut(ctx context.Context, purchaseOrderID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		optOut := &OptOut{PurchaseOrderID: purchaseOrderID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(optOut).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Notification{}).
+			Where("purchase_order_id = ? AND status = ?", purchaseOrderID, StatusList[StatusPending]).
+			Update("status", StatusList[StatusCancelled]).Error
+	})
+}
+
+func (r *repository) DeleteOptOut(ctx context.Context, purchaseOrderID string) error {
+	result := r.db.WithContext(ctx).Where("purchase_order_id = ?", purchaseOrderID).Delete(&OptOut{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// static functions
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
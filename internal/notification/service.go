@@ -0,0 +1,60 @@
+package notification
+
+import (
+	"context"
+)
+
+type Service interface {
+	GetNotifications(ctx context.Context, filter *Filter) ([]Notification, error)
+	GetOptOut(ctx context.Context, purchaseOrderID string) (*OptOut, error)
+	OptOut(ctx context.Context, purchaseOrderID string) (*OptOut, error)
+	OptIn(ctx context.Context, purchaseOrderID string) error
+}
+
+type service struct {
+	repository Repository
+}
+
+// GetNotifications returns the most recent notifications first.
+func (s *service) GetNotifications(ctx context.Context, filter *Filter) ([]Notification, error) {
+	if filter.Status != "" {
+		if _, ok := StatusList[Status(filter.Status)]; !ok {
+			return nil, ErrInvalidStatus
+		}
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	return s.repository.GetNotifications(ctx, filter, limit)
+}
+
+func (s *service) GetOptOut(ctx context.Context, purchaseOrderID string) (*OptOut, error) {
+	return s.repository.GetOptOut(ctx, purchaseOrderID)
+}
+
+// OptOut stops the notifications about the purchase order, including those
+// already queued. Opting out again changes nothing.
+func (s *service) OptOut(ctx context.Context, purchaseOrderID string) (*OptOut, error) {
+	if err := s.repository.CreateOptOut(ctx, purchaseOrderID); err != nil {
+		return nil, err
+	}
+	return s.repository.GetOptOut(ctx, purchaseOrderID)
+}
+
+// OptIn lets the notifications about the purchase order through again, from
+// its next status change on.
+func (s *service) OptIn(ctx context.Context, purchaseOrderID string) error {
+	return s.repository.DeleteOptOut(ctx, purchaseOrderID)
+}
+
+// static functions
+
+func NewService(repository Repository) *service {
+	return &service{repository: repository}
+}
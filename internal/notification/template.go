@@ -0,0 +1,121 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Template is the kind of message a customer is sent.
+type Template string
+
+const (
+	TemplateOutForDelivery Template = "out_for_delivery"
+	// TemplateNextStop tells the customer theirs is the next delivery of
+	// the route
+	TemplateNextStop      Template = "next_stop"
+	TemplateDelivered     Template = "delivered"
+	TemplateFailedAttempt Template = "failed_attempt"
+)
+
+var TemplateList = map[Template]string{
+	TemplateOutForDelivery: "out_for_delivery",
+	TemplateNextStop:       "next_stop",
+	TemplateDelivered:      "delivered",
+	TemplateFailedAttempt:  "failed_attempt",
+}
+
+type Locale string
+
+const (
+	LocaleEs Locale = "es"
+	LocaleEn Locale = "en"
+)
+
+var LocaleList = map[Locale]string{
+	LocaleEs: "es",
+	LocaleEn: "en",
+}
+
+// Data fills in the templates. Window is the delivery window agreed with the
+// customer, such as "14:00-18:00", if any.
+type Data struct {
+	CustomerName string
+	OrderNumber  string
+	Address      string
+	Window       string
+}
+
+// Message is a rendered template. SMS only carry the body.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body"`
+}
+
+type message struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+var messages = map[Locale]map[Template]message{
+	LocaleEs: {
+		TemplateOutForDelivery: newMessage(
+			"Tu pedido {{.OrderNumber}} está en camino",
+			"Hola {{.CustomerName}}, tu pedido {{.OrderNumber}} salió para ser entregado en {{.Address}}{{if .Window}} entre las {{.Window}}{{end}}."),
+		TemplateNextStop: newMessage(
+			"Tu pedido {{.OrderNumber}} es la próxima entrega",
+			"Hola {{.CustomerName}}, el repartidor va hacia {{.Address}}: tu pedido {{.OrderNumber}} es la próxima entrega."),
+		TemplateDelivered: newMessage(
+			"Tu pedido {{.OrderNumber}} fue entregado",
+			"Hola {{.CustomerName}}, entregamos tu pedido {{.OrderNumber}} en {{.Address}}. ¡Gracias por tu compra!"),
+		TemplateFailedAttempt: newMessage(
+			"No pudimos entregar tu pedido {{.OrderNumber}}",
+			"Hola {{.CustomerName}}, no pudimos entregar tu pedido {{.OrderNumber}} en {{.Address}}. Nos vamos a comunicar para coordinar una nueva entrega."),
+	},
+	LocaleEn: {
+		TemplateOutForDelivery: newMessage(
+			"Your order {{.OrderNumber}} is on its way",
+			"Hi {{.CustomerName}}, your order {{.OrderNumber}} is out for delivery to {{.Address}}{{if .Window}} between {{.Window}}{{end}}."),
+		TemplateNextStop: newMessage(
+			"Your order {{.OrderNumber}} is the next delivery",
+			"Hi {{.CustomerName}}, the driver is heading to {{.Address}}: your order {{.OrderNumber}} is the next delivery."),
+		TemplateDelivered: newMessage(
+			"Your order {{.OrderNumber}} was delivered",
+			"Hi {{.CustomerName}}, your order {{.OrderNumber}} was delivered to {{.Address}}. Thank you for your purchase!"),
+		TemplateFailedAttempt: newMessage(
+			"We could not deliver your order {{.OrderNumber}}",
+			"Hi {{.CustomerName}}, we could not deliver your order {{.OrderNumber}} to {{.Address}}. We will get in touch to arrange a new delivery."),
+	},
+}
+
+// static functions
+
+// Render fills in the template in the locale, falling back to Spanish for
+// unknown locales.
+func Render(tmpl Template, locale Locale, data *Data) (*Message, error) {
+	localized, ok := messages[locale]
+	if !ok {
+		localized = messages[LocaleEs]
+	}
+	m, ok := localized[tmpl]
+	if !ok {
+		return nil, fmt.Errorf("unknown notification template %s", tmpl)
+	}
+
+	var subject, body strings.Builder
+	if err := m.subject.Execute(&subject, data); err != nil {
+		return nil, err
+	}
+	if err := m.body.Execute(&body, data); err != nil {
+		return nil, err
+	}
+	return &Message{Subject: subject.String(), Body: body.String()}, nil
+}
+
+func newMessage(subject string, body string) message {
+	return message{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		body:    template.Must(template.New("body").Parse(body)),
+	}
+}
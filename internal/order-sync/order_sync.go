@@ -0,0 +1,206 @@
+package orderSync
+
+import (
+	"challenge-fravega/internal/outbox"
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	routePoint "challenge-fravega/internal/route-point"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobName is the name of the job reporting purchase orders out of sync
+const JobName = "purchase_order_sync"
+
+const (
+	// DefaultWindow is how far back the route points changed are compared
+	DefaultWindow = 7 * 24 * time.Hour
+	// pageSize bounds the route points loaded at a time
+	pageSize = 500
+)
+
+// Statuses maps the status of a route point to that of its purchase order in
+// the order system.
+var Statuses = map[routePoint.RoutePointStatus]purchaseOrder.Status{
+	routePoint.RoutePointStatusPending:   purchaseOrder.StatusPending,
+	routePoint.RoutePointStatusInRoute:   purchaseOrder.StatusShipped,
+	routePoint.RoutePointStatusArrived:   purchaseOrder.StatusShipped,
+	routePoint.RoutePointStatusCompleted: purchaseOrder.StatusDelivered,
+	routePoint.RoutePointStatusFailed:    purchaseOrder.StatusFailed,
+}
+
+// Syncer updates the purchase orders in the order system as their route
+// points change status.
+type Syncer struct {
+	client purchaseOrder.StatusClient
+}
+
+// Handle is an outbox bus handler for route point status changes. Failures
+// are returned for the outbox to retry the event, except for purchase orders
+// the order system does not know or whose status it refuses, which retrying
+// would not change and are only logged.
+func (s *Syncer) Handle(ctx context.Context, event *outbox.Event) error {
+	data := routePoint.EventData{}
+	if err := json.Unmarshal(event.Payload, &data); err != nil {
+		return fmt.Errorf("failed to decode event %s: %w", event.ID, err)
+	}
+	status, ok := Statuses[routePoint.RoutePointStatus(data.Status)]
+	if !ok {
+		return nil
+	}
+
+	err := s.client.UpdateStatus(ctx, data.PurchaseOrderID, status)
+	if errors.Is(err, purchaseOrder.ErrNotFound) || errors.Is(err, purchaseOrder.ErrRejected) {
+		slog.WarnContext(ctx, "Skipped purchase order status", "status", purchaseOrder.StatusList[status],
+			"purchase_order_id", data.PurchaseOrderID, "error", err)
+		return nil
+	}
+	return err
+}
+
+// Summary is the outcome of a run of the drift report.
+type Summary struct {
+	Checked int       `json:"checked"`
+	InSync  int       `json:"in_sync"`
+	Drift   []Drift   `json:"drift"`
+	Failed  []Failure `json:"failed"`
+}
+
+// Drift is a purchase order whose status in the order system differs from
+// that of its route point.
+type Drift struct {
+	RoutePointID     uuid.UUID `json:"route_point_id"`
+	PurchaseOrderID  string    `json:"purchase_order_id"`
+	RoutePointStatus string    `json:"route_point_status"`
+	Expected         string    `json:"expected"`
+	Upstream         string    `json:"upstream"`
+}
+
+// Failure is a purchase order that could not be compared.
+type Failure struct {
+	RoutePointID    uuid.UUID `json:"route_point_id"`
+	PurchaseOrderID string    `json:"purchase_order_id"`
+	Error           string    `json:"error"`
+}
+
+// RoutePoints pages through the route points to compare with the order
+// system.
+type RoutePoints interface {
+	GetChangedRoutePoints(ctx context.Context, since time.Time, afterID uuid.UUID, limit int) ([]routePoint.RoutePoint, error)
+}
+
+// Job compares the status of the purchase orders in the order system with
+// that of their route points changed within the window, reporting the
+// differences. Nothing is changed. Route points settled before the window
+// were compared by the runs back then.
+type Job struct {
+	routePoints RoutePoints
+	client      purchaseOrder.Client
+	window      time.Duration
+}
+
+func (j *Job) Name() string {
+	return JobName
+}
+
+func (j *Job) Run(ctx context.Context) (interface{}, error) {
+	since := time.Now().Add(-j.window)
+	summary := &Summary{Drift: []Drift{}, Failed: []Failure{}}
+	afterID := uuid.Nil
+	for {
+		routePoints, err := j.routePoints.GetChangedRoutePoints(ctx, since, afterID, pageSize)
+		if err != nil {
+			return summary, err
+		}
+		if err := j.compare(ctx, routePoints, summary); err != nil {
+			return summary, err
+		}
+		if len(routePoints) < pageSize {
+			return summary, nil
+		}
+		afterID = routePoints[len(routePoints)-1].ID
+	}
+}
+
+// compare looks up the purchase orders of a page of route points,
+// routePoint.MaxConcurrentVerifications at a time, and adds the outcome to
+// the summary in the order of the page.
+func (j *Job) compare(ctx context.Context, routePoints []routePoint.RoutePoint, summary *Summary) error {
+	type lookup struct {
+		upstream *purchaseOrder.PurchaseOrder
+		err      error
+	}
+	lookups := make([]*lookup, len(routePoints))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, routePoint.MaxConcurrentVerifications)
+	for i, rp := range routePoints {
+		if _, ok := Statuses[routePoint.RoutePointStatus(rp.Status)]; !ok {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, purchaseOrderID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			upstream, err := j.client.GetPurchaseOrder(ctx, purchaseOrderID)
+			lookups[i] = &lookup{upstream: upstream, err: err}
+		}(i, rp.PurchaseOrderID)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	for i, rp := range routePoints {
+		if lookups[i] == nil {
+			continue
+		}
+		if lookups[i].err != nil {
+			summary.Failed = append(summary.Failed, Failure{RoutePointID: rp.ID, PurchaseOrderID: rp.PurchaseOrderID, Error: lookups[i].err.Error()})
+			continue
+		}
+
+		expected := Statuses[routePoint.RoutePointStatus(rp.Status)]
+		summary.Checked++
+		if lookups[i].upstream.Status == purchaseOrder.StatusList[expected] {
+			summary.InSync++
+			continue
+		}
+		summary.Drift = append(summary.Drift, Drift{
+			RoutePointID:     rp.ID,
+			PurchaseOrderID:  rp.PurchaseOrderID,
+			RoutePointStatus: rp.Status,
+			Expected:         purchaseOrder.StatusList[expected],
+			Upstream:         lookups[i].upstream.Status,
+		})
+	}
+	return nil
+}
+
+// static functions
+
+func NewSyncer(client purchaseOrder.StatusClient) *Syncer {
+	return &Syncer{client: client}
+}
+
+// NewJob creates the drift report of the route points changed within window,
+// DefaultWindow when it is not positive.
+func NewJob(routePoints RoutePoints, client purchaseOrder.Client, window time.Duration) *Job {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Job{routePoints: routePoints, client: client, window: window}
+}
@@ -0,0 +1,140 @@
+package orderSync
+
+import (
+	"challenge-fravega/internal/outbox"
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	routePoint "challenge-fravega/internal/route-point"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOrderSystem keeps the status of the purchase orders it knows
+type fakeOrderSystem struct {
+	statuses map[string]string
+	err      error
+	updates  []string
+}
+
+func (f *fakeOrderSystem) GetPurchaseOrder(ctx context.Context, id string) (*purchaseOrder.PurchaseOrder, error) {
+	status, ok := f.statuses[id]
+	if !ok {
+		return nil, purchaseOrder.ErrNotFound
+	}
+	return &purchaseOrder.PurchaseOrder{ID: id, Status: status}, nil
+}
+
+func (f *fakeOrderSystem) UpdateStatus(ctx context.Context, id string, status purchaseOrder.Status) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.updates = append(f.updates, id+":"+purchaseOrder.StatusList[status])
+	return nil
+}
+
+func statusChanged(t *testing.T, purchaseOrderID string, from string, to string) *outbox.Event {
+	rp := &routePoint.RoutePoint{ID: uuid.New(), PurchaseOrderID: purchaseOrderID, Status: to}
+	event, err := routePoint.NewEvent(outbox.EventRoutePointStatusChanged, from, rp)
+	assert.NoError(t, err)
+	return &event
+}
+
+func TestHandleUpdatesStatus(t *testing.T) {
+	// Arrange
+	orders := &fakeOrderSystem{}
+	syncer := NewSyncer(orders)
+
+	// Act
+	shippedErr := syncer.Handle(context.Background(), statusChanged(t, "PO1", "pending", "in_route"))
+	arrivedErr := syncer.Handle(context.Background(), statusChanged(t, "PO1", "in_route", "arrived"))
+	deliveredErr := syncer.Handle(context.Background(), statusChanged(t, "PO1", "arrived", "completed"))
+	failedErr := syncer.Handle(context.Background(), statusChanged(t, "PO2", "arrived", "failed"))
+
+	// Assert
+	assert.NoError(t, errors.Join(shippedErr, arrivedErr, deliveredErr, failedErr))
+	assert.Equal(t, []string{"PO1:SHIPPED", "PO1:SHIPPED", "PO1:DELIVERED", "PO2:FAILED"}, orders.updates)
+}
+
+func TestHandleRetriesOnlyTransientFailures(t *testing.T) {
+	// Arrange
+	unavailable := &fakeOrderSystem{err: errors.New("unexpected status 503")}
+	unknown := &fakeOrderSystem{err: purchaseOrder.ErrNotFound}
+	refused := &fakeOrderSystem{err: purchaseOrder.ErrRejected}
+	event := statusChanged(t, "PO1", "pending", "in_route")
+
+	// Act
+	unavailableErr := NewSyncer(unavailable).Handle(context.Background(), event)
+	unknownErr := NewSyncer(unknown).Handle(context.Background(), event)
+	refusedErr := NewSyncer(refused).Handle(context.Background(), event)
+
+	// Assert
+	assert.Error(t, unavailableErr)
+	assert.NoError(t, unknownErr)
+	assert.NoError(t, refusedErr)
+}
+
+func TestJobReportsDrift(t *testing.T) {
+	// Arrange
+	orders := &fakeOrderSystem{statuses: map[string]string{
+		"PO1": "SHIPPED",
+		"PO2": "PENDING",
+		"PO3": "DELIVERED",
+	}}
+	drifted := routePoint.RoutePoint{ID: uuid.New(), PurchaseOrderID: "PO2", Status: "completed"}
+	job := NewJob(routePoint.NewFakeRepository(
+		routePoint.RoutePoint{ID: uuid.New(), PurchaseOrderID: "PO1", Status: "arrived"},
+		drifted,
+		routePoint.RoutePoint{ID: uuid.New(), PurchaseOrderID: "PO3", Status: "completed"},
+		routePoint.RoutePoint{ID: uuid.New(), PurchaseOrderID: "PO4", Status: "pending"},
+	), orders, 0)
+
+	// Act
+	result, err := job.Run(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	summary := result.(*Summary)
+	assert.Equal(t, 3, summary.Checked)
+	assert.Equal(t, 2, summary.InSync)
+	assert.Equal(t, []Drift{{
+		RoutePointID:     drifted.ID,
+		PurchaseOrderID:  "PO2",
+		RoutePointStatus: "completed",
+		Expected:         "DELIVERED",
+		Upstream:         "PENDING",
+	}}, summary.Drift)
+	assert.Len(t, summary.Failed, 1)
+	assert.Equal(t, "PO4", summary.Failed[0].PurchaseOrderID)
+	assert.Empty(t, orders.updates)
+}
+
+func TestJobChecksOnlyChangedWithinWindow(t *testing.T) {
+	// Arrange
+	orders := &fakeOrderSystem{statuses: map[string]string{}}
+	routePoints := []routePoint.RoutePoint{}
+	for i := 0; i < pageSize+1; i++ {
+		id := fmt.Sprintf("PO%d", i)
+		orders.statuses[id] = "SHIPPED"
+		routePoints = append(routePoints, routePoint.RoutePoint{ID: uuid.New(), PurchaseOrderID: id, Status: "in_route"})
+	}
+	settled := time.Now().Add(-30 * 24 * time.Hour)
+	routePoints = append(routePoints, routePoint.RoutePoint{
+		ID: uuid.New(), PurchaseOrderID: "OLD", Status: "completed", CreatedAt: settled, UpdatedAt: settled,
+	})
+	job := NewJob(routePoint.NewFakeRepository(routePoints...), orders, 24*time.Hour)
+
+	// Act
+	result, err := job.Run(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	summary := result.(*Summary)
+	assert.Equal(t, pageSize+1, summary.Checked)
+	assert.Equal(t, pageSize+1, summary.InSync)
+	assert.Empty(t, summary.Failed)
+}
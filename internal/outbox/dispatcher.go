@@ -0,0 +1,150 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// batchSize bounds the events read on each poll
+	batchSize = 100
+	// lease is how long an instance has to publish an event it claimed before
+	// another one takes it over
+	lease = 5 * time.Minute
+)
+
+// Dispatcher publishes the events recorded in the outbox to every sink, at
+// least once and in order per aggregate: an event is only published once all
+// the earlier events of its aggregate were, so a failing event holds back the
+// later ones of its aggregate, but not those of other aggregates. An event
+// failing maxAttempts times is given up on, releasing its aggregate. Every
+// instance of the server may dispatch the same outbox: each event is claimed
+// before it is published, and an event claimed by another instance holds back
+// the later ones of its aggregate.
+type Dispatcher struct {
+	repository    Repository
+	sinks         []Sink
+	interval      time.Duration
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
+	maxAttempts   int
+}
+
+// Start polls the outbox every interval until ctx is cancelled, then
+// finishes the batch in flight and marks wg done.
+func (d *Dispatcher) Start(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.Dispatch(context.WithoutCancel(ctx)); err != nil {
+					slog.ErrorContext(ctx, "Failed to dispatch outbox events", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Dispatch publishes the unpublished events that are due.
+func (d *Dispatcher) Dispatch(ctx context.Context) error {
+	events, err := d.repository.GetDue(ctx, time.Now(), batchSize)
+	if err != nil {
+		return err
+	}
+
+	// Events after one failing in this batch wait for it to be retried
+	held := map[string]bool{}
+	for i := range events {
+		event := &events[i]
+		aggregate := event.AggregateType + ":" + event.AggregateID.String()
+		if held[aggregate] {
+			continue
+		}
+
+		now := time.Now()
+		claimed, err := d.repository.Claim(ctx, event, now, now.Add(lease))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			held[aggregate] = true
+			continue
+		}
+
+		event.Attempts++
+		if err := d.publish(ctx, event); err != nil {
+			event.LastError = err.Error()
+			if event.Attempts >= d.maxAttempts {
+				event.FailedAt = &now
+				slog.ErrorContext(ctx, "Gave up publishing outbox event", "event_id", event.ID, "event_type", event.Type,
+					"attempts", event.Attempts, "error", err)
+			} else {
+				held[aggregate] = true
+				event.NextAttemptAt = now.Add(d.delay(event.Attempts))
+				slog.WarnContext(ctx, "Failed to publish outbox event", "event_id", event.ID, "event_type", event.Type,
+					"attempt", event.Attempts, "error", err)
+			}
+			if err := d.repository.MarkFailed(ctx, event); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.repository.MarkPublished(ctx, event, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publish sends the event to every sink, even if one fails, so that a failing
+// sink does not keep the event from the others.
+func (d *Dispatcher) publish(ctx context.Context, event *Event) error {
+	var errs []error
+	for _, sink := range d.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// delay returns how long to wait after the given number of failed attempts.
+func (d *Dispatcher) delay(attempts int) time.Duration {
+	delay := d.retryDelay
+	for i := 1; i < attempts && delay < d.maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.maxRetryDelay {
+		delay = d.maxRetryDelay
+	}
+	return delay
+}
+
+// static functions
+
+func NewDispatcher(repository Repository, interval time.Duration, maxAttempts int, sinks ...Sink) *Dispatcher {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	return &Dispatcher{
+		repository:    repository,
+		sinks:         sinks,
+		interval:      interval,
+		retryDelay:    DefaultRetryDelay,
+		maxRetryDelay: DefaultMaxRetryDelay,
+		maxAttempts:   maxAttempts,
+	}
+}
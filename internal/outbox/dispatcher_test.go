@@ -0,0 +1,332 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// recordingSink remembers the events published to it, failing those of the
+// aggregates in failing
+type recordingSink struct {
+	published []string
+	failing   map[uuid.UUID]bool
+}
+
+func (r *recordingSink) Name() string {
+	return "recording"
+}
+
+func (r *recordingSink) Publish(ctx context.Context, event *Event) error {
+	if r.failing[event.AggregateID] {
+		return errors.New("sink unavailable")
+	}
+	r.published = append(r.published, event.Type+":"+event.AggregateID.String())
+	return nil
+}
+
+type DispatcherTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	repository Repository
+	sink       *recordingSink
+	dispatcher *Dispatcher
+}
+
+func (suite *DispatcherTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	err = db.AutoMigrate(&Event{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	suite.db = db
+	suite.repository = NewRepository(db)
+	suite.sink = &recordingSink{failing: map[uuid.UUID]bool{}}
+	suite.dispatcher = NewDispatcher(suite.repository, time.Second, 3, suite.sink)
+}
+
+func (suite *DispatcherTestSuite) append(aggregateID uuid.UUID, eventType EventType) Event {
+	event, err := NewEvent(AggregateRoute, aggregateID, eventType, map[string]string{"id": aggregateID.String()})
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.db.Transaction(func(tx *gorm.DB) error {
+		return Append(tx, event)
+	}))
+	return event
+}
+
+func (suite *DispatcherTestSuite) TestDispatchPublishesInOrder() {
+	// Arrange
+	first, second := uuid.New(), uuid.New()
+	suite.append(first, EventRouteCreated)
+	suite.append(second, EventRouteCreated)
+	suite.append(first, EventRouteStarted)
+
+	// Act
+	err := suite.dispatcher.Dispatch(context.Background())
+	againErr := suite.dispatcher.Dispatch(context.Background())
+
+	// Assert
+	suite.NoError(err)
+	suite.NoError(againErr)
+	suite.Equal([]string{
+		"route.created:" + first.String(),
+		"route.created:" + second.String(),
+		"route.started:" + first.String(),
+	}, suite.sink.published)
+	unpublished, _ := suite.repository.GetUnpublished(context.Background(), 10)
+	suite.Empty(unpublished)
+}
+
+func (suite *DispatcherTestSuite) TestDispatchHoldsBackFailingAggregate() {
+	// Arrange
+	failing, other := uuid.New(), uuid.New()
+	suite.sink.failing[failing] = true
+	suite.append(failing, EventRouteCreated)
+	suite.append(failing, EventRouteStarted)
+	suite.append(other, EventRouteCreated)
+
+	// Act
+	err := suite.dispatcher.Dispatch(context.Background())
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal([]string{"route.created:" + other.String()}, suite.sink.published)
+	unpublished, _ := suite.repository.GetUnpublished(context.Background(), 10)
+	suite.Require().Len(unpublished, 2)
+	suite.Equal(1, unpublished[0].Attempts)
+	suite.Contains(unpublished[0].LastError, "recording: sink unavailable")
+	suite.WithinDuration(time.Now().Add(DefaultRetryDelay), unpublished[0].NextAttemptAt, time.Second)
+	suite.Equal(0, unpublished[1].Attempts)
+}
+
+func (suite *DispatcherTestSuite) TestDispatchRetriesWhenDue() {
+	// Arrange
+	failing := uuid.New()
+	suite.sink.failing[failing] = true
+	suite.append(failing, EventRouteCreated)
+	suite.append(failing, EventRouteStarted)
+	suite.Require().NoError(suite.dispatcher.Dispatch(context.Background()))
+	suite.sink.failing[failing] = false
+
+	// Act
+	notDueErr := suite.dispatcher.Dispatch(context.Background())
+	notDue := len(suite.sink.published)
+	suite.db.Model(&Event{}).Where("1 = 1").Update("next_attempt_at", time.Now().Add(-time.Second))
+	err := suite.dispatcher.Dispatch(context.Background())
+
+	// Assert
+	suite.NoError(notDueErr)
+	suite.NoError(err)
+	suite.Zero(notDue)
+	suite.Equal([]string{
+		"route.created:" + failing.String(),
+		"route.started:" + failing.String(),
+	}, suite.sink.published)
+}
+
+func (suite *DispatcherTestSuite) TestDispatchGivesUpAfterMaxAttempts() {
+	// Arrange
+	failing := uuid.New()
+	suite.sink.failing[failing] = true
+	suite.append(failing, EventRouteCreated)
+	suite.append(failing, EventRouteStarted)
+
+	// Act
+	for i := 0; i < 3; i++ {
+		suite.db.Model(&Event{}).Where("1 = 1").Update("next_attempt_at", time.Now().Add(-time.Second))
+		suite.Require().NoError(suite.dispatcher.Dispatch(context.Background()))
+	}
+	suite.sink.failing[failing] = false
+	suite.db.Model(&Event{}).Where("1 = 1").Update("next_attempt_at", time.Now().Add(-time.Second))
+	err := suite.dispatcher.Dispatch(context.Background())
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal([]string{"route.started:" + failing.String()}, suite.sink.published)
+	var failed Event
+	suite.Require().NoError(suite.db.First(&failed, "event_type = ?", EventRouteCreated).Error)
+	suite.NotNil(failed.FailedAt)
+	suite.Nil(failed.PublishedAt)
+	suite.Equal(3, failed.Attempts)
+	unpublished, _ := suite.repository.GetUnpublished(context.Background(), 10)
+	suite.Empty(unpublished)
+}
+
+func (suite *DispatcherTestSuite) TestGetDueSkipsHeldEvents() {
+	// Arrange
+	held, other := uuid.New(), uuid.New()
+	suite.append(held, EventRouteCreated)
+	suite.append(held, EventRouteStarted)
+	suite.append(other, EventRouteCreated)
+	suite.db.Model(&Event{}).Where("event_type = ?", EventRouteCreated).Where("aggregate_id = ?", held).
+		Update("next_attempt_at", time.Now().Add(time.Hour))
+
+	// Act
+	due, err := suite.repository.GetDue(context.Background(), time.Now(), 1)
+
+	// Assert
+	suite.NoError(err)
+	suite.Require().Len(due, 1)
+	suite.Equal(other, due[0].AggregateID)
+}
+
+func (suite *DispatcherTestSuite) TestGetDueSkipsClaimedEvents() {
+	// Arrange
+	claimed, other := uuid.New(), uuid.New()
+	suite.append(claimed, EventRouteCreated)
+	suite.append(claimed, EventRouteStarted)
+	suite.append(other, EventRouteCreated)
+	due, _ := suite.repository.GetDue(context.Background(), time.Now(), 1)
+	suite.Require().Len(due, 1)
+	now := time.Now()
+	first, _ := suite.repository.Claim(context.Background(), &due[0], now, now.Add(time.Minute))
+
+	// Act
+	again, err := suite.repository.Claim(context.Background(), &due[0], now, now.Add(time.Minute))
+	remaining, dueErr := suite.repository.GetDue(context.Background(), time.Now(), 10)
+
+	// Assert
+	suite.True(first)
+	suite.NoError(err)
+	suite.False(again)
+	suite.NoError(dueErr)
+	suite.Require().Len(remaining, 1)
+	suite.Equal(other, remaining[0].AggregateID)
+}
+
+func (suite *DispatcherTestSuite) TestDispatchPublishesToEverySink() {
+	// Arrange
+	failingSink := &recordingSink{failing: map[uuid.UUID]bool{}}
+	aggregateID := uuid.New()
+	failingSink.failing[aggregateID] = true
+	dispatcher := NewDispatcher(suite.repository, time.Second, 3, failingSink, suite.sink)
+	suite.append(aggregateID, EventRouteCreated)
+
+	// Act
+	err := dispatcher.Dispatch(context.Background())
+
+	// Assert
+	suite.NoError(err)
+	suite.Len(suite.sink.published, 1)
+	unpublished, _ := suite.repository.GetUnpublished(context.Background(), 10)
+	suite.Len(unpublished, 1)
+}
+
+func (suite *DispatcherTestSuite) TestAppendIsRolledBackWithTransaction() {
+	// Arrange
+	event, err := NewEvent(AggregateRoute, uuid.New(), EventRouteUpdated, nil)
+	suite.Require().NoError(err)
+
+	// Act
+	txErr := suite.db.Transaction(func(tx *gorm.DB) error {
+		if err := Append(tx, event); err != nil {
+			return err
+		}
+		return errors.New("version conflict")
+	})
+
+	// Assert
+	suite.Error(txErr)
+	unpublished, _ := suite.repository.GetUnpublished(context.Background(), 10)
+	suite.Empty(unpublished)
+}
+
+func TestDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(DispatcherTestSuite))
+}
+
+// syncSink remembers the events published to it by several dispatchers at
+// once
+type syncSink struct {
+	mu        sync.Mutex
+	published []Event
+}
+
+func (s *syncSink) Name() string {
+	return "sync"
+}
+
+func (s *syncSink) Publish(ctx context.Context, event *Event) error {
+	time.Sleep(time.Millisecond)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published = append(s.published, *event)
+	return nil
+}
+
+func TestDispatchersShareOutbox(t *testing.T) {
+	// Arrange
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "outbox.sqlite")+"?_busy_timeout=5000"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Event{}))
+	eventTypes := []EventType{EventRouteCreated, EventRouteStarted, EventRouteUpdated, EventRouteCompleted}
+	for i := 0; i < 10; i++ {
+		aggregateID := uuid.New()
+		for _, eventType := range eventTypes {
+			event, err := NewEvent(AggregateRoute, aggregateID, eventType, nil)
+			require.NoError(t, err)
+			require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+				return Append(tx, event)
+			}))
+		}
+	}
+	sink := &syncSink{}
+	repository := NewRepository(db)
+
+	// Act
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		dispatcher := NewDispatcher(repository, time.Second, 3, sink)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				unpublished, err := repository.GetUnpublished(context.Background(), 1)
+				if err != nil || len(unpublished) == 0 {
+					return
+				}
+				if err := dispatcher.Dispatch(context.Background()); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	require.Equal(t, 40, len(sink.published), "events published")
+	byAggregate := map[uuid.UUID][]string{}
+	for _, event := range sink.published {
+		byAggregate[event.AggregateID] = append(byAggregate[event.AggregateID], event.Type)
+	}
+	for aggregateID, published := range byAggregate {
+		assert.Equal(t, []string{"route.created", "route.started", "route.updated", "route.completed"}, published,
+			aggregateID.String())
+	}
+}
+
+func TestDispatcherDelay(t *testing.T) {
+	// Arrange
+	dispatcher := NewDispatcher(nil, 0, 0)
+
+	// Act & Assert
+	assert.Equal(t, DefaultRetryDelay, dispatcher.delay(1))
+	assert.Equal(t, 4*DefaultRetryDelay, dispatcher.delay(3))
+	assert.Equal(t, DefaultMaxRetryDelay, dispatcher.delay(50))
+}
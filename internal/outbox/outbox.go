@@ -0,0 +1,157 @@
+package outbox
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPollInterval = time.Second
+	// DefaultRetryDelay is how long after a failure an event is sent again,
+	// doubling with every attempt up to DefaultMaxRetryDelay
+	DefaultRetryDelay    = 5 * time.Second
+	DefaultMaxRetryDelay = 10 * time.Minute
+	// DefaultMaxAttempts is how many times an event is sent before it is
+	// given up on
+	DefaultMaxAttempts = 10
+)
+
+// Event is a change to an aggregate, a route or a route point, recorded in the
+// outbox in the same transaction as the change itself. Position orders the
+// events as they were recorded. LockedUntil is set while an instance of the
+// server publishes the event.
+type Event struct {
+	Position      int64      `gorm:"column:position;primaryKey;autoIncrement" json:"position"`
+	ID            uuid.UUID  `gorm:"column:id" json:"id"`
+	AggregateType string     `gorm:"column:aggregate_type" json:"aggregate_type"`
+	AggregateID   uuid.UUID  `gorm:"column:aggregate_id" json:"aggregate_id"`
+	Type          string     `gorm:"column:event_type" json:"type"`
+	Payload       Payload    `gorm:"column:payload" json:"data"`
+	OccurredAt    time.Time  `gorm:"column:occurred_at" json:"occurred_at"`
+	Attempts      int        `gorm:"column:attempts" json:"-"`
+	NextAttemptAt time.Time  `gorm:"column:next_attempt_at" json:"-"`
+	LastError     string     `gorm:"column:last_error" json:"-"`
+	PublishedAt   *time.Time `gorm:"column:published_at" json:"-"`
+	FailedAt      *time.Time `gorm:"column:failed_at" json:"-"`
+	LockedUntil   *time.Time `gorm:"column:locked_until" json:"-"`
+}
+
+func (Event) TableName() string {
+	return "outbox_event"
+}
+
+type AggregateType string
+
+const (
+	AggregateRoute      AggregateType = "route"
+	AggregateRoutePoint AggregateType = "route_point"
+)
+
+var AggregateTypeList = map[AggregateType]string{
+	AggregateRoute:      "route",
+	AggregateRoutePoint: "route_point",
+}
+
+type EventType string
+
+const (
+	EventRouteCreated            EventType = "route.created"
+	EventRouteUpdated            EventType = "route.updated"
+	EventRouteStarted            EventType = "route.started"
+	EventRouteCompleted          EventType = "route.completed"
+	EventRoutePointCreated       EventType = "route_point.created"
+	EventRoutePointUpdated       EventType = "route_point.updated"
+	EventRoutePointStatusChanged EventType = "route_point.status_changed"
+)
+
+var EventTypeList = map[EventType]string{
+	EventRouteCreated:            "route.created",
+	EventRouteUpdated:            "route.updated",
+	EventRouteStarted:            "route.started",
+	EventRouteCompleted:          "route.completed",
+	EventRoutePointCreated:       "route_point.created",
+	EventRoutePointUpdated:       "route_point.updated",
+	EventRoutePointStatusChanged: "route_point.status_changed",
+}
+
+// Payload is the data of an event, kept as JSON.
+type Payload json.RawMessage
+
+func (p Payload) GormDataType() string {
+	return "text"
+}
+
+func (p Payload) Value() (driver.Value, error) {
+	if p == nil {
+		return "null", nil
+	}
+	return string(p), nil
+}
+
+func (p *Payload) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*p = nil
+	case []byte:
+		*p = append(Payload(nil), v...)
+	case string:
+		*p = Payload(v)
+	default:
+		return fmt.Errorf("unsupported outbox payload type %T", value)
+	}
+	return nil
+}
+
+func (p Payload) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	return p, nil
+}
+
+// Sink is where the dispatcher publishes events. Events may be published more
+// than once, if the dispatcher stops before recording that they were, or if
+// another sink failed, so sinks must tolerate duplicates, telling them apart by
+// the event ID.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event *Event) error
+}
+
+// static functions
+
+// NewEvent describes a change to an aggregate, with data as its payload.
+func NewEvent(aggregateType AggregateType, aggregateID uuid.UUID, eventType EventType, data interface{}) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to encode %s event: %w", EventTypeList[eventType], err)
+	}
+	now := time.Now()
+	return Event{
+		ID:            uuid.New(),
+		AggregateType: AggregateTypeList[aggregateType],
+		AggregateID:   aggregateID,
+		Type:          EventTypeList[eventType],
+		Payload:       payload,
+		OccurredAt:    now,
+		NextAttemptAt: now,
+	}, nil
+}
+
+// Append records the events with tx, the transaction of the change they
+// describe, so that they are recorded if and only if the change is saved.
+func Append(tx *gorm.DB, events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := tx.Create(&events).Error; err != nil {
+		return fmt.Errorf("failed to record outbox events: %w", err)
+	}
+	return nil
+}
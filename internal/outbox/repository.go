@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repository reads the outbox events to publish and marks them.
+type Repository interface {
+	GetUnpublished(ctx context.Context, limit int) ([]Event, error)
+	GetDue(ctx context.Context, now time.Time, limit int) ([]Event, error)
+	Claim(ctx context.Context, event *Event, now time.Time, until time.Time) (bool, error)
+	MarkPublished(ctx context.Context, event *Event, at time.Time) error
+	MarkFailed(ctx context.Context, event *Event) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// GetUnpublished returns the oldest events not yet published nor given up on,
+// in the order they were recorded, whether or not they are due to be retried.
+func (r *repository) GetUnpublished(ctx context.Context, limit int) ([]Event, error) {
+	var events []Event
+	err := r.db.WithContext(ctx).Where("published_at IS NULL AND failed_at IS NULL").
+		Order("position").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// GetDue returns the oldest unpublished events due by now and not claimed by
+// another instance, in the order they were recorded, leaving out those held
+// back by an earlier event of their aggregate waiting to be retried or being
+// published, so that held events do not fill the batch.
+func (r *repository) GetDue(ctx context.Context, now time.Time, limit int) ([]Event, error) {
+	var events []Event
+	err := r.db.WithContext(ctx).
+		Where("published_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", now).
+		Where("locked_until IS NULL OR locked_until <= ?", now).
+		Where(`NOT EXISTS (SELECT 1 FROM outbox_event earlier
+			WHERE earlier.aggregate_type = outbox_event.aggregate_type AND earlier.aggregate_id = outbox_event.aggregate_id
+			AND earlier.position < outbox_event.position AND earlier.published_at IS NULL AND earlier.failed_at IS NULL
+			AND (earlier.next_attempt_at > ? OR earlier.locked_until > ?))`, now, now).
+		Order("position").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// Claim leases the event until the given time, unless it was published,
+// given up on, put off or claimed by another instance since it was read, and
+// tells whether it was. MarkPublished and MarkFailed release it.
+func (r *repository) Claim(ctx context.Context, event *Event, now time.Time, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Event{}).
+		Where("position = ? AND published_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", event.Position, now).
+		Where("locked_until IS NULL OR locked_until <= ?", now).
+		Update("locked_until", until)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	event.LockedUntil = &until
+	return true, nil
+}
+
+func (r *repository) MarkPublished(ctx context.Context, event *Event, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&Event{}).Where("position = ?", event.Position).
+		Updates(map[string]interface{}{
+			"attempts":     event.Attempts,
+			"last_error":   "",
+			"published_at": at,
+			"locked_until": nil,
+		}).Error
+}
+
+// MarkFailed records a failed attempt and when to retry the event, or when
+// it was given up on.
+func (r *repository) MarkFailed(ctx context.Context, event *Event) error {
+	return r.db.WithContext(ctx).Model(&Event{}).Where("position = ?", event.Position).
+		Updates(map[string]interface{}{
+			"attempts":        event.Attempts,
+			"last_error":      event.LastError,
+			"next_attempt_at": event.NextAttemptAt,
+			"failed_at":       event.FailedAt,
+			"locked_until":    nil,
+		}).Error
+}
+
+// static functions
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
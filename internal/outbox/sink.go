@@ -0,0 +1,93 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Handler is told about the events of the types it subscribed to.
+type Handler func(ctx context.Context, event *Event) error
+
+// Bus is an in-process sink, handing events to the handlers subscribed to
+// their type.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func (b *Bus) Name() string {
+	return "bus"
+}
+
+// Subscribe calls handler for every event of the given types, or of any type
+// when none is given. Handlers must be idempotent as events may repeat.
+func (b *Bus) Subscribe(handler Handler, eventTypes ...EventType) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(eventTypes) == 0 {
+		b.handlers[""] = append(b.handlers[""], handler)
+	}
+	for _, eventType := range eventTypes {
+		name := EventTypeList[eventType]
+		b.handlers[name] = append(b.handlers[name], handler)
+	}
+}
+
+// Publish calls every handler of the event, returning the errors of those that
+// failed.
+func (b *Bus) Publish(ctx context.Context, event *Event) error {
+	b.mu.RLock()
+	handlers := append(append([]Handler(nil), b.handlers[""]...), b.handlers[event.Type]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FileSink appends every event to a file as a line of JSON (NDJSON).
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (f *FileSink) Name() string {
+	return "file"
+}
+
+func (f *FileSink) Publish(ctx context.Context, event *Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+	return file.Close()
+}
+
+// static functions
+
+func NewBus() *Bus {
+	return &Bus{handlers: map[string][]Handler{}}
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
@@ -0,0 +1,74 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusPublish(t *testing.T) {
+	// Arrange
+	bus := NewBus()
+	var all, started []string
+	bus.Subscribe(func(ctx context.Context, event *Event) error {
+		all = append(all, event.Type)
+		return nil
+	})
+	bus.Subscribe(func(ctx context.Context, event *Event) error {
+		started = append(started, event.Type)
+		return errors.New("handler failed")
+	}, EventRouteStarted)
+	created, _ := NewEvent(AggregateRoute, uuid.New(), EventRouteCreated, nil)
+	start, _ := NewEvent(AggregateRoute, uuid.New(), EventRouteStarted, nil)
+
+	// Act
+	createdErr := bus.Publish(context.Background(), &created)
+	startErr := bus.Publish(context.Background(), &start)
+
+	// Assert
+	assert.NoError(t, createdErr)
+	assert.EqualError(t, startErr, "handler failed")
+	assert.Equal(t, []string{"route.created", "route.started"}, all)
+	assert.Equal(t, []string{"route.started"}, started)
+}
+
+func TestFileSinkPublish(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	sink := NewFileSink(path)
+	routeID := uuid.New()
+	first, _ := NewEvent(AggregateRoute, routeID, EventRouteCreated, map[string]string{"name": "Morning"})
+	second, _ := NewEvent(AggregateRoute, routeID, EventRouteStarted, nil)
+
+	// Act
+	firstErr := sink.Publish(context.Background(), &first)
+	secondErr := sink.Publish(context.Background(), &second)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	assert.Len(t, lines, 2)
+	assert.Equal(t, first.ID.String(), lines[0]["id"])
+	assert.Equal(t, "route", lines[0]["aggregate_type"])
+	assert.Equal(t, routeID.String(), lines[0]["aggregate_id"])
+	assert.Equal(t, map[string]interface{}{"name": "Morning"}, lines[0]["data"])
+	assert.Equal(t, "route.started", lines[1]["type"])
+	assert.Nil(t, lines[1]["data"])
+}
@@ -0,0 +1,159 @@
+package purchaseOrder
+
+import (
+	"bytes"
+	"challenge-fravega/internal/tracing"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout = 5 * time.Second
+	// DefaultStatusPath is where the status of a purchase order is updated,
+	// {id} standing for its ID
+	DefaultStatusPath = "/purchase-orders/{id}/status"
+)
+
+type Client interface {
+	GetPurchaseOrder(ctx context.Context, id string) (*PurchaseOrder, error)
+}
+
+// StatusClient updates the status of purchase orders in the order system.
+type StatusClient interface {
+	UpdateStatus(ctx context.Context, id string, status Status) error
+}
+
+type client struct {
+	baseURL    string
+	apiKey     string
+	statusPath string
+	httpClient *http.Client
+}
+
+// envelope is the response format of the purchase order service
+type envelope struct {
+	Success bool          `json:"success"`
+	Data    PurchaseOrder `json:"data"`
+}
+
+func (c *client) GetPurchaseOrder(ctx context.Context, id string) (*PurchaseOrder, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/purchase-orders/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	c.authorize(req)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchase order %s: %w", id, err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		return nil, ErrUnauthorized
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to get purchase order %s: unexpected status %d", id, res.StatusCode)
+	}
+
+	var body envelope
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode purchase order %s: %w", id, err)
+	}
+	if !body.Success {
+		return nil, fmt.Errorf("failed to get purchase order %s: unsuccessful response", id)
+	}
+	return &body.Data, nil
+}
+
+// UpdateStatus sets the status of the purchase order with a PATCH to the
+// status path. ErrRejected is returned when the order system refuses the
+// status, which retrying will not change.
+func (c *client) UpdateStatus(ctx context.Context, id string, status Status) error {
+	body, err := json.Marshal(map[string]string{"status": StatusList[status]})
+	if err != nil {
+		return err
+	}
+	path := strings.ReplaceAll(c.statusPath, "{id}", url.PathEscape(id))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	c.authorize(req)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update purchase order %s: %w", id, err)
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case res.StatusCode >= 400 && res.StatusCode < 500:
+		return fmt.Errorf("%w: status %s of purchase order %s, got %d", ErrRejected, StatusList[status], id, res.StatusCode)
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		return fmt.Errorf("failed to update purchase order %s: unexpected status %d", id, res.StatusCode)
+	}
+	return nil
+}
+
+// Ping checks the order system answers. Any response but a server error
+// will do, as the base URL needs not be a resource.
+func (c *client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL+"/", nil)
+	if err != nil {
+		return err
+	}
+	c.authorize(req)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the order system: %w", err)
+	}
+	res.Body.Close()
+	if res.StatusCode >= 500 {
+		return fmt.Errorf("failed to reach the order system: unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
+
+func (c *client) authorize(req *http.Request) {
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+}
+
+// static functions
+
+// NewClient creates a client of the purchase order service at baseURL.
+// statusPath defaults to DefaultStatusPath. Requests are traced, carrying
+// the trace context to the order system.
+func NewClient(baseURL string, apiKey string, statusPath string, timeout time.Duration) *client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if statusPath == "" {
+		statusPath = DefaultStatusPath
+	}
+	return &client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		statusPath: "/" + strings.TrimLeft(statusPath, "/"),
+		httpClient: &http.Client{Timeout: timeout, Transport: tracing.NewTransport("purchase_order", nil)},
+	}
+}
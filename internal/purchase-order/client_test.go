@@ -0,0 +1,155 @@
+package purchaseOrder
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/purchase-orders/00000000-0000-0000-0000-000000000000":
+			w.WriteHeader(http.StatusNotFound)
+		case "/purchase-orders/forbidden":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/purchase-orders/broken":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"success":true,"data":{"id":"85b01dae-d210-4ccf-a709-9ff7ba528abf","order_number":"PO-12345",` +
+				`"customer_name":"Test Customer","delivery_address":"123 Test Street, Test City","total_amount":105.50,` +
+				`"status":"PENDING","items":[{"id":"i1","product_id":"p1","product_name":"Test Product","quantity":2,"unit_price":52.75}]}}`))
+		}
+	}))
+}
+
+func TestGetPurchaseOrder(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	defer server.Close()
+	client := NewClient(server.URL+"/", "secret", "", time.Second)
+
+	// Act
+	result, err := client.GetPurchaseOrder(context.Background(), "85b01dae-d210-4ccf-a709-9ff7ba528abf")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "85b01dae-d210-4ccf-a709-9ff7ba528abf", result.ID)
+	assert.Equal(t, "Test Customer", result.CustomerName)
+	assert.Equal(t, "PENDING", result.Status)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, 2, result.Items[0].Quantity)
+}
+
+func TestGetPurchaseOrderNotFound(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	defer server.Close()
+	client := NewClient(server.URL, "secret", "", time.Second)
+
+	// Act
+	_, err := client.GetPurchaseOrder(context.Background(), "00000000-0000-0000-0000-000000000000")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGetPurchaseOrderUnauthorized(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	defer server.Close()
+	client := NewClient(server.URL, "secret", "", time.Second)
+
+	// Act
+	_, err := client.GetPurchaseOrder(context.Background(), "forbidden")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestGetPurchaseOrderUpstreamError(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	defer server.Close()
+	client := NewClient(server.URL, "secret", "", time.Second)
+
+	// Act
+	_, err := client.GetPurchaseOrder(context.Background(), "broken")
+
+	// Assert
+	assert.ErrorContains(t, err, "unexpected status 502")
+}
+
+func newStatusServer(t *testing.T, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		*requests = append(*requests, r.Method+" "+r.URL.Path+" "+string(body))
+		switch r.URL.Path {
+		case "/orders/00000000-0000-0000-0000-000000000000/status":
+			w.WriteHeader(http.StatusNotFound)
+		case "/orders/cancelled/status":
+			w.WriteHeader(http.StatusConflict)
+		case "/orders/broken/status":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+}
+
+func TestUpdateStatus(t *testing.T) {
+	// Arrange
+	var requests []string
+	server := newStatusServer(t, &requests)
+	defer server.Close()
+	client := NewClient(server.URL, "secret", "orders/{id}/status", time.Second)
+
+	// Act
+	err := client.UpdateStatus(context.Background(), "85b01dae-d210-4ccf-a709-9ff7ba528abf", StatusShipped)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`PATCH /orders/85b01dae-d210-4ccf-a709-9ff7ba528abf/status {"status":"SHIPPED"}`}, requests)
+}
+
+func TestUpdateStatusErrors(t *testing.T) {
+	// Arrange
+	var requests []string
+	server := newStatusServer(t, &requests)
+	defer server.Close()
+	client := NewClient(server.URL, "secret", "/orders/{id}/status", time.Second)
+
+	// Act
+	notFoundErr := client.UpdateStatus(context.Background(), "00000000-0000-0000-0000-000000000000", StatusDelivered)
+	rejectedErr := client.UpdateStatus(context.Background(), "cancelled", StatusDelivered)
+	brokenErr := client.UpdateStatus(context.Background(), "broken", StatusDelivered)
+
+	// Assert
+	assert.ErrorIs(t, notFoundErr, ErrNotFound)
+	assert.ErrorIs(t, rejectedErr, ErrRejected)
+	assert.Error(t, brokenErr)
+	assert.NotErrorIs(t, brokenErr, ErrRejected)
+}
+
+func TestPing(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	client := NewClient(server.URL, "secret", "", time.Second)
+
+	// Act
+	err := client.Ping(context.Background())
+	server.Close()
+	downErr := client.Ping(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Error(t, downErr)
+}
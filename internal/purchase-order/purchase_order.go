@@ -0,0 +1,51 @@
+package purchaseOrder
+
+import "errors"
+
+type PurchaseOrder struct {
+	ID           string `json:"id"`
+	OrderNumber  string `json:"order_number"`
+	CustomerName string `json:"customer_name"`
+	// CustomerEmail, CustomerPhone and CustomerLocale are how and in which
+	// language the customer is told about the delivery, each of them optional
+	CustomerEmail   string  `json:"customer_email"`
+	CustomerPhone   string  `json:"customer_phone"`
+	CustomerLocale  string  `json:"customer_locale"`
+	DeliveryAddress string  `json:"delivery_address"`
+	TotalAmount     float64 `json:"total_amount"`
+	Status          string  `json:"status"`
+	Items           []Item  `json:"items"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
+}
+
+type Item struct {
+	ID          string  `json:"id"`
+	ProductID   string  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+}
+
+// Status is the status of a purchase order in the order system.
+type Status string
+
+const (
+	StatusPending   Status = "PENDING"
+	StatusShipped   Status = "SHIPPED"
+	StatusDelivered Status = "DELIVERED"
+	StatusFailed    Status = "FAILED"
+)
+
+var StatusList = map[Status]string{
+	StatusPending:   "PENDING",
+	StatusShipped:   "SHIPPED",
+	StatusDelivered: "DELIVERED",
+	StatusFailed:    "FAILED",
+}
+
+var (
+	ErrNotFound     = errors.New("purchase order not found")
+	ErrUnauthorized = errors.New("not authorized to access the purchase order service")
+	ErrRejected     = errors.New("purchase order status update rejected")
+)
@@ -0,0 +1,43 @@
+package reconciliation
+
+import (
+	"challenge-fravega/internal/route"
+	"context"
+	"time"
+)
+
+// Job reconciles, at the end of each day, the routes scheduled up to that day.
+type Job struct {
+	repository Repository
+	policy     Policy
+	location   *time.Location
+}
+
+func (j *Job) Name() string {
+	return JobName
+}
+
+// Run reconciles the routes of the current day, in the job's location.
+func (j *Job) Run(ctx context.Context) (interface{}, error) {
+	now := time.Now()
+	date := now.In(j.location).Format(route.DateLayout)
+
+	routes, err := j.repository.GetOpenRoutes(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := NewPlan(routes, date, j.policy, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := j.repository.Apply(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan.Summary(date, j.policy), nil
+}
+
+// static functions
+
+func NewJob(repository Repository, policy Policy, location *time.Location) *Job {
+	return &Job{repository: repository, policy: policy, location: location}
+}
@@ -0,0 +1,147 @@
+package reconciliation
+
+import (
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobName identifies the reconciliation in the job runs
+const JobName = "reconciliation"
+
+var ErrInvalidPolicy = errors.New("invalid reconciliation policy, must be close or flag")
+
+// Policy tells what to do with routes still started at the end of the day.
+// Routes never started are always flagged, as they cannot be closed.
+type Policy string
+
+const (
+	// PolicyClose completes them as of their last delivery
+	PolicyClose Policy = "close"
+	// PolicyFlag leaves them started and flags them for review
+	PolicyFlag Policy = "flag"
+)
+
+var PolicyList = map[Policy]string{
+	PolicyClose: "close",
+	PolicyFlag:  "flag",
+}
+
+// UnvisitedStatuses are the statuses of the route points a driver did not
+// deliver, which go back to the unassigned pool at the end of the day.
+var UnvisitedStatuses = []string{
+	routePoint.RoutePointStatusList[routePoint.RoutePointStatusPending],
+	routePoint.RoutePointStatusList[routePoint.RoutePointStatusInRoute],
+	routePoint.RoutePointStatusList[routePoint.RoutePointStatusArrived],
+}
+
+// Plan holds the changes that reconcile the routes of a day.
+type Plan struct {
+	Close    []route.Route
+	Flag     []route.Route
+	Unassign []routePoint.RoutePoint
+	PoolDate string
+}
+
+// Summary is the outcome of a reconciliation, stored with its job run.
+type Summary struct {
+	Date            string      `json:"date"`
+	PoolDate        string      `json:"pool_date"`
+	Policy          Policy      `json:"policy"`
+	ClosedRoutes    []uuid.UUID `json:"closed_routes"`
+	FlaggedRoutes   []uuid.UUID `json:"flagged_routes"`
+	UnassignedStops []uuid.UUID `json:"unassigned_stops"`
+}
+
+// Summary describes the plan.
+func (p *Plan) Summary(date string, policy Policy) *Summary {
+	summary := &Summary{
+		Date:            date,
+		PoolDate:        p.PoolDate,
+		Policy:          policy,
+		ClosedRoutes:    []uuid.UUID{},
+		FlaggedRoutes:   []uuid.UUID{},
+		UnassignedStops: []uuid.UUID{},
+	}
+	for _, r := range p.Close {
+		summary.ClosedRoutes = append(summary.ClosedRoutes, r.ID)
+	}
+	for _, r := range p.Flag {
+		summary.FlaggedRoutes = append(summary.FlaggedRoutes, r.ID)
+	}
+	for _, rp := range p.Unassign {
+		summary.UnassignedStops = append(summary.UnassignedStops, rp.ID)
+	}
+	return summary
+}
+
+// static functions
+
+// NewPlan reconciles routes scheduled up to date, which must have their route
+// points loaded. Unvisited route points go back to the pool for the next day,
+// routes still started are closed or flagged as the policy says and routes
+// never started are flagged, unless they already are.
+func NewPlan(routes []route.Route, date string, policy Policy, now time.Time) (*Plan, error) {
+	day, err := time.Parse(route.DateLayout, date)
+	if err != nil {
+		return nil, route.ErrInvalidScheduledDate
+	}
+	plan := &Plan{PoolDate: day.AddDate(0, 0, 1).Format(route.DateLayout)}
+
+	for _, r := range routes {
+		for _, rp := range r.RoutePoints {
+			if unvisited(rp.Status) {
+				plan.Unassign = append(plan.Unassign, rp)
+			}
+		}
+
+		switch route.RouteStatus(r.Status) {
+		case route.RouteStatusStarted:
+			if policy == PolicyClose {
+				completedAt := lastActivity(r, now)
+				r.CompletedAt = &completedAt
+				r.Status = route.RouteStatusList[route.RouteStatusCompleted]
+				plan.Close = append(plan.Close, r)
+			} else if !r.NeedsReview {
+				plan.Flag = append(plan.Flag, r)
+			}
+		case route.RouteStatusPending:
+			if !r.NeedsReview {
+				plan.Flag = append(plan.Flag, r)
+			}
+		}
+	}
+	return plan, nil
+}
+
+func unvisited(status string) bool {
+	for _, s := range UnvisitedStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// lastActivity returns when the driver was last seen along the route: the
+// latest arrival or delivery, else when the route was started.
+func lastActivity(r route.Route, now time.Time) time.Time {
+	var last *time.Time
+	for _, rp := range r.RoutePoints {
+		for _, t := range []*time.Time{rp.ArrivedAt, rp.CompletedAt} {
+			if t != nil && (last == nil || t.After(*last)) {
+				last = t
+			}
+		}
+	}
+	switch {
+	case last != nil:
+		return *last
+	case r.StartedAt != nil:
+		return *r.StartedAt
+	}
+	return now
+}
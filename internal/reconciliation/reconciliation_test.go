@@ -0,0 +1,97 @@
+package reconciliation
+
+import (
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func at(hour int, minute int) *time.Time {
+	t := time.Date(2025, 3, 10, hour, minute, 0, 0, time.UTC)
+	return &t
+}
+
+func testRoutes() []route.Route {
+	return []route.Route{
+		{
+			ID: uuid.New(), Status: "started", ScheduledDate: "2025-03-10", StartedAt: at(9, 0),
+			RoutePoints: []routePoint.RoutePoint{
+				{ID: uuid.New(), Status: "completed", ArrivedAt: at(10, 0), CompletedAt: at(10, 15)},
+				{ID: uuid.New(), Status: "arrived", ArrivedAt: at(11, 30)},
+				{ID: uuid.New(), Status: "failed", CompletedAt: at(11, 0)},
+			},
+		},
+		{
+			ID: uuid.New(), Status: "pending", ScheduledDate: "2025-03-10",
+			RoutePoints: []routePoint.RoutePoint{{ID: uuid.New(), Status: "pending"}},
+		},
+		{
+			ID: uuid.New(), Status: "completed", ScheduledDate: "2025-03-09",
+			RoutePoints: []routePoint.RoutePoint{{ID: uuid.New(), Status: "in_route"}},
+		},
+	}
+}
+
+func TestNewPlanClose(t *testing.T) {
+	// Arrange
+	routes := testRoutes()
+	now := time.Date(2025, 3, 10, 23, 0, 0, 0, time.UTC)
+
+	// Act
+	plan, err := NewPlan(routes, "2025-03-10", PolicyClose, now)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-03-11", plan.PoolDate)
+	assert.Len(t, plan.Close, 1)
+	assert.Equal(t, "completed", plan.Close[0].Status)
+	assert.Equal(t, *at(11, 30), *plan.Close[0].CompletedAt)
+	assert.Len(t, plan.Flag, 1)
+	assert.Equal(t, routes[1].ID, plan.Flag[0].ID)
+	assert.Len(t, plan.Unassign, 3)
+	assert.Equal(t, "started", routes[0].Status)
+}
+
+func TestNewPlanFlag(t *testing.T) {
+	// Arrange
+	routes := testRoutes()
+	routes[1].NeedsReview = true
+
+	// Act
+	plan, err := NewPlan(routes, "2025-03-10", PolicyFlag, time.Now())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, plan.Close)
+	assert.Len(t, plan.Flag, 1)
+	assert.Equal(t, routes[0].ID, plan.Flag[0].ID)
+}
+
+func TestNewPlanInvalidDate(t *testing.T) {
+	_, err := NewPlan(nil, "today", PolicyFlag, time.Now())
+	assert.ErrorIs(t, err, route.ErrInvalidScheduledDate)
+}
+
+func TestLastActivity(t *testing.T) {
+	now := time.Now()
+	assert.Equal(t, now, lastActivity(route.Route{}, now))
+	assert.Equal(t, *at(9, 0), lastActivity(route.Route{StartedAt: at(9, 0)}, now))
+}
+
+func TestPlanSummary(t *testing.T) {
+	// Arrange
+	plan := &Plan{PoolDate: "2025-03-11", Flag: []route.Route{{ID: uuid.New()}}}
+
+	// Act
+	summary := plan.Summary("2025-03-10", PolicyFlag)
+
+	// Assert
+	assert.Equal(t, "2025-03-10", summary.Date)
+	assert.Equal(t, []uuid.UUID{plan.Flag[0].ID}, summary.FlaggedRoutes)
+	assert.NotNil(t, summary.ClosedRoutes)
+	assert.NotNil(t, summary.UnassignedStops)
+}
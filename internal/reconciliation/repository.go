@@ -0,0 +1,118 @@
+package reconciliation
+
+import (
+	"challenge-fravega/internal/outbox"
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repository reads the open routes and applies the reconciliation plans.
+type Repository interface {
+	GetOpenRoutes(ctx context.Context, date string) ([]route.Route, error)
+	Apply(ctx context.Context, plan *Plan) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// GetOpenRoutes returns the routes scheduled up to date that are not completed
+// or still have unvisited route points, with their route points.
+func (r *repository) GetOpenRoutes(ctx context.Context, date string) ([]route.Route, error) {
+	var routes []route.Route
+	err := r.db.WithContext(ctx).Preload("RoutePoints").
+		Where("scheduled_date <> '' AND scheduled_date <= ?", date).
+		Where("status <> ? OR id IN (?)", route.RouteStatusList[route.RouteStatusCompleted],
+			r.db.Model(&routePoint.RoutePoint{}).Select("route_id").Where("status IN ?", UnvisitedStatuses)).
+		Order("scheduled_date, created_at").
+		Find(&routes).Error
+	return routes, err
+}
+
+// Apply saves the plan in a single transaction, along with an event for every
+// route and route point changed. Nothing is saved, and
+// route.ErrVersionConflict or routePoint.ErrVersionConflict is returned, if
+// any route or route point changed since it was read.
+func (r *repository) Apply(ctx context.Context, plan *Plan) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, closed := range plan.Close {
+			if err := updateRoute(tx, closed, outbox.EventRouteCompleted, map[string]interface{}{
+				"status":       closed.Status,
+				"completed_at": closed.CompletedAt,
+			}); err != nil {
+				return err
+			}
+		}
+		for _, flagged := range plan.Flag {
+			flagged.NeedsReview = true
+			if err := updateRoute(tx, flagged, outbox.EventRouteUpdated, map[string]interface{}{"needs_review": true}); err != nil {
+				return err
+			}
+		}
+		for _, rp := range plan.Unassign {
+			result := tx.Model(&routePoint.RoutePoint{}).
+				Where("id = ? AND version = ?", rp.ID, rp.Version).
+				Updates(map[string]interface{}{
+					"route_id":   nil,
+					"pool_date":  plan.PoolDate,
+					"status":     routePoint.RoutePointStatusList[routePoint.RoutePointStatusPending],
+					"arrived_at": nil,
+					"version":    gorm.Expr("version + 1"),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return routePoint.ErrVersionConflict
+			}
+			if err := appendUnassigned(tx, rp, plan.PoolDate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// static functions
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
+
+func updateRoute(tx *gorm.DB, r route.Route, eventType outbox.EventType, updates map[string]interface{}) error {
+	updates["version"] = gorm.Expr("version + 1")
+	result := tx.Model(&route.Route{}).Where("id = ? AND version = ?", r.ID, r.Version).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return route.ErrVersionConflict
+	}
+	event, err := route.NewEvent(eventType, &r)
+	if err != nil {
+		return err
+	}
+	return outbox.Append(tx, event)
+}
+
+// appendUnassigned records the event of a route point moved to the pool.
+func appendUnassigned(tx *gorm.DB, rp routePoint.RoutePoint, poolDate string) error {
+	previousStatus := rp.Status
+	rp.RouteID = nil
+	rp.PoolDate = &poolDate
+	rp.Status = routePoint.RoutePointStatusList[routePoint.RoutePointStatusPending]
+	rp.ArrivedAt = nil
+
+	eventType := outbox.EventRoutePointStatusChanged
+	if previousStatus == rp.Status {
+		eventType, previousStatus = outbox.EventRoutePointUpdated, ""
+	}
+	event, err := routePoint.NewEvent(eventType, previousStatus, &rp)
+	if err != nil {
+		return err
+	}
+	return outbox.Append(tx, event)
+}
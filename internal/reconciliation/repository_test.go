@@ -0,0 +1,126 @@
+package reconciliation
+
+import (
+	"challenge-fravega/internal/outbox"
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type RepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	repository Repository
+}
+
+func (suite *RepositoryTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	err = db.AutoMigrate(&route.Route{}, &routePoint.RoutePoint{}, &outbox.Event{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	suite.db = db
+	suite.repository = NewRepository(db)
+}
+
+func (suite *RepositoryTestSuite) createRoute(status string, date string, pointStatuses ...string) route.Route {
+	r := route.Route{ID: uuid.New(), Status: status, ScheduledDate: date, Version: 1}
+	suite.db.Create(&r)
+	for _, pointStatus := range pointStatuses {
+		suite.db.Create(&routePoint.RoutePoint{ID: uuid.New(), RouteID: &r.ID, Status: pointStatus, Version: 1})
+	}
+	return r
+}
+
+func (suite *RepositoryTestSuite) TestGetOpenRoutes() {
+	// Arrange
+	started := suite.createRoute("started", "2025-03-10", "completed", "pending")
+	leftovers := suite.createRoute("completed", "2025-03-09", "completed", "in_route")
+	suite.createRoute("completed", "2025-03-10", "completed", "failed")
+	suite.createRoute("pending", "2025-03-11", "pending")
+	suite.createRoute("pending", "")
+
+	// Act
+	routes, err := suite.repository.GetOpenRoutes(context.Background(), "2025-03-10")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), routes, 2)
+	assert.Equal(suite.T(), leftovers.ID, routes[0].ID)
+	assert.Equal(suite.T(), started.ID, routes[1].ID)
+	assert.Len(suite.T(), routes[1].RoutePoints, 2)
+}
+
+func (suite *RepositoryTestSuite) TestApply() {
+	// Arrange
+	suite.createRoute("started", "2025-03-10", "completed", "arrived")
+	suite.createRoute("pending", "2025-03-10")
+	routes, _ := suite.repository.GetOpenRoutes(context.Background(), "2025-03-10")
+	plan, _ := NewPlan(routes, "2025-03-10", PolicyClose, time.Now())
+
+	// Act
+	err := suite.repository.Apply(context.Background(), plan)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	var closed, flagged route.Route
+	suite.db.First(&closed, "id = ?", plan.Close[0].ID)
+	suite.db.First(&flagged, "id = ?", plan.Flag[0].ID)
+	assert.Equal(suite.T(), "completed", closed.Status)
+	assert.NotNil(suite.T(), closed.CompletedAt)
+	assert.Equal(suite.T(), 2, closed.Version)
+	assert.True(suite.T(), flagged.NeedsReview)
+
+	var unassigned routePoint.RoutePoint
+	suite.db.First(&unassigned, "id = ?", plan.Unassign[0].ID)
+	assert.Nil(suite.T(), unassigned.RouteID)
+	assert.Equal(suite.T(), "2025-03-11", *unassigned.PoolDate)
+	assert.Equal(suite.T(), "pending", unassigned.Status)
+	assert.Nil(suite.T(), unassigned.ArrivedAt)
+	assert.Equal(suite.T(), 2, unassigned.Version)
+
+	var events []outbox.Event
+	suite.db.Order("position").Find(&events)
+	assert.Len(suite.T(), events, 3)
+	assert.Equal(suite.T(), "route.completed", events[0].Type)
+	assert.Equal(suite.T(), "route.updated", events[1].Type)
+	assert.Equal(suite.T(), "route_point.status_changed", events[2].Type)
+	assert.Contains(suite.T(), string(events[2].Payload), `"route_id":null`)
+}
+
+func (suite *RepositoryTestSuite) TestApplyVersionConflict() {
+	// Arrange
+	suite.createRoute("started", "2025-03-10", "pending")
+	routes, _ := suite.repository.GetOpenRoutes(context.Background(), "2025-03-10")
+	plan, _ := NewPlan(routes, "2025-03-10", PolicyClose, time.Now())
+	suite.db.Model(&routePoint.RoutePoint{}).Where("id = ?", plan.Unassign[0].ID).Update("version", 2)
+
+	// Act
+	err := suite.repository.Apply(context.Background(), plan)
+
+	// Assert
+	assert.ErrorIs(suite.T(), err, routePoint.ErrVersionConflict)
+	var r route.Route
+	suite.db.First(&r, "id = ?", plan.Close[0].ID)
+	assert.Equal(suite.T(), "started", r.Status)
+	var events int64
+	suite.db.Model(&outbox.Event{}).Count(&events)
+	assert.Zero(suite.T(), events)
+}
+
+func TestRepositorySuite(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
@@ -0,0 +1,209 @@
+package reporting
+
+import (
+	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/geocoder"
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"challenge-fravega/internal/vehicle"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// group is the routes of a report row
+type group struct {
+	key    string
+	label  string
+	routes []route.Route
+}
+
+// static functions
+
+// Deliveries sums up the stops of the routes by group.
+func Deliveries(routes []route.Route, groupBy GroupBy) []DeliveryRow {
+	rows := []DeliveryRow{}
+	for _, group := range groupRoutes(routes, groupBy) {
+		row := DeliveryRow{Key: group.key, Label: group.label, Routes: len(group.routes)}
+		var dwell time.Duration
+		var dwelled int
+		for _, r := range group.routes {
+			for _, point := range r.RoutePoints {
+				row.Stops++
+				switch routePoint.RoutePointStatus(point.Status) {
+				case routePoint.RoutePointStatusCompleted:
+					row.Completed++
+					if point.DeliveryWindowStart != nil || point.DeliveryWindowEnd != nil {
+						row.WithWindow++
+						if point.OnTime() {
+							row.OnTime++
+						}
+					}
+				case routePoint.RoutePointStatusFailed:
+					row.Failed++
+				default:
+					row.Open++
+				}
+				if point.ArrivedAt != nil && point.CompletedAt != nil && point.CompletedAt.After(*point.ArrivedAt) {
+					dwell += point.CompletedAt.Sub(*point.ArrivedAt)
+					dwelled++
+				}
+			}
+		}
+
+		row.StopsPerRoute = round(float64(row.Stops) / float64(row.Routes))
+		row.CompletionRate = ratio(float64(row.Completed), float64(row.Completed+row.Failed))
+		row.OnTimeRate = ratio(float64(row.OnTime), float64(row.WithWindow))
+		row.AverageDwellMinutes = ratio(dwell.Minutes(), float64(dwelled))
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Distances sums up the kilometres driven by the routes by group, given the
+// odometer readings taken as the routes started and completed.
+func Distances(routes []route.Route, readings []vehicle.OdometerReading, groupBy GroupBy) []DistanceRow {
+	started, completed := map[uuid.UUID]int{}, map[uuid.UUID]int{}
+	for _, reading := range readings {
+		if reading.RouteID == nil {
+			continue
+		}
+		id := *reading.RouteID
+		switch vehicle.OdometerEvent(reading.Event) {
+		case vehicle.OdometerEventRouteStarted:
+			if km, ok := started[id]; !ok || reading.Kilometers < km {
+				started[id] = reading.Kilometers
+			}
+		case vehicle.OdometerEventRouteCompleted:
+			if reading.Kilometers > completed[id] {
+				completed[id] = reading.Kilometers
+			}
+		}
+	}
+
+	rows := []DistanceRow{}
+	for _, group := range groupRoutes(routes, groupBy) {
+		row := DistanceRow{Key: group.key, Label: group.label, Routes: len(group.routes)}
+		for _, r := range group.routes {
+			start, hasStart := started[r.ID]
+			end, hasEnd := completed[r.ID]
+			if hasStart && hasEnd && end >= start {
+				row.Kilometers += float64(end - start)
+				row.MeasuredRoutes++
+				continue
+			}
+			row.Kilometers += estimateKilometers(r.RoutePoints)
+		}
+		row.Kilometers = round(row.Kilometers)
+		row.KilometersPerRoute = ratio(row.Kilometers, float64(row.Routes))
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// DriverUtilization compares, for every driver, the time they drove the
+// routes with the time of their shifts over the days from and to.
+func DriverUtilization(drivers []carDriver.Driver, shifts []carDriver.Shift, routes []route.Route, from time.Time, to time.Time, now time.Time) []DriverRow {
+	weekly := map[uuid.UUID]map[time.Weekday]time.Duration{}
+	for _, shift := range shifts {
+		if weekly[shift.DriverID] == nil {
+			weekly[shift.DriverID] = map[time.Weekday]time.Duration{}
+		}
+		startsAt, _ := time.Parse(carDriver.HourLayout, shift.StartsAt)
+		endsAt, _ := time.Parse(carDriver.HourLayout, shift.EndsAt)
+		weekly[shift.DriverID][shift.Weekday] += endsAt.Sub(startsAt)
+	}
+
+	rows := []DriverRow{}
+	for _, driver := range drivers {
+		row := DriverRow{DriverID: driver.ID.String(), Name: driver.Name}
+		var driven, scheduled time.Duration
+		for _, r := range routes {
+			if r.DriverID != driver.ID {
+				continue
+			}
+			row.Routes++
+			if r.StartedAt == nil {
+				continue
+			}
+			end := now
+			if r.CompletedAt != nil {
+				end = *r.CompletedAt
+			}
+			if end.After(*r.StartedAt) {
+				driven += end.Sub(*r.StartedAt)
+			}
+		}
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			scheduled += weekly[driver.ID][day.Weekday()]
+		}
+
+		row.DrivingHours = round(driven.Hours())
+		row.ShiftHours = round(scheduled.Hours())
+		row.Utilization = ratio(driven.Hours(), scheduled.Hours())
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows
+}
+
+// groupRoutes splits the routes by day, route, driver or vehicle, ordered by
+// label.
+func groupRoutes(routes []route.Route, groupBy GroupBy) []group {
+	groups := []group{}
+	index := map[string]int{}
+	for _, r := range routes {
+		var key, label string
+		switch groupBy {
+		case GroupByRoute:
+			key, label = r.ID.String(), r.Name
+		case GroupByDriver:
+			key, label = r.DriverID.String(), r.Driver.Name
+		case GroupByVehicle:
+			key, label = r.VehicleID.String(), r.Vehicle.PlateNumber
+		default:
+			key, label = r.ScheduledDate, r.ScheduledDate
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, group{key: key, label: label})
+		}
+		groups[i].routes = append(groups[i].routes, r)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].label != groups[j].label {
+			return groups[i].label < groups[j].label
+		}
+		return groups[i].key < groups[j].key
+	})
+	return groups
+}
+
+// estimateKilometers measures the straight lines between the stops of a
+// route in delivery order.
+func estimateKilometers(routePoints []routePoint.RoutePoint) float64 {
+	stops := append([]routePoint.RoutePoint{}, routePoints...)
+	routePoint.SortForDelivery(stops)
+	var meters float64
+	for i := 1; i < len(stops); i++ {
+		meters += geocoder.Distance(stops[i-1].Latitude, stops[i-1].Longitude, stops[i].Latitude, stops[i].Longitude)
+	}
+	return meters / 1000
+}
+
+// ratio divides rounding to two decimals, nil when dividing by zero.
+func ratio(dividend float64, divisor float64) *float64 {
+	if divisor == 0 {
+		return nil
+	}
+	value := round(dividend / divisor)
+	return &value
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
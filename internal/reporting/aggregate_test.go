@@ -0,0 +1,114 @@
+package reporting
+
+import (
+	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"challenge-fravega/internal/vehicle"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func at(day int, hour int, minute int) *time.Time {
+	t := time.Date(2025, 3, day, hour, minute, 0, 0, time.UTC)
+	return &t
+}
+
+func testRoutes() []route.Route {
+	ana := carDriver.Driver{ID: uuid.New(), Name: "Ana"}
+	bruno := carDriver.Driver{ID: uuid.New(), Name: "Bruno"}
+	return []route.Route{
+		{
+			ID: uuid.New(), Name: "Norte", ScheduledDate: "2025-03-10", DriverID: ana.ID, Driver: ana,
+			StartedAt: at(10, 9, 0), CompletedAt: at(10, 13, 0),
+			RoutePoints: []routePoint.RoutePoint{
+				{Status: "completed", Latitude: -34.6037, Longitude: -58.3816, DeliveryWindowEnd: at(10, 11, 0), ArrivedAt: at(10, 10, 0), CompletedAt: at(10, 10, 10)},
+				{Status: "completed", Latitude: -34.5881, Longitude: -58.3974, DeliveryWindowEnd: at(10, 11, 0), ArrivedAt: at(10, 11, 0), CompletedAt: at(10, 11, 20)},
+				{Status: "failed", Latitude: -34.6158, Longitude: -58.4333},
+			},
+		},
+		{
+			ID: uuid.New(), Name: "Sur", ScheduledDate: "2025-03-11", DriverID: bruno.ID, Driver: bruno,
+			RoutePoints: []routePoint.RoutePoint{
+				{Status: "pending"},
+			},
+		},
+	}
+}
+
+func TestDeliveries(t *testing.T) {
+	// Act
+	rows := Deliveries(testRoutes(), GroupByDay)
+
+	// Assert
+	assert.Len(t, rows, 2)
+	day := rows[0]
+	assert.Equal(t, "2025-03-10", day.Key)
+	assert.Equal(t, 1, day.Routes)
+	assert.Equal(t, 3, day.Stops)
+	assert.Equal(t, 2, day.Completed)
+	assert.Equal(t, 1, day.Failed)
+	assert.Equal(t, 0.67, *day.CompletionRate)
+	assert.Equal(t, 2, day.WithWindow)
+	assert.Equal(t, 1, day.OnTime)
+	assert.Equal(t, 0.5, *day.OnTimeRate)
+	assert.Equal(t, 15.0, *day.AverageDwellMinutes)
+	assert.Equal(t, 1, rows[1].Open)
+	assert.Nil(t, rows[1].CompletionRate)
+	assert.Nil(t, rows[1].AverageDwellMinutes)
+}
+
+func TestDeliveriesByDriver(t *testing.T) {
+	// Act
+	rows := Deliveries(testRoutes(), GroupByDriver)
+
+	// Assert
+	assert.Equal(t, []string{"Ana", "Bruno"}, []string{rows[0].Label, rows[1].Label})
+	assert.Equal(t, 3.0, rows[0].StopsPerRoute)
+}
+
+func TestDistances(t *testing.T) {
+	// Arrange
+	routes := testRoutes()
+	readings := []vehicle.OdometerReading{
+		{RouteID: &routes[0].ID, Kilometers: 1000, Event: "route_started"},
+		{RouteID: &routes[0].ID, Kilometers: 1042, Event: "route_completed"},
+	}
+	routes[1].RoutePoints = append(routes[1].RoutePoints, routePoint.RoutePoint{Latitude: 0.01})
+
+	// Act
+	rows := Distances(routes, readings, GroupByRoute)
+
+	// Assert
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "Norte", rows[0].Label)
+	assert.Equal(t, 42.0, rows[0].Kilometers)
+	assert.Equal(t, 1, rows[0].MeasuredRoutes)
+	assert.Equal(t, 0, rows[1].MeasuredRoutes)
+	assert.InDelta(t, 1.11, rows[1].Kilometers, 0.01)
+}
+
+func TestDriverUtilization(t *testing.T) {
+	// Arrange
+	routes := testRoutes()
+	drivers := []carDriver.Driver{routes[1].Driver, routes[0].Driver}
+	shifts := []carDriver.Shift{
+		{DriverID: routes[0].DriverID, Weekday: time.Monday, StartsAt: "08:00", EndsAt: "16:00"},
+	}
+	from, to := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	rows := DriverUtilization(drivers, shifts, routes, from, to, time.Now())
+
+	// Assert
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "Ana", rows[0].Name)
+	assert.Equal(t, 4.0, rows[0].DrivingHours)
+	assert.Equal(t, 8.0, rows[0].ShiftHours)
+	assert.Equal(t, 0.5, *rows[0].Utilization)
+	assert.Equal(t, 1, rows[1].Routes)
+	assert.Nil(t, rows[1].Utilization)
+}
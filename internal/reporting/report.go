@@ -0,0 +1,180 @@
+package reporting
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	// DateLayout is the format of the dates reports span, matched against the
+	// scheduled date of routes
+	DateLayout = time.DateOnly
+	// MaxReportDays bounds the days a single report spans
+	MaxReportDays = 366
+)
+
+var (
+	ErrInvalidDateRange = fmt.Errorf("invalid date range, from and to must be formatted as YYYY-MM-DD and span at most %d days", MaxReportDays)
+	ErrInvalidGroupBy   = errors.New("invalid group by, must be one of day, route, driver or vehicle")
+	ErrInvalidFormat    = errors.New("invalid report format, must be json or csv")
+)
+
+// Query selects the routes scheduled from From to To, both included, and how
+// to group them.
+type Query struct {
+	From    string
+	To      string
+	GroupBy GroupBy
+}
+
+type GroupBy string
+
+const (
+	GroupByDay     GroupBy = "day"
+	GroupByRoute   GroupBy = "route"
+	GroupByDriver  GroupBy = "driver"
+	GroupByVehicle GroupBy = "vehicle"
+)
+
+var GroupByList = map[GroupBy]string{
+	GroupByDay:     "day",
+	GroupByRoute:   "route",
+	GroupByDriver:  "driver",
+	GroupByVehicle: "vehicle",
+}
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+)
+
+var FormatList = map[Format]string{
+	FormatJSON: "json",
+	FormatCSV:  "csv",
+}
+
+// DeliveryRow sums up the stops of a group of routes. Open stops are neither
+// completed nor failed yet. Rates and averages are nil when there is nothing
+// to compute them from.
+type DeliveryRow struct {
+	Key                 string   `json:"key"`
+	Label               string   `json:"label"`
+	Routes              int      `json:"routes"`
+	Stops               int      `json:"stops"`
+	Completed           int      `json:"completed"`
+	Failed              int      `json:"failed"`
+	Open                int      `json:"open"`
+	StopsPerRoute       float64  `json:"stops_per_route"`
+	CompletionRate      *float64 `json:"completion_rate"`
+	WithWindow          int      `json:"with_window"`
+	OnTime              int      `json:"on_time"`
+	OnTimeRate          *float64 `json:"on_time_rate"`
+	AverageDwellMinutes *float64 `json:"average_dwell_minutes"`
+}
+
+// DistanceRow sums up the kilometres driven by a group of routes, measured
+// with the odometer when read at both the start and the completion of the
+// route, and else estimated along its stops.
+type DistanceRow struct {
+	Key                string   `json:"key"`
+	Label              string   `json:"label"`
+	Routes             int      `json:"routes"`
+	MeasuredRoutes     int      `json:"measured_routes"`
+	Kilometers         float64  `json:"kilometers"`
+	KilometersPerRoute *float64 `json:"kilometers_per_route"`
+}
+
+// DriverRow compares the time a driver drove with the time of their shifts.
+// Utilization is nil for drivers without shifts.
+type DriverRow struct {
+	DriverID     string   `json:"driver_id"`
+	Name         string   `json:"name"`
+	Routes       int      `json:"routes"`
+	DrivingHours float64  `json:"driving_hours"`
+	ShiftHours   float64  `json:"shift_hours"`
+	Utilization  *float64 `json:"utilization"`
+}
+
+// Row is a report row that can be written as CSV.
+type Row interface {
+	CSVHeader() []string
+	CSVRecord() []string
+}
+
+func (r DeliveryRow) CSVHeader() []string {
+	return []string{"key", "label", "routes", "stops", "completed", "failed", "open", "stops_per_route",
+		"completion_rate", "with_window", "on_time", "on_time_rate", "average_dwell_minutes"}
+}
+
+func (r DeliveryRow) CSVRecord() []string {
+	return []string{r.Key, r.Label, strconv.Itoa(r.Routes), strconv.Itoa(r.Stops), strconv.Itoa(r.Completed),
+		strconv.Itoa(r.Failed), strconv.Itoa(r.Open), formatFloat(&r.StopsPerRoute), formatFloat(r.CompletionRate),
+		strconv.Itoa(r.WithWindow), strconv.Itoa(r.OnTime), formatFloat(r.OnTimeRate), formatFloat(r.AverageDwellMinutes)}
+}
+
+func (r DistanceRow) CSVHeader() []string {
+	return []string{"key", "label", "routes", "measured_routes", "kilometers", "kilometers_per_route"}
+}
+
+func (r DistanceRow) CSVRecord() []string {
+	return []string{r.Key, r.Label, strconv.Itoa(r.Routes), strconv.Itoa(r.MeasuredRoutes),
+		formatFloat(&r.Kilometers), formatFloat(r.KilometersPerRoute)}
+}
+
+func (r DriverRow) CSVHeader() []string {
+	return []string{"driver_id", "name", "routes", "driving_hours", "shift_hours", "utilization"}
+}
+
+func (r DriverRow) CSVRecord() []string {
+	return []string{r.DriverID, r.Name, strconv.Itoa(r.Routes), formatFloat(&r.DrivingHours),
+		formatFloat(&r.ShiftHours), formatFloat(r.Utilization)}
+}
+
+// static functions
+
+// WriteCSV writes the rows of a report as CSV, with a header row even when
+// there are no rows.
+func WriteCSV[R Row](w io.Writer, rows []R) error {
+	writer := csv.NewWriter(w)
+	var zero R
+	if err := writer.Write(zero.CSVHeader()); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(row.CSVRecord()); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatFloat writes a number in its shortest form, and nil as empty.
+func formatFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+// parseRange parses the days of a query, from and to both included.
+func parseRange(query Query) (time.Time, time.Time, error) {
+	from, err := time.Parse(DateLayout, query.From)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	to, err := time.Parse(DateLayout, query.To)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	if to.Before(from) || !to.Before(from.AddDate(0, 0, MaxReportDays)) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	return from, to, nil
+}
@@ -0,0 +1,53 @@
+package reporting
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCSV(t *testing.T) {
+	// Arrange
+	rate := 0.5
+	rows := []DistanceRow{
+		{Key: "k1", Label: "Norte, CABA", Routes: 2, MeasuredRoutes: 1, Kilometers: 84.25, KilometersPerRoute: &rate},
+		{Key: "k2", Label: "Sur", Routes: 1},
+	}
+	var body bytes.Buffer
+
+	// Act
+	err := WriteCSV(&body, rows)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "key,label,routes,measured_routes,kilometers,kilometers_per_route\n"+
+		"k1,\"Norte, CABA\",2,1,84.25,0.5\n"+
+		"k2,Sur,1,0,0,\n", body.String())
+}
+
+func TestWriteCSVEmpty(t *testing.T) {
+	// Arrange
+	var body bytes.Buffer
+
+	// Act
+	err := WriteCSV(&body, []DriverRow{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "driver_id,name,routes,driving_hours,shift_hours,utilization\n", body.String())
+}
+
+func TestParseRange(t *testing.T) {
+	_, _, err := parseRange(Query{From: "2025-03-01", To: "2025-03-31"})
+	assert.NoError(t, err)
+
+	_, _, err = parseRange(Query{From: "2025-03-31", To: "2025-03-01"})
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+
+	_, _, err = parseRange(Query{From: "2024-01-01", To: "2025-03-01"})
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+
+	_, _, err = parseRange(Query{From: "March", To: "2025-03-01"})
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+}
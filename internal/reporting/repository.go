@@ -0,0 +1,64 @@
+package reporting
+
+import (
+	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/route"
+	"challenge-fravega/internal/vehicle"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository reads the routes, odometer readings, drivers and shifts the
+// reports are made of.
+type Repository interface {
+	GetRoutes(ctx context.Context, from string, to string) ([]route.Route, error)
+	GetRouteOdometers(ctx context.Context, routeIDs []uuid.UUID) ([]vehicle.OdometerReading, error)
+	GetDrivers(ctx context.Context) ([]carDriver.Driver, error)
+	GetShifts(ctx context.Context) ([]carDriver.Shift, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// GetRoutes returns the routes scheduled from and to, both included, with
+// their driver, vehicle and route points.
+func (r *repository) GetRoutes(ctx context.Context, from string, to string) ([]route.Route, error) {
+	var routes []route.Route
+	err := r.db.WithContext(ctx).Preload("Vehicle").Preload("Driver").Preload("RoutePoints").
+		Where("scheduled_date BETWEEN ? AND ?", from, to).
+		Order("scheduled_date, created_at").Find(&routes).Error
+	return routes, err
+}
+
+// GetRouteOdometers returns the odometer readings taken as the routes
+// started and completed.
+func (r *repository) GetRouteOdometers(ctx context.Context, routeIDs []uuid.UUID) ([]vehicle.OdometerReading, error) {
+	readings := []vehicle.OdometerReading{}
+	if len(routeIDs) == 0 {
+		return readings, nil
+	}
+	err := r.db.WithContext(ctx).Where("route_id IN ? AND event IN ?", routeIDs, []string{
+		vehicle.OdometerEventList[vehicle.OdometerEventRouteStarted],
+		vehicle.OdometerEventList[vehicle.OdometerEventRouteCompleted],
+	}).Find(&readings).Error
+	return readings, err
+}
+
+func (r *repository) GetDrivers(ctx context.Context) ([]carDriver.Driver, error) {
+	var drivers []carDriver.Driver
+	return drivers, r.db.WithContext(ctx).Order("name").Find(&drivers).Error
+}
+
+func (r *repository) GetShifts(ctx context.Context) ([]carDriver.Shift, error) {
+	var shifts []carDriver.Shift
+	return shifts, r.db.WithContext(ctx).Find(&shifts).Error
+}
+
+// static functions
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
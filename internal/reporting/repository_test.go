@@ -0,0 +1,85 @@
+package reporting
+
+import (
+	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/route"
+	routePoint "challenge-fravega/internal/route-point"
+	"challenge-fravega/internal/vehicle"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type RepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	repository Repository
+}
+
+func (suite *RepositoryTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	err = db.AutoMigrate(&vehicle.Vehicle{}, &carDriver.Driver{}, &carDriver.Shift{}, &route.Route{},
+		&routePoint.RoutePoint{}, &vehicle.OdometerReading{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	suite.db = db
+	suite.repository = NewRepository(db)
+}
+
+func (suite *RepositoryTestSuite) TestGetRoutes() {
+	// Arrange
+	driver := carDriver.Driver{ID: uuid.New(), Name: "Ana"}
+	suite.db.Create(&driver)
+	inRange := route.Route{ID: uuid.New(), Name: "In range", ScheduledDate: "2025-03-10", DriverID: driver.ID}
+	suite.db.Create(&inRange)
+	suite.db.Create(&route.Route{ID: uuid.New(), Name: "Later", ScheduledDate: "2025-04-10"})
+	suite.db.Create(&route.Route{ID: uuid.New(), Name: "Unscheduled"})
+	suite.db.Create(&routePoint.RoutePoint{ID: uuid.New(), RouteID: &inRange.ID, Status: "completed"})
+
+	// Act
+	routes, err := suite.repository.GetRoutes(context.Background(), "2025-03-01", "2025-03-31")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), routes, 1)
+	assert.Equal(suite.T(), "In range", routes[0].Name)
+	assert.Equal(suite.T(), "Ana", routes[0].Driver.Name)
+	assert.Len(suite.T(), routes[0].RoutePoints, 1)
+}
+
+func (suite *RepositoryTestSuite) TestGetRouteOdometers() {
+	// Arrange
+	routeID := uuid.New()
+	for _, reading := range []vehicle.OdometerReading{
+		{ID: uuid.New(), RouteID: &routeID, Kilometers: 1000, Event: "route_started"},
+		{ID: uuid.New(), RouteID: &routeID, Kilometers: 1042, Event: "route_completed"},
+		{ID: uuid.New(), Kilometers: 1100, Event: "manual"},
+	} {
+		suite.db.Create(&reading)
+	}
+
+	// Act
+	readings, err := suite.repository.GetRouteOdometers(context.Background(), []uuid.UUID{routeID})
+	none, noneErr := suite.repository.GetRouteOdometers(context.Background(), nil)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), readings, 2)
+	assert.NoError(suite.T(), noneErr)
+	assert.Empty(suite.T(), none)
+}
+
+func TestRepositorySuite(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
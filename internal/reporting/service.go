@@ -0,0 +1,98 @@
+package reporting
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Service interface {
+	GetDeliveryReport(ctx context.Context, query Query) ([]DeliveryRow, error)
+	GetDistanceReport(ctx context.Context, query Query) ([]DistanceRow, error)
+	GetDriverReport(ctx context.Context, query Query) ([]DriverRow, error)
+}
+
+type service struct {
+	repository Repository
+}
+
+// GetDeliveryReport reports on-time delivery, completed and failed stops and
+// dwell time, grouped by day unless asked otherwise.
+func (s *service) GetDeliveryReport(ctx context.Context, query Query) ([]DeliveryRow, error) {
+	groupBy, err := groupByOr(query.GroupBy, GroupByDay)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := parseRange(query); err != nil {
+		return nil, err
+	}
+	routes, err := s.repository.GetRoutes(ctx, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	return Deliveries(routes, groupBy), nil
+}
+
+// GetDistanceReport reports the kilometres driven, grouped by route unless
+// asked otherwise.
+func (s *service) GetDistanceReport(ctx context.Context, query Query) ([]DistanceRow, error) {
+	groupBy, err := groupByOr(query.GroupBy, GroupByRoute)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := parseRange(query); err != nil {
+		return nil, err
+	}
+	routes, err := s.repository.GetRoutes(ctx, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	routeIDs := make([]uuid.UUID, len(routes))
+	for i, r := range routes {
+		routeIDs[i] = r.ID
+	}
+	readings, err := s.repository.GetRouteOdometers(ctx, routeIDs)
+	if err != nil {
+		return nil, err
+	}
+	return Distances(routes, readings, groupBy), nil
+}
+
+// GetDriverReport reports the utilization of every driver. It is always
+// grouped by driver.
+func (s *service) GetDriverReport(ctx context.Context, query Query) ([]DriverRow, error) {
+	from, to, err := parseRange(query)
+	if err != nil {
+		return nil, err
+	}
+	drivers, err := s.repository.GetDrivers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := s.repository.GetShifts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := s.repository.GetRoutes(ctx, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	return DriverUtilization(drivers, shifts, routes, from, to, time.Now()), nil
+}
+
+// static functions
+
+func NewService(repository Repository) *service {
+	return &service{repository: repository}
+}
+
+func groupByOr(groupBy GroupBy, fallback GroupBy) (GroupBy, error) {
+	if groupBy == "" {
+		return fallback, nil
+	}
+	if _, ok := GroupByList[groupBy]; !ok {
+		return "", ErrInvalidGroupBy
+	}
+	return groupBy, nil
+}
@@ -0,0 +1,101 @@
+package reporting
+
+import (
+	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/route"
+	"challenge-fravega/internal/vehicle"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Define a mock repository for testing the service
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetRoutes(ctx context.Context, from string, to string) ([]route.Route, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]route.Route), args.Error(1)
+}
+
+func (m *MockRepository) GetRouteOdometers(ctx context.Context, routeIDs []uuid.UUID) ([]vehicle.OdometerReading, error) {
+	args := m.Called(routeIDs)
+	return args.Get(0).([]vehicle.OdometerReading), args.Error(1)
+}
+
+func (m *MockRepository) GetDrivers(ctx context.Context) ([]carDriver.Driver, error) {
+	args := m.Called()
+	return args.Get(0).([]carDriver.Driver), args.Error(1)
+}
+
+func (m *MockRepository) GetShifts(ctx context.Context) ([]carDriver.Shift, error) {
+	args := m.Called()
+	return args.Get(0).([]carDriver.Shift), args.Error(1)
+}
+
+func createTestService(mockRepo *MockRepository) Service {
+	return NewService(mockRepo)
+}
+
+func TestGetDeliveryReport(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	mockRepo.On("GetRoutes", "2025-03-01", "2025-03-31").Return(testRoutes(), nil)
+
+	// Act
+	rows, err := service.GetDeliveryReport(context.Background(), Query{From: "2025-03-01", To: "2025-03-31", GroupBy: GroupByVehicle})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, 2, rows[0].Routes)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetDeliveryReportInvalidGroupBy(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	// Act
+	_, err := service.GetDeliveryReport(context.Background(), Query{From: "2025-03-01", To: "2025-03-31", GroupBy: "zone"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidGroupBy)
+	mockRepo.AssertNotCalled(t, "GetRoutes", mock.Anything, mock.Anything)
+}
+
+func TestGetDistanceReport(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	routes := testRoutes()
+	mockRepo.On("GetRoutes", "2025-03-01", "2025-03-31").Return(routes, nil)
+	mockRepo.On("GetRouteOdometers", []uuid.UUID{routes[0].ID, routes[1].ID}).Return([]vehicle.OdometerReading{}, nil)
+
+	// Act
+	rows, err := service.GetDistanceReport(context.Background(), Query{From: "2025-03-01", To: "2025-03-31"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, 0, rows[0].MeasuredRoutes)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetDriverReportInvalidRange(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	// Act
+	_, err := service.GetDriverReport(context.Background(), Query{From: "2025-03-31", To: "2025-03-01"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+}
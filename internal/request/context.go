@@ -0,0 +1,42 @@
+package request
+
+import "context"
+
+// SystemActor identifies changes that don't originate from an API call,
+// such as migrations or background jobs.
+const SystemActor = "system"
+
+type contextKey string
+
+const (
+	actorKey contextKey = "actor"
+	idKey    contextKey = "request_id"
+)
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns who is performing the request, or SystemActor when unknown.
+func Actor(ctx context.Context) string {
+	if ctx != nil {
+		if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+			return actor
+		}
+	}
+	return SystemActor
+}
+
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey, id)
+}
+
+// ID returns the request ID, or an empty string outside of a request.
+func ID(ctx context.Context) string {
+	if ctx != nil {
+		if id, ok := ctx.Value(idKey).(string); ok {
+			return id
+		}
+	}
+	return ""
+}
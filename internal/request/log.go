@@ -0,0 +1,40 @@
+package request
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// logHandler adds the request ID and the actor of the context to every
+// record logged with it, such as through slog.InfoContext, and the trace and
+// span IDs when it is traced.
+type logHandler struct {
+	slog.Handler
+}
+
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := ID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+		record.AddAttrs(slog.String("actor", Actor(ctx)))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// NewLogHandler wraps a handler so the records logged within a request carry
+// its request ID and actor.
+func NewLogHandler(handler slog.Handler) slog.Handler {
+	return &logHandler{Handler: handler}
+}
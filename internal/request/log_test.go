@@ -0,0 +1,32 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogHandler(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&out, nil))).With("component", "test")
+	ctx := WithActor(WithID(context.Background(), "req-1"), "dispatcher@example.com")
+
+	// Act
+	logger.InfoContext(ctx, "Created route")
+	var record map[string]any
+	err := json.Unmarshal(out.Bytes(), &record)
+	out.Reset()
+	logger.InfoContext(context.Background(), "Started")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "dispatcher@example.com", record["actor"])
+	assert.Equal(t, "test", record["component"])
+	assert.NotContains(t, out.String(), "request_id")
+}
@@ -1,11 +1,68 @@
 package routePoint
 
-import "github.com/google/uuid"
+import (
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+)
+
+// AddPurchaseOrder adds a purchase order to a route. Either the address or the
+// coordinates may be left out for the Locator to fill in.
 type AddPurchaseOrder struct {
 	RouteID         uuid.UUID `json:"route_id"`
 	PurchaseOrderID string    `json:"purchase_order_id"`
-	Latitude        float64   `json:"latitude"`
-	Longitude       float64   `json:"longitude"`
+	Latitude        *float64  `json:"latitude"`
+	Longitude       *float64  `json:"longitude"`
 	Address         string    `json:"address"`
+	// Optional delivery window
+	DeliveryWindowStart *time.Time `json:"delivery_window_start"`
+	DeliveryWindowEnd   *time.Time `json:"delivery_window_end"`
+}
+
+// Validate returns a description of every invalid field, or nil if the
+// purchase order can be added to a route.
+func (a *AddPurchaseOrder) Validate() []string {
+	var problems []string
+	if a.RouteID == uuid.Nil {
+		problems = append(problems, "route_id is required")
+	}
+	if strings.TrimSpace(a.PurchaseOrderID) == "" {
+		problems = append(problems, "purchase_order_id is required")
+	}
+	if (a.Latitude == nil) != (a.Longitude == nil) {
+		problems = append(problems, "latitude and longitude must be sent together")
+	}
+	if !validLatitude(a.Latitude) {
+		problems = append(problems, "latitude must be between -90 and 90")
+	}
+	if !validLongitude(a.Longitude) {
+		problems = append(problems, "longitude must be between -180 and 180")
+	}
+	if a.Latitude == nil && a.Longitude == nil && strings.TrimSpace(a.Address) == "" {
+		problems = append(problems, "address or coordinates are required")
+	}
+	if !validDeliveryWindow(a.DeliveryWindowStart, a.DeliveryWindowEnd) {
+		problems = append(problems, "delivery_window_end must be after delivery_window_start")
+	}
+	return problems
+}
+
+// static functions
+
+func validDeliveryWindow(start *time.Time, end *time.Time) bool {
+	return start == nil || end == nil || end.After(*start)
+}
+
+// validCoordinates tells whether the coordinates sent, if any, are in range.
+func validCoordinates(latitude *float64, longitude *float64) bool {
+	return validLatitude(latitude) && validLongitude(longitude)
+}
+
+func validLatitude(latitude *float64) bool {
+	return latitude == nil || (*latitude >= -90 && *latitude <= 90)
+}
+
+func validLongitude(longitude *float64) bool {
+	return longitude == nil || (*longitude >= -180 && *longitude <= 180)
 }
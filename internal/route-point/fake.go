@@ -0,0 +1,152 @@
+package routePoint
+
+import (
+	"challenge-fravega/internal/outbox"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FakeRepository is an in-memory Repository for the tests of the services
+// built on route points. The events recorded with the changes are kept in
+// Events.
+type FakeRepository struct {
+	mu          sync.Mutex
+	routePoints map[uuid.UUID]RoutePoint
+	ids         []uuid.UUID
+	Events      []outbox.Event
+}
+
+func (f *FakeRepository) CreateRoutePoint(ctx context.Context, routePoint *RoutePoint, events ...outbox.Event) (*RoutePoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.create(routePoint)
+	f.Events = append(f.Events, events...)
+	return routePoint, nil
+}
+
+func (f *FakeRepository) CreateRoutePoints(ctx context.Context, routePoints []*RoutePoint, events ...outbox.Event) ([]RoutePoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	created := make([]RoutePoint, len(routePoints))
+	for i, routePoint := range routePoints {
+		f.create(routePoint)
+		created[i] = *routePoint
+	}
+	f.Events = append(f.Events, events...)
+	return created, nil
+}
+
+func (f *FakeRepository) GetRoutePoints(ctx context.Context) ([]RoutePoint, error) {
+	return f.find(func(RoutePoint) bool { return true }), nil
+}
+
+func (f *FakeRepository) GetUnassignedRoutePoints(ctx context.Context, date string) ([]RoutePoint, error) {
+	routePoints := f.find(func(routePoint RoutePoint) bool {
+		return routePoint.RouteID == nil && (date == "" || routePoint.PoolDate != nil && *routePoint.PoolDate <= date)
+	})
+	sort.SliceStable(routePoints, func(i, j int) bool {
+		return poolDate(routePoints[i]) < poolDate(routePoints[j])
+	})
+	return routePoints, nil
+}
+
+func (f *FakeRepository) GetRoutePoint(ctx context.Context, id string) (*RoutePoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	routePointID, err := uuid.Parse(id)
+	if err != nil {
+		return &RoutePoint{}, gorm.ErrRecordNotFound
+	}
+	routePoint, ok := f.routePoints[routePointID]
+	if !ok {
+		return &RoutePoint{}, gorm.ErrRecordNotFound
+	}
+	return &routePoint, nil
+}
+
+func (f *FakeRepository) GetChangedRoutePoints(ctx context.Context, since time.Time, afterID uuid.UUID, limit int) ([]RoutePoint, error) {
+	routePoints := f.find(func(routePoint RoutePoint) bool {
+		return !routePoint.UpdatedAt.Before(since) && (afterID == uuid.Nil || routePoint.ID.String() > afterID.String())
+	})
+	sort.Slice(routePoints, func(i, j int) bool {
+		return routePoints[i].ID.String() < routePoints[j].ID.String()
+	})
+	if len(routePoints) > limit {
+		routePoints = routePoints[:limit]
+	}
+	return routePoints, nil
+}
+
+func (f *FakeRepository) UpdateRoutePoint(ctx context.Context, routePoint *RoutePoint, version int, events ...outbox.Event) (*RoutePoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.routePoints[routePoint.ID]
+	if !ok || stored.Version != version {
+		return nil, ErrVersionConflict
+	}
+	routePoint.Version = version + 1
+	routePoint.UpdatedAt = time.Now()
+	f.routePoints[routePoint.ID] = *routePoint
+	f.Events = append(f.Events, events...)
+	return routePoint, nil
+}
+
+func (f *FakeRepository) create(routePoint *RoutePoint) {
+	if routePoint.ID == uuid.Nil {
+		routePoint.ID = uuid.New()
+	}
+	if routePoint.Version == 0 {
+		routePoint.Version = 1
+	}
+	if routePoint.CreatedAt.IsZero() {
+		routePoint.CreatedAt = time.Now()
+	}
+	if routePoint.UpdatedAt.IsZero() {
+		routePoint.UpdatedAt = routePoint.CreatedAt
+	}
+	if _, ok := f.routePoints[routePoint.ID]; !ok {
+		f.ids = append(f.ids, routePoint.ID)
+	}
+	f.routePoints[routePoint.ID] = *routePoint
+}
+
+// find returns the route points that match, oldest first.
+func (f *FakeRepository) find(match func(RoutePoint) bool) []RoutePoint {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	routePoints := []RoutePoint{}
+	for _, id := range f.ids {
+		if routePoint := f.routePoints[id]; match(routePoint) {
+			routePoints = append(routePoints, routePoint)
+		}
+	}
+	sort.SliceStable(routePoints, func(i, j int) bool {
+		return routePoints[i].CreatedAt.Before(routePoints[j].CreatedAt)
+	})
+	return routePoints
+}
+
+// static functions
+
+// NewFakeRepository creates an in-memory repository holding the route points.
+func NewFakeRepository(routePoints ...RoutePoint) *FakeRepository {
+	f := &FakeRepository{routePoints: map[uuid.UUID]RoutePoint{}}
+	for _, routePoint := range routePoints {
+		f.CreateRoutePoint(context.Background(), &routePoint)
+	}
+	return f
+}
+
+// poolDate is the day a route point is due in the pool, empty for none, which
+// sorts first as NULL does.
+func poolDate(routePoint RoutePoint) string {
+	if routePoint.PoolDate == nil {
+		return ""
+	}
+	return *routePoint.PoolDate
+}
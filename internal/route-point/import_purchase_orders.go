@@ -0,0 +1,358 @@
+package routePoint
+
+import (
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxImportRows = 1000
+	// MaxImportBytes bounds the body of an import, read before its rows are
+	// counted
+	MaxImportBytes = 4 << 20
+	// MaxConcurrentVerifications bounds the requests made to the purchase
+	// order service while verifying an import
+	MaxConcurrentVerifications = 8
+	// MaxConcurrentGeocodes bounds the requests made to the geocoder while
+	// locating an import
+	MaxConcurrentGeocodes = 4
+)
+
+var (
+	ErrImportEmpty             = errors.New("import has no rows")
+	ErrImportTooLarge          = fmt.Errorf("import exceeds %d rows", MaxImportRows)
+	ErrInvalidImport           = errors.New("import has invalid rows, nothing was imported")
+	ErrInvalidCSV              = errors.New("invalid CSV")
+	ErrVerificationUnavailable = errors.New("purchase order verification is not configured")
+	ErrVerificationFailed      = errors.New("purchase order verification failed")
+)
+
+// csvColumns are the required header names of an import CSV, matching the
+// JSON fields of AddPurchaseOrder. The delivery window columns are optional.
+var csvColumns = []string{"route_id", "purchase_order_id", "latitude", "longitude", "address"}
+
+// ImportRow is a purchase order to add along with the problems found in it.
+type ImportRow struct {
+	AddPurchaseOrder
+	Errors []string
+
+	placement *Placement
+}
+
+type ImportOptions struct {
+	// DryRun reports the problems of every row without importing anything
+	DryRun bool
+	// Verify checks that every purchase order exists in the purchase order service
+	Verify bool
+}
+
+type RowError struct {
+	Row             int      `json:"row"`
+	PurchaseOrderID string   `json:"purchase_order_id"`
+	Errors          []string `json:"errors"`
+}
+
+type ImportResult struct {
+	DryRun      bool         `json:"dry_run"`
+	Total       int          `json:"total"`
+	Valid       int          `json:"valid"`
+	Errors      []RowError   `json:"errors"`
+	RoutePoints []RoutePoint `json:"route_points,omitempty"`
+}
+
+// static functions
+
+func NewImportRows(addPurchaseOrders []AddPurchaseOrder) []ImportRow {
+	rows := make([]ImportRow, len(addPurchaseOrders))
+	for i, addPurchaseOrder := range addPurchaseOrders {
+		rows[i] = ImportRow{AddPurchaseOrder: addPurchaseOrder}
+	}
+	return rows
+}
+
+// ParseCSV reads import rows from a CSV with a header naming the columns in
+// any order. Values that cannot be parsed are reported as row errors.
+func ParseCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrImportEmpty
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCSV, err)
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet exports often start with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, column := range csvColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", ErrInvalidCSV, column)
+		}
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCSV, err)
+		}
+		if len(rows) == MaxImportRows {
+			return nil, ErrImportTooLarge
+		}
+
+		field := func(column string) string {
+			if i, ok := index[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := ImportRow{}
+		if len(record) != len(header) {
+			row.Errors = append(row.Errors, fmt.Sprintf("expected %d fields, got %d", len(header), len(record)))
+		}
+		row.PurchaseOrderID = field("purchase_order_id")
+		row.Address = field("address")
+		if value := field("route_id"); value != "" {
+			if row.RouteID, err = uuid.Parse(value); err != nil {
+				row.Errors = append(row.Errors, "route_id is not a valid UUID")
+			}
+		}
+		if row.Latitude, err = parseFloat(field("latitude")); err != nil {
+			row.Errors = append(row.Errors, "latitude is not a number")
+		}
+		if row.Longitude, err = parseFloat(field("longitude")); err != nil {
+			row.Errors = append(row.Errors, "longitude is not a number")
+		}
+		if row.DeliveryWindowStart, err = parseTime(field("delivery_window_start")); err != nil {
+			row.Errors = append(row.Errors, "delivery_window_start is not an RFC 3339 time")
+		}
+		if row.DeliveryWindowEnd, err = parseTime(field("delivery_window_end")); err != nil {
+			row.Errors = append(row.Errors, "delivery_window_end is not an RFC 3339 time")
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrImportEmpty
+	}
+	return rows, nil
+}
+
+// parseFloat parses an optional number, an empty value being no number.
+func parseFloat(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// parseTime parses an optional RFC 3339 time, an empty value being no time.
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CheckImport validates every row, verifies the purchase orders of the valid
+// rows when requested, locates them and checks them against the zone of their
+// route. The returned result reports the
+// problems of each row; ErrVerificationFailed or ErrGeocodingFailed is
+// returned when the purchase order service or the geocoder could not answer,
+// as the rows cannot be judged then.
+func CheckImport(ctx context.Context, purchaseOrders purchaseOrder.Client, locator *Locator, zones *ZoneCheck, rows []ImportRow, options ImportOptions) (*ImportResult, error) {
+	if len(rows) == 0 {
+		return nil, ErrImportEmpty
+	}
+	if len(rows) > MaxImportRows {
+		return nil, ErrImportTooLarge
+	}
+
+	seen := map[string]int{}
+	for i := range rows {
+		row := &rows[i]
+		row.Errors = append(row.Errors, row.Validate()...)
+		if row.PurchaseOrderID == "" {
+			continue
+		}
+		if first, ok := seen[row.PurchaseOrderID]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("purchase_order_id is already in row %d", first))
+		} else {
+			seen[row.PurchaseOrderID] = i + 1
+		}
+	}
+
+	if options.Verify {
+		if purchaseOrders == nil {
+			return nil, ErrVerificationUnavailable
+		}
+		if err := verifyPurchaseOrders(ctx, purchaseOrders, rows); err != nil {
+			return nil, err
+		}
+	}
+	if err := locateRows(ctx, locator, zones, rows); err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{DryRun: options.DryRun, Total: len(rows), Errors: []RowError{}}
+	for i, row := range rows {
+		if len(row.Errors) == 0 {
+			result.Valid++
+			continue
+		}
+		result.Errors = append(result.Errors, RowError{
+			Row:             i + 1,
+			PurchaseOrderID: row.PurchaseOrderID,
+			Errors:          row.Errors,
+		})
+	}
+	return result, nil
+}
+
+// verifyPurchaseOrders looks up the purchase order of every valid row, at most
+// MaxConcurrentVerifications at a time. Unknown purchase orders are reported
+// on their row; any other failure stops the verification.
+func verifyPurchaseOrders(ctx context.Context, purchaseOrders purchaseOrder.Client, rows []ImportRow) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, MaxConcurrentVerifications)
+
+	for i := range rows {
+		if len(rows[i].Errors) > 0 {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(row *ImportRow) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, err := purchaseOrders.GetPurchaseOrder(ctx, row.PurchaseOrderID)
+			switch {
+			case errors.Is(err, purchaseOrder.ErrNotFound):
+				row.Errors = append(row.Errors, "purchase order does not exist")
+			case err != nil:
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(&rows[i])
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return fmt.Errorf("%w: %v", ErrVerificationFailed, firstErr)
+	}
+	return ctx.Err()
+}
+
+// locateRows places every valid row, at most MaxConcurrentGeocodes at a time.
+// Locations that cannot be found or are rejected by the zone check are
+// reported on their row; any other failure stops the import.
+func locateRows(ctx context.Context, locator *Locator, zones *ZoneCheck, rows []ImportRow) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, MaxConcurrentGeocodes)
+
+	for i := range rows {
+		if len(rows[i].Errors) > 0 {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(row *ImportRow) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			placement, err := locator.Locate(ctx, &row.AddPurchaseOrder)
+			if err == nil {
+				placement.OutsideZone, err = zones.Check(ctx, row.RouteID, placement.Latitude, placement.Longitude)
+			}
+			switch {
+			case errors.Is(err, ErrAddressNotFound), errors.Is(err, ErrCoordinatesNotFound),
+				errors.Is(err, ErrLocationRequired), errors.Is(err, ErrOutsideZone):
+				row.Errors = append(row.Errors, err.Error())
+			case err != nil:
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			default:
+				row.placement = placement
+			}
+		}(&rows[i])
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// newRoutePoints converts the rows of a checked import into pending route points.
+func newRoutePoints(rows []ImportRow) []*RoutePoint {
+	routePoints := make([]*RoutePoint, len(rows))
+	for i := range rows {
+		routePoints[i] = newRoutePoint(&rows[i].AddPurchaseOrder, rows[i].placement)
+	}
+	return routePoints
+}
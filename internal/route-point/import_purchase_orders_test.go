@@ -0,0 +1,80 @@
+package routePoint
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	// Arrange
+	input := "\ufeffPurchase_Order_ID,route_id,latitude,longitude,address,notes\n" +
+		"PO1,2b1c5a3e-6f0e-4a8e-9d3f-1c2b3a4d5e6f,-34.6037,-58.3816,\"Av. Corrientes 1234, CABA\",fragile\n" +
+		"PO2,not-a-uuid,north,-58.3816,Florida 100,\n" +
+		"PO3,2b1c5a3e-6f0e-4a8e-9d3f-1c2b3a4d5e6f\n"
+
+	// Act
+	rows, err := ParseCSV(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "PO1", rows[0].PurchaseOrderID)
+	assert.Equal(t, "2b1c5a3e-6f0e-4a8e-9d3f-1c2b3a4d5e6f", rows[0].RouteID.String())
+	assert.Equal(t, -34.6037, *rows[0].Latitude)
+	assert.Equal(t, "Av. Corrientes 1234, CABA", rows[0].Address)
+	assert.Empty(t, rows[0].Errors)
+	assert.Equal(t, []string{"route_id is not a valid UUID", "latitude is not a number"}, rows[1].Errors)
+	assert.Equal(t, []string{"expected 6 fields, got 2"}, rows[2].Errors)
+	assert.Nil(t, rows[2].Latitude)
+}
+
+func TestParseCSVDeliveryWindow(t *testing.T) {
+	// Arrange
+	input := "route_id,purchase_order_id,latitude,longitude,address,delivery_window_start,delivery_window_end\n" +
+		",PO1,0,0,address,2025-03-10T14:00:00-03:00,2025-03-10T16:00:00-03:00\n" +
+		",PO2,0,0,address,tomorrow,\n"
+
+	// Act
+	rows, err := ParseCSV(strings.NewReader(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 10, 17, 0, 0, 0, time.UTC), rows[0].DeliveryWindowStart.UTC())
+	assert.Equal(t, time.Date(2025, 3, 10, 19, 0, 0, 0, time.UTC), rows[0].DeliveryWindowEnd.UTC())
+	assert.Empty(t, rows[0].Errors)
+	assert.Nil(t, rows[1].DeliveryWindowStart)
+	assert.Nil(t, rows[1].DeliveryWindowEnd)
+	assert.Equal(t, []string{"delivery_window_start is not an RFC 3339 time"}, rows[1].Errors)
+}
+
+func TestParseCSVMissingColumn(t *testing.T) {
+	// Act
+	_, err := ParseCSV(strings.NewReader("route_id,purchase_order_id,latitude,longitude\n"))
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidCSV)
+	assert.ErrorContains(t, err, "missing column address")
+}
+
+func TestParseCSVEmpty(t *testing.T) {
+	// Act
+	_, err := ParseCSV(strings.NewReader("route_id,purchase_order_id,latitude,longitude,address\n"))
+
+	// Assert
+	assert.ErrorIs(t, err, ErrImportEmpty)
+}
+
+func TestParseCSVTooLarge(t *testing.T) {
+	// Arrange
+	input := "route_id,purchase_order_id,latitude,longitude,address\n" +
+		strings.Repeat(",PO,0,0,address\n", MaxImportRows+1)
+
+	// Act
+	_, err := ParseCSV(strings.NewReader(input))
+
+	// Assert
+	assert.ErrorIs(t, err, ErrImportTooLarge)
+}
@@ -0,0 +1,102 @@
+package routePoint
+
+import (
+	"challenge-fravega/internal/geocoder"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// DefaultMismatchThreshold is the distance in meters beyond which the address
+// and the coordinates of a route point are considered to disagree
+const DefaultMismatchThreshold = 500
+
+var (
+	ErrAddressNotFound     = errors.New("address could not be geocoded")
+	ErrCoordinatesNotFound = errors.New("no address found at the coordinates")
+	ErrLocationRequired    = errors.New("address and coordinates are both required as geocoding is disabled")
+	ErrGeocodingFailed     = errors.New("geocoding failed")
+)
+
+// Placement is where a purchase order is delivered, along with how well its
+// address and coordinates agree.
+type Placement struct {
+	Latitude  float64
+	Longitude float64
+	Address   string
+	// GeocodeDistance is the distance in meters between the given coordinates
+	// and the geocoded address, when both were given and could be compared
+	GeocodeDistance  *float64
+	LocationMismatch bool
+	// OutsideZone is set by the ZoneCheck, not the Locator
+	OutsideZone bool
+}
+
+// Locator completes the location of purchase orders with a geocoder.
+type Locator struct {
+	geocoder          geocoder.Geocoder
+	mismatchThreshold float64
+}
+
+// Locate fills in the coordinates from the address or the address from the
+// coordinates, whichever is missing. When both are given they are checked
+// against each other, and flagged as a mismatch if they are further apart than
+// the threshold. A nil locator requires both.
+func (l *Locator) Locate(ctx context.Context, a *AddPurchaseOrder) (*Placement, error) {
+	hasCoordinates := a.Latitude != nil && a.Longitude != nil
+	hasAddress := strings.TrimSpace(a.Address) != ""
+
+	switch {
+	case hasCoordinates && hasAddress:
+		placement := &Placement{Latitude: *a.Latitude, Longitude: *a.Longitude, Address: a.Address}
+		if l == nil {
+			return placement, nil
+		}
+		location, err := l.geocoder.Geocode(ctx, a.Address)
+		if err != nil {
+			// Both were given, so the route point can be created unchecked
+			if !errors.Is(err, geocoder.ErrNotFound) {
+				slog.WarnContext(ctx, "Failed to geocode, skipping location check", "address", a.Address, "error", err)
+			}
+			return placement, nil
+		}
+		distance := geocoder.Distance(placement.Latitude, placement.Longitude, location.Latitude, location.Longitude)
+		placement.GeocodeDistance = &distance
+		placement.LocationMismatch = distance > l.mismatchThreshold
+		return placement, nil
+
+	case l == nil:
+		return nil, ErrLocationRequired
+
+	case hasAddress:
+		location, err := l.geocoder.Geocode(ctx, a.Address)
+		if errors.Is(err, geocoder.ErrNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrGeocodingFailed, err)
+		}
+		return &Placement{Latitude: location.Latitude, Longitude: location.Longitude, Address: a.Address}, nil
+
+	default:
+		location, err := l.geocoder.ReverseGeocode(ctx, *a.Latitude, *a.Longitude)
+		if errors.Is(err, geocoder.ErrNotFound) {
+			return nil, ErrCoordinatesNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrGeocodingFailed, err)
+		}
+		return &Placement{Latitude: *a.Latitude, Longitude: *a.Longitude, Address: location.Address}, nil
+	}
+}
+
+// static functions
+
+func NewLocator(geocoder geocoder.Geocoder, mismatchThreshold float64) *Locator {
+	if mismatchThreshold <= 0 {
+		mismatchThreshold = DefaultMismatchThreshold
+	}
+	return &Locator{geocoder: geocoder, mismatchThreshold: mismatchThreshold}
+}
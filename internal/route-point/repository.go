@@ -1,36 +1,157 @@
 package routePoint
 
 import (
+	"challenge-fravega/internal/outbox"
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type Repository struct {
+// Repository stores the route points, recording the outbox events of their
+// changes.
+type Repository interface {
+	CreateRoutePoint(ctx context.Context, routePoint *RoutePoint, events ...outbox.Event) (*RoutePoint, error)
+	CreateRoutePoints(ctx context.Context, routePoints []*RoutePoint, events ...outbox.Event) ([]RoutePoint, error)
+	GetRoutePoints(ctx context.Context) ([]RoutePoint, error)
+	GetUnassignedRoutePoints(ctx context.Context, date string) ([]RoutePoint, error)
+	GetRoutePoint(ctx context.Context, id string) (*RoutePoint, error)
+	GetChangedRoutePoints(ctx context.Context, since time.Time, afterID uuid.UUID, limit int) ([]RoutePoint, error)
+	UpdateRoutePoint(ctx context.Context, routePoint *RoutePoint, version int, events ...outbox.Event) (*RoutePoint, error)
+}
+
+type repository struct {
 	db *gorm.DB
 }
 
-func (r *Repository) CreateRoutePoint(routePoint *RoutePoint) (*RoutePoint, error) {
+// CreateRoutePoint creates the route point, recording the events in the same
+// transaction.
+func (r *repository) CreateRoutePoint(ctx context.Context, routePoint *RoutePoint, events ...outbox.Event) (*RoutePoint, error) {
 	if routePoint.ID == uuid.Nil {
 		routePoint.ID = uuid.New()
 	}
-	err := r.db.Create(routePoint).Error
+	if routePoint.Version == 0 {
+		routePoint.Version = 1
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(routePoint).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, events...)
+	})
 	return routePoint, err
 }
 
-func (r *Repository) GetRoutePoints() ([]RoutePoint, error) {
+// CreateRoutePoints creates all the route points in a single transaction,
+// along with the events.
+func (r *repository) CreateRoutePoints(ctx context.Context, routePoints []*RoutePoint, events ...outbox.Event) ([]RoutePoint, error) {
+	for _, routePoint := range routePoints {
+		if routePoint.ID == uuid.Nil {
+			routePoint.ID = uuid.New()
+		}
+		if routePoint.Version == 0 {
+			routePoint.Version = 1
+		}
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(routePoints, 100).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]RoutePoint, len(routePoints))
+	for i, routePoint := range routePoints {
+		created[i] = *routePoint
+	}
+	return created, nil
+}
+
+func (r *repository) GetRoutePoints(ctx context.Context) ([]RoutePoint, error) {
+	var routePoints []RoutePoint
+	err := r.db.WithContext(ctx).Find(&routePoints).Error
+	return routePoints, err
+}
+
+// GetUnassignedRoutePoints returns the route points in the unassigned pool due
+// by the given date, or all of them when date is empty, oldest first.
+func (r *repository) GetUnassignedRoutePoints(ctx context.Context, date string) ([]RoutePoint, error) {
 	var routePoints []RoutePoint
-	err := r.db.Find(&routePoints).Error
+	query := r.db.WithContext(ctx).Where("route_id IS NULL")
+	if date != "" {
+		query = query.Where("pool_date <= ?", date)
+	}
+	err := query.Order("pool_date NULLS FIRST, created_at").Find(&routePoints).Error
 	return routePoints, err
 }
 
-func (r *Repository) GetRoutePoint(id string) (*RoutePoint, error) {
+func (r *repository) GetRoutePoint(ctx context.Context, id string) (*RoutePoint, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return &RoutePoint{}, gorm.ErrRecordNotFound
+	}
 	var routePoint RoutePoint
-	err := r.db.First(&routePoint, "id = ?", id).Error
+	err := r.db.WithContext(ctx).First(&routePoint, "id = ?", id).Error
 	return &routePoint, err
 }
 
+// GetChangedRoutePoints returns a page of the route points updated since the
+// given time, by ID, starting after afterID. uuid.Nil starts from the first.
+func (r *repository) GetChangedRoutePoints(ctx context.Context, since time.Time, afterID uuid.UUID, limit int) ([]RoutePoint, error) {
+	var routePoints []RoutePoint
+	query := r.db.WithContext(ctx).Where("updated_at >= ?", since)
+	if afterID != uuid.Nil {
+		query = query.Where("id > ?", afterID)
+	}
+	err := query.Order("id").Limit(limit).Find(&routePoints).Error
+	return routePoints, err
+}
+
+// UpdateRoutePoint saves the route point only if it is still at the given
+// version, and increments it, recording the events in the same transaction.
+// ErrVersionConflict is returned when the version changed.
+func (r *repository) UpdateRoutePoint(ctx context.Context, routePoint *RoutePoint, version int, events ...outbox.Event) (*RoutePoint, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&RoutePoint{}).
+			Where("id = ? AND version = ?", routePoint.ID, version).
+			Updates(map[string]interface{}{
+				"route_id":  routePoint.RouteID,
+				"pool_date": routePoint.PoolDate,
+				"status":    routePoint.Status,
+				"latitude":  routePoint.Latitude,
+				"longitude": routePoint.Longitude,
+				"address":   routePoint.Address,
+				"version":   gorm.Expr("version + 1"),
+
+				"delivery_window_start": routePoint.DeliveryWindowStart,
+				"delivery_window_end":   routePoint.DeliveryWindowEnd,
+				"geocode_distance":      routePoint.GeocodeDistance,
+				"location_mismatch":     routePoint.LocationMismatch,
+				"outside_zone":          routePoint.OutsideZone,
+				"arrived_at":            routePoint.ArrivedAt,
+				"completed_at":          routePoint.CompletedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		return outbox.Append(tx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	routePoint.Version = version + 1
+	return routePoint, nil
+}
+
 // static functions
 
-func NewRepository(db *gorm.DB) *Repository {
-	return &Repository{db: db}
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
 }
@@ -1,6 +1,8 @@
 package routePoint
 
 import (
+	"challenge-fravega/internal/outbox"
+	"context"
 	"testing"
 	"time"
 
@@ -14,7 +16,7 @@ import (
 type RepositoryTestSuite struct {
 	suite.Suite
 	db         *gorm.DB
-	repository *Repository
+	repository Repository
 }
 
 func (suite *RepositoryTestSuite) SetupTest() {
@@ -23,7 +25,7 @@ func (suite *RepositoryTestSuite) SetupTest() {
 		suite.T().Fatal(err)
 	}
 
-	err = db.AutoMigrate(&RoutePoint{})
+	err = db.AutoMigrate(&RoutePoint{}, &outbox.Event{})
 	if err != nil {
 		suite.T().Fatal(err)
 	}
@@ -37,7 +39,7 @@ func (suite *RepositoryTestSuite) TestCreateRoutePoint() {
 	routeID := uuid.New()
 	routePoint := &RoutePoint{
 		PurchaseOrderID: "PO12345",
-		RouteID:         routeID,
+		RouteID:         &routeID,
 		Status:          RoutePointStatusList[RoutePointStatusPending],
 		Latitude:        37.7749,
 		Longitude:       -122.4194,
@@ -45,7 +47,7 @@ func (suite *RepositoryTestSuite) TestCreateRoutePoint() {
 	}
 
 	// Act
-	result, err := suite.repository.CreateRoutePoint(routePoint)
+	result, err := suite.repository.CreateRoutePoint(context.Background(), routePoint)
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -66,7 +68,7 @@ func (suite *RepositoryTestSuite) TestGetRoutePoint() {
 	routePoint := &RoutePoint{
 		ID:              routePointID,
 		PurchaseOrderID: "PO12345",
-		RouteID:         routeID,
+		RouteID:         &routeID,
 		Status:          RoutePointStatusList[RoutePointStatusPending],
 		Latitude:        37.7749,
 		Longitude:       -122.4194,
@@ -78,7 +80,7 @@ func (suite *RepositoryTestSuite) TestGetRoutePoint() {
 	suite.db.Create(routePoint)
 
 	// Act
-	result, err := suite.repository.GetRoutePoint(routePointID.String())
+	result, err := suite.repository.GetRoutePoint(context.Background(), routePointID.String())
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -100,7 +102,7 @@ func (suite *RepositoryTestSuite) TestGetRoutePoints() {
 	routePoint1 := &RoutePoint{
 		ID:              uuid.New(),
 		PurchaseOrderID: "PO12345",
-		RouteID:         routeID,
+		RouteID:         &routeID,
 		Status:          RoutePointStatusList[RoutePointStatusPending],
 		Latitude:        37.7749,
 		Longitude:       -122.4194,
@@ -110,7 +112,7 @@ func (suite *RepositoryTestSuite) TestGetRoutePoints() {
 	routePoint2 := &RoutePoint{
 		ID:              uuid.New(),
 		PurchaseOrderID: "PO67890",
-		RouteID:         routeID,
+		RouteID:         &routeID,
 		Status:          RoutePointStatusList[RoutePointStatusInRoute],
 		Latitude:        34.0522,
 		Longitude:       -118.2437,
@@ -121,13 +123,163 @@ func (suite *RepositoryTestSuite) TestGetRoutePoints() {
 	suite.db.Create(routePoint2)
 
 	// Act
-	results, err := suite.repository.GetRoutePoints()
+	results, err := suite.repository.GetRoutePoints(context.Background())
 
 	// Assert
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), results, 2)
 }
 
+func (suite *RepositoryTestSuite) TestUpdateRoutePoint() {
+	// Arrange
+	routePoint := &RoutePoint{
+		PurchaseOrderID: "PO12345",
+		RouteID:         newID(),
+		Status:          RoutePointStatusList[RoutePointStatusPending],
+		Address:         "123 Test St",
+	}
+	suite.repository.CreateRoutePoint(context.Background(), routePoint)
+	routePoint.Status = RoutePointStatusList[RoutePointStatusInRoute]
+
+	// Act
+	result, err := suite.repository.UpdateRoutePoint(context.Background(), routePoint, 1)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, result.Version)
+
+	var stored RoutePoint
+	suite.db.First(&stored, "id = ?", routePoint.ID)
+	assert.Equal(suite.T(), RoutePointStatusList[RoutePointStatusInRoute], stored.Status)
+	assert.Equal(suite.T(), 2, stored.Version)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateRoutePointVersionConflict() {
+	// Arrange
+	routePoint := &RoutePoint{
+		PurchaseOrderID: "PO12345",
+		RouteID:         newID(),
+		Status:          RoutePointStatusList[RoutePointStatusPending],
+		Address:         "123 Test St",
+	}
+	suite.repository.CreateRoutePoint(context.Background(), routePoint)
+
+	// Act
+	_, err := suite.repository.UpdateRoutePoint(context.Background(), routePoint, 7)
+
+	// Assert
+	assert.ErrorIs(suite.T(), err, ErrVersionConflict)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateRoutePointRecordsEvents() {
+	// Arrange
+	routePoint := &RoutePoint{ID: uuid.New(), PurchaseOrderID: "PO12345", RouteID: newID(), Status: "pending"}
+	suite.repository.CreateRoutePoint(context.Background(), routePoint)
+	event, err := NewEvent(outbox.EventRoutePointUpdated, "", routePoint)
+	suite.Require().NoError(err)
+	conflicting, err := NewEvent(outbox.EventRoutePointUpdated, "", routePoint)
+	suite.Require().NoError(err)
+
+	// Act
+	_, updateErr := suite.repository.UpdateRoutePoint(context.Background(), routePoint, 1, event)
+	_, conflictErr := suite.repository.UpdateRoutePoint(context.Background(), routePoint, 1, conflicting)
+
+	// Assert
+	assert.NoError(suite.T(), updateErr)
+	assert.ErrorIs(suite.T(), conflictErr, ErrVersionConflict)
+	var events []outbox.Event
+	suite.db.Find(&events)
+	assert.Len(suite.T(), events, 1)
+	assert.Equal(suite.T(), event.ID, events[0].ID)
+	assert.Equal(suite.T(), routePoint.ID, events[0].AggregateID)
+}
+
+func (suite *RepositoryTestSuite) TestCreateRoutePoints() {
+	// Arrange
+	routeID := uuid.New()
+	routePoints := []*RoutePoint{
+		{PurchaseOrderID: "PO1", RouteID: &routeID, Status: RoutePointStatusList[RoutePointStatusPending]},
+		{PurchaseOrderID: "PO2", RouteID: &routeID, Status: RoutePointStatusList[RoutePointStatusPending]},
+	}
+
+	// Act
+	results, err := suite.repository.CreateRoutePoints(context.Background(), routePoints)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 2)
+	assert.NotEqual(suite.T(), uuid.Nil, results[0].ID)
+	assert.Equal(suite.T(), 1, results[1].Version)
+
+	var count int64
+	suite.db.Model(&RoutePoint{}).Where("route_id = ?", routeID).Count(&count)
+	assert.Equal(suite.T(), int64(2), count)
+}
+
+func (suite *RepositoryTestSuite) TestCreateRoutePointsIsAtomic() {
+	// Arrange
+	existing := &RoutePoint{ID: uuid.New(), PurchaseOrderID: "PO1"}
+	suite.db.Create(existing)
+	routePoints := []*RoutePoint{
+		{PurchaseOrderID: "PO2"},
+		{ID: existing.ID, PurchaseOrderID: "PO3"},
+	}
+
+	// Act
+	_, err := suite.repository.CreateRoutePoints(context.Background(), routePoints)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	var count int64
+	suite.db.Model(&RoutePoint{}).Count(&count)
+	assert.Equal(suite.T(), int64(1), count)
+}
+
+func (suite *RepositoryTestSuite) TestGetUnassignedRoutePoints() {
+	// Arrange
+	today, tomorrow := "2025-03-11", "2025-03-12"
+	suite.db.Create(&RoutePoint{ID: uuid.New(), PurchaseOrderID: "PO1", PoolDate: &today})
+	suite.db.Create(&RoutePoint{ID: uuid.New(), PurchaseOrderID: "PO2", PoolDate: &tomorrow})
+	suite.db.Create(&RoutePoint{ID: uuid.New(), PurchaseOrderID: "PO3", RouteID: newID()})
+
+	// Act
+	due, err := suite.repository.GetUnassignedRoutePoints(context.Background(), today)
+	all, allErr := suite.repository.GetUnassignedRoutePoints(context.Background(), "")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), due, 1)
+	assert.Equal(suite.T(), "PO1", due[0].PurchaseOrderID)
+	assert.NoError(suite.T(), allErr)
+	assert.Len(suite.T(), all, 2)
+}
+
+func (suite *RepositoryTestSuite) TestGetChangedRoutePoints() {
+	// Arrange
+	settled := time.Now().Add(-48 * time.Hour)
+	changed := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for _, id := range changed {
+		suite.db.Create(&RoutePoint{ID: id, PurchaseOrderID: id.String(), Status: "pending", UpdatedAt: time.Now()})
+	}
+	suite.db.Create(&RoutePoint{ID: uuid.New(), PurchaseOrderID: "PO-OLD", Status: "completed", UpdatedAt: settled})
+	since := time.Now().Add(-time.Hour)
+
+	// Act
+	first, firstErr := suite.repository.GetChangedRoutePoints(context.Background(), since, uuid.Nil, 2)
+	rest, restErr := suite.repository.GetChangedRoutePoints(context.Background(), since, first[len(first)-1].ID, 2)
+
+	// Assert
+	assert.NoError(suite.T(), firstErr)
+	assert.NoError(suite.T(), restErr)
+	assert.Len(suite.T(), first, 2)
+	assert.Len(suite.T(), rest, 1)
+	ids := []uuid.UUID{}
+	for _, rp := range append(first, rest...) {
+		ids = append(ids, rp.ID)
+	}
+	assert.ElementsMatch(suite.T(), changed, ids)
+}
+
 func TestRepositorySuite(t *testing.T) {
 	suite.Run(t, new(RepositoryTestSuite))
 }
@@ -1,6 +1,9 @@
 package routePoint
 
 import (
+	"challenge-fravega/internal/outbox"
+	"errors"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,25 +12,137 @@ import (
 type RoutePoint struct {
 	ID              uuid.UUID `gorm:"column:id" json:"id"`
 	PurchaseOrderID string    `gorm:"column:purchase_order_id" json:"purchase_order_id"`
-	RouteID         uuid.UUID `gorm:"column:route_id" json:"route_id"`
-	Status          string    `gorm:"column:status" json:"status"`
-	Latitude        float64   `gorm:"column:latitude" json:"latitude"`
-	Longitude       float64   `gorm:"column:longitude" json:"longitude"`
-	Address         string    `gorm:"column:address" json:"address"`
-	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
-	UpdatedAt       time.Time `gorm:"column:updated_at" json:"updated_at"`
+	// RouteID is nil while the route point waits in the unassigned pool, to
+	// be delivered on PoolDate
+	RouteID   *uuid.UUID `gorm:"column:route_id" json:"route_id"`
+	PoolDate  *string    `gorm:"column:pool_date" json:"pool_date"`
+	Status    string     `gorm:"column:status" json:"status"`
+	Version   int        `gorm:"column:version" json:"version"`
+	Latitude  float64    `gorm:"column:latitude" json:"latitude"`
+	Longitude float64    `gorm:"column:longitude" json:"longitude"`
+	Address   string     `gorm:"column:address" json:"address"`
+	// GeocodeDistance is the distance in meters between the coordinates and
+	// the geocoded address, and LocationMismatch whether it exceeds the
+	// threshold. Both are cleared when the location is updated.
+	GeocodeDistance  *float64 `gorm:"column:geocode_distance" json:"geocode_distance"`
+	LocationMismatch bool     `gorm:"column:location_mismatch" json:"location_mismatch"`
+	// OutsideZone flags route points lying outside the zone of their route
+	OutsideZone bool `gorm:"column:outside_zone" json:"outside_zone"`
+	// DeliveryWindowStart and DeliveryWindowEnd bound the time agreed with
+	// the customer for the delivery, if any
+	DeliveryWindowStart *time.Time `gorm:"column:delivery_window_start" json:"delivery_window_start"`
+	DeliveryWindowEnd   *time.Time `gorm:"column:delivery_window_end" json:"delivery_window_end"`
+	// ArrivedAt is when the driver reached the stop and CompletedAt when the
+	// delivery was completed or failed
+	ArrivedAt   *time.Time `gorm:"column:arrived_at" json:"arrived_at"`
+	CompletedAt *time.Time `gorm:"column:completed_at" json:"completed_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at" json:"updated_at"`
 }
 
+// PoolDateLayout is the format of PoolDate
+const PoolDateLayout = time.DateOnly
+
 type RoutePointStatus string
 
 const (
 	RoutePointStatusPending   RoutePointStatus = "pending"
 	RoutePointStatusInRoute   RoutePointStatus = "in_route"
+	RoutePointStatusArrived   RoutePointStatus = "arrived"
 	RoutePointStatusCompleted RoutePointStatus = "completed"
+	RoutePointStatusFailed    RoutePointStatus = "failed"
 )
 
 var RoutePointStatusList = map[RoutePointStatus]string{
 	RoutePointStatusPending:   "pending",
 	RoutePointStatusInRoute:   "in_route",
+	RoutePointStatusArrived:   "arrived",
 	RoutePointStatusCompleted: "completed",
+	RoutePointStatusFailed:    "failed",
+}
+
+// RoutePointStatusTransitions lists the statuses a route point can move to from each status
+var RoutePointStatusTransitions = map[RoutePointStatus][]RoutePointStatus{
+	RoutePointStatusPending: {RoutePointStatusInRoute},
+	RoutePointStatusInRoute: {RoutePointStatusArrived, RoutePointStatusCompleted, RoutePointStatusFailed},
+	RoutePointStatusArrived: {RoutePointStatusCompleted, RoutePointStatusFailed},
+}
+
+func CanTransition(from RoutePointStatus, to RoutePointStatus) bool {
+	for _, status := range RoutePointStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// OnTime tells whether the delivery was completed within its delivery window.
+// Route points without a window, or not completed, are never on time.
+func (r *RoutePoint) OnTime() bool {
+	if r.Status != RoutePointStatusList[RoutePointStatusCompleted] || r.CompletedAt == nil {
+		return false
+	}
+	if r.DeliveryWindowStart == nil && r.DeliveryWindowEnd == nil {
+		return false
+	}
+	if r.DeliveryWindowStart != nil && r.CompletedAt.Before(*r.DeliveryWindowStart) {
+		return false
+	}
+	return r.DeliveryWindowEnd == nil || !r.CompletedAt.After(*r.DeliveryWindowEnd)
 }
+
+// EventData is the payload of the route point events. A purchase order is out
+// for delivery when in_route and delivered when completed.
+type EventData struct {
+	RoutePointID    uuid.UUID  `json:"route_point_id"`
+	RouteID         *uuid.UUID `json:"route_id"`
+	PurchaseOrderID string     `json:"purchase_order_id"`
+	PreviousStatus  string     `json:"previous_status,omitempty"`
+	Status          string     `json:"status"`
+	ArrivedAt       *time.Time `json:"arrived_at"`
+	CompletedAt     *time.Time `json:"completed_at"`
+}
+
+// NewEvent describes a change to the route point, to record along with it.
+// previousStatus is set by status changes.
+func NewEvent(eventType outbox.EventType, previousStatus string, routePoint *RoutePoint) (outbox.Event, error) {
+	return outbox.NewEvent(outbox.AggregateRoutePoint, routePoint.ID, eventType, &EventData{
+		RoutePointID:    routePoint.ID,
+		RouteID:         routePoint.RouteID,
+		PurchaseOrderID: routePoint.PurchaseOrderID,
+		PreviousStatus:  previousStatus,
+		Status:          routePoint.Status,
+		ArrivedAt:       routePoint.ArrivedAt,
+		CompletedAt:     routePoint.CompletedAt,
+	})
+}
+
+// SortForDelivery orders route points as they are to be delivered: by the
+// start of their delivery window, those without one last, and then by the
+// order they were added in.
+func SortForDelivery(routePoints []RoutePoint) {
+	sort.SliceStable(routePoints, func(i, j int) bool {
+		a, b := routePoints[i].DeliveryWindowStart, routePoints[j].DeliveryWindowStart
+		switch {
+		case a != nil && b != nil && !a.Equal(*b):
+			return a.Before(*b)
+		case a != nil && b == nil:
+			return true
+		case a == nil && b != nil:
+			return false
+		}
+		return routePoints[i].CreatedAt.Before(routePoints[j].CreatedAt)
+	})
+}
+
+var (
+	ErrInvalidStatus           = errors.New("invalid route point status")
+	ErrInvalidStatusTransition = errors.New("invalid route point status transition")
+	ErrVersionConflict         = errors.New("route point was modified by another request")
+	ErrInvalidDeliveryWindow   = errors.New("delivery window must end after it starts")
+	ErrInvalidCoordinates      = errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+	ErrUnassigned              = errors.New("route point is not assigned to a route")
+	ErrInvalidPoolDate         = errors.New("invalid pool date, must be formatted as YYYY-MM-DD")
+	ErrRouteNotFound           = errors.New("route not found")
+)
@@ -0,0 +1,23 @@
+package routePoint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnTime(t *testing.T) {
+	at := func(hour int) *time.Time {
+		t := time.Date(2025, 3, 10, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	completed := RoutePointStatusList[RoutePointStatusCompleted]
+
+	assert.True(t, (&RoutePoint{Status: completed, CompletedAt: at(15), DeliveryWindowStart: at(14), DeliveryWindowEnd: at(16)}).OnTime())
+	assert.True(t, (&RoutePoint{Status: completed, CompletedAt: at(16), DeliveryWindowEnd: at(16)}).OnTime())
+	assert.False(t, (&RoutePoint{Status: completed, CompletedAt: at(17), DeliveryWindowStart: at(14), DeliveryWindowEnd: at(16)}).OnTime())
+	assert.False(t, (&RoutePoint{Status: completed, CompletedAt: at(13), DeliveryWindowStart: at(14)}).OnTime())
+	assert.False(t, (&RoutePoint{Status: completed, CompletedAt: at(15)}).OnTime())
+	assert.False(t, (&RoutePoint{Status: "failed", CompletedAt: at(15), DeliveryWindowEnd: at(16)}).OnTime())
+}
@@ -1,37 +1,271 @@
 package routePoint
 
+import (
+	"challenge-fravega/internal/outbox"
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	"challenge-fravega/internal/tracing"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type Service interface {
-	GetRoutePoints() ([]RoutePoint, error)
-	GetRoutePoint(id string) (*RoutePoint, error)
-	CreateRoutePoint(addPurchaseOrder *AddPurchaseOrder) (*RoutePoint, error)
+	GetRoutePoints(ctx context.Context) ([]RoutePoint, error)
+	GetRoutePoint(ctx context.Context, id string) (*RoutePoint, error)
+	GetUnassignedRoutePoints(ctx context.Context, date string) ([]RoutePoint, error)
+	CreateRoutePoint(ctx context.Context, addPurchaseOrder *AddPurchaseOrder) (*RoutePoint, error)
+	UpdateRoutePoint(ctx context.Context, id string, version int, update *UpdateRoutePoint) (*RoutePoint, error)
+	ImportPurchaseOrders(ctx context.Context, rows []ImportRow, options ImportOptions) (*ImportResult, error)
+}
+
+// Routes tells whether a route exists, before route points are moved to it.
+type Routes interface {
+	RouteExists(ctx context.Context, id uuid.UUID) (bool, error)
 }
 
 type service struct {
-	repository *Repository
+	repository     Repository
+	purchaseOrders purchaseOrder.Client
+	locator        *Locator
+	zones          *ZoneCheck
+	routes         Routes
 }
 
-func (s *service) GetRoutePoints() ([]RoutePoint, error) {
-	return s.repository.GetRoutePoints()
+func (s *service) GetRoutePoints(ctx context.Context) ([]RoutePoint, error) {
+	return s.repository.GetRoutePoints(ctx)
 }
 
-func (s *service) GetRoutePoint(id string) (*RoutePoint, error) {
-	return s.repository.GetRoutePoint(id)
+func (s *service) GetRoutePoint(ctx context.Context, id string) (*RoutePoint, error) {
+	return s.repository.GetRoutePoint(ctx, id)
+}
+
+// GetUnassignedRoutePoints lists the route points waiting in the unassigned
+// pool to be delivered by date (YYYY-MM-DD), or all of them when it is empty.
+func (s *service) GetUnassignedRoutePoints(ctx context.Context, date string) ([]RoutePoint, error) {
+	if date != "" {
+		if _, err := time.Parse(PoolDateLayout, date); err != nil {
+			return nil, ErrInvalidPoolDate
+		}
+	}
+	return s.repository.GetUnassignedRoutePoints(ctx, date)
 }
 
-func (s *service) CreateRoutePoint(addPurchaseOrder *AddPurchaseOrder) (*RoutePoint, error) {
-	routePoint := &RoutePoint{
-		RouteID:         addPurchaseOrder.RouteID,
-		PurchaseOrderID: addPurchaseOrder.PurchaseOrderID,
-		Latitude:        addPurchaseOrder.Latitude,
-		Longitude:       addPurchaseOrder.Longitude,
-		Address:         addPurchaseOrder.Address,
-		Status:          RoutePointStatusList[RoutePointStatusPending],
+// CreateRoutePoint adds a purchase order to a route. Unlike imports, the
+// purchase order and the route are not required up front, but the coordinates
+// and delivery window sent must still make sense.
+func (s *service) CreateRoutePoint(ctx context.Context, addPurchaseOrder *AddPurchaseOrder) (*RoutePoint, error) {
+	ctx, span := tracing.Start(ctx, "route_point.CreateRoutePoint")
+	defer span.End()
+
+	if !validCoordinates(addPurchaseOrder.Latitude, addPurchaseOrder.Longitude) {
+		return nil, ErrInvalidCoordinates
+	}
+	if !validDeliveryWindow(addPurchaseOrder.DeliveryWindowStart, addPurchaseOrder.DeliveryWindowEnd) {
+		return nil, ErrInvalidDeliveryWindow
+	}
+	placement, err := s.locator.Locate(ctx, addPurchaseOrder)
+	if err != nil {
+		return nil, err
+	}
+	placement.OutsideZone, err = s.zones.Check(ctx, addPurchaseOrder.RouteID, placement.Latitude, placement.Longitude)
+	if err != nil {
+		return nil, err
 	}
-	return s.repository.CreateRoutePoint(routePoint)
+	routePoint := newRoutePoint(addPurchaseOrder, placement)
+	events, err := createdEvents(routePoint)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.CreateRoutePoint(ctx, routePoint, events...)
+}
+
+// UpdateRoutePoint applies the update if the route point is still at the
+// version the client last read. Status changes must follow
+// RoutePointStatusTransitions, and the route it is moved to must exist.
+func (s *service) UpdateRoutePoint(ctx context.Context, id string, version int, update *UpdateRoutePoint) (*RoutePoint, error) {
+	ctx, span := tracing.Start(ctx, "route_point.UpdateRoutePoint")
+	defer span.End()
+
+	routePoint, err := s.repository.GetRoutePoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if routePoint.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	if update.RouteID != nil {
+		if routePoint.RouteID == nil || *routePoint.RouteID != *update.RouteID {
+			if err := s.checkRoute(ctx, *update.RouteID); err != nil {
+				return nil, err
+			}
+		}
+		routePoint.RouteID = update.RouteID
+		routePoint.PoolDate = nil
+	}
+	if !validCoordinates(update.Latitude, update.Longitude) {
+		return nil, ErrInvalidCoordinates
+	}
+	if update.Latitude != nil {
+		routePoint.Latitude = *update.Latitude
+	}
+	if update.Longitude != nil {
+		routePoint.Longitude = *update.Longitude
+	}
+	if update.Address != nil {
+		routePoint.Address = *update.Address
+	}
+	if update.Latitude != nil || update.Longitude != nil || update.Address != nil {
+		// The location check no longer applies to the new location
+		routePoint.GeocodeDistance = nil
+		routePoint.LocationMismatch = false
+	}
+	if update.RouteID != nil || update.Latitude != nil || update.Longitude != nil {
+		if routePoint.RouteID != nil {
+			routePoint.OutsideZone, err = s.zones.Check(ctx, *routePoint.RouteID, routePoint.Latitude, routePoint.Longitude)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if update.DeliveryWindowStart != nil {
+		routePoint.DeliveryWindowStart = update.DeliveryWindowStart
+	}
+	if update.DeliveryWindowEnd != nil {
+		routePoint.DeliveryWindowEnd = update.DeliveryWindowEnd
+	}
+	if !validDeliveryWindow(routePoint.DeliveryWindowStart, routePoint.DeliveryWindowEnd) {
+		return nil, ErrInvalidDeliveryWindow
+	}
+	previousStatus := ""
+	if update.Status != nil {
+		status := RoutePointStatus(*update.Status)
+		if _, ok := RoutePointStatusList[status]; !ok {
+			return nil, ErrInvalidStatus
+		}
+		if status != RoutePointStatus(routePoint.Status) && !CanTransition(RoutePointStatus(routePoint.Status), status) {
+			return nil, ErrInvalidStatusTransition
+		}
+		if routePoint.RouteID == nil && status != RoutePointStatusPending {
+			return nil, ErrUnassigned
+		}
+		if status != RoutePointStatus(routePoint.Status) {
+			previousStatus = routePoint.Status
+			now := time.Now()
+			switch status {
+			case RoutePointStatusArrived:
+				routePoint.ArrivedAt = &now
+			case RoutePointStatusCompleted, RoutePointStatusFailed:
+				routePoint.CompletedAt = &now
+			}
+		}
+		routePoint.Status = RoutePointStatusList[status]
+	}
+
+	eventType := outbox.EventRoutePointUpdated
+	if previousStatus != "" {
+		eventType = outbox.EventRoutePointStatusChanged
+	}
+	event, err := NewEvent(eventType, previousStatus, routePoint)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repository.UpdateRoutePoint(ctx, routePoint, version, event); err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetRoutePoint(ctx, id)
+}
+
+// ImportPurchaseOrders adds every row as a pending route point in a single
+// transaction. Nothing is imported if any row is invalid, in which case the
+// result reports the problems along with ErrInvalidImport. A dry run only
+// reports the problems.
+func (s *service) ImportPurchaseOrders(ctx context.Context, rows []ImportRow, options ImportOptions) (*ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "route_point.ImportPurchaseOrders")
+	defer span.End()
+
+	result, err := CheckImport(ctx, s.purchaseOrders, s.locator, s.zones, rows, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 && !options.DryRun {
+		return result, ErrInvalidImport
+	}
+	if options.DryRun {
+		return result, nil
+	}
+
+	newPoints := newRoutePoints(rows)
+	events, err := createdEvents(newPoints...)
+	if err != nil {
+		return nil, err
+	}
+	routePoints, err := s.repository.CreateRoutePoints(ctx, newPoints, events...)
+	if err != nil {
+		return nil, err
+	}
+	result.RoutePoints = routePoints
+	return result, nil
+}
+
+// checkRoute makes sure a route exists, returning ErrRouteNotFound otherwise.
+// No check is made without routes.
+func (s *service) checkRoute(ctx context.Context, routeID uuid.UUID) error {
+	if s.routes == nil {
+		return nil
+	}
+	exists, err := s.routes.RouteExists(ctx, routeID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRouteNotFound
+	}
+	return nil
 }
 
 // static functions
 
-func NewService(repository *Repository) *service {
-	return &service{repository: repository}
+// NewService creates the route point service. purchaseOrders may be nil, in
+// which case imports cannot be verified, and so may locator, in which case
+// purchase orders need both an address and coordinates, zones, in which case
+// route points are not checked against the zone of their route, and routes,
+// in which case the routes they are moved to are not checked to exist.
+func NewService(repository Repository, purchaseOrders purchaseOrder.Client, locator *Locator, zones *ZoneCheck, routes Routes) *service {
+	return &service{repository: repository, purchaseOrders: purchaseOrders, locator: locator, zones: zones, routes: routes}
+}
+
+// createdEvents describes the creation of the route points.
+func createdEvents(routePoints ...*RoutePoint) ([]outbox.Event, error) {
+	events := make([]outbox.Event, len(routePoints))
+	for i, routePoint := range routePoints {
+		event, err := NewEvent(outbox.EventRoutePointCreated, "", routePoint)
+		if err != nil {
+			return nil, err
+		}
+		events[i] = event
+	}
+	return events, nil
+}
+
+// newRoutePoint creates a pending route point for a purchase order at its placement.
+func newRoutePoint(addPurchaseOrder *AddPurchaseOrder, placement *Placement) *RoutePoint {
+	routeID := addPurchaseOrder.RouteID
+	return &RoutePoint{
+		ID:               uuid.New(),
+		RouteID:          &routeID,
+		PurchaseOrderID:  addPurchaseOrder.PurchaseOrderID,
+		Latitude:         placement.Latitude,
+		Longitude:        placement.Longitude,
+		Address:          placement.Address,
+		GeocodeDistance:  placement.GeocodeDistance,
+		LocationMismatch: placement.LocationMismatch,
+		OutsideZone:      placement.OutsideZone,
+		Status:           RoutePointStatusList[RoutePointStatusPending],
+
+		DeliveryWindowStart: addPurchaseOrder.DeliveryWindowStart,
+		DeliveryWindowEnd:   addPurchaseOrder.DeliveryWindowEnd,
+	}
 }
@@ -1,32 +1,43 @@
 package routePoint
 
 import (
+	"challenge-fravega/internal/geocoder"
+	"challenge-fravega/internal/outbox"
+	purchaseOrder "challenge-fravega/internal/purchase-order"
+	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-// Define a repository interface that our mock can implement
-type RepositoryInterface interface {
-	CreateRoutePoint(routePoint *RoutePoint) (*RoutePoint, error)
-	GetRoutePoint(id string) (*RoutePoint, error)
-	GetRoutePoints() ([]RoutePoint, error)
-}
-
 // Define a mock repository for testing the service
 type MockRepository struct {
 	mock.Mock
+	// events are those recorded along with the changes
+	events []outbox.Event
 }
 
-func (m *MockRepository) CreateRoutePoint(routePoint *RoutePoint) (*RoutePoint, error) {
-	args := m.Called(routePoint)
+func (m *MockRepository) CreateRoutePoint(ctx context.Context, routePoint *RoutePoint, events ...outbox.Event) (*RoutePoint, error) {
+	m.events = append(m.events, events...)
+	args := m.Called(ctx, routePoint)
 	return args.Get(0).(*RoutePoint), args.Error(1)
 }
 
-func (m *MockRepository) GetRoutePoint(id string) (*RoutePoint, error) {
+func (m *MockRepository) CreateRoutePoints(ctx context.Context, routePoints []*RoutePoint, events ...outbox.Event) ([]RoutePoint, error) {
+	m.events = append(m.events, events...)
+	args := m.Called(ctx, routePoints)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]RoutePoint), args.Error(1)
+}
+
+func (m *MockRepository) GetRoutePoint(ctx context.Context, id string) (*RoutePoint, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -34,38 +45,83 @@ func (m *MockRepository) GetRoutePoint(id string) (*RoutePoint, error) {
 	return args.Get(0).(*RoutePoint), args.Error(1)
 }
 
-func (m *MockRepository) GetRoutePoints() ([]RoutePoint, error) {
+func (m *MockRepository) GetRoutePoints(ctx context.Context) ([]RoutePoint, error) {
 	args := m.Called()
 	return args.Get(0).([]RoutePoint), args.Error(1)
 }
 
-// Create a custom service for testing
-type testService struct {
-	repo RepositoryInterface
+func (m *MockRepository) GetUnassignedRoutePoints(ctx context.Context, date string) ([]RoutePoint, error) {
+	args := m.Called(date)
+	return args.Get(0).([]RoutePoint), args.Error(1)
 }
 
-func (s *testService) CreateRoutePoint(addPurchaseOrder *AddPurchaseOrder) (*RoutePoint, error) {
-	routePoint := &RoutePoint{
-		RouteID:         addPurchaseOrder.RouteID,
-		PurchaseOrderID: addPurchaseOrder.PurchaseOrderID,
-		Latitude:        addPurchaseOrder.Latitude,
-		Longitude:       addPurchaseOrder.Longitude,
-		Address:         addPurchaseOrder.Address,
-		Status:          RoutePointStatusList[RoutePointStatusPending],
+func (m *MockRepository) GetChangedRoutePoints(ctx context.Context, since time.Time, afterID uuid.UUID, limit int) ([]RoutePoint, error) {
+	args := m.Called(since, afterID, limit)
+	return args.Get(0).([]RoutePoint), args.Error(1)
+}
+
+func (m *MockRepository) UpdateRoutePoint(ctx context.Context, routePoint *RoutePoint, version int, events ...outbox.Event) (*RoutePoint, error) {
+	m.events = append(m.events, events...)
+	args := m.Called(ctx, routePoint, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RoutePoint), args.Error(1)
+}
+
+// Define a purchase order client that knows a fixed set of purchase orders
+type fakePurchaseOrders struct {
+	known map[string]bool
+	err   error
+}
+
+func (f *fakePurchaseOrders) GetPurchaseOrder(ctx context.Context, id string) (*purchaseOrder.PurchaseOrder, error) {
+	if f.err != nil {
+		return nil, f.err
 	}
-	return s.repo.CreateRoutePoint(routePoint)
+	if !f.known[id] {
+		return nil, purchaseOrder.ErrNotFound
+	}
+	return &purchaseOrder.PurchaseOrder{ID: id}, nil
+}
+
+// testLocator geocodes from a couple of known addresses in Buenos Aires
+func testLocator() *Locator {
+	return NewLocator(geocoder.NewFileProvider([]geocoder.Location{
+		{Address: "Av. Corrientes 1234, Buenos Aires", Latitude: -34.6037, Longitude: -58.3816},
+		{Address: "Av. Santa Fe 3253, Buenos Aires", Latitude: -34.5881, Longitude: -58.4106},
+	}), DefaultMismatchThreshold)
+}
+
+// Define route zones where only routes in zoned have a zone, south of -34.6
+type fakeRouteZones struct {
+	zoned map[uuid.UUID]bool
+}
+
+func (f *fakeRouteZones) InZone(ctx context.Context, routeID uuid.UUID, latitude float64, longitude float64) (bool, error) {
+	return !f.zoned[routeID] || latitude < -34.6, nil
+}
+
+// Define routes where only those in known exist
+type fakeRoutes struct {
+	known map[uuid.UUID]bool
+}
+
+func (f *fakeRoutes) RouteExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return f.known[id], nil
 }
 
-func (s *testService) GetRoutePoint(id string) (*RoutePoint, error) {
-	return s.repo.GetRoutePoint(id)
+func float(value float64) *float64 {
+	return &value
 }
 
-func (s *testService) GetRoutePoints() ([]RoutePoint, error) {
-	return s.repo.GetRoutePoints()
+func newID() *uuid.UUID {
+	id := uuid.New()
+	return &id
 }
 
 func createTestService(mockRepo *MockRepository) Service {
-	return &testService{repo: mockRepo}
+	return NewService(mockRepo, nil, nil, nil, nil)
 }
 
 func TestCreateRoutePoint(t *testing.T) {
@@ -77,14 +133,14 @@ func TestCreateRoutePoint(t *testing.T) {
 	addPurchaseOrder := &AddPurchaseOrder{
 		RouteID:         routeID,
 		PurchaseOrderID: "PO12345",
-		Latitude:        37.7749,
-		Longitude:       -122.4194,
+		Latitude:        float(37.7749),
+		Longitude:       float(-122.4194),
 		Address:         "123 Test St",
 	}
 
 	// The route point that will be created in the service
 	expectedRoutePoint := &RoutePoint{
-		RouteID:         routeID,
+		RouteID:         &routeID,
 		PurchaseOrderID: "PO12345",
 		Latitude:        37.7749,
 		Longitude:       -122.4194,
@@ -95,7 +151,7 @@ func TestCreateRoutePoint(t *testing.T) {
 	// The route point that will be returned by the repository
 	createdRoutePoint := &RoutePoint{
 		ID:              uuid.New(),
-		RouteID:         routeID,
+		RouteID:         &routeID,
 		PurchaseOrderID: "PO12345",
 		Latitude:        37.7749,
 		Longitude:       -122.4194,
@@ -104,8 +160,8 @@ func TestCreateRoutePoint(t *testing.T) {
 	}
 
 	// Mock the repository call
-	mockRepo.On("CreateRoutePoint", mock.MatchedBy(func(rp *RoutePoint) bool {
-		return rp.RouteID == expectedRoutePoint.RouteID &&
+	mockRepo.On("CreateRoutePoint", mock.Anything, mock.MatchedBy(func(rp *RoutePoint) bool {
+		return *rp.RouteID == *expectedRoutePoint.RouteID &&
 			rp.PurchaseOrderID == expectedRoutePoint.PurchaseOrderID &&
 			rp.Latitude == expectedRoutePoint.Latitude &&
 			rp.Longitude == expectedRoutePoint.Longitude &&
@@ -114,15 +170,15 @@ func TestCreateRoutePoint(t *testing.T) {
 	})).Return(createdRoutePoint, nil)
 
 	// Act
-	result, err := service.CreateRoutePoint(addPurchaseOrder)
+	result, err := service.CreateRoutePoint(context.Background(), addPurchaseOrder)
 
 	// Assert
 	assert.NoError(t, err)
 	assert.Equal(t, createdRoutePoint.ID, result.ID)
-	assert.Equal(t, addPurchaseOrder.RouteID, result.RouteID)
+	assert.Equal(t, addPurchaseOrder.RouteID, *result.RouteID)
 	assert.Equal(t, addPurchaseOrder.PurchaseOrderID, result.PurchaseOrderID)
-	assert.Equal(t, addPurchaseOrder.Latitude, result.Latitude)
-	assert.Equal(t, addPurchaseOrder.Longitude, result.Longitude)
+	assert.Equal(t, *addPurchaseOrder.Latitude, result.Latitude)
+	assert.Equal(t, *addPurchaseOrder.Longitude, result.Longitude)
 	assert.Equal(t, addPurchaseOrder.Address, result.Address)
 	assert.Equal(t, RoutePointStatusList[RoutePointStatusPending], result.Status)
 	mockRepo.AssertExpectations(t)
@@ -137,7 +193,7 @@ func TestGetRoutePoint(t *testing.T) {
 	routeID := uuid.New()
 	expectedRoutePoint := &RoutePoint{
 		ID:              routePointID,
-		RouteID:         routeID,
+		RouteID:         &routeID,
 		PurchaseOrderID: "PO12345",
 		Latitude:        37.7749,
 		Longitude:       -122.4194,
@@ -148,12 +204,12 @@ func TestGetRoutePoint(t *testing.T) {
 	mockRepo.On("GetRoutePoint", routePointID.String()).Return(expectedRoutePoint, nil)
 
 	// Act
-	result, err := service.GetRoutePoint(routePointID.String())
+	result, err := service.GetRoutePoint(context.Background(), routePointID.String())
 
 	// Assert
 	assert.NoError(t, err)
 	assert.Equal(t, routePointID, result.ID)
-	assert.Equal(t, routeID, result.RouteID)
+	assert.Equal(t, routeID, *result.RouteID)
 	assert.Equal(t, "PO12345", result.PurchaseOrderID)
 	assert.Equal(t, 37.7749, result.Latitude)
 	assert.Equal(t, -122.4194, result.Longitude)
@@ -173,7 +229,7 @@ func TestGetRoutePointNotFound(t *testing.T) {
 	mockRepo.On("GetRoutePoint", routePointID.String()).Return((*RoutePoint)(nil), expectedError)
 
 	// Act
-	_, err := service.GetRoutePoint(routePointID.String())
+	_, err := service.GetRoutePoint(context.Background(), routePointID.String())
 
 	// Assert
 	assert.Error(t, err)
@@ -190,7 +246,7 @@ func TestGetRoutePoints(t *testing.T) {
 	expectedRoutePoints := []RoutePoint{
 		{
 			ID:              uuid.New(),
-			RouteID:         routeID,
+			RouteID:         &routeID,
 			PurchaseOrderID: "PO12345",
 			Latitude:        37.7749,
 			Longitude:       -122.4194,
@@ -199,7 +255,7 @@ func TestGetRoutePoints(t *testing.T) {
 		},
 		{
 			ID:              uuid.New(),
-			RouteID:         routeID,
+			RouteID:         &routeID,
 			PurchaseOrderID: "PO67890",
 			Latitude:        34.0522,
 			Longitude:       -118.2437,
@@ -211,7 +267,7 @@ func TestGetRoutePoints(t *testing.T) {
 	mockRepo.On("GetRoutePoints").Return(expectedRoutePoints, nil)
 
 	// Act
-	results, err := service.GetRoutePoints()
+	results, err := service.GetRoutePoints(context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -220,3 +276,597 @@ func TestGetRoutePoints(t *testing.T) {
 	assert.Equal(t, expectedRoutePoints[1].PurchaseOrderID, results[1].PurchaseOrderID)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestUpdateRoutePointReassign(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	routePointID := uuid.New()
+	newRouteID := uuid.New()
+	current := &RoutePoint{
+		ID:      routePointID,
+		RouteID: newID(),
+		Status:  RoutePointStatusList[RoutePointStatusPending],
+		Version: 2,
+	}
+	updated := &RoutePoint{
+		ID:      routePointID,
+		RouteID: &newRouteID,
+		Status:  RoutePointStatusList[RoutePointStatusPending],
+		Version: 3,
+	}
+
+	mockRepo.On("GetRoutePoint", routePointID.String()).Return(current, nil).Once()
+	mockRepo.On("UpdateRoutePoint", mock.Anything, mock.MatchedBy(func(rp *RoutePoint) bool {
+		return *rp.RouteID == newRouteID
+	}), 2).Return(updated, nil)
+	mockRepo.On("GetRoutePoint", routePointID.String()).Return(updated, nil).Once()
+
+	// Act
+	result, err := service.UpdateRoutePoint(context.Background(), routePointID.String(), 2, &UpdateRoutePoint{RouteID: &newRouteID})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, newRouteID, *result.RouteID)
+	assert.Equal(t, 3, result.Version)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateRoutePointUnknownRoute(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	currentRouteID := uuid.New()
+	service := NewService(mockRepo, nil, nil, nil, &fakeRoutes{known: map[uuid.UUID]bool{currentRouteID: true}})
+
+	routePointID := uuid.New()
+	mockRepo.On("GetRoutePoint", routePointID.String()).Return(&RoutePoint{
+		ID:      routePointID,
+		RouteID: &currentRouteID,
+		Status:  RoutePointStatusList[RoutePointStatusPending],
+		Version: 2,
+	}, nil)
+
+	// Act
+	_, err := service.UpdateRoutePoint(context.Background(), routePointID.String(), 2, &UpdateRoutePoint{RouteID: newID()})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrRouteNotFound)
+	mockRepo.AssertNotCalled(t, "UpdateRoutePoint", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateRoutePointVersionConflict(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	routePointID := uuid.New()
+	mockRepo.On("GetRoutePoint", routePointID.String()).Return(&RoutePoint{ID: routePointID, Version: 2}, nil)
+
+	// Act
+	_, err := service.UpdateRoutePoint(context.Background(), routePointID.String(), 1, &UpdateRoutePoint{})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	mockRepo.AssertNotCalled(t, "UpdateRoutePoint", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateRoutePointInvalidStatus(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	routePointID := uuid.New()
+	status := "lost"
+	mockRepo.On("GetRoutePoint", routePointID.String()).Return(&RoutePoint{
+		ID:      routePointID,
+		Status:  RoutePointStatusList[RoutePointStatusPending],
+		Version: 1,
+	}, nil)
+
+	// Act
+	_, err := service.UpdateRoutePoint(context.Background(), routePointID.String(), 1, &UpdateRoutePoint{Status: &status})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+}
+
+func TestUpdateRoutePointInvalidDeliveryWindow(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	start := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+	end := start.Add(-time.Hour)
+	existing := &RoutePoint{ID: uuid.New(), Status: RoutePointStatusList[RoutePointStatusPending], Version: 1, DeliveryWindowStart: &start}
+	mockRepo.On("GetRoutePoint", existing.ID.String()).Return(existing, nil)
+
+	// Act
+	result, err := service.UpdateRoutePoint(context.Background(), existing.ID.String(), 1, &UpdateRoutePoint{DeliveryWindowEnd: &end})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrInvalidDeliveryWindow)
+	mockRepo.AssertNotCalled(t, "UpdateRoutePoint")
+}
+
+func TestCreateRoutePointOutOfRangeCoordinates(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	// Act
+	latitudeResult, latitudeErr := service.CreateRoutePoint(context.Background(), &AddPurchaseOrder{
+		Latitude: float(91), Longitude: float(0), Address: "Av. Corrientes 1234, Buenos Aires",
+	})
+	_, longitudeErr := service.CreateRoutePoint(context.Background(), &AddPurchaseOrder{
+		Latitude: float(0), Longitude: float(200), Address: "Av. Corrientes 1234, Buenos Aires",
+	})
+
+	// Assert
+	assert.Nil(t, latitudeResult)
+	assert.ErrorIs(t, latitudeErr, ErrInvalidCoordinates)
+	assert.ErrorIs(t, longitudeErr, ErrInvalidCoordinates)
+	mockRepo.AssertNotCalled(t, "CreateRoutePoint")
+}
+
+func TestCreateRoutePointInvalid(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	start := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+	end := start.Add(-time.Hour)
+
+	// Act
+	result, err := service.CreateRoutePoint(context.Background(), &AddPurchaseOrder{
+		PurchaseOrderID:     "PO-1",
+		Latitude:            float(-34.6),
+		Longitude:           float(-58.4),
+		DeliveryWindowStart: &start,
+		DeliveryWindowEnd:   &end,
+	})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrInvalidDeliveryWindow)
+	mockRepo.AssertNotCalled(t, "CreateRoutePoint")
+}
+
+func importRows(routeID uuid.UUID, purchaseOrderIDs ...string) []ImportRow {
+	rows := make([]ImportRow, len(purchaseOrderIDs))
+	for i, id := range purchaseOrderIDs {
+		rows[i] = ImportRow{AddPurchaseOrder: AddPurchaseOrder{
+			RouteID:         routeID,
+			PurchaseOrderID: id,
+			Latitude:        float(-34.6037),
+			Longitude:       float(-58.3816),
+			Address:         "Av. Corrientes 1234",
+		}}
+	}
+	return rows
+}
+
+func TestImportPurchaseOrders(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, &fakePurchaseOrders{known: map[string]bool{"PO1": true, "PO2": true}}, nil, nil, nil)
+	routeID := uuid.New()
+	created := []RoutePoint{{ID: uuid.New(), PurchaseOrderID: "PO1"}, {ID: uuid.New(), PurchaseOrderID: "PO2"}}
+
+	mockRepo.On("CreateRoutePoints", mock.Anything, mock.MatchedBy(func(routePoints []*RoutePoint) bool {
+		return len(routePoints) == 2 &&
+			routePoints[0].PurchaseOrderID == "PO1" &&
+			*routePoints[1].RouteID == routeID &&
+			routePoints[1].Status == RoutePointStatusList[RoutePointStatusPending]
+	})).Return(created, nil)
+
+	// Act
+	result, err := service.ImportPurchaseOrders(context.Background(), importRows(routeID, "PO1", "PO2"), ImportOptions{Verify: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, 2, result.Valid)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, created, result.RoutePoints)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportPurchaseOrdersInvalidRows(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, &fakePurchaseOrders{known: map[string]bool{"PO1": true}}, nil, nil, nil)
+	rows := importRows(uuid.New(), "PO1", "PO1", "PO404", "")
+
+	// Act
+	result, err := service.ImportPurchaseOrders(context.Background(), rows, ImportOptions{Verify: true})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidImport)
+	assert.Equal(t, 4, result.Total)
+	assert.Equal(t, 1, result.Valid)
+	assert.Equal(t, []RowError{
+		{Row: 2, PurchaseOrderID: "PO1", Errors: []string{"purchase_order_id is already in row 1"}},
+		{Row: 3, PurchaseOrderID: "PO404", Errors: []string{"purchase order does not exist"}},
+		{Row: 4, PurchaseOrderID: "", Errors: []string{"purchase_order_id is required"}},
+	}, result.Errors)
+	mockRepo.AssertNotCalled(t, "CreateRoutePoints")
+}
+
+func TestImportPurchaseOrdersDryRun(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	rows := importRows(uuid.New(), "PO1", "PO2")
+	rows[1].Longitude = float(200)
+
+	// Act
+	result, err := service.ImportPurchaseOrders(context.Background(), rows, ImportOptions{DryRun: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 1, result.Valid)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, 2, result.Errors[0].Row)
+	assert.Empty(t, result.RoutePoints)
+	mockRepo.AssertNotCalled(t, "CreateRoutePoints")
+}
+
+func TestImportPurchaseOrdersVerificationFailed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, &fakePurchaseOrders{err: purchaseOrder.ErrUnauthorized}, nil, nil, nil)
+
+	// Act
+	result, err := service.ImportPurchaseOrders(context.Background(), importRows(uuid.New(), "PO1"), ImportOptions{Verify: true})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+	mockRepo.AssertNotCalled(t, "CreateRoutePoints")
+}
+
+func TestImportPurchaseOrdersVerificationUnavailable(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	// Act
+	_, err := service.ImportPurchaseOrders(context.Background(), importRows(uuid.New(), "PO1"), ImportOptions{Verify: true})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrVerificationUnavailable)
+}
+
+func TestCreateRoutePointGeocodesAddress(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, testLocator(), nil, nil)
+	addPurchaseOrder := &AddPurchaseOrder{RouteID: uuid.New(), PurchaseOrderID: "PO1", Address: "av corrientes 1234, buenos aires"}
+
+	mockRepo.On("CreateRoutePoint", mock.Anything, mock.Anything).Return(&RoutePoint{}, nil)
+
+	// Act
+	_, err := service.CreateRoutePoint(context.Background(), addPurchaseOrder)
+
+	// Assert
+	assert.NoError(t, err)
+	created := mockRepo.Calls[0].Arguments.Get(1).(*RoutePoint)
+	assert.Equal(t, -34.6037, created.Latitude)
+	assert.Equal(t, -58.3816, created.Longitude)
+	assert.Equal(t, "av corrientes 1234, buenos aires", created.Address)
+	assert.Nil(t, created.GeocodeDistance)
+}
+
+func TestCreateRoutePointReverseGeocodesCoordinates(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, testLocator(), nil, nil)
+	addPurchaseOrder := &AddPurchaseOrder{RouteID: uuid.New(), PurchaseOrderID: "PO1", Latitude: float(-34.5882), Longitude: float(-58.4105)}
+
+	mockRepo.On("CreateRoutePoint", mock.Anything, mock.Anything).Return(&RoutePoint{}, nil)
+
+	// Act
+	_, err := service.CreateRoutePoint(context.Background(), addPurchaseOrder)
+
+	// Assert
+	assert.NoError(t, err)
+	created := mockRepo.Calls[0].Arguments.Get(1).(*RoutePoint)
+	assert.Equal(t, "Av. Santa Fe 3253, Buenos Aires", created.Address)
+	assert.Equal(t, -34.5882, created.Latitude)
+}
+
+func TestCreateRoutePointFlagsLocationMismatch(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, testLocator(), nil, nil)
+	// The coordinates of Av. Santa Fe 3253, about 3 km away
+	addPurchaseOrder := &AddPurchaseOrder{
+		RouteID:         uuid.New(),
+		PurchaseOrderID: "PO1",
+		Latitude:        float(-34.5881),
+		Longitude:       float(-58.4106),
+		Address:         "Av. Corrientes 1234, Buenos Aires",
+	}
+
+	mockRepo.On("CreateRoutePoint", mock.Anything, mock.Anything).Return(&RoutePoint{}, nil)
+
+	// Act
+	_, err := service.CreateRoutePoint(context.Background(), addPurchaseOrder)
+
+	// Assert
+	assert.NoError(t, err)
+	created := mockRepo.Calls[0].Arguments.Get(1).(*RoutePoint)
+	assert.True(t, created.LocationMismatch)
+	assert.InDelta(t, 3200, *created.GeocodeDistance, 300)
+	assert.Equal(t, -34.5881, created.Latitude)
+}
+
+func TestCreateRoutePointAddressNotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, testLocator(), nil, nil)
+
+	// Act
+	_, err := service.CreateRoutePoint(context.Background(), &AddPurchaseOrder{RouteID: uuid.New(), PurchaseOrderID: "PO1", Address: "Nowhere 1"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrAddressNotFound)
+	mockRepo.AssertNotCalled(t, "CreateRoutePoint")
+}
+
+func TestCreateRoutePointWithoutGeocoder(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	// Act
+	_, err := service.CreateRoutePoint(context.Background(), &AddPurchaseOrder{RouteID: uuid.New(), PurchaseOrderID: "PO1", Address: "Av. Corrientes 1234"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrLocationRequired)
+	mockRepo.AssertNotCalled(t, "CreateRoutePoint")
+}
+
+func TestImportPurchaseOrdersGeocodes(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, testLocator(), nil, nil)
+	rows := importRows(uuid.New(), "PO1", "PO2")
+	rows[0].Latitude, rows[0].Longitude = nil, nil
+	rows[0].Address = "Av. Santa Fe 3253, Buenos Aires"
+	rows[1].Latitude, rows[1].Longitude = nil, nil
+	rows[1].Address = "Nowhere 1"
+
+	// Act
+	result, err := service.ImportPurchaseOrders(context.Background(), rows, ImportOptions{DryRun: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Valid)
+	assert.Equal(t, []RowError{{Row: 2, PurchaseOrderID: "PO2", Errors: []string{ErrAddressNotFound.Error()}}}, result.Errors)
+	assert.Equal(t, -34.5881, rows[0].placement.Latitude)
+}
+
+func TestCreateRoutePointOutsideZoneWarns(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	routeID := uuid.New()
+	service := NewService(mockRepo, nil, nil, NewZoneCheck(&fakeRouteZones{zoned: map[uuid.UUID]bool{routeID: true}}, ZonePolicyWarn), nil)
+
+	mockRepo.On("CreateRoutePoint", mock.Anything, mock.MatchedBy(func(rp *RoutePoint) bool {
+		return rp.OutsideZone
+	})).Return(&RoutePoint{OutsideZone: true}, nil)
+
+	// Act
+	result, err := service.CreateRoutePoint(context.Background(), &AddPurchaseOrder{
+		RouteID:         routeID,
+		PurchaseOrderID: "PO1",
+		Latitude:        float(-34.5881),
+		Longitude:       float(-58.4106),
+		Address:         "Av. Santa Fe 3253",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, result.OutsideZone)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateRoutePointOutsideZoneRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	routeID := uuid.New()
+	service := NewService(mockRepo, nil, nil, NewZoneCheck(&fakeRouteZones{zoned: map[uuid.UUID]bool{routeID: true}}, ZonePolicyReject), nil)
+
+	// Act
+	_, err := service.CreateRoutePoint(context.Background(), &AddPurchaseOrder{
+		RouteID:         routeID,
+		PurchaseOrderID: "PO1",
+		Latitude:        float(-34.5881),
+		Longitude:       float(-58.4106),
+		Address:         "Av. Santa Fe 3253",
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrOutsideZone)
+	mockRepo.AssertNotCalled(t, "CreateRoutePoint")
+}
+
+func TestImportPurchaseOrdersOutsideZone(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	routeID := uuid.New()
+	service := NewService(mockRepo, nil, nil, NewZoneCheck(&fakeRouteZones{zoned: map[uuid.UUID]bool{routeID: true}}, ZonePolicyReject), nil)
+	rows := importRows(routeID, "PO1", "PO2")
+	rows[1].Latitude = float(-34.5881)
+
+	// Act
+	result, err := service.ImportPurchaseOrders(context.Background(), rows, ImportOptions{DryRun: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Valid)
+	assert.Equal(t, []RowError{{Row: 2, PurchaseOrderID: "PO2", Errors: []string{ErrOutsideZone.Error()}}}, result.Errors)
+}
+
+func TestUpdateRoutePointRechecksZone(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	zonedRouteID := uuid.New()
+	service := NewService(mockRepo, nil, nil, NewZoneCheck(&fakeRouteZones{zoned: map[uuid.UUID]bool{zonedRouteID: true}}, ZonePolicyWarn), nil)
+	existing := &RoutePoint{ID: uuid.New(), RouteID: newID(), Latitude: -34.5881, Status: RoutePointStatusList[RoutePointStatusPending], Version: 1}
+
+	mockRepo.On("GetRoutePoint", existing.ID.String()).Return(existing, nil)
+	mockRepo.On("UpdateRoutePoint", mock.Anything, mock.MatchedBy(func(rp *RoutePoint) bool {
+		return rp.OutsideZone
+	}), 1).Return(existing, nil)
+
+	// Act
+	_, err := service.UpdateRoutePoint(context.Background(), existing.ID.String(), 1, &UpdateRoutePoint{RouteID: &zonedRouteID})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateRoutePointRecordsDeliveryTimes(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	existing := &RoutePoint{ID: uuid.New(), RouteID: newID(), Status: RoutePointStatusList[RoutePointStatusArrived], Version: 1}
+	failed := "failed"
+
+	mockRepo.On("GetRoutePoint", existing.ID.String()).Return(existing, nil)
+	mockRepo.On("UpdateRoutePoint", mock.Anything, mock.MatchedBy(func(rp *RoutePoint) bool {
+		return rp.Status == "failed" && rp.CompletedAt != nil
+	}), 1).Return(existing, nil)
+
+	// Act
+	_, err := service.UpdateRoutePoint(context.Background(), existing.ID.String(), 1, &UpdateRoutePoint{Status: &failed})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateRoutePointUnassigned(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	poolDate := "2025-03-11"
+	existing := &RoutePoint{ID: uuid.New(), PoolDate: &poolDate, Status: RoutePointStatusList[RoutePointStatusPending], Version: 1}
+	inRoute := "in_route"
+	mockRepo.On("GetRoutePoint", existing.ID.String()).Return(existing, nil)
+
+	// Act
+	_, err := service.UpdateRoutePoint(context.Background(), existing.ID.String(), 1, &UpdateRoutePoint{Status: &inRoute})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrUnassigned)
+	mockRepo.AssertNotCalled(t, "UpdateRoutePoint", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateRoutePointAssignsFromPool(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	poolDate := "2025-03-11"
+	existing := &RoutePoint{ID: uuid.New(), PoolDate: &poolDate, Status: RoutePointStatusList[RoutePointStatusPending], Version: 1}
+	routeID := uuid.New()
+
+	mockRepo.On("GetRoutePoint", existing.ID.String()).Return(existing, nil)
+	mockRepo.On("UpdateRoutePoint", mock.Anything, mock.MatchedBy(func(rp *RoutePoint) bool {
+		return rp.RouteID != nil && *rp.RouteID == routeID && rp.PoolDate == nil
+	}), 1).Return(existing, nil)
+
+	// Act
+	_, err := service.UpdateRoutePoint(context.Background(), existing.ID.String(), 1, &UpdateRoutePoint{RouteID: &routeID})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetUnassignedRoutePoints(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	poolDate := "2025-03-11"
+	mockRepo.On("GetUnassignedRoutePoints", "2025-03-11").Return([]RoutePoint{{ID: uuid.New(), PoolDate: &poolDate}}, nil)
+
+	// Act
+	result, err := service.GetUnassignedRoutePoints(context.Background(), "2025-03-11")
+	_, invalidErr := service.GetUnassignedRoutePoints(context.Background(), "tomorrow")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.ErrorIs(t, invalidErr, ErrInvalidPoolDate)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateRoutePointRecordsEvent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	existing := &RoutePoint{ID: uuid.New(), RouteID: newID(), PurchaseOrderID: "PO1", Status: RoutePointStatusList[RoutePointStatusPending], Version: 1}
+	inRoute := "in_route"
+	mockRepo.On("GetRoutePoint", existing.ID.String()).Return(existing, nil)
+	mockRepo.On("UpdateRoutePoint", mock.Anything, existing, 1).Return(existing, nil)
+
+	// Act
+	_, err := service.UpdateRoutePoint(context.Background(), existing.ID.String(), 1, &UpdateRoutePoint{Status: &inRoute})
+	_, sameErr := service.UpdateRoutePoint(context.Background(), existing.ID.String(), 1, &UpdateRoutePoint{Status: &inRoute})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, sameErr)
+	assert.Len(t, mockRepo.events, 2)
+	assert.Equal(t, "route_point.status_changed", mockRepo.events[0].Type)
+	assert.Equal(t, "route_point", mockRepo.events[0].AggregateType)
+	assert.Equal(t, existing.ID, mockRepo.events[0].AggregateID)
+	data := EventData{}
+	assert.NoError(t, json.Unmarshal(mockRepo.events[0].Payload, &data))
+	assert.Equal(t, "pending", data.PreviousStatus)
+	assert.Equal(t, "in_route", data.Status)
+	assert.Equal(t, "PO1", data.PurchaseOrderID)
+	assert.Equal(t, "route_point.updated", mockRepo.events[1].Type)
+}
+
+func TestRoutePointLifecycle(t *testing.T) {
+	// Arrange
+	repository := NewFakeRepository()
+	service := NewService(repository, nil, nil, nil, nil)
+	latitude, longitude := -34.6037, -58.3816
+	created, err := service.CreateRoutePoint(context.Background(), &AddPurchaseOrder{
+		RouteID: uuid.New(), PurchaseOrderID: "PO1", Latitude: &latitude, Longitude: &longitude, Address: "Av. Corrientes 1234",
+	})
+	assert.NoError(t, err)
+	inRoute, arrived, completed, failed := "in_route", "arrived", "completed", "failed"
+
+	// Act
+	started, startErr := service.UpdateRoutePoint(context.Background(), created.ID.String(), 1, &UpdateRoutePoint{Status: &inRoute})
+	_, staleErr := service.UpdateRoutePoint(context.Background(), created.ID.String(), 1, &UpdateRoutePoint{Status: &arrived})
+	reached, arriveErr := service.UpdateRoutePoint(context.Background(), created.ID.String(), 2, &UpdateRoutePoint{Status: &arrived})
+	delivered, completeErr := service.UpdateRoutePoint(context.Background(), created.ID.String(), 3, &UpdateRoutePoint{Status: &completed})
+	_, transitionErr := service.UpdateRoutePoint(context.Background(), created.ID.String(), 4, &UpdateRoutePoint{Status: &failed})
+
+	// Assert
+	assert.NoError(t, startErr)
+	assert.NoError(t, arriveErr)
+	assert.NoError(t, completeErr)
+	assert.Equal(t, "in_route", started.Status)
+	assert.ErrorIs(t, staleErr, ErrVersionConflict)
+	assert.NotNil(t, reached.ArrivedAt)
+	assert.Equal(t, "completed", delivered.Status)
+	assert.Equal(t, 4, delivered.Version)
+	assert.NotNil(t, delivered.CompletedAt)
+	assert.ErrorIs(t, transitionErr, ErrInvalidStatusTransition)
+	var types []string
+	for _, event := range repository.Events {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{"route_point.created", "route_point.status_changed", "route_point.status_changed", "route_point.status_changed"}, types)
+}
@@ -0,0 +1,19 @@
+package routePoint
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UpdateRoutePoint holds the fields to change; nil fields are left untouched.
+type UpdateRoutePoint struct {
+	RouteID   *uuid.UUID `json:"route_id"`
+	Latitude  *float64   `json:"latitude"`
+	Longitude *float64   `json:"longitude"`
+	Address   *string    `json:"address"`
+	Status    *string    `json:"status"`
+
+	DeliveryWindowStart *time.Time `json:"delivery_window_start"`
+	DeliveryWindowEnd   *time.Time `json:"delivery_window_end"`
+}
@@ -0,0 +1,61 @@
+package routePoint
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var ErrOutsideZone = errors.New("location lies outside the zone of the route")
+
+// RouteZones tells whether a location lies within the zone of a route.
+type RouteZones interface {
+	InZone(ctx context.Context, routeID uuid.UUID, latitude float64, longitude float64) (bool, error)
+}
+
+// ZonePolicy is what happens to route points outside the zone of their route.
+type ZonePolicy string
+
+const (
+	// ZonePolicyWarn creates the route point flagged as outside the zone
+	ZonePolicyWarn ZonePolicy = "warn"
+	// ZonePolicyReject refuses the route point with ErrOutsideZone
+	ZonePolicyReject ZonePolicy = "reject"
+)
+
+var ZonePolicyList = map[ZonePolicy]string{
+	ZonePolicyWarn:   "warn",
+	ZonePolicyReject: "reject",
+}
+
+// ZoneCheck applies a ZonePolicy to the location of route points.
+type ZoneCheck struct {
+	routes RouteZones
+	policy ZonePolicy
+}
+
+// Check tells whether a location lies outside the zone of a route, or returns
+// ErrOutsideZone if the policy rejects it. A nil check takes any location.
+func (z *ZoneCheck) Check(ctx context.Context, routeID uuid.UUID, latitude float64, longitude float64) (bool, error) {
+	if z == nil {
+		return false, nil
+	}
+	inside, err := z.routes.InZone(ctx, routeID, latitude, longitude)
+	if err != nil {
+		return false, err
+	}
+	if !inside && z.policy == ZonePolicyReject {
+		return false, ErrOutsideZone
+	}
+	return !inside, nil
+}
+
+// static functions
+
+func NewZoneCheck(routes RouteZones, policy ZonePolicy) *ZoneCheck {
+	if _, ok := ZonePolicyList[policy]; !ok {
+		policy = ZonePolicyWarn
+	}
+	return &ZoneCheck{routes: routes, policy: policy}
+}
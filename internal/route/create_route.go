@@ -2,9 +2,15 @@ package route
 
 import "github.com/google/uuid"
 
+// CreateRoute creates a route, optionally within a zone and starting from a
+// depot. The depot is picked from the zone when only the zone is given, and
+// the zone is the depot's when only the depot is.
 type CreateRoute struct {
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	VehicleId   uuid.UUID `json:"vehicle_id"`
-	DriverId    uuid.UUID `json:"driver_id"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	VehicleId     uuid.UUID  `json:"vehicle_id"`
+	DriverId      uuid.UUID  `json:"driver_id"`
+	ScheduledDate string     `json:"scheduled_date"`
+	ZoneId        *uuid.UUID `json:"zone_id"`
+	DepotId       *uuid.UUID `json:"depot_id"`
 }
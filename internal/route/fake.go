@@ -0,0 +1,121 @@
+package route
+
+import (
+	"challenge-fravega/internal/outbox"
+	"challenge-fravega/internal/vehicle"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FakeRepository is an in-memory Repository for the tests of the services
+// built on routes. Routes are returned without their vehicle, driver and
+// route points, and the events and odometer readings recorded with the
+// changes are kept in Events and Readings.
+type FakeRepository struct {
+	mu       sync.Mutex
+	routes   map[uuid.UUID]Route
+	ids      []uuid.UUID
+	Events   []outbox.Event
+	Readings []vehicle.OdometerReading
+}
+
+func (f *FakeRepository) CreateRoute(ctx context.Context, route *Route, events ...outbox.Event) (*Route, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if route.ID == uuid.Nil {
+		route.ID = uuid.New()
+	}
+	if route.Version == 0 {
+		route.Version = 1
+	}
+	if route.CreatedAt.IsZero() {
+		route.CreatedAt = time.Now()
+	}
+	if _, ok := f.routes[route.ID]; !ok {
+		f.ids = append(f.ids, route.ID)
+	}
+	f.routes[route.ID] = *route
+	f.Events = append(f.Events, events...)
+	return route, nil
+}
+
+func (f *FakeRepository) GetRoutes(ctx context.Context) ([]Route, error) {
+	return f.find(func(Route) bool { return true }), nil
+}
+
+func (f *FakeRepository) GetRoute(ctx context.Context, id string) (*Route, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	routeID, err := uuid.Parse(id)
+	if err != nil {
+		return &Route{}, gorm.ErrRecordNotFound
+	}
+	route, ok := f.routes[routeID]
+	if !ok {
+		return &Route{}, gorm.ErrRecordNotFound
+	}
+	return &route, nil
+}
+
+func (f *FakeRepository) GetRoutesByDate(ctx context.Context, date string) ([]Route, error) {
+	return f.find(func(route Route) bool { return route.ScheduledDate == date }), nil
+}
+
+func (f *FakeRepository) GetRouteZoneID(ctx context.Context, id uuid.UUID) (*uuid.UUID, error) {
+	route, err := f.GetRoute(ctx, id.String())
+	return route.ZoneID, err
+}
+
+func (f *FakeRepository) GetDrivingTime(ctx context.Context, driverID uuid.UUID, from time.Time, to time.Time) (time.Duration, error) {
+	routes := f.find(func(route Route) bool {
+		return route.DriverID == driverID && route.StartedAt != nil && route.StartedAt.Before(to) &&
+			(route.CompletedAt == nil || route.CompletedAt.After(from))
+	})
+	return drivingTime(routes, from, to, time.Now()), nil
+}
+
+func (f *FakeRepository) UpdateRoute(ctx context.Context, route *Route, version int, reading *vehicle.OdometerReading, events ...outbox.Event) (*Route, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.routes[route.ID]
+	if !ok || stored.Version != version {
+		return nil, ErrVersionConflict
+	}
+	route.Version = version + 1
+	f.routes[route.ID] = *route
+	if reading != nil {
+		f.Readings = append(f.Readings, *reading)
+	}
+	f.Events = append(f.Events, events...)
+	return route, nil
+}
+
+// find returns the routes that match, oldest first.
+func (f *FakeRepository) find(match func(Route) bool) []Route {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	routes := []Route{}
+	for _, id := range f.ids {
+		if route := f.routes[id]; match(route) {
+			routes = append(routes, route)
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool { return routes[i].CreatedAt.Before(routes[j].CreatedAt) })
+	return routes
+}
+
+// static functions
+
+// NewFakeRepository creates an in-memory repository holding the routes.
+func NewFakeRepository(routes ...Route) *FakeRepository {
+	f := &FakeRepository{routes: map[uuid.UUID]Route{}}
+	for _, route := range routes {
+		f.CreateRoute(context.Background(), &route)
+	}
+	return f
+}
@@ -1,36 +1,156 @@
 package route
 
 import (
+	"challenge-fravega/internal/outbox"
+	"challenge-fravega/internal/vehicle"
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type Repository struct {
+// Repository stores the routes, recording the outbox events of their changes.
+type Repository interface {
+	CreateRoute(ctx context.Context, route *Route, events ...outbox.Event) (*Route, error)
+	GetRoutes(ctx context.Context) ([]Route, error)
+	GetRoute(ctx context.Context, id string) (*Route, error)
+	GetRoutesByDate(ctx context.Context, date string) ([]Route, error)
+	GetRouteZoneID(ctx context.Context, id uuid.UUID) (*uuid.UUID, error)
+	GetDrivingTime(ctx context.Context, driverID uuid.UUID, from time.Time, to time.Time) (time.Duration, error)
+	UpdateRoute(ctx context.Context, route *Route, version int, reading *vehicle.OdometerReading, events ...outbox.Event) (*Route, error)
+}
+
+type repository struct {
 	db *gorm.DB
 }
 
-func (r *Repository) CreateRoute(route *Route) (*Route, error) {
+// CreateRoute creates the route, recording the events in the same transaction.
+func (r *repository) CreateRoute(ctx context.Context, route *Route, events ...outbox.Event) (*Route, error) {
 	if route.ID == uuid.Nil {
 		route.ID = uuid.New()
 	}
-	err := r.db.Create(route).Error
+	if route.Version == 0 {
+		route.Version = 1
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(route).Error; err != nil {
+			return err
+		}
+		return outbox.Append(tx, events...)
+	})
 	return route, err
 }
 
-func (r *Repository) GetRoutes() ([]Route, error) {
+func (r *repository) GetRoutes(ctx context.Context) ([]Route, error) {
 	var routes []Route
-	err := r.db.Preload("Vehicle").Preload("Driver").Preload("RoutePoints").Find(&routes).Error
+	err := r.db.WithContext(ctx).Preload("Vehicle").Preload("Driver").Preload("RoutePoints").Find(&routes).Error
 	return routes, err
 }
 
-func (r *Repository) GetRoute(id string) (*Route, error) {
+func (r *repository) GetRoute(ctx context.Context, id string) (*Route, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return &Route{}, gorm.ErrRecordNotFound
+	}
 	var route Route
-	err := r.db.Preload("Vehicle").Preload("Driver").Preload("RoutePoints").First(&route, "id = ?", id).Error
+	err := r.db.WithContext(ctx).Preload("Vehicle").Preload("Driver").Preload("RoutePoints").First(&route, "id = ?", id).Error
 	return &route, err
 }
 
+// GetRoutesByDate returns the routes scheduled for the given day.
+func (r *repository) GetRoutesByDate(ctx context.Context, date string) ([]Route, error) {
+	var routes []Route
+	err := r.db.WithContext(ctx).Preload("Vehicle").Preload("Driver").Preload("RoutePoints").
+		Where("scheduled_date = ?", date).Order("created_at").Find(&routes).Error
+	return routes, err
+}
+
+// GetRouteZoneID returns the zone of a route, nil if it has none.
+func (r *repository) GetRouteZoneID(ctx context.Context, id uuid.UUID) (*uuid.UUID, error) {
+	var route Route
+	err := r.db.WithContext(ctx).Select("zone_id").First(&route, "id = ?", id).Error
+	return route.ZoneID, err
+}
+
+// GetDrivingTime adds up how long a driver drove between from and to, taking
+// the time from start to completion of their routes, or until now for routes
+// still under way.
+func (r *repository) GetDrivingTime(ctx context.Context, driverID uuid.UUID, from time.Time, to time.Time) (time.Duration, error) {
+	var routes []Route
+	err := r.db.WithContext(ctx).Select("started_at", "completed_at").
+		Where("driver_id = ? AND started_at IS NOT NULL AND started_at < ?", driverID, to).
+		Where("completed_at IS NULL OR completed_at > ?", from).
+		Find(&routes).Error
+	if err != nil {
+		return 0, err
+	}
+	return drivingTime(routes, from, to, time.Now()), nil
+}
+
+// UpdateRoute saves the route only if it is still at the given version, and
+// increments it, recording the odometer reading, if any, and the events in the
+// same transaction. ErrVersionConflict is returned when the version changed.
+func (r *repository) UpdateRoute(ctx context.Context, route *Route, version int, reading *vehicle.OdometerReading, events ...outbox.Event) (*Route, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Route{}).
+			Where("id = ? AND version = ?", route.ID, version).
+			Updates(map[string]interface{}{
+				"name":           route.Name,
+				"description":    route.Description,
+				"status":         route.Status,
+				"vehicle_id":     route.VehicleID,
+				"driver_id":      route.DriverID,
+				"scheduled_date": route.ScheduledDate,
+				"started_at":     route.StartedAt,
+				"completed_at":   route.CompletedAt,
+				"needs_review":   route.NeedsReview,
+				"version":        gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		if reading != nil {
+			if err := vehicle.AppendOdometerReading(tx, reading); err != nil {
+				return err
+			}
+		}
+		return outbox.Append(tx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	route.Version = version + 1
+	return route, nil
+}
+
 // static functions
 
-func NewRepository(db *gorm.DB) *Repository {
-	return &Repository{db: db}
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
+
+// drivingTime adds up the time from start to completion of the routes within
+// from and to, until now for the routes still under way.
+func drivingTime(routes []Route, from time.Time, to time.Time, now time.Time) time.Duration {
+	var driven time.Duration
+	for _, route := range routes {
+		start, end := *route.StartedAt, now
+		if route.CompletedAt != nil {
+			end = *route.CompletedAt
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			driven += end.Sub(start)
+		}
+	}
+	return driven
 }
@@ -4,6 +4,7 @@ import (
 	carDriver "challenge-fravega/internal/car-driver"
 	routePoint "challenge-fravega/internal/route-point"
 	"challenge-fravega/internal/vehicle"
+	"context"
 	"testing"
 	"time"
 
@@ -17,7 +18,7 @@ import (
 type RepositoryTestSuite struct {
 	suite.Suite
 	db         *gorm.DB
-	repository *Repository
+	repository Repository
 }
 
 func (suite *RepositoryTestSuite) SetupTest() {
@@ -30,6 +31,7 @@ func (suite *RepositoryTestSuite) SetupTest() {
 	err = db.AutoMigrate(
 		&Route{},
 		&vehicle.Vehicle{},
+		&vehicle.OdometerReading{},
 		&carDriver.Driver{},
 		&routePoint.RoutePoint{},
 	)
@@ -73,7 +75,7 @@ func (suite *RepositoryTestSuite) TestCreateRoute() {
 	}
 
 	// Act
-	result, err := suite.repository.CreateRoute(route)
+	result, err := suite.repository.CreateRoute(context.Background(), route)
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -124,7 +126,7 @@ func (suite *RepositoryTestSuite) TestGetRoute() {
 	suite.db.Create(route)
 
 	// Act
-	result, err := suite.repository.GetRoute(routeID.String())
+	result, err := suite.repository.GetRoute(context.Background(), routeID.String())
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -183,13 +185,33 @@ func (suite *RepositoryTestSuite) TestGetRoutes() {
 	suite.db.Create(route2)
 
 	// Act
-	results, err := suite.repository.GetRoutes()
+	results, err := suite.repository.GetRoutes(context.Background())
 
 	// Assert
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), results, 2)
 }
 
+func (suite *RepositoryTestSuite) TestGetRoutesByDate() {
+	// Arrange
+	scheduled := &Route{ID: uuid.New(), Name: "Scheduled", ScheduledDate: "2025-03-10"}
+	otherDay := &Route{ID: uuid.New(), Name: "Other day", ScheduledDate: "2025-03-11"}
+	unscheduled := &Route{ID: uuid.New(), Name: "Unscheduled"}
+	suite.db.Create(scheduled)
+	suite.db.Create(otherDay)
+	suite.db.Create(unscheduled)
+	suite.db.Create(&routePoint.RoutePoint{ID: uuid.New(), RouteID: &scheduled.ID, PurchaseOrderID: "PO1"})
+
+	// Act
+	results, err := suite.repository.GetRoutesByDate(context.Background(), "2025-03-10")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 1)
+	assert.Equal(suite.T(), scheduled.ID, results[0].ID)
+	assert.Len(suite.T(), results[0].RoutePoints, 1)
+}
+
 func (suite *RepositoryTestSuite) TestGetRouteWithRelations() {
 	// Arrange
 	// Create required vehicle and driver first
@@ -229,7 +251,7 @@ func (suite *RepositoryTestSuite) TestGetRouteWithRelations() {
 	routePoint1 := &routePoint.RoutePoint{
 		ID:              uuid.New(),
 		PurchaseOrderID: "PO12345",
-		RouteID:         routeID,
+		RouteID:         &routeID,
 		Status:          routePoint.RoutePointStatusList[routePoint.RoutePointStatusPending],
 		Latitude:        37.7749,
 		Longitude:       -122.4194,
@@ -239,7 +261,7 @@ func (suite *RepositoryTestSuite) TestGetRouteWithRelations() {
 	suite.db.Create(routePoint1)
 
 	// Act
-	result, err := suite.repository.GetRoute(routeID.String())
+	result, err := suite.repository.GetRoute(context.Background(), routeID.String())
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -271,6 +293,126 @@ func (suite *RepositoryTestSuite) TestGetRouteWithRelations() {
 	assert.Equal(suite.T(), routePoint1.Address, foundRoutePoint.Address)
 }
 
+func (suite *RepositoryTestSuite) TestUpdateRoute() {
+	// Arrange
+	route := &Route{
+		Name:      "Route",
+		Status:    RouteStatusList[RouteStatusPending],
+		VehicleID: uuid.New(),
+		DriverID:  uuid.New(),
+	}
+	suite.repository.CreateRoute(context.Background(), route)
+	route.Name = "Renamed Route"
+	route.Status = RouteStatusList[RouteStatusStarted]
+
+	// Act
+	result, err := suite.repository.UpdateRoute(context.Background(), route, 1, nil)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, result.Version)
+
+	var stored Route
+	suite.db.First(&stored, "id = ?", route.ID)
+	assert.Equal(suite.T(), "Renamed Route", stored.Name)
+	assert.Equal(suite.T(), RouteStatusList[RouteStatusStarted], stored.Status)
+	assert.Equal(suite.T(), 2, stored.Version)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateRouteVersionConflict() {
+	// Arrange
+	route := &Route{
+		Name:      "Route",
+		Status:    RouteStatusList[RouteStatusPending],
+		VehicleID: uuid.New(),
+		DriverID:  uuid.New(),
+	}
+	suite.repository.CreateRoute(context.Background(), route)
+	route.Name = "First Writer"
+	suite.repository.UpdateRoute(context.Background(), route, 1, nil)
+	route.Name = "Second Writer"
+
+	// Act
+	_, err := suite.repository.UpdateRoute(context.Background(), route, 1, nil)
+
+	// Assert
+	assert.ErrorIs(suite.T(), err, ErrVersionConflict)
+
+	var stored Route
+	suite.db.First(&stored, "id = ?", route.ID)
+	assert.Equal(suite.T(), "First Writer", stored.Name)
+	assert.Equal(suite.T(), 2, stored.Version)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateRouteRecordsOdometer() {
+	// Arrange
+	fleetVehicle := &vehicle.Vehicle{ID: uuid.New(), PlateNumber: "AB123CD", Odometer: 1000}
+	suite.db.Create(fleetVehicle)
+	route := &Route{Name: "Route", Status: RouteStatusList[RouteStatusStarted], VehicleID: fleetVehicle.ID, DriverID: uuid.New()}
+	suite.repository.CreateRoute(context.Background(), route)
+	route.Status = RouteStatusList[RouteStatusCompleted]
+
+	// Act
+	_, err := suite.repository.UpdateRoute(context.Background(), route, 1,
+		&vehicle.OdometerReading{VehicleID: fleetVehicle.ID, RouteID: &route.ID, Kilometers: 1050, Event: "route_completed"})
+	_, staleErr := suite.repository.UpdateRoute(context.Background(), route, 1,
+		&vehicle.OdometerReading{VehicleID: fleetVehicle.ID, RouteID: &route.ID, Kilometers: 1090, Event: "route_completed"})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.ErrorIs(suite.T(), staleErr, ErrVersionConflict)
+	var readings []vehicle.OdometerReading
+	suite.db.Find(&readings)
+	assert.Len(suite.T(), readings, 1)
+	var stored vehicle.Vehicle
+	suite.db.First(&stored, "id = ?", fleetVehicle.ID)
+	assert.Equal(suite.T(), 1050, stored.Odometer)
+}
+
+func (suite *RepositoryTestSuite) TestGetRouteZoneID() {
+	// Arrange
+	zoneID := uuid.New()
+	zoned := &Route{ID: uuid.New(), Name: "Zoned", ZoneID: &zoneID}
+	unzoned := &Route{ID: uuid.New(), Name: "Unzoned"}
+	suite.db.Create(zoned)
+	suite.db.Create(unzoned)
+
+	// Act
+	result, err := suite.repository.GetRouteZoneID(context.Background(), zoned.ID)
+	none, noneErr := suite.repository.GetRouteZoneID(context.Background(), unzoned.ID)
+	_, missingErr := suite.repository.GetRouteZoneID(context.Background(), uuid.New())
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), zoneID, *result)
+	assert.NoError(suite.T(), noneErr)
+	assert.Nil(suite.T(), none)
+	assert.ErrorIs(suite.T(), missingErr, gorm.ErrRecordNotFound)
+}
+
+func (suite *RepositoryTestSuite) TestGetDrivingTime() {
+	// Arrange
+	driverID := uuid.New()
+	at := func(day int, hour int) *time.Time {
+		t := time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	suite.db.Create(&Route{ID: uuid.New(), DriverID: driverID, StartedAt: at(10, 22), CompletedAt: at(11, 2)})
+	suite.db.Create(&Route{ID: uuid.New(), DriverID: driverID, StartedAt: at(11, 8), CompletedAt: at(11, 13)})
+	suite.db.Create(&Route{ID: uuid.New(), DriverID: driverID})
+	suite.db.Create(&Route{ID: uuid.New(), DriverID: uuid.New(), StartedAt: at(11, 8), CompletedAt: at(11, 18)})
+
+	// Act
+	day, err := suite.repository.GetDrivingTime(context.Background(), driverID, *at(11, 0), *at(12, 0))
+	week, weekErr := suite.repository.GetDrivingTime(context.Background(), driverID, *at(10, 0), *at(17, 0))
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 7*time.Hour, day)
+	assert.NoError(suite.T(), weekErr)
+	assert.Equal(suite.T(), 9*time.Hour, week)
+}
+
 func TestRepositorySuite(t *testing.T) {
 	suite.Run(t, new(RepositoryTestSuite))
 }
@@ -2,18 +2,28 @@ package route
 
 import (
 	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/outbox"
 	routePoint "challenge-fravega/internal/route-point"
 	"challenge-fravega/internal/vehicle"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Route struct {
-	ID          uuid.UUID               `gorm:"column:id" json:"id"`
-	Name        string                  `gorm:"column:name" json:"name"`
-	Description string                  `gorm:"column:description" json:"description"`
-	Status      string                  `gorm:"column:status" json:"status"`
+	ID            uuid.UUID  `gorm:"column:id" json:"id"`
+	Name          string     `gorm:"column:name" json:"name"`
+	Description   string     `gorm:"column:description" json:"description"`
+	Status        string     `gorm:"column:status" json:"status"`
+	ScheduledDate string     `gorm:"column:scheduled_date" json:"scheduled_date"`
+	ZoneID        *uuid.UUID `gorm:"column:zone_id" json:"zone_id"`
+	DepotID       *uuid.UUID `gorm:"column:depot_id" json:"depot_id"`
+	StartedAt     *time.Time `gorm:"column:started_at" json:"started_at"`
+	CompletedAt   *time.Time `gorm:"column:completed_at" json:"completed_at"`
+	// NeedsReview flags routes the end of day reconciliation left open
+	NeedsReview bool                    `gorm:"column:needs_review" json:"needs_review"`
+	Version     int                     `gorm:"column:version" json:"version"`
 	CreatedAt   time.Time               `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt   time.Time               `gorm:"column:updated_at" json:"updated_at"`
 	VehicleID   uuid.UUID               `gorm:"column:vehicle_id" json:"vehicle_id"`
@@ -23,6 +33,24 @@ type Route struct {
 	RoutePoints []routePoint.RoutePoint `gorm:"foreignKey:RouteID" json:"route_points"`
 }
 
+// EventData is the payload of the route events.
+type EventData struct {
+	RouteID       uuid.UUID  `json:"route_id"`
+	Name          string     `json:"name"`
+	Status        string     `json:"status"`
+	ScheduledDate string     `json:"scheduled_date"`
+	DriverID      uuid.UUID  `json:"driver_id"`
+	VehicleID     uuid.UUID  `json:"vehicle_id"`
+	StartedAt     *time.Time `json:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	NeedsReview   bool       `json:"needs_review"`
+	// PurchaseOrderIDs are those of the route points on the route
+	PurchaseOrderIDs []string `json:"purchase_order_ids"`
+}
+
+// DateLayout is the format of ScheduledDate, the day a route is planned for
+const DateLayout = time.DateOnly
+
 type RouteStatus string
 
 const (
@@ -36,3 +64,55 @@ var RouteStatusList = map[RouteStatus]string{
 	RouteStatusStarted:   "started",
 	RouteStatusCompleted: "completed",
 }
+
+// RouteStatusTransitions lists the statuses a route can move to from each status
+var RouteStatusTransitions = map[RouteStatus][]RouteStatus{
+	RouteStatusPending: {RouteStatusStarted},
+	RouteStatusStarted: {RouteStatusCompleted},
+}
+
+func ValidDate(date string) bool {
+	_, err := time.Parse(DateLayout, date)
+	return err == nil
+}
+
+func CanTransition(from RouteStatus, to RouteStatus) bool {
+	for _, status := range RouteStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrInvalidStatus           = errors.New("invalid route status")
+	ErrInvalidStatusTransition = errors.New("invalid route status transition")
+	ErrVersionConflict         = errors.New("route was modified by another request")
+	ErrInvalidScheduledDate    = errors.New("invalid scheduled date, must be formatted as YYYY-MM-DD")
+	ErrOdometerWithoutStatus   = errors.New("odometer can only be recorded when starting or completing the route")
+)
+
+// NewEvent describes a change to the route, to record along with it.
+func NewEvent(eventType outbox.EventType, route *Route) (outbox.Event, error) {
+	return outbox.NewEvent(outbox.AggregateRoute, route.ID, eventType, newEventData(route))
+}
+
+func newEventData(route *Route) *EventData {
+	purchaseOrderIDs := make([]string, len(route.RoutePoints))
+	for i, rp := range route.RoutePoints {
+		purchaseOrderIDs[i] = rp.PurchaseOrderID
+	}
+	return &EventData{
+		RouteID:          route.ID,
+		Name:             route.Name,
+		Status:           route.Status,
+		ScheduledDate:    route.ScheduledDate,
+		DriverID:         route.DriverID,
+		VehicleID:        route.VehicleID,
+		StartedAt:        route.StartedAt,
+		CompletedAt:      route.CompletedAt,
+		NeedsReview:      route.NeedsReview,
+		PurchaseOrderIDs: purchaseOrderIDs,
+	}
+}
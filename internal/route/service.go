@@ -1,40 +1,276 @@
 package route
 
+import (
+	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/outbox"
+	"challenge-fravega/internal/tracing"
+	"challenge-fravega/internal/vehicle"
+	"challenge-fravega/internal/zone"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
 type Service interface {
-	GetRoutes() ([]Route, error)
-	GetRoute(id string) (*Route, error)
-	CreateRoute(newRoute *CreateRoute) (*Route, error)
+	GetRoutes(ctx context.Context) ([]Route, error)
+	GetRoute(ctx context.Context, id string) (*Route, error)
+	GetRoutesByDate(ctx context.Context, date string) ([]Route, error)
+	CreateRoute(ctx context.Context, newRoute *CreateRoute) (*Route, error)
+	UpdateRoute(ctx context.Context, id string, version int, update *UpdateRoute) (*Route, error)
+	InZone(ctx context.Context, id uuid.UUID, latitude float64, longitude float64) (bool, error)
+	RouteExists(ctx context.Context, id uuid.UUID) (bool, error)
 }
 
 type service struct {
-	repository *Repository
+	repository Repository
+	zones      zone.Service
+	drivers    carDriver.Service
+	vehicles   vehicle.Service
 }
 
-func (s *service) GetRoutes() ([]Route, error) {
-	return s.repository.GetRoutes()
+func (s *service) GetRoutes(ctx context.Context) ([]Route, error) {
+	ctx, span := tracing.Start(ctx, "route.GetRoutes")
+	defer span.End()
+
+	return s.repository.GetRoutes(ctx)
 }
 
-func (s *service) GetRoute(id string) (*Route, error) {
-	return s.repository.GetRoute(id)
+func (s *service) GetRoute(ctx context.Context, id string) (*Route, error) {
+	return s.repository.GetRoute(ctx, id)
 }
 
-func (s *service) CreateRoute(newRoute *CreateRoute) (*Route, error) {
-	route, err := s.repository.CreateRoute(&Route{
-		Name:        newRoute.Name,
-		Description: newRoute.Description,
-		Status:      RouteStatusList[RouteStatusPending],
-		VehicleID:   newRoute.VehicleId,
-		DriverID:    newRoute.DriverId,
-	})
+func (s *service) GetRoutesByDate(ctx context.Context, date string) ([]Route, error) {
+	if !ValidDate(date) {
+		return nil, ErrInvalidScheduledDate
+	}
+	return s.repository.GetRoutesByDate(ctx, date)
+}
+
+func (s *service) CreateRoute(ctx context.Context, newRoute *CreateRoute) (*Route, error) {
+	ctx, span := tracing.Start(ctx, "route.CreateRoute")
+	defer span.End()
+
+	if newRoute.ScheduledDate != "" && !ValidDate(newRoute.ScheduledDate) {
+		return nil, ErrInvalidScheduledDate
+	}
+	if err := s.checkDriver(ctx, newRoute.DriverId, newRoute.ScheduledDate); err != nil {
+		return nil, err
+	}
+	if err := s.checkVehicle(ctx, newRoute.VehicleId, newRoute.ScheduledDate); err != nil {
+		return nil, err
+	}
+	zoneID, depotID, err := s.pickDepot(ctx, newRoute.ZoneId, newRoute.DepotId)
+	if err != nil {
+		return nil, err
+	}
+	route := &Route{
+		ID:            uuid.New(),
+		Name:          newRoute.Name,
+		Description:   newRoute.Description,
+		Status:        RouteStatusList[RouteStatusPending],
+		VehicleID:     newRoute.VehicleId,
+		DriverID:      newRoute.DriverId,
+		ScheduledDate: newRoute.ScheduledDate,
+		ZoneID:        zoneID,
+		DepotID:       depotID,
+	}
+	event, err := NewEvent(outbox.EventRouteCreated, route)
+	if err != nil {
+		return nil, err
+	}
+
+	createdRoute, err := s.repository.CreateRoute(ctx, route, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetRoute(ctx, createdRoute.ID.String())
+}
+
+// UpdateRoute applies the update if the route is still at the version the
+// client last read. Status changes must follow RouteStatusTransitions, and a
+// pending route may only be moved to a driver and vehicle available on its
+// date. The odometer reading sent when starting or completing the route is
+// recorded for its vehicle.
+func (s *service) UpdateRoute(ctx context.Context, id string, version int, update *UpdateRoute) (*Route, error) {
+	ctx, span := tracing.Start(ctx, "route.UpdateRoute")
+	defer span.End()
+
+	route, err := s.repository.GetRoute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if route.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	if update.Name != nil {
+		route.Name = *update.Name
+	}
+	if update.Description != nil {
+		route.Description = *update.Description
+	}
+	if update.NeedsReview != nil {
+		route.NeedsReview = *update.NeedsReview
+	}
+	if update.VehicleId != nil {
+		route.VehicleID = *update.VehicleId
+	}
+	vehicleID, driverID, scheduledDate := route.VehicleID, route.DriverID, route.ScheduledDate
+	if update.DriverId != nil {
+		route.DriverID = *update.DriverId
+	}
+	if update.ScheduledDate != nil {
+		if *update.ScheduledDate != "" && !ValidDate(*update.ScheduledDate) {
+			return nil, ErrInvalidScheduledDate
+		}
+		route.ScheduledDate = *update.ScheduledDate
+	}
+	var odometerEvent string
+	eventType := outbox.EventRouteUpdated
+	if update.Status != nil {
+		status := RouteStatus(*update.Status)
+		if _, ok := RouteStatusList[status]; !ok {
+			return nil, ErrInvalidStatus
+		}
+		if status != RouteStatus(route.Status) && !CanTransition(RouteStatus(route.Status), status) {
+			return nil, ErrInvalidStatusTransition
+		}
+		if status != RouteStatus(route.Status) {
+			now := time.Now()
+			switch status {
+			case RouteStatusStarted:
+				route.StartedAt = &now
+				odometerEvent = vehicle.OdometerEventList[vehicle.OdometerEventRouteStarted]
+				eventType = outbox.EventRouteStarted
+			case RouteStatusCompleted:
+				route.CompletedAt = &now
+				odometerEvent = vehicle.OdometerEventList[vehicle.OdometerEventRouteCompleted]
+				eventType = outbox.EventRouteCompleted
+			}
+		}
+		route.Status = RouteStatusList[status]
+	}
+	if route.Status == RouteStatusList[RouteStatusPending] && (route.DriverID != driverID || route.ScheduledDate != scheduledDate) {
+		if err := s.checkDriver(ctx, route.DriverID, route.ScheduledDate); err != nil {
+			return nil, err
+		}
+	}
+	if route.Status == RouteStatusList[RouteStatusPending] && (route.VehicleID != vehicleID || route.ScheduledDate != scheduledDate) {
+		if err := s.checkVehicle(ctx, route.VehicleID, route.ScheduledDate); err != nil {
+			return nil, err
+		}
+	}
+	var reading *vehicle.OdometerReading
+	if update.Odometer != nil {
+		if odometerEvent == "" {
+			return nil, ErrOdometerWithoutStatus
+		}
+		var err error
+		if reading, err = s.odometerReading(ctx, route, *update.Odometer, odometerEvent); err != nil {
+			return nil, err
+		}
+	}
+
+	event, err := NewEvent(eventType, route)
 	if err != nil {
 		return nil, err
 	}
+	if _, err := s.repository.UpdateRoute(ctx, route, version, reading, event); err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetRoute(ctx, id)
+}
+
+// InZone tells whether a location lies within the zone of a route. Routes
+// without a zone take any location, and so do routes that do not exist, as
+// there is nothing to check against.
+func (s *service) InZone(ctx context.Context, id uuid.UUID, latitude float64, longitude float64) (bool, error) {
+	zoneID, err := s.repository.GetRouteZoneID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if zoneID == nil {
+		return true, nil
+	}
+	return s.zones.Contains(ctx, *zoneID, latitude, longitude)
+}
+
+// RouteExists tells whether a route exists.
+func (s *service) RouteExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	_, err := s.repository.GetRouteZoneID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// checkDriver makes sure the driver is available on the date of a route,
+// today for routes without a date. No check is made without a driver service.
+func (s *service) checkDriver(ctx context.Context, driverID uuid.UUID, date string) error {
+	if s.drivers == nil {
+		return nil
+	}
+	return s.drivers.CheckAvailability(ctx, driverID, date)
+}
 
-	return s.repository.GetRoute(route.ID.String())
+// checkVehicle makes sure the vehicle is not in maintenance on the date of a
+// route, today for routes without a date. No check is made without a vehicle
+// service.
+func (s *service) checkVehicle(ctx context.Context, vehicleID uuid.UUID, date string) error {
+	if s.vehicles == nil {
+		return nil
+	}
+	return s.vehicles.CheckAvailability(ctx, vehicleID, date)
+}
+
+// odometerReading checks the odometer of the vehicle of a route as it starts
+// or completes, to be stored along with the route. There is no reading
+// without a vehicle service.
+func (s *service) odometerReading(ctx context.Context, route *Route, kilometers int, event string) (*vehicle.OdometerReading, error) {
+	if s.vehicles == nil {
+		return nil, nil
+	}
+	return s.vehicles.NewOdometerReading(ctx, route.VehicleID, &vehicle.RecordOdometer{
+		Kilometers: kilometers,
+		Event:      event,
+		RouteId:    &route.ID,
+	})
+}
+
+// pickDepot completes the zone and depot of a new route from whichever was
+// given, checking that the depot belongs to the zone.
+func (s *service) pickDepot(ctx context.Context, zoneID *uuid.UUID, depotID *uuid.UUID) (*uuid.UUID, *uuid.UUID, error) {
+	switch {
+	case depotID != nil:
+		depot, err := s.zones.GetDepot(ctx, *depotID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if zoneID != nil && *zoneID != depot.ZoneID {
+			return nil, nil, zone.ErrDepotNotInZone
+		}
+		return &depot.ZoneID, &depot.ID, nil
+	case zoneID != nil:
+		depot, err := s.zones.PickDepot(ctx, *zoneID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zoneID, &depot.ID, nil
+	default:
+		return nil, nil, nil
+	}
 }
 
 // static functions
 
-func NewService(repository *Repository) *service {
-	return &service{repository: repository}
+func NewService(repository Repository, zones zone.Service, drivers carDriver.Service, vehicles vehicle.Service) *service {
+	return &service{repository: repository, zones: zones, drivers: drivers, vehicles: vehicles}
 }
@@ -2,33 +2,38 @@ package route
 
 import (
 	carDriver "challenge-fravega/internal/car-driver"
+	"challenge-fravega/internal/outbox"
+	routePoint "challenge-fravega/internal/route-point"
 	"challenge-fravega/internal/vehicle"
+	"challenge-fravega/internal/zone"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
-// Define a repository interface that our mock can implement
-type RepositoryInterface interface {
-	CreateRoute(route *Route) (*Route, error)
-	GetRoute(id string) (*Route, error)
-	GetRoutes() ([]Route, error)
-}
-
 // Define a mock repository for testing the service
 type MockRepository struct {
 	mock.Mock
+	// events and readings are those recorded along with the changes
+	events   []outbox.Event
+	readings []vehicle.OdometerReading
 }
 
-func (m *MockRepository) CreateRoute(route *Route) (*Route, error) {
-	args := m.Called(route)
+func (m *MockRepository) CreateRoute(ctx context.Context, route *Route, events ...outbox.Event) (*Route, error) {
+	m.events = append(m.events, events...)
+	args := m.Called(ctx, route)
 	return args.Get(0).(*Route), args.Error(1)
 }
 
-func (m *MockRepository) GetRoute(id string) (*Route, error) {
+func (m *MockRepository) GetRoute(ctx context.Context, id string) (*Route, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -36,43 +41,101 @@ func (m *MockRepository) GetRoute(id string) (*Route, error) {
 	return args.Get(0).(*Route), args.Error(1)
 }
 
-func (m *MockRepository) GetRoutes() ([]Route, error) {
+func (m *MockRepository) GetRoutes(ctx context.Context) ([]Route, error) {
 	args := m.Called()
 	return args.Get(0).([]Route), args.Error(1)
 }
 
-// Create a custom service for testing
-type testService struct {
-	repo RepositoryInterface
+func (m *MockRepository) GetRoutesByDate(ctx context.Context, date string) ([]Route, error) {
+	args := m.Called(date)
+	return args.Get(0).([]Route), args.Error(1)
 }
 
-func (s *testService) CreateRoute(newRoute *CreateRoute) (*Route, error) {
-	route := &Route{
-		Name:        newRoute.Name,
-		Description: newRoute.Description,
-		Status:      RouteStatusList[RouteStatusPending],
-		VehicleID:   newRoute.VehicleId,
-		DriverID:    newRoute.DriverId,
+func (m *MockRepository) UpdateRoute(ctx context.Context, route *Route, version int, reading *vehicle.OdometerReading, events ...outbox.Event) (*Route, error) {
+	m.events = append(m.events, events...)
+	if reading != nil {
+		m.readings = append(m.readings, *reading)
 	}
+	args := m.Called(ctx, route, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Route), args.Error(1)
+}
 
-	createdRoute, err := s.repo.CreateRoute(route)
-	if err != nil {
-		return nil, err
+func (m *MockRepository) GetRouteZoneID(ctx context.Context, id uuid.UUID) (*uuid.UUID, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
 	}
+	return args.Get(0).(*uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetDrivingTime(ctx context.Context, driverID uuid.UUID, from time.Time, to time.Time) (time.Duration, error) {
+	args := m.Called(driverID, from, to)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
 
-	return s.repo.GetRoute(createdRoute.ID.String())
+// Define a zone service with a single zone, a box around the Obelisco, and
+// its depots
+type fakeZones struct {
+	zone.Service
+	zoneID uuid.UUID
+	depots []zone.Depot
 }
 
-func (s *testService) GetRoute(id string) (*Route, error) {
-	return s.repo.GetRoute(id)
+func (f *fakeZones) GetDepot(ctx context.Context, id uuid.UUID) (*zone.Depot, error) {
+	for _, depot := range f.depots {
+		if depot.ID == id {
+			return &depot, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeZones) PickDepot(ctx context.Context, zoneID uuid.UUID) (*zone.Depot, error) {
+	if zoneID != f.zoneID || len(f.depots) == 0 {
+		return nil, zone.ErrNoDepot
+	}
+	return &f.depots[0], nil
+}
+
+func (f *fakeZones) Contains(ctx context.Context, zoneID uuid.UUID, latitude float64, longitude float64) (bool, error) {
+	return latitude > -34.61 && latitude < -34.60 && longitude > -58.39 && longitude < -58.37, nil
+}
+
+// Define a driver service where only the listed drivers are available
+type fakeDrivers struct {
+	carDriver.Service
+	available map[uuid.UUID]bool
+}
+
+func (f *fakeDrivers) CheckAvailability(ctx context.Context, driverID uuid.UUID, date string) error {
+	if !f.available[driverID] {
+		return fmt.Errorf("%w on %s: driver is on time off", carDriver.ErrDriverUnavailable, date)
+	}
+	return nil
+}
+
+// Define a vehicle service where the listed vehicles are in maintenance
+type fakeVehicles struct {
+	vehicle.Service
+	inMaintenance map[uuid.UUID]bool
+}
+
+func (f *fakeVehicles) CheckAvailability(ctx context.Context, vehicleID uuid.UUID, date string) error {
+	if f.inMaintenance[vehicleID] {
+		return fmt.Errorf("%w on %s", vehicle.ErrVehicleInMaintenance, date)
+	}
+	return nil
 }
 
-func (s *testService) GetRoutes() ([]Route, error) {
-	return s.repo.GetRoutes()
+func (f *fakeVehicles) NewOdometerReading(ctx context.Context, vehicleID uuid.UUID, record *vehicle.RecordOdometer) (*vehicle.OdometerReading, error) {
+	return &vehicle.OdometerReading{VehicleID: vehicleID, RouteID: record.RouteId, Kilometers: record.Kilometers, Event: record.Event}, nil
 }
 
 func createTestService(mockRepo *MockRepository) Service {
-	return &testService{repo: mockRepo}
+	return NewService(mockRepo, nil, nil, nil)
 }
 
 func TestCreateRoute(t *testing.T) {
@@ -123,7 +186,7 @@ func TestCreateRoute(t *testing.T) {
 	}
 
 	// Mock the repository calls
-	mockRepo.On("CreateRoute", mock.MatchedBy(func(r *Route) bool {
+	mockRepo.On("CreateRoute", mock.Anything, mock.MatchedBy(func(r *Route) bool {
 		return r.Name == expectedRouteCreate.Name &&
 			r.Description == expectedRouteCreate.Description &&
 			r.Status == expectedRouteCreate.Status &&
@@ -134,7 +197,7 @@ func TestCreateRoute(t *testing.T) {
 	mockRepo.On("GetRoute", routeID.String()).Return(fullRoute, nil)
 
 	// Act
-	result, err := service.CreateRoute(createRequest)
+	result, err := service.CreateRoute(context.Background(), createRequest)
 
 	// Assert
 	assert.NoError(t, err)
@@ -165,7 +228,7 @@ func TestGetRoute(t *testing.T) {
 	mockRepo.On("GetRoute", routeID.String()).Return(expectedRoute, nil)
 
 	// Act
-	result, err := service.GetRoute(routeID.String())
+	result, err := service.GetRoute(context.Background(), routeID.String())
 
 	// Assert
 	assert.NoError(t, err)
@@ -186,7 +249,7 @@ func TestGetRouteNotFound(t *testing.T) {
 	mockRepo.On("GetRoute", routeID.String()).Return((*Route)(nil), expectedError)
 
 	// Act
-	_, err := service.GetRoute(routeID.String())
+	_, err := service.GetRoute(context.Background(), routeID.String())
 
 	// Assert
 	assert.Error(t, err)
@@ -221,7 +284,7 @@ func TestGetRoutes(t *testing.T) {
 	mockRepo.On("GetRoutes").Return(expectedRoutes, nil)
 
 	// Act
-	results, err := service.GetRoutes()
+	results, err := service.GetRoutes(context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -230,3 +293,436 @@ func TestGetRoutes(t *testing.T) {
 	assert.Equal(t, expectedRoutes[1].Name, results[1].Name)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGetRoutesByDate(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	expectedRoutes := []Route{{ID: uuid.New(), Name: "Route 1", ScheduledDate: "2025-03-10"}}
+	mockRepo.On("GetRoutesByDate", "2025-03-10").Return(expectedRoutes, nil)
+
+	// Act
+	results, err := service.GetRoutesByDate(context.Background(), "2025-03-10")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, expectedRoutes, results)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetRoutesByDateInvalid(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	// Act
+	results, err := service.GetRoutesByDate(context.Background(), "10/03/2025")
+
+	// Assert
+	assert.Nil(t, results)
+	assert.ErrorIs(t, err, ErrInvalidScheduledDate)
+	mockRepo.AssertNotCalled(t, "GetRoutesByDate")
+}
+
+func TestCreateRouteInvalidScheduledDate(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	// Act
+	result, err := service.CreateRoute(context.Background(), &CreateRoute{Name: "Route", ScheduledDate: "2025-02-30"})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrInvalidScheduledDate)
+	mockRepo.AssertNotCalled(t, "CreateRoute")
+}
+
+func TestUpdateRoute(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	routeID := uuid.New()
+	current := &Route{
+		ID:      routeID,
+		Name:    "Route",
+		Status:  RouteStatusList[RouteStatusPending],
+		Version: 3,
+	}
+	updated := &Route{
+		ID:      routeID,
+		Name:    "Renamed Route",
+		Status:  RouteStatusList[RouteStatusStarted],
+		Version: 4,
+	}
+	name := "Renamed Route"
+	status := "started"
+
+	mockRepo.On("GetRoute", routeID.String()).Return(current, nil).Once()
+	mockRepo.On("UpdateRoute", mock.Anything, mock.MatchedBy(func(r *Route) bool {
+		return r.Name == name && r.Status == status
+	}), 3).Return(updated, nil)
+	mockRepo.On("GetRoute", routeID.String()).Return(updated, nil).Once()
+
+	// Act
+	result, err := service.UpdateRoute(context.Background(), routeID.String(), 3, &UpdateRoute{Name: &name, Status: &status})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result.Version)
+	assert.Equal(t, name, result.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateRouteVersionConflict(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	routeID := uuid.New()
+	mockRepo.On("GetRoute", routeID.String()).Return(&Route{ID: routeID, Version: 5}, nil)
+
+	// Act
+	_, err := service.UpdateRoute(context.Background(), routeID.String(), 4, &UpdateRoute{})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	mockRepo.AssertNotCalled(t, "UpdateRoute", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateRouteInvalidTransition(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	routeID := uuid.New()
+	status := "completed"
+	mockRepo.On("GetRoute", routeID.String()).Return(&Route{
+		ID:      routeID,
+		Status:  RouteStatusList[RouteStatusPending],
+		Version: 1,
+	}, nil)
+
+	// Act
+	_, err := service.UpdateRoute(context.Background(), routeID.String(), 1, &UpdateRoute{Status: &status})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+	mockRepo.AssertNotCalled(t, "UpdateRoute", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(RouteStatusPending, RouteStatusStarted))
+	assert.True(t, CanTransition(RouteStatusStarted, RouteStatusCompleted))
+	assert.False(t, CanTransition(RouteStatusPending, RouteStatusCompleted))
+	assert.False(t, CanTransition(RouteStatusCompleted, RouteStatusPending))
+}
+
+func newFakeZones() *fakeZones {
+	zoneID := uuid.New()
+	return &fakeZones{zoneID: zoneID, depots: []zone.Depot{
+		{ID: uuid.New(), ZoneID: zoneID, Name: "Early", OpensAt: "06:00", ClosesAt: "14:00"},
+		{ID: uuid.New(), ZoneID: zoneID, Name: "Late", OpensAt: "09:00", ClosesAt: "18:00"},
+	}}
+}
+
+func TestCreateRoutePicksDepot(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	zones := newFakeZones()
+	service := NewService(mockRepo, zones, nil, nil)
+	created := &Route{ID: uuid.New()}
+
+	mockRepo.On("CreateRoute", mock.Anything, mock.MatchedBy(func(r *Route) bool {
+		return *r.ZoneID == zones.zoneID && *r.DepotID == zones.depots[0].ID
+	})).Return(created, nil)
+	mockRepo.On("GetRoute", created.ID.String()).Return(created, nil)
+
+	// Act
+	_, err := service.CreateRoute(context.Background(), &CreateRoute{Name: "Route", ZoneId: &zones.zoneID})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateRouteWithDepot(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	zones := newFakeZones()
+	service := NewService(mockRepo, zones, nil, nil)
+	created := &Route{ID: uuid.New()}
+
+	mockRepo.On("CreateRoute", mock.Anything, mock.MatchedBy(func(r *Route) bool {
+		return *r.ZoneID == zones.zoneID && *r.DepotID == zones.depots[1].ID
+	})).Return(created, nil)
+	mockRepo.On("GetRoute", created.ID.String()).Return(created, nil)
+
+	// Act
+	_, err := service.CreateRoute(context.Background(), &CreateRoute{Name: "Route", DepotId: &zones.depots[1].ID})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateRouteDepotNotInZone(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	zones := newFakeZones()
+	service := NewService(mockRepo, zones, nil, nil)
+	otherZoneID := uuid.New()
+
+	// Act
+	_, err := service.CreateRoute(context.Background(), &CreateRoute{Name: "Route", ZoneId: &otherZoneID, DepotId: &zones.depots[0].ID})
+
+	// Assert
+	assert.ErrorIs(t, err, zone.ErrDepotNotInZone)
+	mockRepo.AssertNotCalled(t, "CreateRoute")
+}
+
+func TestInZone(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	zones := newFakeZones()
+	service := NewService(mockRepo, zones, nil, nil)
+	zoned, unzoned, missing := uuid.New(), uuid.New(), uuid.New()
+
+	mockRepo.On("GetRouteZoneID", zoned).Return(&zones.zoneID, nil)
+	mockRepo.On("GetRouteZoneID", unzoned).Return(nil, nil)
+	mockRepo.On("GetRouteZoneID", missing).Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	inside, insideErr := service.InZone(context.Background(), zoned, -34.6037, -58.3816)
+	outside, outsideErr := service.InZone(context.Background(), zoned, -34.5881, -58.4106)
+	anywhere, anywhereErr := service.InZone(context.Background(), unzoned, -34.5881, -58.4106)
+	unknown, unknownErr := service.InZone(context.Background(), missing, -34.5881, -58.4106)
+
+	// Assert
+	assert.NoError(t, errors.Join(insideErr, outsideErr, anywhereErr, unknownErr))
+	assert.True(t, inside)
+	assert.False(t, outside)
+	assert.True(t, anywhere)
+	assert.True(t, unknown)
+}
+
+func TestRouteExists(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, newFakeZones(), nil, nil)
+	existing, missing := uuid.New(), uuid.New()
+
+	mockRepo.On("GetRouteZoneID", existing).Return(nil, nil)
+	mockRepo.On("GetRouteZoneID", missing).Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	found, foundErr := service.RouteExists(context.Background(), existing)
+	notFound, notFoundErr := service.RouteExists(context.Background(), missing)
+
+	// Assert
+	assert.NoError(t, errors.Join(foundErr, notFoundErr))
+	assert.True(t, found)
+	assert.False(t, notFound)
+}
+
+func TestCreateRouteDriverUnavailable(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, &fakeDrivers{}, nil)
+
+	// Act
+	_, err := service.CreateRoute(context.Background(), &CreateRoute{Name: "Route", DriverId: uuid.New(), ScheduledDate: "2025-03-10"})
+
+	// Assert
+	assert.ErrorIs(t, err, carDriver.ErrDriverUnavailable)
+	mockRepo.AssertNotCalled(t, "CreateRoute", mock.Anything, mock.Anything)
+}
+
+func TestUpdateRouteDriverUnavailable(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	available, unavailable := uuid.New(), uuid.New()
+	service := NewService(mockRepo, nil, &fakeDrivers{available: map[uuid.UUID]bool{available: true}}, nil)
+	routeID := uuid.New()
+	mockRepo.On("GetRoute", routeID.String()).Return(&Route{ID: routeID, Status: "pending", DriverID: available, Version: 1}, nil)
+
+	// Act
+	_, err := service.UpdateRoute(context.Background(), routeID.String(), 1, &UpdateRoute{DriverId: &unavailable})
+
+	// Assert
+	assert.ErrorIs(t, err, carDriver.ErrDriverUnavailable)
+	mockRepo.AssertNotCalled(t, "UpdateRoute", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateRouteRecordsStart(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, &fakeDrivers{}, nil)
+	routeID := uuid.New()
+	route := &Route{ID: routeID, Status: "pending", Version: 1}
+	mockRepo.On("GetRoute", routeID.String()).Return(route, nil)
+	mockRepo.On("UpdateRoute", mock.Anything, route, 1).Return(route, nil)
+	started := "started"
+
+	// Act
+	_, err := service.UpdateRoute(context.Background(), routeID.String(), 1, &UpdateRoute{Status: &started})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, route.StartedAt)
+	assert.Nil(t, route.CompletedAt)
+}
+
+func TestCreateRouteVehicleInMaintenance(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	vehicleID := uuid.New()
+	service := NewService(mockRepo, nil, nil, &fakeVehicles{inMaintenance: map[uuid.UUID]bool{vehicleID: true}})
+
+	// Act
+	_, err := service.CreateRoute(context.Background(), &CreateRoute{Name: "Route", VehicleId: vehicleID, ScheduledDate: "2025-03-10"})
+
+	// Assert
+	assert.ErrorIs(t, err, vehicle.ErrVehicleInMaintenance)
+	mockRepo.AssertNotCalled(t, "CreateRoute", mock.Anything, mock.Anything)
+}
+
+func TestUpdateRouteRecordsOdometer(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	vehicles := &fakeVehicles{}
+	service := NewService(mockRepo, nil, nil, vehicles)
+	routeID := uuid.New()
+	route := &Route{ID: routeID, Status: "started", Version: 1}
+	mockRepo.On("GetRoute", routeID.String()).Return(route, nil)
+	mockRepo.On("UpdateRoute", mock.Anything, route, 1).Return(route, nil)
+	completed, odometer := "completed", 12345
+
+	// Act
+	_, err := service.UpdateRoute(context.Background(), routeID.String(), 1, &UpdateRoute{Status: &completed, Odometer: &odometer})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, mockRepo.readings, 1)
+	assert.Equal(t, 12345, mockRepo.readings[0].Kilometers)
+	assert.Equal(t, "route_completed", mockRepo.readings[0].Event)
+	assert.Equal(t, routeID, *mockRepo.readings[0].RouteID)
+}
+
+func TestUpdateRouteOdometerWithoutStatus(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, nil, &fakeVehicles{})
+	routeID := uuid.New()
+	mockRepo.On("GetRoute", routeID.String()).Return(&Route{ID: routeID, Status: "started", Version: 1}, nil)
+	odometer := 12345
+
+	// Act
+	_, err := service.UpdateRoute(context.Background(), routeID.String(), 1, &UpdateRoute{Odometer: &odometer})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrOdometerWithoutStatus)
+	mockRepo.AssertNotCalled(t, "UpdateRoute", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateRouteRecordsEvent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, nil, nil)
+	routeID := uuid.New()
+	route := &Route{ID: routeID, Status: "started", Version: 1, RoutePoints: []routePoint.RoutePoint{{PurchaseOrderID: "PO1"}}}
+	mockRepo.On("GetRoute", routeID.String()).Return(route, nil)
+	mockRepo.On("UpdateRoute", mock.Anything, route, 1).Return(route, nil)
+	completed := "completed"
+
+	// Act
+	_, err := service.UpdateRoute(context.Background(), routeID.String(), 1, &UpdateRoute{Status: &completed})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, mockRepo.events, 1)
+	event := mockRepo.events[0]
+	assert.Equal(t, "route.completed", event.Type)
+	assert.Equal(t, "route", event.AggregateType)
+	assert.Equal(t, routeID, event.AggregateID)
+	data := EventData{}
+	assert.NoError(t, json.Unmarshal(event.Payload, &data))
+	assert.Equal(t, routeID, data.RouteID)
+	assert.Equal(t, "completed", data.Status)
+	assert.Equal(t, []string{"PO1"}, data.PurchaseOrderIDs)
+}
+
+func TestUpdateRouteEventTypes(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, nil, nil)
+	routeID := uuid.New()
+	route := &Route{ID: routeID, Status: "pending", Version: 1}
+	mockRepo.On("GetRoute", routeID.String()).Return(route, nil)
+	mockRepo.On("UpdateRoute", mock.Anything, route, 1).Return(route, nil)
+	name, started := "Renamed", "started"
+
+	// Act
+	_, renameErr := service.UpdateRoute(context.Background(), routeID.String(), 1, &UpdateRoute{Name: &name})
+	_, startErr := service.UpdateRoute(context.Background(), routeID.String(), 1, &UpdateRoute{Status: &started})
+
+	// Assert
+	assert.NoError(t, renameErr)
+	assert.NoError(t, startErr)
+	assert.Len(t, mockRepo.events, 2)
+	assert.Equal(t, "route.updated", mockRepo.events[0].Type)
+	assert.Equal(t, "route.started", mockRepo.events[1].Type)
+}
+
+func TestCreateRouteRecordsEvent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, nil, nil)
+	created := &Route{ID: uuid.New()}
+	mockRepo.On("CreateRoute", mock.Anything, mock.Anything).Return(created, nil)
+	mockRepo.On("GetRoute", created.ID.String()).Return(created, nil)
+
+	// Act
+	_, err := service.CreateRoute(context.Background(), &CreateRoute{Name: "Route"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, mockRepo.events, 1)
+	assert.Equal(t, "route.created", mockRepo.events[0].Type)
+	assert.NotEqual(t, uuid.Nil, mockRepo.events[0].AggregateID)
+}
+
+func TestRouteLifecycle(t *testing.T) {
+	// Arrange
+	fleet := vehicle.NewFakeRepository(vehicle.Vehicle{ID: uuid.New(), PlateNumber: "AB123CD", Odometer: 1000})
+	vehicles, _ := fleet.GetVehicles(context.Background())
+	repository := NewFakeRepository()
+	service := NewService(repository, nil, nil, vehicle.NewService(fleet, vehicle.DefaultMaintenancePolicy, time.UTC))
+	created, err := service.CreateRoute(context.Background(), &CreateRoute{Name: "Morning", VehicleId: vehicles[0].ID})
+	assert.NoError(t, err)
+	started, completed, pending := "started", "completed", "pending"
+	startOdometer, endOdometer := 1010, 1050
+
+	// Act
+	startedRoute, startErr := service.UpdateRoute(context.Background(), created.ID.String(), 1, &UpdateRoute{Status: &started, Odometer: &startOdometer})
+	_, staleErr := service.UpdateRoute(context.Background(), created.ID.String(), 1, &UpdateRoute{Status: &completed, Odometer: &endOdometer})
+	completedRoute, completeErr := service.UpdateRoute(context.Background(), created.ID.String(), 2, &UpdateRoute{Status: &completed, Odometer: &endOdometer})
+	_, transitionErr := service.UpdateRoute(context.Background(), created.ID.String(), 3, &UpdateRoute{Status: &pending})
+
+	// Assert
+	assert.NoError(t, startErr)
+	assert.NoError(t, completeErr)
+	assert.NotNil(t, startedRoute.StartedAt)
+	assert.ErrorIs(t, staleErr, ErrVersionConflict)
+	assert.Equal(t, "completed", completedRoute.Status)
+	assert.NotNil(t, completedRoute.CompletedAt)
+	assert.ErrorIs(t, transitionErr, ErrInvalidStatusTransition)
+	assert.Len(t, repository.Readings, 2)
+	assert.Equal(t, 1050, repository.Readings[1].Kilometers)
+	var types []string
+	for _, event := range repository.Events {
+		types = append(types, string(event.Type))
+	}
+	assert.Equal(t, []string{"route.created", "route.started", "route.completed"}, types)
+}
@@ -0,0 +1,18 @@
+package route
+
+import "github.com/google/uuid"
+
+// UpdateRoute holds the fields to change; nil fields are left untouched. An
+// empty scheduled date unschedules the route. Odometer is the reading of the
+// vehicle, in kilometres, when the route is started or completed. NeedsReview
+// clears the flag left by the end of day reconciliation once it is dealt with.
+type UpdateRoute struct {
+	Name          *string    `json:"name"`
+	Description   *string    `json:"description"`
+	VehicleId     *uuid.UUID `json:"vehicle_id"`
+	DriverId      *uuid.UUID `json:"driver_id"`
+	Status        *string    `json:"status"`
+	ScheduledDate *string    `json:"scheduled_date"`
+	Odometer      *int       `json:"odometer"`
+	NeedsReview   *bool      `json:"needs_review"`
+}
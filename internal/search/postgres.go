@@ -0,0 +1,125 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchPostgres runs the terms of a match expression against the search
+// index of PostgreSQL, where every term must appear in the title or the body
+// ignoring case and diacritics. Hits are ranked by trigram similarity,
+// weighting title matches above body matches, and highlighted as FTS5 does.
+func (r *repository) searchPostgres(ctx context.Context, match string, entityType string, limit int) ([]Hit, error) {
+	terms := matchTerms(match)
+	text := strings.Join(terms, " ")
+	var rows []struct {
+		EntityType string
+		EntityID   string
+		Title      string
+		Body       string
+		Score      float64
+	}
+	query := r.db.WithContext(ctx).Table("search_index").
+		Select(`entity_type, entity_id::text AS entity_id, title, body,
+			10 * word_similarity(search_fold(?), search_fold(title)) + word_similarity(search_fold(?), search_fold(body)) AS score`,
+			text, text)
+	for _, term := range terms {
+		query = query.Where("search_fold(title || ' ' || body) LIKE '%' || search_fold(?) || '%'", likeEscaper.Replace(term))
+	}
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if err := query.Order("score DESC").Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, len(rows))
+	for i, row := range rows {
+		title, _ := highlight(row.Title, terms)
+		snippet, ok := highlight(row.Body, terms)
+		if !ok {
+			snippet = title
+		}
+		hits[i] = Hit{Type: row.EntityType, ID: row.EntityID, Title: title, Snippet: snippet, Score: row.Score}
+	}
+	return hits, nil
+}
+
+// static functions
+
+// matchTerms returns the quoted terms of a match expression built by
+// BuildMatchExpression.
+func matchTerms(match string) []string {
+	var terms []string
+	for i := 0; i < len(match); i++ {
+		if match[i] != '"' {
+			continue
+		}
+		var term strings.Builder
+		for i++; i < len(match); i++ {
+			if match[i] == '"' {
+				if i+1 < len(match) && match[i+1] == '"' {
+					term.WriteByte('"')
+					i++
+					continue
+				}
+				break
+			}
+			term.WriteByte(match[i])
+		}
+		terms = append(terms, term.String())
+	}
+	return terms
+}
+
+// highlight marks the occurrences of the terms in text, ignoring case and
+// diacritics, and tells whether there were any.
+func highlight(text string, terms []string) (string, bool) {
+	runes := []rune(text)
+	folded := foldRunes(runes)
+	marked := make([]bool, len(runes))
+	found := false
+	for _, term := range terms {
+		foldedTerm := foldRunes([]rune(term))
+		if len(foldedTerm) == 0 {
+			continue
+		}
+		for i := 0; i+len(foldedTerm) <= len(folded); i++ {
+			if string(folded[i:i+len(foldedTerm)]) == string(foldedTerm) {
+				for j := i; j < i+len(foldedTerm); j++ {
+					marked[j] = true
+				}
+				found = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range runes {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteRune(r)
+		if marked[i] && (i == len(runes)-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+	return b.String(), found
+}
+
+// foldRunes lowercases the runes and strips their diacritics, one for one.
+func foldRunes(runes []rune) []rune {
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		base, _ := utf8.DecodeRuneInString(norm.NFD.String(string(r)))
+		folded[i] = unicode.ToLower(base)
+	}
+	return folded
+}
@@ -0,0 +1,42 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchTerms(t *testing.T) {
+	// Act
+	result := matchTerms(`"ABC""123" "NEAR(x)"`)
+
+	// Assert
+	assert.Equal(t, []string{`ABC"123`, "NEAR(x)"}, result)
+}
+
+func TestHighlightIgnoresCaseAndAccents(t *testing.T) {
+	// Act
+	result, found := highlight("Av. Córdoba 1111, Buenos Aires", []string{"cordoba", "1111"})
+
+	// Assert
+	assert.True(t, found)
+	assert.Equal(t, "Av. <mark>Córdoba</mark> <mark>1111</mark>, Buenos Aires", result)
+}
+
+func TestHighlightMergesOverlappingTerms(t *testing.T) {
+	// Act
+	result, found := highlight("AB123CD", []string{"b12", "23c"})
+
+	// Assert
+	assert.True(t, found)
+	assert.Equal(t, "A<mark>B123C</mark>D", result)
+}
+
+func TestHighlightNoMatch(t *testing.T) {
+	// Act
+	result, found := highlight("Av. Cabildo 2000", []string{"cordoba"})
+
+	// Assert
+	assert.False(t, found)
+	assert.Equal(t, "Av. Cabildo 2000", result)
+}
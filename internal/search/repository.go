@@ -0,0 +1,44 @@
+package search
+
+import (
+	"challenge-fravega/internal/database"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repository queries the full-text search index.
+type Repository interface {
+	Search(ctx context.Context, match string, entityType string, limit int) ([]Hit, error)
+}
+
+type repository struct {
+	db       *gorm.DB
+	postgres bool
+}
+
+// Search runs an FTS5 match expression against the search index. Hits are
+// ranked with bm25, weighting title matches above body matches.
+func (r *repository) Search(ctx context.Context, match string, entityType string, limit int) ([]Hit, error) {
+	if r.postgres {
+		return r.searchPostgres(ctx, match, entityType, limit)
+	}
+	hits := []Hit{}
+	query := r.db.WithContext(ctx).Table("search_index").
+		Select(`entity_type, entity_id,
+			highlight(search_index, 2, '<mark>', '</mark>') AS title,
+			snippet(search_index, -1, '<mark>', '</mark>', '…', 32) AS snippet,
+			-bm25(search_index, 0.0, 0.0, 10.0, 1.0) AS score`).
+		Where("search_index MATCH ?", match)
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	err := query.Order("score DESC").Limit(limit).Scan(&hits).Error
+	return hits, err
+}
+
+// static functions
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db, postgres: database.IsPostgres(db)}
+}
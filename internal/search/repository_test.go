@@ -0,0 +1,137 @@
+//go:build sqlite_fts5
+
+package search
+
+import (
+	"challenge-fravega/internal/database"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type RepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	repository Repository
+}
+
+func (suite *RepositoryTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	// The index and its triggers only exist in the SQL migrations
+	if err := database.MigrateDB(db, "../../db/migrations/sqlite", database.DefaultLockTimeout); err != nil {
+		suite.T().Fatal(err)
+	}
+
+	suite.db = db
+	suite.repository = NewRepository(db)
+}
+
+func (suite *RepositoryTestSuite) TestSearchSeededAddress() {
+	// Act
+	results, err := suite.repository.Search(context.Background(), `"cordoba"`, "", 10)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 1)
+	assert.Equal(suite.T(), "route_point", results[0].Type)
+	assert.Equal(suite.T(), "53af236a-eccc-4966-a802-3abea337ed4c", results[0].ID)
+	assert.Equal(suite.T(), "Av. <mark>Córdoba</mark> 1111, Buenos Aires", results[0].Title)
+}
+
+func (suite *RepositoryTestSuite) TestSearchPlateFragment() {
+	// Act
+	results, err := suite.repository.Search(context.Background(), `"c12"`, "", 10)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 1)
+	assert.Equal(suite.T(), "vehicle", results[0].Type)
+	assert.Equal(suite.T(), "AB<mark>C12</mark>3", results[0].Title)
+}
+
+func (suite *RepositoryTestSuite) TestSearchFilterByType() {
+	// Act
+	results, err := suite.repository.Search(context.Background(), `"delivery"`, "route", 10)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 2)
+	for _, hit := range results {
+		assert.Equal(suite.T(), "route", hit.Type)
+	}
+}
+
+func (suite *RepositoryTestSuite) TestSearchRanksTitleAboveBody() {
+	// Arrange
+	suite.db.Exec(`INSERT INTO route (id, name, description, status, vehicle_id, driver_id)
+		VALUES ('a4d1f0b4-4b1e-4a57-9d6c-3b0e1f1a7c11', 'Palermo', 'Stops near Belgrano', 'pending',
+			'171f1ef5-1b5b-4fed-a4b4-9b3d2845893c', 'e3b57a7a-fb4f-45bb-8fa6-81a406c1c596')`)
+	suite.db.Exec(`INSERT INTO route (id, name, description, status, vehicle_id, driver_id)
+		VALUES ('0f5c2a9e-8f43-4d6b-b8a3-6c2d9e4f7a22', 'Belgrano', 'Stops near Palermo', 'pending',
+			'171f1ef5-1b5b-4fed-a4b4-9b3d2845893c', 'e3b57a7a-fb4f-45bb-8fa6-81a406c1c596')`)
+
+	// Act
+	results, err := suite.repository.Search(context.Background(), `"belgrano"`, "route", 10)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 2)
+	assert.Equal(suite.T(), "0f5c2a9e-8f43-4d6b-b8a3-6c2d9e4f7a22", results[0].ID)
+	assert.Greater(suite.T(), results[0].Score, results[1].Score)
+}
+
+func (suite *RepositoryTestSuite) TestSearchFollowsUpdatesAndDeletes() {
+	// Arrange
+	suite.db.Exec("UPDATE driver SET name = 'Juan Pérez' WHERE id = 'e3b57a7a-fb4f-45bb-8fa6-81a406c1c596'")
+	suite.db.Exec("DELETE FROM vehicle WHERE id = '98fe6948-7adf-41b4-b096-2250e2ecb8db'")
+
+	// Act
+	renamed, err := suite.repository.Search(context.Background(), `"perez"`, "", 10)
+	assert.NoError(suite.T(), err)
+	stale, err := suite.repository.Search(context.Background(), `"john doe"`, "", 10)
+	assert.NoError(suite.T(), err)
+	deleted, err := suite.repository.Search(context.Background(), `"xyz789"`, "", 10)
+	assert.NoError(suite.T(), err)
+
+	// Assert
+	assert.Len(suite.T(), renamed, 1)
+	assert.Equal(suite.T(), "Juan <mark>Pérez</mark>", renamed[0].Title)
+	assert.Empty(suite.T(), stale)
+	assert.Empty(suite.T(), deleted)
+}
+
+func (suite *RepositoryTestSuite) TestSearchIndexKeyedByEntity() {
+	// Arrange
+	suite.db.Exec("UPDATE driver SET name = 'Juan Pérez' WHERE id = 'e3b57a7a-fb4f-45bb-8fa6-81a406c1c596'")
+	suite.db.Exec("DELETE FROM vehicle WHERE id = '98fe6948-7adf-41b4-b096-2250e2ecb8db'")
+
+	// Act
+	var entries, keyed, orphans int64
+	suite.db.Raw("SELECT COUNT(*) FROM search_index").Scan(&entries)
+	suite.db.Raw(`SELECT COUNT(*) FROM search_index s
+		JOIN search_index_entry e ON e.id = s.rowid AND e.entity_type = s.entity_type AND e.entity_id = s.entity_id`).
+		Scan(&keyed)
+	suite.db.Raw("SELECT COUNT(*) FROM search_index_entry WHERE id NOT IN (SELECT rowid FROM search_index)").Scan(&orphans)
+
+	// Assert
+	assert.NotZero(suite.T(), entries)
+	assert.Equal(suite.T(), entries, keyed)
+	assert.Zero(suite.T(), orphans)
+}
+
+func TestRepositorySuite(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
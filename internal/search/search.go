@@ -0,0 +1,38 @@
+package search
+
+import "errors"
+
+type Hit struct {
+	Type    string  `gorm:"column:entity_type" json:"type"`
+	ID      string  `gorm:"column:entity_id" json:"id"`
+	Title   string  `gorm:"column:title" json:"title"`
+	Snippet string  `gorm:"column:snippet" json:"snippet"`
+	Score   float64 `gorm:"column:score" json:"score"`
+}
+
+type Query struct {
+	Text  string `form:"q"`
+	Type  string `form:"type"`
+	Limit int    `form:"limit"`
+}
+
+type EntityType string
+
+const (
+	EntityTypeRoute      EntityType = "route"
+	EntityTypeRoutePoint EntityType = "route_point"
+	EntityTypeDriver     EntityType = "driver"
+	EntityTypeVehicle    EntityType = "vehicle"
+)
+
+var EntityTypeList = map[EntityType]string{
+	EntityTypeRoute:      "route",
+	EntityTypeRoutePoint: "route_point",
+	EntityTypeDriver:     "driver",
+	EntityTypeVehicle:    "vehicle",
+}
+
+var (
+	ErrQueryTooShort     = errors.New("query must contain at least one term of 3 or more characters")
+	ErrInvalidEntityType = errors.New("invalid entity type")
+)
@@ -0,0 +1,71 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+
+	// The trigram tokenizer can't match terms shorter than a trigram
+	minTermLength = 3
+)
+
+type Service interface {
+	Search(ctx context.Context, query *Query) ([]Hit, error)
+}
+
+type service struct {
+	repository Repository
+}
+
+func (s *service) Search(ctx context.Context, query *Query) ([]Hit, error) {
+	match, err := BuildMatchExpression(query.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Type != "" {
+		if _, ok := EntityTypeList[EntityType(query.Type)]; !ok {
+			return nil, ErrInvalidEntityType
+		}
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	return s.repository.Search(ctx, match, query.Type, limit)
+}
+
+// BuildMatchExpression turns free text into an FTS5 match expression where
+// every term must appear somewhere in the document. Terms are quoted so
+// user input can never be interpreted as FTS5 query syntax.
+func BuildMatchExpression(text string) (string, error) {
+	var terms []string
+	for _, term := range strings.Fields(text) {
+		if utf8.RuneCountInString(term) < minTermLength {
+			continue
+		}
+		terms = append(terms, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+
+	if len(terms) == 0 {
+		return "", ErrQueryTooShort
+	}
+
+	return strings.Join(terms, " "), nil
+}
+
+// static functions
+
+func NewService(repository Repository) *service {
+	return &service{repository: repository}
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMatchExpression(t *testing.T) {
+	// Act
+	result, err := BuildMatchExpression("  Córdoba   1111 ")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, `"Córdoba" "1111"`, result)
+}
+
+func TestBuildMatchExpressionSkipsShortTerms(t *testing.T) {
+	// Act
+	result, err := BuildMatchExpression("av cabildo")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, `"cabildo"`, result)
+}
+
+func TestBuildMatchExpressionEscapesQuotes(t *testing.T) {
+	// Act
+	result, err := BuildMatchExpression(`ABC"123 OR NEAR(x)`)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, `"ABC""123" "NEAR(x)"`, result)
+}
+
+func TestBuildMatchExpressionTooShort(t *testing.T) {
+	// Act
+	_, err := BuildMatchExpression("ab c")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrQueryTooShort)
+}
+
+func TestSearchInvalidEntityType(t *testing.T) {
+	// Arrange
+	service := NewService(nil)
+
+	// Act
+	_, err := service.Search(context.Background(), &Query{Text: "cabildo", Type: "purchase_order"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidEntityType)
+}
@@ -0,0 +1,121 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName names the tracer of the spans of the server
+const TracerName = "challenge-fravega"
+
+type Exporter string
+
+const (
+	// ExporterNone records no spans, though trace context is still propagated
+	ExporterNone   Exporter = "none"
+	ExporterOTLP   Exporter = "otlp"
+	ExporterStdout Exporter = "stdout"
+	ExporterFile   Exporter = "file"
+)
+
+var ExporterList = map[Exporter]string{
+	ExporterNone:   "none",
+	ExporterOTLP:   "otlp",
+	ExporterStdout: "stdout",
+	ExporterFile:   "file",
+}
+
+// Options configure where spans are exported and how many are sampled.
+type Options struct {
+	ServiceName string
+	Exporter    Exporter
+	// Endpoint is the OTLP/HTTP endpoint URL, such as
+	// http://localhost:4318, or that of the OTEL_EXPORTER_OTLP_* variables
+	// when empty
+	Endpoint string
+	// File receives the spans of the file exporter, one JSON span per line
+	File string
+	// SampleRatio is the share of the traces started here that are sampled.
+	// Traces started upstream follow the decision of their parent.
+	SampleRatio float64
+}
+
+// Tracer returns the tracer of the server, a no-op until Setup.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Start starts an internal span, such as that of a service method.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// static functions
+
+// Setup installs the global tracer provider and the W3C trace context and
+// baggage propagators. The returned function flushes the spans and must be
+// called on shutdown.
+func Setup(ctx context.Context, options Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if options.Exporter == ExporterNone || options.Exporter == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var closeFile func() error
+	var err error
+	switch options.Exporter {
+	case ExporterOTLP:
+		var clientOptions []otlptracehttp.Option
+		if options.Endpoint != "" {
+			clientOptions = append(clientOptions, otlptracehttp.WithEndpointURL(options.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, clientOptions...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		file, openErr := os.OpenFile(options.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if openErr != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", openErr)
+		}
+		closeFile = file.Close
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", options.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(options.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeFile != nil {
+			err = errors.Join(err, closeFile())
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+func spanNames(recorder *tracetest.SpanRecorder) []string {
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	return names
+}
+
+func TestGormPlugin(t *testing.T) {
+	// Arrange
+	recorder := setupRecorder(t)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Use(NewGormPlugin("sqlite")))
+	assert.NoError(t, db.Exec("CREATE TABLE route (id TEXT PRIMARY KEY)").Error)
+
+	// Act
+	ctx, span := Start(context.Background(), "route.GetRoutes")
+	var ids []string
+	err = db.WithContext(ctx).Table("route").Pluck("id", &ids).Error
+	span.End()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"query route", "route.GetRoutes"}, spanNames(recorder))
+	query := recorder.Ended()[0]
+	assert.Equal(t, span.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Contains(t, query.Attributes(), attribute.String("db.system.name", "sqlite"))
+}
+
+func TestTransport(t *testing.T) {
+	// Arrange
+	recorder := setupRecorder(t)
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: NewTransport("purchase_order", nil)}
+
+	// Act
+	ctx, span := Start(context.Background(), "notification.Handle")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/purchase-orders/1", nil)
+	res, err := client.Do(req)
+	span.End()
+
+	// Assert
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, []string{"purchase_order GET", "notification.Handle"}, spanNames(recorder))
+	outbound := recorder.Ended()[0]
+	assert.Contains(t, traceparent, outbound.SpanContext().TraceID().String())
+	assert.Contains(t, traceparent, outbound.SpanContext().SpanID().String())
+	assert.Equal(t, "Error", outbound.Status().Code.String())
+}
+
+func TestSetupFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "traces.json")
+
+	// Act
+	shutdown, err := Setup(context.Background(), Options{ServiceName: "test", Exporter: ExporterFile, File: path, SampleRatio: 1})
+	assert.NoError(t, err)
+	_, span := Start(context.Background(), "route.CreateRoute")
+	span.End()
+	shutdownErr := shutdown(context.Background())
+	content, readErr := os.ReadFile(path)
+
+	// Assert
+	assert.NoError(t, shutdownErr)
+	assert.NoError(t, readErr)
+	assert.Contains(t, string(content), `"Name":"route.CreateRoute"`)
+	assert.Contains(t, string(content), `"Value":"test"`)
+}
@@ -0,0 +1,18 @@
+package vehicle
+
+type CreateMaintenance struct {
+	Type          string `json:"type"`
+	ScheduledDate string `json:"scheduled_date"`
+	Notes         string `json:"notes"`
+}
+
+// UpdateMaintenance completes or cancels a scheduled maintenance, or moves
+// it to another day. The completion date defaults to today and the odometer
+// to the last reading of the vehicle.
+type UpdateMaintenance struct {
+	Status        *string `json:"status"`
+	ScheduledDate *string `json:"scheduled_date"`
+	CompletedDate *string `json:"completed_date"`
+	Odometer      *int    `json:"odometer"`
+	Notes         *string `json:"notes"`
+}
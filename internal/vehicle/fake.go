@@ -0,0 +1,175 @@
+package vehicle
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FakeRepository is an in-memory Repository for the tests of the services
+// built on vehicles, their maintenance and their odometer readings.
+type FakeRepository struct {
+	mu           sync.Mutex
+	vehicles     []Vehicle
+	maintenances []Maintenance
+	readings     []OdometerReading
+}
+
+func (f *FakeRepository) CreateVehicle(ctx context.Context, vehicle *Vehicle) (*Vehicle, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if vehicle.ID == uuid.Nil {
+		vehicle.ID = uuid.New()
+	}
+	f.vehicles = append(f.vehicles, *vehicle)
+	return vehicle, nil
+}
+
+func (f *FakeRepository) GetVehicle(ctx context.Context, id uuid.UUID) (*Vehicle, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, vehicle := range f.vehicles {
+		if vehicle.ID == id {
+			return &vehicle, nil
+		}
+	}
+	return &Vehicle{}, gorm.ErrRecordNotFound
+}
+
+func (f *FakeRepository) GetVehicles(ctx context.Context) ([]Vehicle, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Vehicle{}, f.vehicles...), nil
+}
+
+func (f *FakeRepository) UpdateVehicle(ctx context.Context, vehicle *Vehicle) (*Vehicle, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.vehicles {
+		if f.vehicles[i].ID == vehicle.ID {
+			f.vehicles[i] = *vehicle
+			return vehicle, nil
+		}
+	}
+	f.vehicles = append(f.vehicles, *vehicle)
+	return vehicle, nil
+}
+
+func (f *FakeRepository) GetMaintenances(ctx context.Context, vehicleID uuid.UUID) ([]Maintenance, error) {
+	maintenances := f.findMaintenances(func(maintenance Maintenance) bool { return maintenance.VehicleID == vehicleID })
+	sort.SliceStable(maintenances, func(i, j int) bool {
+		return maintenances[i].ScheduledDate > maintenances[j].ScheduledDate
+	})
+	return maintenances, nil
+}
+
+func (f *FakeRepository) GetMaintenance(ctx context.Context, vehicleID uuid.UUID, id uuid.UUID) (*Maintenance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, maintenance := range f.maintenances {
+		if maintenance.VehicleID == vehicleID && maintenance.ID == id {
+			return &maintenance, nil
+		}
+	}
+	return &Maintenance{}, gorm.ErrRecordNotFound
+}
+
+func (f *FakeRepository) GetMaintenancesUntil(ctx context.Context, vehicleID uuid.UUID, date string) ([]Maintenance, error) {
+	return f.findMaintenances(func(maintenance Maintenance) bool {
+		return maintenance.VehicleID == vehicleID && maintenance.ScheduledDate <= date &&
+			maintenance.Status != MaintenanceStatusList[MaintenanceStatusCancelled]
+	}), nil
+}
+
+func (f *FakeRepository) GetLastMaintenances(ctx context.Context) (map[uuid.UUID]Maintenance, error) {
+	completed := f.findMaintenances(func(maintenance Maintenance) bool {
+		return maintenance.Status == MaintenanceStatusList[MaintenanceStatusCompleted]
+	})
+	sort.SliceStable(completed, func(i, j int) bool {
+		return valueOf(completed[i].CompletedDate) < valueOf(completed[j].CompletedDate)
+	})
+
+	last := map[uuid.UUID]Maintenance{}
+	for _, maintenance := range completed {
+		last[maintenance.VehicleID] = maintenance
+	}
+	return last, nil
+}
+
+func (f *FakeRepository) CreateMaintenance(ctx context.Context, maintenance *Maintenance) (*Maintenance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if maintenance.ID == uuid.Nil {
+		maintenance.ID = uuid.New()
+	}
+	f.maintenances = append(f.maintenances, *maintenance)
+	return maintenance, nil
+}
+
+func (f *FakeRepository) UpdateMaintenance(ctx context.Context, maintenance *Maintenance) (*Maintenance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.maintenances {
+		if f.maintenances[i].ID == maintenance.ID {
+			f.maintenances[i] = *maintenance
+			return maintenance, nil
+		}
+	}
+	f.maintenances = append(f.maintenances, *maintenance)
+	return maintenance, nil
+}
+
+func (f *FakeRepository) GetOdometerReadings(ctx context.Context, vehicleID uuid.UUID) ([]OdometerReading, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	readings := []OdometerReading{}
+	for _, reading := range f.readings {
+		if reading.VehicleID == vehicleID {
+			readings = append(readings, reading)
+		}
+	}
+	sort.SliceStable(readings, func(i, j int) bool { return readings[i].RecordedAt.After(readings[j].RecordedAt) })
+	return readings, nil
+}
+
+func (f *FakeRepository) CreateOdometerReading(ctx context.Context, reading *OdometerReading) (*OdometerReading, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if reading.ID == uuid.Nil {
+		reading.ID = uuid.New()
+	}
+	if reading.RecordedAt.IsZero() {
+		reading.RecordedAt = time.Now()
+	}
+	f.readings = append(f.readings, *reading)
+	for i := range f.vehicles {
+		if f.vehicles[i].ID == reading.VehicleID {
+			f.vehicles[i].Odometer = reading.Kilometers
+		}
+	}
+	return reading, nil
+}
+
+// findMaintenances returns the maintenance that matches, in creation order.
+func (f *FakeRepository) findMaintenances(match func(Maintenance) bool) []Maintenance {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	maintenances := []Maintenance{}
+	for _, maintenance := range f.maintenances {
+		if match(maintenance) {
+			maintenances = append(maintenances, maintenance)
+		}
+	}
+	return maintenances
+}
+
+// static functions
+
+// NewFakeRepository creates an in-memory repository holding the vehicles.
+func NewFakeRepository(vehicles ...Vehicle) *FakeRepository {
+	return &FakeRepository{vehicles: append([]Vehicle{}, vehicles...)}
+}
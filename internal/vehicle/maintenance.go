@@ -0,0 +1,194 @@
+package vehicle
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DateLayout is the format of the days maintenance is scheduled and completed
+const DateLayout = time.DateOnly
+
+// DefaultMaintenancePolicy services vehicles every 10,000 km or six months,
+// warning two weeks or 500 km ahead
+var DefaultMaintenancePolicy = MaintenancePolicy{IntervalKm: 10000, IntervalDays: 180, WarningKm: 500, WarningDays: 14}
+
+var (
+	ErrInvalidMaintenance           = errors.New("invalid maintenance, type must be one of oil_change, tires, brakes, inspection or repair and scheduled_date formatted as YYYY-MM-DD")
+	ErrInvalidMaintenanceTransition = errors.New("invalid maintenance status transition")
+	ErrInvalidDate                  = errors.New("invalid date, must be formatted as YYYY-MM-DD")
+	ErrInvalidOdometer              = errors.New("odometer reading must be positive")
+	ErrOdometerDecreased            = errors.New("odometer reading is lower than the last reading of the vehicle")
+	ErrVehicleInMaintenance         = errors.New("vehicle is in maintenance")
+)
+
+// Maintenance is a visit of a vehicle to the workshop. The vehicle is in
+// maintenance from the scheduled date until it is completed, the completion
+// day excluded.
+type Maintenance struct {
+	ID            uuid.UUID `gorm:"column:id" json:"id"`
+	VehicleID     uuid.UUID `gorm:"column:vehicle_id" json:"vehicle_id"`
+	Type          string    `gorm:"column:type" json:"type"`
+	Status        string    `gorm:"column:status" json:"status"`
+	ScheduledDate string    `gorm:"column:scheduled_date" json:"scheduled_date"`
+	CompletedDate *string   `gorm:"column:completed_date" json:"completed_date"`
+	// Odometer is the reading of the vehicle when the maintenance was completed
+	Odometer  *int      `gorm:"column:odometer" json:"odometer"`
+	Notes     string    `gorm:"column:notes" json:"notes"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (Maintenance) TableName() string {
+	return "vehicle_maintenance"
+}
+
+// InMaintenance tells whether the maintenance keeps the vehicle in the
+// workshop on a date formatted as DateLayout.
+func (m *Maintenance) InMaintenance(date string) bool {
+	switch MaintenanceStatus(m.Status) {
+	case MaintenanceStatusScheduled:
+		return m.ScheduledDate <= date
+	case MaintenanceStatusCompleted:
+		return m.ScheduledDate <= date && m.CompletedDate != nil && date < *m.CompletedDate
+	default:
+		return false
+	}
+}
+
+type MaintenanceType string
+
+const (
+	MaintenanceTypeOilChange  MaintenanceType = "oil_change"
+	MaintenanceTypeTires      MaintenanceType = "tires"
+	MaintenanceTypeBrakes     MaintenanceType = "brakes"
+	MaintenanceTypeInspection MaintenanceType = "inspection"
+	MaintenanceTypeRepair     MaintenanceType = "repair"
+)
+
+var MaintenanceTypeList = map[MaintenanceType]string{
+	MaintenanceTypeOilChange:  "oil_change",
+	MaintenanceTypeTires:      "tires",
+	MaintenanceTypeBrakes:     "brakes",
+	MaintenanceTypeInspection: "inspection",
+	MaintenanceTypeRepair:     "repair",
+}
+
+type MaintenanceStatus string
+
+const (
+	MaintenanceStatusScheduled MaintenanceStatus = "scheduled"
+	MaintenanceStatusCompleted MaintenanceStatus = "completed"
+	MaintenanceStatusCancelled MaintenanceStatus = "cancelled"
+)
+
+var MaintenanceStatusList = map[MaintenanceStatus]string{
+	MaintenanceStatusScheduled: "scheduled",
+	MaintenanceStatusCompleted: "completed",
+	MaintenanceStatusCancelled: "cancelled",
+}
+
+// OdometerReading is the distance a vehicle had covered, in kilometres, when
+// a route was started or completed, a maintenance completed or someone read it.
+type OdometerReading struct {
+	ID         uuid.UUID  `gorm:"column:id" json:"id"`
+	VehicleID  uuid.UUID  `gorm:"column:vehicle_id" json:"vehicle_id"`
+	RouteID    *uuid.UUID `gorm:"column:route_id" json:"route_id"`
+	Kilometers int        `gorm:"column:kilometers" json:"kilometers"`
+	Event      string     `gorm:"column:event" json:"event"`
+	RecordedAt time.Time  `gorm:"column:recorded_at" json:"recorded_at"`
+}
+
+type OdometerEvent string
+
+const (
+	OdometerEventRouteStarted   OdometerEvent = "route_started"
+	OdometerEventRouteCompleted OdometerEvent = "route_completed"
+	OdometerEventMaintenance    OdometerEvent = "maintenance"
+	OdometerEventManual         OdometerEvent = "manual"
+)
+
+var OdometerEventList = map[OdometerEvent]string{
+	OdometerEventRouteStarted:   "route_started",
+	OdometerEventRouteCompleted: "route_completed",
+	OdometerEventMaintenance:    "maintenance",
+	OdometerEventManual:         "manual",
+}
+
+// MaintenancePolicy is how often vehicles are serviced, by distance or by
+// time since their last completed maintenance, and how far ahead to warn.
+type MaintenancePolicy struct {
+	IntervalKm   int
+	IntervalDays int
+	WarningKm    int
+	WarningDays  int
+}
+
+// MaintenanceAlert warns that a vehicle is due for maintenance by date,
+// distance or both, as listed in Reasons. Overdue alerts are past due.
+type MaintenanceAlert struct {
+	VehicleID               uuid.UUID `json:"vehicle_id"`
+	PlateNumber             string    `json:"plate_number"`
+	Odometer                int       `json:"odometer"`
+	LastMaintenanceDate     string    `json:"last_maintenance_date"`
+	LastMaintenanceOdometer int       `json:"last_maintenance_odometer"`
+	DueDate                 string    `json:"due_date"`
+	DueOdometer             int       `json:"due_odometer"`
+	Reasons                 []string  `json:"reasons"`
+	Overdue                 bool      `json:"overdue"`
+}
+
+// static functions
+
+// NewMaintenanceAlert returns the alert of a vehicle given its last completed
+// maintenance, nil when it is not due yet. Vehicles never serviced count
+// from the day they were added.
+func NewMaintenanceAlert(vehicle Vehicle, last *Maintenance, today time.Time, policy MaintenancePolicy) *MaintenanceAlert {
+	alert := &MaintenanceAlert{
+		VehicleID:           vehicle.ID,
+		PlateNumber:         vehicle.PlateNumber,
+		Odometer:            vehicle.Odometer,
+		LastMaintenanceDate: vehicle.CreatedAt.In(today.Location()).Format(DateLayout),
+	}
+	if last != nil && last.CompletedDate != nil {
+		alert.LastMaintenanceDate = *last.CompletedDate
+		if last.Odometer != nil {
+			alert.LastMaintenanceOdometer = *last.Odometer
+		}
+	}
+
+	lastDate, err := time.ParseInLocation(DateLayout, alert.LastMaintenanceDate, today.Location())
+	if err != nil {
+		return nil
+	}
+	dueDate := lastDate.AddDate(0, 0, policy.IntervalDays)
+	alert.DueDate = dueDate.Format(DateLayout)
+	alert.DueOdometer = alert.LastMaintenanceOdometer + policy.IntervalKm
+
+	alert.Reasons = []string{}
+	if !today.Before(dueDate.AddDate(0, 0, -policy.WarningDays)) {
+		alert.Reasons = append(alert.Reasons, "date")
+	}
+	if vehicle.Odometer >= alert.DueOdometer-policy.WarningKm {
+		alert.Reasons = append(alert.Reasons, "distance")
+	}
+	if len(alert.Reasons) == 0 {
+		return nil
+	}
+	alert.Overdue = !today.Before(dueDate) || vehicle.Odometer >= alert.DueOdometer
+	return alert
+}
+
+// parseDay parses a date as midnight in location, today when empty.
+func parseDay(date string, location *time.Location) (time.Time, error) {
+	if date == "" {
+		now := time.Now().In(location)
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location), nil
+	}
+	day, err := time.ParseInLocation(DateLayout, date, location)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return day, nil
+}
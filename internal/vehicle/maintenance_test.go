@@ -0,0 +1,43 @@
+package vehicle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMaintenance(t *testing.T) {
+	completedDate := "2025-03-12"
+	scheduled := Maintenance{Status: "scheduled", ScheduledDate: "2025-03-10"}
+	completed := Maintenance{Status: "completed", ScheduledDate: "2025-03-10", CompletedDate: &completedDate}
+	cancelled := Maintenance{Status: "cancelled", ScheduledDate: "2025-03-10"}
+
+	assert.False(t, scheduled.InMaintenance("2025-03-09"))
+	assert.True(t, scheduled.InMaintenance("2025-03-10"))
+	assert.True(t, scheduled.InMaintenance("2025-04-10"))
+	assert.True(t, completed.InMaintenance("2025-03-11"))
+	assert.False(t, completed.InMaintenance("2025-03-12"))
+	assert.False(t, cancelled.InMaintenance("2025-03-10"))
+}
+
+func TestNewMaintenanceAlert(t *testing.T) {
+	today := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	vehicle := Vehicle{PlateNumber: "AB123CD", Odometer: 4000, CreatedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+	lastDate, lastOdometer := "2025-08-01", 3000
+
+	alert := NewMaintenanceAlert(vehicle, nil, today, DefaultMaintenancePolicy)
+	assert.NotNil(t, alert)
+	assert.Equal(t, "2025-03-01", alert.LastMaintenanceDate)
+	assert.Equal(t, "2025-08-28", alert.DueDate)
+	assert.Equal(t, 10000, alert.DueOdometer)
+	assert.Equal(t, []string{"date"}, alert.Reasons)
+	assert.True(t, alert.Overdue)
+
+	assert.Nil(t, NewMaintenanceAlert(vehicle, &Maintenance{CompletedDate: &lastDate, Odometer: &lastOdometer}, today, DefaultMaintenancePolicy))
+
+	vehicle.Odometer = 12600
+	alert = NewMaintenanceAlert(vehicle, &Maintenance{CompletedDate: &lastDate, Odometer: &lastOdometer}, today, DefaultMaintenancePolicy)
+	assert.Equal(t, []string{"distance"}, alert.Reasons)
+	assert.False(t, alert.Overdue)
+}
@@ -0,0 +1,11 @@
+package vehicle
+
+import "github.com/google/uuid"
+
+// RecordOdometer is a reading of the odometer of a vehicle. Readings without
+// an event are manual.
+type RecordOdometer struct {
+	Kilometers int        `json:"kilometers"`
+	Event      string     `json:"-"`
+	RouteId    *uuid.UUID `json:"-"`
+}
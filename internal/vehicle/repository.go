@@ -1,37 +1,145 @@
 package vehicle
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type Repository struct {
+// Repository stores the vehicles, their maintenance and their odometer
+// readings.
+type Repository interface {
+	CreateVehicle(ctx context.Context, vehicle *Vehicle) (*Vehicle, error)
+	GetVehicle(ctx context.Context, id uuid.UUID) (*Vehicle, error)
+	GetVehicles(ctx context.Context) ([]Vehicle, error)
+	UpdateVehicle(ctx context.Context, vehicle *Vehicle) (*Vehicle, error)
+	GetMaintenances(ctx context.Context, vehicleID uuid.UUID) ([]Maintenance, error)
+	GetMaintenance(ctx context.Context, vehicleID uuid.UUID, id uuid.UUID) (*Maintenance, error)
+	GetMaintenancesUntil(ctx context.Context, vehicleID uuid.UUID, date string) ([]Maintenance, error)
+	GetLastMaintenances(ctx context.Context) (map[uuid.UUID]Maintenance, error)
+	CreateMaintenance(ctx context.Context, maintenance *Maintenance) (*Maintenance, error)
+	UpdateMaintenance(ctx context.Context, maintenance *Maintenance) (*Maintenance, error)
+	GetOdometerReadings(ctx context.Context, vehicleID uuid.UUID) ([]OdometerReading, error)
+	CreateOdometerReading(ctx context.Context, reading *OdometerReading) (*OdometerReading, error)
+}
+
+type repository struct {
 	db *gorm.DB
 }
 
-func (r *Repository) CreateVehicle(vehicle *Vehicle) (*Vehicle, error) {
+func (r *repository) CreateVehicle(ctx context.Context, vehicle *Vehicle) (*Vehicle, error) {
 	if vehicle.ID == uuid.Nil {
 		vehicle.ID = uuid.New()
 	}
-	return vehicle, r.db.Create(vehicle).Error
+	return vehicle, r.db.WithContext(ctx).Create(vehicle).Error
 }
 
-func (r *Repository) GetVehicle(id uuid.UUID) (*Vehicle, error) {
+func (r *repository) GetVehicle(ctx context.Context, id uuid.UUID) (*Vehicle, error) {
 	var vehicle Vehicle
-	return &vehicle, r.db.First(&vehicle, "id = ?", id).Error
+	return &vehicle, r.db.WithContext(ctx).First(&vehicle, "id = ?", id).Error
 }
 
-func (r *Repository) GetVehicles() ([]Vehicle, error) {
+func (r *repository) GetVehicles(ctx context.Context) ([]Vehicle, error) {
 	var vehicles []Vehicle
-	return vehicles, r.db.Find(&vehicles).Error
+	return vehicles, r.db.WithContext(ctx).Find(&vehicles).Error
+}
+
+func (r *repository) UpdateVehicle(ctx context.Context, vehicle *Vehicle) (*Vehicle, error) {
+	return vehicle, r.db.WithContext(ctx).Save(vehicle).Error
+}
+
+func (r *repository) GetMaintenances(ctx context.Context, vehicleID uuid.UUID) ([]Maintenance, error) {
+	maintenances := []Maintenance{}
+	err := r.db.WithContext(ctx).Where("vehicle_id = ?", vehicleID).Order("scheduled_date DESC").Find(&maintenances).Error
+	return maintenances, err
+}
+
+func (r *repository) GetMaintenance(ctx context.Context, vehicleID uuid.UUID, id uuid.UUID) (*Maintenance, error) {
+	var maintenance Maintenance
+	return &maintenance, r.db.WithContext(ctx).First(&maintenance, "vehicle_id = ? AND id = ?", vehicleID, id).Error
+}
+
+// GetMaintenancesUntil returns the maintenance of a vehicle scheduled up to
+// a date, which may keep it in the workshop that day.
+func (r *repository) GetMaintenancesUntil(ctx context.Context, vehicleID uuid.UUID, date string) ([]Maintenance, error) {
+	maintenances := []Maintenance{}
+	err := r.db.WithContext(ctx).Where("vehicle_id = ? AND scheduled_date <= ? AND status <> ?",
+		vehicleID, date, MaintenanceStatusList[MaintenanceStatusCancelled]).
+		Find(&maintenances).Error
+	return maintenances, err
+}
+
+// GetLastMaintenances returns the last completed maintenance of every
+// vehicle serviced, by vehicle.
+func (r *repository) GetLastMaintenances(ctx context.Context) (map[uuid.UUID]Maintenance, error) {
+	var maintenances []Maintenance
+	err := r.db.WithContext(ctx).Where("status = ?", MaintenanceStatusList[MaintenanceStatusCompleted]).
+		Order("completed_date").Find(&maintenances).Error
+	if err != nil {
+		return nil, err
+	}
+
+	last := map[uuid.UUID]Maintenance{}
+	for _, maintenance := range maintenances {
+		last[maintenance.VehicleID] = maintenance
+	}
+	return last, nil
+}
+
+func (r *repository) CreateMaintenance(ctx context.Context, maintenance *Maintenance) (*Maintenance, error) {
+	if maintenance.ID == uuid.Nil {
+		maintenance.ID = uuid.New()
+	}
+	return maintenance, r.db.WithContext(ctx).Create(maintenance).Error
+}
+
+func (r *repository) UpdateMaintenance(ctx context.Context, maintenance *Maintenance) (*Maintenance, error) {
+	return maintenance, r.db.WithContext(ctx).Save(maintenance).Error
+}
+
+func (r *repository) GetOdometerReadings(ctx context.Context, vehicleID uuid.UUID) ([]OdometerReading, error) {
+	readings := []OdometerReading{}
+	err := r.db.WithContext(ctx).Where("vehicle_id = ?", vehicleID).Order("recorded_at DESC").Find(&readings).Error
+	return readings, err
 }
 
-func (r *Repository) UpdateVehicle(vehicle *Vehicle) (*Vehicle, error) {
-	return vehicle, r.db.Save(vehicle).Error
+// CreateOdometerReading stores the reading and makes it the odometer of the
+// vehicle.
+func (r *repository) CreateOdometerReading(ctx context.Context, reading *OdometerReading) (*OdometerReading, error) {
+	return reading, r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return AppendOdometerReading(tx, reading)
+	})
 }
 
 // static functions
 
-func NewRepository(db *gorm.DB) *Repository {
-	return &Repository{db: db}
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
+
+// AppendOdometerReading stores the reading within the transaction of another
+// change and makes it the odometer of the vehicle. ErrOdometerDecreased is
+// returned when a higher reading was stored since the reading was checked.
+func AppendOdometerReading(tx *gorm.DB, reading *OdometerReading) error {
+	if reading.ID == uuid.Nil {
+		reading.ID = uuid.New()
+	}
+	if reading.RecordedAt.IsZero() {
+		reading.RecordedAt = time.Now()
+	}
+	if err := tx.Create(reading).Error; err != nil {
+		return err
+	}
+	result := tx.Model(&Vehicle{}).
+		Where("id = ? AND odometer <= ?", reading.VehicleID, reading.Kilometers).
+		Update("odometer", reading.Kilometers)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOdometerDecreased
+	}
+	return nil
 }
@@ -1,6 +1,7 @@
 package vehicle
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -14,7 +15,7 @@ import (
 type RepositoryTestSuite struct {
 	suite.Suite
 	db         *gorm.DB
-	repository *Repository
+	repository Repository
 }
 
 func (suite *RepositoryTestSuite) SetupTest() {
@@ -23,7 +24,7 @@ func (suite *RepositoryTestSuite) SetupTest() {
 		suite.T().Fatal(err)
 	}
 
-	err = db.AutoMigrate(&Vehicle{})
+	err = db.AutoMigrate(&Vehicle{}, &Maintenance{}, &OdometerReading{})
 	if err != nil {
 		suite.T().Fatal(err)
 	}
@@ -39,7 +40,7 @@ func (suite *RepositoryTestSuite) TestCreateVehicle() {
 	}
 
 	// Act
-	result, err := suite.repository.CreateVehicle(vehicle)
+	result, err := suite.repository.CreateVehicle(context.Background(), vehicle)
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -61,7 +62,7 @@ func (suite *RepositoryTestSuite) TestGetVehicle() {
 	suite.db.Create(vehicle)
 
 	// Act
-	result, err := suite.repository.GetVehicle(vehicleID)
+	result, err := suite.repository.GetVehicle(context.Background(), vehicleID)
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -88,7 +89,7 @@ func (suite *RepositoryTestSuite) TestGetVehicles() {
 	suite.db.Create(vehicle2)
 
 	// Act
-	results, err := suite.repository.GetVehicles()
+	results, err := suite.repository.GetVehicles(context.Background())
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -109,7 +110,7 @@ func (suite *RepositoryTestSuite) TestUpdateVehicle() {
 	vehicle.PlateNumber = "NEW456"
 
 	// Act
-	result, err := suite.repository.UpdateVehicle(vehicle)
+	result, err := suite.repository.UpdateVehicle(context.Background(), vehicle)
 
 	// Assert
 	assert.NoError(suite.T(), err)
@@ -121,6 +122,65 @@ func (suite *RepositoryTestSuite) TestUpdateVehicle() {
 	assert.Equal(suite.T(), "NEW456", updatedVehicle.PlateNumber)
 }
 
+func (suite *RepositoryTestSuite) TestCreateOdometerReading() {
+	// Arrange
+	vehicle := &Vehicle{ID: uuid.New(), PlateNumber: "AB123CD", Odometer: 1000}
+	suite.db.Create(vehicle)
+
+	// Act
+	reading, err := suite.repository.CreateOdometerReading(context.Background(), &OdometerReading{VehicleID: vehicle.ID, Kilometers: 1250, Event: "manual"})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), reading.RecordedAt.IsZero())
+	stored, _ := suite.repository.GetVehicle(context.Background(), vehicle.ID)
+	assert.Equal(suite.T(), 1250, stored.Odometer)
+	readings, _ := suite.repository.GetOdometerReadings(context.Background(), vehicle.ID)
+	assert.Len(suite.T(), readings, 1)
+}
+
+func (suite *RepositoryTestSuite) TestGetMaintenancesUntil() {
+	// Arrange
+	vehicleID := uuid.New()
+	for _, maintenance := range []Maintenance{
+		{VehicleID: vehicleID, Type: "tires", Status: "scheduled", ScheduledDate: "2025-03-10"},
+		{VehicleID: vehicleID, Type: "brakes", Status: "cancelled", ScheduledDate: "2025-03-10"},
+		{VehicleID: vehicleID, Type: "repair", Status: "scheduled", ScheduledDate: "2025-03-20"},
+		{VehicleID: uuid.New(), Type: "tires", Status: "scheduled", ScheduledDate: "2025-03-10"},
+	} {
+		suite.repository.CreateMaintenance(context.Background(), &maintenance)
+	}
+
+	// Act
+	result, err := suite.repository.GetMaintenancesUntil(context.Background(), vehicleID, "2025-03-12")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), "tires", result[0].Type)
+}
+
+func (suite *RepositoryTestSuite) TestGetLastMaintenances() {
+	// Arrange
+	vehicleID := uuid.New()
+	first, last := "2025-01-10", "2025-03-10"
+	for _, maintenance := range []Maintenance{
+		{VehicleID: vehicleID, Type: "repair", Status: "completed", ScheduledDate: last, CompletedDate: &last},
+		{VehicleID: vehicleID, Type: "tires", Status: "completed", ScheduledDate: first, CompletedDate: &first},
+		{VehicleID: vehicleID, Type: "brakes", Status: "scheduled", ScheduledDate: "2025-04-10"},
+	} {
+		suite.repository.CreateMaintenance(context.Background(), &maintenance)
+	}
+
+	// Act
+	result, err := suite.repository.GetLastMaintenances(context.Background())
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), "repair", result[vehicleID].Type)
+}
+
 func TestRepositorySuite(t *testing.T) {
 	suite.Run(t, new(RepositoryTestSuite))
 }
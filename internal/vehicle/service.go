@@ -1,31 +1,241 @@
 package vehicle
 
-import "github.com/google/uuid"
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Service interface {
-	CreateVehicle(vehicle *Vehicle) (*Vehicle, error)
-	GetVehicle(id uuid.UUID) (*Vehicle, error)
-	GetVehicles() ([]Vehicle, error)
+	CreateVehicle(ctx context.Context, vehicle *Vehicle) (*Vehicle, error)
+	GetVehicle(ctx context.Context, id uuid.UUID) (*Vehicle, error)
+	GetVehicles(ctx context.Context) ([]Vehicle, error)
+	GetMaintenances(ctx context.Context, vehicleID uuid.UUID) ([]Maintenance, error)
+	ScheduleMaintenance(ctx context.Context, vehicleID uuid.UUID, createMaintenance *CreateMaintenance) (*Maintenance, error)
+	UpdateMaintenance(ctx context.Context, vehicleID uuid.UUID, id uuid.UUID, update *UpdateMaintenance) (*Maintenance, error)
+	GetMaintenanceAlerts(ctx context.Context) ([]MaintenanceAlert, error)
+	GetOdometerReadings(ctx context.Context, vehicleID uuid.UUID) ([]OdometerReading, error)
+	RecordOdometer(ctx context.Context, vehicleID uuid.UUID, record *RecordOdometer) (*OdometerReading, error)
+	NewOdometerReading(ctx context.Context, vehicleID uuid.UUID, record *RecordOdometer) (*OdometerReading, error)
+	CheckAvailability(ctx context.Context, vehicleID uuid.UUID, date string) error
 }
 
 type service struct {
-	repository *Repository
+	repository Repository
+	policy     MaintenancePolicy
+	location   *time.Location
+}
+
+func (s *service) CreateVehicle(ctx context.Context, vehicle *Vehicle) (*Vehicle, error) {
+	return s.repository.CreateVehicle(ctx, vehicle)
+}
+
+func (s *service) GetVehicle(ctx context.Context, id uuid.UUID) (*Vehicle, error) {
+	return s.repository.GetVehicle(ctx, id)
+}
+
+func (s *service) GetVehicles(ctx context.Context) ([]Vehicle, error) {
+	return s.repository.GetVehicles(ctx)
+}
+
+func (s *service) GetMaintenances(ctx context.Context, vehicleID uuid.UUID) ([]Maintenance, error) {
+	if _, err := s.repository.GetVehicle(ctx, vehicleID); err != nil {
+		return nil, err
+	}
+	return s.repository.GetMaintenances(ctx, vehicleID)
+}
+
+func (s *service) ScheduleMaintenance(ctx context.Context, vehicleID uuid.UUID, createMaintenance *CreateMaintenance) (*Maintenance, error) {
+	if _, ok := MaintenanceTypeList[MaintenanceType(createMaintenance.Type)]; !ok {
+		return nil, ErrInvalidMaintenance
+	}
+	if _, err := time.Parse(DateLayout, createMaintenance.ScheduledDate); err != nil {
+		return nil, ErrInvalidMaintenance
+	}
+	if _, err := s.repository.GetVehicle(ctx, vehicleID); err != nil {
+		return nil, err
+	}
+
+	return s.repository.CreateMaintenance(ctx, &Maintenance{
+		VehicleID:     vehicleID,
+		Type:          createMaintenance.Type,
+		Status:        MaintenanceStatusList[MaintenanceStatusScheduled],
+		ScheduledDate: createMaintenance.ScheduledDate,
+		Notes:         createMaintenance.Notes,
+	})
+}
+
+// UpdateMaintenance changes a scheduled maintenance. Completing it records
+// the odometer of the vehicle, which restarts the count to the next one.
+func (s *service) UpdateMaintenance(ctx context.Context, vehicleID uuid.UUID, id uuid.UUID, update *UpdateMaintenance) (*Maintenance, error) {
+	maintenance, err := s.repository.GetMaintenance(ctx, vehicleID, id)
+	if err != nil {
+		return nil, err
+	}
+	if maintenance.Status != MaintenanceStatusList[MaintenanceStatusScheduled] {
+		return nil, fmt.Errorf("%w: maintenance is %s", ErrInvalidMaintenanceTransition, maintenance.Status)
+	}
+
+	if update.ScheduledDate != nil {
+		if _, err := time.Parse(DateLayout, *update.ScheduledDate); err != nil {
+			return nil, ErrInvalidDate
+		}
+		maintenance.ScheduledDate = *update.ScheduledDate
+	}
+	if update.Notes != nil {
+		maintenance.Notes = *update.Notes
+	}
+	if update.Status != nil {
+		switch MaintenanceStatus(*update.Status) {
+		case MaintenanceStatusScheduled:
+		case MaintenanceStatusCancelled:
+			maintenance.Status = *update.Status
+		case MaintenanceStatusCompleted:
+			if err := s.complete(ctx, maintenance, update); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: to %s", ErrInvalidMaintenanceTransition, *update.Status)
+		}
+	}
+
+	return s.repository.UpdateMaintenance(ctx, maintenance)
+}
+
+func (s *service) complete(ctx context.Context, maintenance *Maintenance, update *UpdateMaintenance) error {
+	day, err := parseDay(valueOf(update.CompletedDate), s.location)
+	if err != nil {
+		return err
+	}
+	completedDate := day.Format(DateLayout)
+
+	odometer := update.Odometer
+	if odometer != nil {
+		if _, err := s.RecordOdometer(ctx, maintenance.VehicleID, &RecordOdometer{
+			Kilometers: *odometer,
+			Event:      OdometerEventList[OdometerEventMaintenance],
+		}); err != nil {
+			return err
+		}
+	} else {
+		vehicle, err := s.repository.GetVehicle(ctx, maintenance.VehicleID)
+		if err != nil {
+			return err
+		}
+		odometer = &vehicle.Odometer
+	}
+
+	maintenance.Status = MaintenanceStatusList[MaintenanceStatusCompleted]
+	maintenance.CompletedDate = &completedDate
+	maintenance.Odometer = odometer
+	return nil
+}
+
+// GetMaintenanceAlerts lists the vehicles due for maintenance, by date or
+// distance, according to the maintenance policy.
+func (s *service) GetMaintenanceAlerts(ctx context.Context) ([]MaintenanceAlert, error) {
+	vehicles, err := s.repository.GetVehicles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	last, err := s.repository.GetLastMaintenances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	today, _ := parseDay("", s.location)
+
+	alerts := []MaintenanceAlert{}
+	for _, vehicle := range vehicles {
+		var maintenance *Maintenance
+		if m, ok := last[vehicle.ID]; ok {
+			maintenance = &m
+		}
+		if alert := NewMaintenanceAlert(vehicle, maintenance, today, s.policy); alert != nil {
+			alerts = append(alerts, *alert)
+		}
+	}
+	return alerts, nil
 }
 
-func (s *service) CreateVehicle(vehicle *Vehicle) (*Vehicle, error) {
-	return s.repository.CreateVehicle(vehicle)
+func (s *service) GetOdometerReadings(ctx context.Context, vehicleID uuid.UUID) ([]OdometerReading, error) {
+	if _, err := s.repository.GetVehicle(ctx, vehicleID); err != nil {
+		return nil, err
+	}
+	return s.repository.GetOdometerReadings(ctx, vehicleID)
 }
 
-func (s *service) GetVehicle(id uuid.UUID) (*Vehicle, error) {
-	return s.repository.GetVehicle(id)
+// RecordOdometer stores a reading of the odometer of a vehicle, which can
+// only go up.
+func (s *service) RecordOdometer(ctx context.Context, vehicleID uuid.UUID, record *RecordOdometer) (*OdometerReading, error) {
+	reading, err := s.NewOdometerReading(ctx, vehicleID, record)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.CreateOdometerReading(ctx, reading)
 }
 
-func (s *service) GetVehicles() ([]Vehicle, error) {
-	return s.repository.GetVehicles()
+// NewOdometerReading checks a reading of the odometer of a vehicle without
+// storing it, for those stored along with another change, such as a route
+// starting.
+func (s *service) NewOdometerReading(ctx context.Context, vehicleID uuid.UUID, record *RecordOdometer) (*OdometerReading, error) {
+	if record.Kilometers <= 0 {
+		return nil, ErrInvalidOdometer
+	}
+	vehicle, err := s.repository.GetVehicle(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Kilometers < vehicle.Odometer {
+		return nil, fmt.Errorf("%w: %d km", ErrOdometerDecreased, vehicle.Odometer)
+	}
+
+	event := record.Event
+	if event == "" {
+		event = OdometerEventList[OdometerEventManual]
+	}
+	return &OdometerReading{
+		VehicleID:  vehicleID,
+		RouteID:    record.RouteId,
+		Kilometers: record.Kilometers,
+		Event:      event,
+	}, nil
+}
+
+// CheckAvailability returns ErrVehicleInMaintenance when the vehicle is in
+// the workshop on the date. An empty date means today.
+func (s *service) CheckAvailability(ctx context.Context, vehicleID uuid.UUID, date string) error {
+	day, err := parseDay(date, s.location)
+	if err != nil {
+		return err
+	}
+	date = day.Format(DateLayout)
+	maintenances, err := s.repository.GetMaintenancesUntil(ctx, vehicleID, date)
+	if err != nil {
+		return err
+	}
+	for _, maintenance := range maintenances {
+		if maintenance.InMaintenance(date) {
+			return fmt.Errorf("%w on %s: %s scheduled for %s", ErrVehicleInMaintenance, date, maintenance.Type, maintenance.ScheduledDate)
+		}
+	}
+	return nil
 }
 
 // static functions
 
-func NewService(repository *Repository) *service {
-	return &service{repository: repository}
+// NewService creates the vehicle service, taking days in location, UTC if nil.
+func NewService(repository Repository, policy MaintenancePolicy, location *time.Location) *service {
+	if location == nil {
+		location = time.UTC
+	}
+	return &service{repository: repository, policy: policy, location: location}
+}
+
+func valueOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
 }
@@ -1,33 +1,27 @@
 package vehicle
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-// Define a repository interface that our mock can implement
-type RepositoryInterface interface {
-	CreateVehicle(vehicle *Vehicle) (*Vehicle, error)
-	GetVehicle(id uuid.UUID) (*Vehicle, error)
-	GetVehicles() ([]Vehicle, error)
-	UpdateVehicle(vehicle *Vehicle) (*Vehicle, error)
-}
-
 // Define a mock repository for testing the service
 type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) CreateVehicle(vehicle *Vehicle) (*Vehicle, error) {
-	args := m.Called(vehicle)
+func (m *MockRepository) CreateVehicle(ctx context.Context, vehicle *Vehicle) (*Vehicle, error) {
+	args := m.Called(ctx, vehicle)
 	return args.Get(0).(*Vehicle), args.Error(1)
 }
 
-func (m *MockRepository) GetVehicle(id uuid.UUID) (*Vehicle, error) {
+func (m *MockRepository) GetVehicle(ctx context.Context, id uuid.UUID) (*Vehicle, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -35,35 +29,61 @@ func (m *MockRepository) GetVehicle(id uuid.UUID) (*Vehicle, error) {
 	return args.Get(0).(*Vehicle), args.Error(1)
 }
 
-func (m *MockRepository) GetVehicles() ([]Vehicle, error) {
+func (m *MockRepository) GetVehicles(ctx context.Context) ([]Vehicle, error) {
 	args := m.Called()
 	return args.Get(0).([]Vehicle), args.Error(1)
 }
 
-func (m *MockRepository) UpdateVehicle(vehicle *Vehicle) (*Vehicle, error) {
-	args := m.Called(vehicle)
+func (m *MockRepository) UpdateVehicle(ctx context.Context, vehicle *Vehicle) (*Vehicle, error) {
+	args := m.Called(ctx, vehicle)
 	return args.Get(0).(*Vehicle), args.Error(1)
 }
 
-// Create a custom service for testing
-type testService struct {
-	repo RepositoryInterface
+func (m *MockRepository) GetMaintenances(ctx context.Context, vehicleID uuid.UUID) ([]Maintenance, error) {
+	args := m.Called(vehicleID)
+	return args.Get(0).([]Maintenance), args.Error(1)
 }
 
-func (s *testService) CreateVehicle(vehicle *Vehicle) (*Vehicle, error) {
-	return s.repo.CreateVehicle(vehicle)
+func (m *MockRepository) GetMaintenance(ctx context.Context, vehicleID uuid.UUID, id uuid.UUID) (*Maintenance, error) {
+	args := m.Called(vehicleID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Maintenance), args.Error(1)
 }
 
-func (s *testService) GetVehicle(id uuid.UUID) (*Vehicle, error) {
-	return s.repo.GetVehicle(id)
+func (m *MockRepository) GetMaintenancesUntil(ctx context.Context, vehicleID uuid.UUID, date string) ([]Maintenance, error) {
+	args := m.Called(vehicleID, date)
+	return args.Get(0).([]Maintenance), args.Error(1)
 }
 
-func (s *testService) GetVehicles() ([]Vehicle, error) {
-	return s.repo.GetVehicles()
+func (m *MockRepository) GetLastMaintenances(ctx context.Context) (map[uuid.UUID]Maintenance, error) {
+	args := m.Called()
+	return args.Get(0).(map[uuid.UUID]Maintenance), args.Error(1)
+}
+
+func (m *MockRepository) CreateMaintenance(ctx context.Context, maintenance *Maintenance) (*Maintenance, error) {
+	args := m.Called(ctx, maintenance)
+	return args.Get(0).(*Maintenance), args.Error(1)
+}
+
+func (m *MockRepository) UpdateMaintenance(ctx context.Context, maintenance *Maintenance) (*Maintenance, error) {
+	args := m.Called(ctx, maintenance)
+	return args.Get(0).(*Maintenance), args.Error(1)
+}
+
+func (m *MockRepository) GetOdometerReadings(ctx context.Context, vehicleID uuid.UUID) ([]OdometerReading, error) {
+	args := m.Called(vehicleID)
+	return args.Get(0).([]OdometerReading), args.Error(1)
+}
+
+func (m *MockRepository) CreateOdometerReading(ctx context.Context, reading *OdometerReading) (*OdometerReading, error) {
+	args := m.Called(ctx, reading)
+	return args.Get(0).(*OdometerReading), args.Error(1)
 }
 
 func createTestService(mockRepo *MockRepository) Service {
-	return &testService{repo: mockRepo}
+	return NewService(mockRepo, DefaultMaintenancePolicy, time.UTC)
 }
 
 func TestCreateVehicle(t *testing.T) {
@@ -81,10 +101,10 @@ func TestCreateVehicle(t *testing.T) {
 		PlateNumber: vehicle.PlateNumber,
 	}
 
-	mockRepo.On("CreateVehicle", vehicle).Return(expectedVehicle, nil)
+	mockRepo.On("CreateVehicle", mock.Anything, vehicle).Return(expectedVehicle, nil)
 
 	// Act
-	result, err := service.CreateVehicle(vehicle)
+	result, err := service.CreateVehicle(context.Background(), vehicle)
 
 	// Assert
 	assert.NoError(t, err)
@@ -107,7 +127,7 @@ func TestGetVehicle(t *testing.T) {
 	mockRepo.On("GetVehicle", vehicleID).Return(expectedVehicle, nil)
 
 	// Act
-	result, err := service.GetVehicle(vehicleID)
+	result, err := service.GetVehicle(context.Background(), vehicleID)
 
 	// Assert
 	assert.NoError(t, err)
@@ -127,7 +147,7 @@ func TestGetVehicleNotFound(t *testing.T) {
 	mockRepo.On("GetVehicle", vehicleID).Return((*Vehicle)(nil), expectedError)
 
 	// Act
-	_, err := service.GetVehicle(vehicleID)
+	_, err := service.GetVehicle(context.Background(), vehicleID)
 
 	// Assert
 	assert.Error(t, err)
@@ -154,7 +174,7 @@ func TestGetVehicles(t *testing.T) {
 	mockRepo.On("GetVehicles").Return(expectedVehicles, nil)
 
 	// Act
-	results, err := service.GetVehicles()
+	results, err := service.GetVehicles(context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -163,3 +183,139 @@ func TestGetVehicles(t *testing.T) {
 	assert.Equal(t, expectedVehicles[1].PlateNumber, results[1].PlateNumber)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestScheduleMaintenanceInvalidType(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+
+	// Act
+	_, err := service.ScheduleMaintenance(context.Background(), uuid.New(), &CreateMaintenance{Type: "car_wash", ScheduledDate: "2025-03-10"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidMaintenance)
+}
+
+func TestCompleteMaintenance(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	vehicleID, id := uuid.New(), uuid.New()
+	mockRepo.On("GetMaintenance", vehicleID, id).Return(&Maintenance{ID: id, VehicleID: vehicleID, Status: "scheduled", ScheduledDate: "2025-03-10"}, nil)
+	mockRepo.On("GetVehicle", vehicleID).Return(&Vehicle{ID: vehicleID, Odometer: 9800}, nil)
+	mockRepo.On("CreateOdometerReading", mock.Anything, mock.Anything).Return(&OdometerReading{}, nil)
+	mockRepo.On("UpdateMaintenance", mock.Anything, mock.Anything).Return(&Maintenance{}, nil)
+	completed, completedDate, odometer := "completed", "2025-03-11", 10020
+
+	// Act
+	_, err := service.UpdateMaintenance(context.Background(), vehicleID, id, &UpdateMaintenance{Status: &completed, CompletedDate: &completedDate, Odometer: &odometer})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertCalled(t, "CreateOdometerReading", mock.Anything, mock.MatchedBy(func(reading *OdometerReading) bool {
+		return reading.Kilometers == 10020 && reading.Event == "maintenance"
+	}))
+	mockRepo.AssertCalled(t, "UpdateMaintenance", mock.Anything, mock.MatchedBy(func(maintenance *Maintenance) bool {
+		return maintenance.Status == "completed" && *maintenance.CompletedDate == "2025-03-11" && *maintenance.Odometer == 10020
+	}))
+}
+
+func TestUpdateMaintenanceAlreadyCompleted(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	vehicleID, id := uuid.New(), uuid.New()
+	mockRepo.On("GetMaintenance", vehicleID, id).Return(&Maintenance{ID: id, VehicleID: vehicleID, Status: "completed"}, nil)
+	cancelled := "cancelled"
+
+	// Act
+	_, err := service.UpdateMaintenance(context.Background(), vehicleID, id, &UpdateMaintenance{Status: &cancelled})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidMaintenanceTransition)
+}
+
+func TestRecordOdometerDecreased(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	vehicleID := uuid.New()
+	mockRepo.On("GetVehicle", vehicleID).Return(&Vehicle{ID: vehicleID, Odometer: 5000}, nil)
+
+	// Act
+	_, err := service.RecordOdometer(context.Background(), vehicleID, &RecordOdometer{Kilometers: 4900})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrOdometerDecreased)
+	mockRepo.AssertNotCalled(t, "CreateOdometerReading", mock.Anything, mock.Anything)
+}
+
+func TestCheckAvailabilityInMaintenance(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	vehicleID := uuid.New()
+	completedDate := "2025-03-12"
+	mockRepo.On("GetMaintenancesUntil", vehicleID, "2025-03-11").Return([]Maintenance{
+		{Type: "tires", Status: "scheduled", ScheduledDate: "2025-03-10"},
+	}, nil)
+	mockRepo.On("GetMaintenancesUntil", vehicleID, "2025-03-12").Return([]Maintenance{
+		{Type: "tires", Status: "completed", ScheduledDate: "2025-03-10", CompletedDate: &completedDate},
+	}, nil)
+
+	// Act
+	inWorkshop := service.CheckAvailability(context.Background(), vehicleID, "2025-03-11")
+	back := service.CheckAvailability(context.Background(), vehicleID, "2025-03-12")
+
+	// Assert
+	assert.ErrorIs(t, inWorkshop, ErrVehicleInMaintenance)
+	assert.NoError(t, back)
+}
+
+func TestGetMaintenanceAlerts(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := createTestService(mockRepo)
+	due, serviced := Vehicle{ID: uuid.New(), PlateNumber: "AA111AA", Odometer: 25800, CreatedAt: time.Now()}, Vehicle{ID: uuid.New(), PlateNumber: "BB222BB", Odometer: 25800, CreatedAt: time.Now()}
+	lastDate, lastOdometer := time.Now().Format(DateLayout), 25000
+	mockRepo.On("GetVehicles").Return([]Vehicle{due, serviced}, nil)
+	mockRepo.On("GetLastMaintenances").Return(map[uuid.UUID]Maintenance{
+		serviced.ID: {VehicleID: serviced.ID, Status: "completed", CompletedDate: &lastDate, Odometer: &lastOdometer},
+	}, nil)
+
+	// Act
+	alerts, err := service.GetMaintenanceAlerts(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "AA111AA", alerts[0].PlateNumber)
+	assert.Equal(t, []string{"distance"}, alerts[0].Reasons)
+	assert.True(t, alerts[0].Overdue)
+}
+
+func TestMaintenanceLifecycle(t *testing.T) {
+	// Arrange
+	vehicleID := uuid.New()
+	repository := NewFakeRepository(Vehicle{ID: vehicleID, PlateNumber: "AB123CD", Odometer: 11800})
+	service := NewService(repository, DefaultMaintenancePolicy, time.UTC)
+	maintenance, err := service.ScheduleMaintenance(context.Background(), vehicleID, &CreateMaintenance{Type: "oil_change", ScheduledDate: "2030-01-07"})
+	assert.NoError(t, err)
+	completed, cancelled := "completed", "cancelled"
+	completedDate, odometer := "2030-01-08", 12000
+
+	// Act
+	scheduledErr := service.CheckAvailability(context.Background(), vehicleID, "2030-01-07")
+	done, completeErr := service.UpdateMaintenance(context.Background(), vehicleID, maintenance.ID, &UpdateMaintenance{Status: &completed, CompletedDate: &completedDate, Odometer: &odometer})
+	completedErr := service.CheckAvailability(context.Background(), vehicleID, "2030-01-08")
+	_, transitionErr := service.UpdateMaintenance(context.Background(), vehicleID, maintenance.ID, &UpdateMaintenance{Status: &cancelled})
+
+	// Assert
+	assert.ErrorIs(t, scheduledErr, ErrVehicleInMaintenance)
+	assert.NoError(t, completeErr)
+	assert.Equal(t, 12000, *done.Odometer)
+	assert.NoError(t, completedErr)
+	assert.ErrorIs(t, transitionErr, ErrInvalidMaintenanceTransition)
+	vehicle, _ := repository.GetVehicle(context.Background(), vehicleID)
+	assert.Equal(t, 12000, vehicle.Odometer)
+}
@@ -9,6 +9,7 @@ import (
 type Vehicle struct {
 	ID          uuid.UUID `gorm:"column:id" json:"id"`
 	PlateNumber string    `gorm:"column:plate_number" json:"plate_number"`
+	Odometer    int       `gorm:"column:odometer" json:"odometer"`
 	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
 }
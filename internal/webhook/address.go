@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned when dialing a subscription that resolves to
+// an address of the server's own network, such as a loopback or private one.
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range, not routed on the
+// internet either
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// static functions
+
+// publicHost tells whether the host of a subscription URL may be public.
+// Names other than localhost can only be told when they are resolved, on
+// dialing.
+func publicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return publicAddr(addr)
+	}
+	return true
+}
+
+// publicAddr tells whether an address is routed on the internet, rather than
+// loopback, private, link-local or otherwise reserved for local use.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// newTransport creates the transport of the deliveries. Unless
+// allowPrivateHosts, it refuses to connect to addresses that are not public,
+// checked once resolved so that neither names pointing at them nor redirects
+// reach the server's own network. Proxies are not used then, as the address
+// dialed would be theirs.
+func newTransport(timeout time.Duration, allowPrivateHosts bool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if allowPrivateHosts {
+		return transport
+	}
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+			}
+			if !publicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network string, address string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, address)
+	}
+	return transport
+}
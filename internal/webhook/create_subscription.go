@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"net/url"
+	"strings"
+)
+
+type CreateSubscription struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+}
+
+// UpdateSubscription holds the fields to change; nil fields are left
+// untouched. Inactive subscriptions receive no new events.
+type UpdateSubscription struct {
+	URL        *string   `json:"url"`
+	Secret     *string   `json:"secret"`
+	EventTypes *[]string `json:"event_types"`
+	Active     *bool     `json:"active"`
+}
+
+// static functions
+
+// validSubscription returns a description of every invalid field, or nil.
+// Unless allowPrivateHosts, the URL must not point to the server's own
+// network.
+func validSubscription(rawURL string, secret string, eventTypes []string, allowPrivateHosts bool) []string {
+	var problems []string
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, "url must be an absolute http or https URL")
+	} else if !allowPrivateHosts && !publicHost(u.Hostname()) {
+		problems = append(problems, "url must not point to a loopback, link-local or private address")
+	}
+	if len(secret) < MinSecretLength {
+		problems = append(problems, "secret must be at least 16 characters long")
+	}
+	if len(eventTypes) == 0 {
+		problems = append(problems, "event_types is required")
+	}
+	for _, eventType := range eventTypes {
+		if _, ok := EventTypeList[EventType(eventType)]; !ok {
+			problems = append(problems, "unknown event type "+eventType+", must be one of "+strings.Join(eventTypeNames(), ", "))
+		}
+	}
+	return problems
+}
+
+func eventTypeNames() []string {
+	return []string{
+		EventTypeList[EventRouteStarted],
+		EventTypeList[EventRouteCompleted],
+		EventTypeList[EventRoutePointStatusChanged],
+	}
+}
@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// batchSize bounds the deliveries sent on each poll
+	batchSize = 100
+	// lease is how long an instance has to send a delivery it claimed before
+	// another one takes it over, at least twice the timeout of the request
+	lease = 5 * time.Minute
+)
+
+// Dispatcher sends the pending deliveries in the background, retrying failed
+// ones with exponential backoff until they run out of attempts. Every instance
+// of the server may run one: each delivery is claimed before it is sent.
+type Dispatcher struct {
+	repository Repository
+	httpClient *http.Client
+	backoff    Backoff
+	interval   time.Duration
+	lease      time.Duration
+}
+
+// Start polls for due deliveries every interval until ctx is cancelled, then
+// finishes the batch in flight and marks wg done.
+func (d *Dispatcher) Start(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.DispatchDue(context.WithoutCancel(ctx)); err != nil {
+					slog.ErrorContext(ctx, "Failed to dispatch webhooks", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// DispatchDue sends every delivery due by now.
+func (d *Dispatcher) DispatchDue(ctx context.Context) error {
+	deliveries, err := d.repository.GetDueDeliveries(ctx, time.Now(), batchSize)
+	if err != nil {
+		return err
+	}
+	subscriptions := map[string]*Subscription{}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		now := time.Now()
+		claimed, err := d.repository.ClaimDelivery(ctx, delivery, now, now.Add(d.lease))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		id := delivery.SubscriptionID.String()
+		if _, ok := subscriptions[id]; !ok {
+			if subscriptions[id], err = d.repository.GetSubscription(ctx, id); err != nil {
+				return err
+			}
+		}
+		d.attempt(ctx, subscriptions[id], delivery)
+		if err := d.repository.UpdateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt sends the delivery once and records the outcome on it.
+func (d *Dispatcher) attempt(ctx context.Context, subscription *Subscription, delivery *Delivery) {
+	now := time.Now()
+	delivery.Attempts++
+	statusCode, err := d.send(ctx, subscription, delivery, now)
+	delivery.LastStatusCode = statusCode
+	if err == nil {
+		delivery.Status = DeliveryStatusList[DeliveryStatusDelivered]
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.backoff.MaxAttempts {
+		delivery.Status = DeliveryStatusList[DeliveryStatusDead]
+		return
+	}
+	delivery.NextAttemptAt = now.Add(d.backoff.Delay(delivery.Attempts))
+}
+
+func (d *Dispatcher) send(ctx context.Context, subscription *Subscription, delivery *Delivery, now time.Time) (*int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, now, body))
+
+	res, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return &res.StatusCode, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return &res.StatusCode, nil
+}
+
+// static functions
+
+// NewDispatcher creates the dispatcher of the deliveries, which refuses to
+// connect to the server's own network unless allowPrivateHosts.
+func NewDispatcher(repository Repository, backoff Backoff, timeout time.Duration, interval time.Duration, allowPrivateHosts bool) *Dispatcher {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	return &Dispatcher{
+		repository: repository,
+		httpClient: &http.Client{Timeout: timeout, Transport: newTransport(timeout, allowPrivateHosts)},
+		backoff:    backoff,
+		interval:   interval,
+		lease:      max(lease, 2*timeout),
+	}
+}
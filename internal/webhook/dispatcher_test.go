@@ -0,0 +1,321 @@
+package webhook
+
+import (
+	"challenge-fravega/internal/outbox"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+type DispatcherTestSuite struct {
+	suite.Suite
+	repository *repository
+	service    *service
+	dispatcher *Dispatcher
+	server     *httptest.Server
+	status     int
+	received   []received
+}
+
+func (suite *DispatcherTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	err = db.AutoMigrate(&Subscription{}, &Delivery{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	suite.status = http.StatusOK
+	suite.received = nil
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		suite.received = append(suite.received, received{header: r.Header.Clone(), body: body})
+		w.WriteHeader(suite.status)
+	}))
+
+	suite.repository = NewRepository(db)
+	// The receiver listens on loopback
+	suite.service = NewService(suite.repository, true)
+	suite.dispatcher = NewDispatcher(suite.repository, Backoff{Base: time.Minute, Max: time.Hour, MaxAttempts: 2}, time.Second, time.Second, true)
+}
+
+func (suite *DispatcherTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *DispatcherTestSuite) subscribe(eventTypes ...string) *Subscription {
+	subscription, err := suite.service.CreateSubscription(context.Background(), &CreateSubscription{
+		URL:        suite.server.URL,
+		Secret:     "0123456789abcdef",
+		EventTypes: eventTypes,
+	})
+	suite.Require().NoError(err)
+	return subscription
+}
+
+func (suite *DispatcherTestSuite) publish(eventType EventType) error {
+	return suite.service.Publish(context.Background(), Event{
+		ID:         uuid.New(),
+		Type:       EventTypeList[eventType],
+		OccurredAt: time.Now(),
+		Data:       map[string]string{"route_id": "r1"},
+	})
+}
+
+// due makes every pending delivery due now, as if the backoff had elapsed
+func (suite *DispatcherTestSuite) due() {
+	err := suite.repository.db.Model(&Delivery{}).Where("1 = 1").Update("next_attempt_at", time.Now().Add(-time.Second)).Error
+	suite.Require().NoError(err)
+}
+
+func (suite *DispatcherTestSuite) TestPublishQueuesMatchingSubscriptions() {
+	// Arrange
+	started := suite.subscribe("route.started")
+	suite.subscribe("route.completed")
+	inactive := suite.subscribe("route.started")
+	active := false
+	_, err := suite.service.UpdateSubscription(context.Background(), inactive.ID.String(), &UpdateSubscription{Active: &active})
+	suite.Require().NoError(err)
+
+	// Act
+	err = suite.publish(EventRouteStarted)
+
+	// Assert
+	suite.NoError(err)
+	deliveries, err := suite.service.GetDeliveries(context.Background(), &DeliveryFilter{})
+	suite.NoError(err)
+	suite.Len(deliveries, 1)
+	suite.Equal(started.ID, deliveries[0].SubscriptionID)
+	suite.Equal("pending", deliveries[0].Status)
+	event := Event{}
+	suite.NoError(json.Unmarshal([]byte(deliveries[0].Payload), &event))
+	suite.Equal("route.started", event.Type)
+	suite.Equal(deliveries[0].EventID, event.ID)
+}
+
+func (suite *DispatcherTestSuite) TestDispatchDueSignsAndDelivers() {
+	// Arrange
+	suite.subscribe("route.completed")
+	suite.Require().NoError(suite.publish(EventRouteCompleted))
+
+	// Act
+	err := suite.dispatcher.DispatchDue(context.Background())
+
+	// Assert
+	suite.NoError(err)
+	suite.Require().Len(suite.received, 1)
+	header := suite.received[0].header
+	timestamp, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	suite.NoError(err)
+	suite.Equal(Sign("0123456789abcdef", time.Unix(timestamp, 0), suite.received[0].body), header.Get(SignatureHeader))
+	suite.Equal("route.completed", header.Get(EventHeader))
+
+	deliveries, _ := suite.service.GetDeliveries(context.Background(), &DeliveryFilter{})
+	suite.Equal("delivered", deliveries[0].Status)
+	suite.Equal(1, deliveries[0].Attempts)
+	suite.Equal(http.StatusOK, *deliveries[0].LastStatusCode)
+	suite.NotNil(deliveries[0].DeliveredAt)
+	suite.Equal(deliveries[0].ID.String(), header.Get(DeliveryHeader))
+}
+
+func (suite *DispatcherTestSuite) TestDispatchDueRefusesPrivateAddresses() {
+	// Arrange
+	suite.subscribe("route.completed")
+	suite.Require().NoError(suite.publish(EventRouteCompleted))
+	dispatcher := NewDispatcher(suite.repository, Backoff{Base: time.Minute, Max: time.Hour, MaxAttempts: 2}, time.Second, time.Second, false)
+
+	// Act
+	err := dispatcher.DispatchDue(context.Background())
+
+	// Assert
+	suite.NoError(err)
+	suite.Empty(suite.received)
+	deliveries, _ := suite.service.GetDeliveries(context.Background(), &DeliveryFilter{})
+	suite.Equal("pending", deliveries[0].Status)
+	suite.Contains(deliveries[0].LastError, ErrPrivateAddress.Error())
+}
+
+func (suite *DispatcherTestSuite) TestDispatchDueRetriesWithBackoffThenDeadLetters() {
+	// Arrange
+	suite.status = http.StatusServiceUnavailable
+	suite.subscribe("route.started")
+	suite.Require().NoError(suite.publish(EventRouteStarted))
+
+	// Act
+	firstErr := suite.dispatcher.DispatchDue(context.Background())
+	first, _ := suite.service.GetDeliveries(context.Background(), &DeliveryFilter{})
+	notDueErr := suite.dispatcher.DispatchDue(context.Background())
+	suite.due()
+	secondErr := suite.dispatcher.DispatchDue(context.Background())
+
+	// Assert
+	suite.NoError(firstErr)
+	suite.NoError(notDueErr)
+	suite.NoError(secondErr)
+	suite.Equal("pending", first[0].Status)
+	suite.WithinDuration(time.Now().Add(time.Minute), first[0].NextAttemptAt, 5*time.Second)
+	suite.Len(suite.received, 2)
+
+	dead, _ := suite.service.GetDeliveries(context.Background(), &DeliveryFilter{Status: "dead"})
+	suite.Require().Len(dead, 1)
+	suite.Equal(2, dead[0].Attempts)
+	suite.Equal(http.StatusServiceUnavailable, *dead[0].LastStatusCode)
+	suite.Contains(dead[0].LastError, "503")
+}
+
+func (suite *DispatcherTestSuite) TestReplayDelivery() {
+	// Arrange
+	suite.status = http.StatusInternalServerError
+	suite.subscribe("route.started")
+	suite.Require().NoError(suite.publish(EventRouteStarted))
+	pending, _ := suite.service.GetDeliveries(context.Background(), &DeliveryFilter{})
+	_, pendingErr := suite.service.ReplayDelivery(context.Background(), pending[0].ID.String())
+	suite.Require().NoError(suite.dispatcher.DispatchDue(context.Background()))
+	suite.due()
+	suite.Require().NoError(suite.dispatcher.DispatchDue(context.Background()))
+	suite.status = http.StatusNoContent
+
+	// Act
+	replayed, err := suite.service.ReplayDelivery(context.Background(), pending[0].ID.String())
+	dispatchErr := suite.dispatcher.DispatchDue(context.Background())
+
+	// Assert
+	suite.ErrorIs(pendingErr, ErrDeliveryPending)
+	suite.NoError(err)
+	suite.Equal("pending", replayed.Status)
+	suite.Equal(0, replayed.Attempts)
+	suite.NoError(dispatchErr)
+	delivered, _ := suite.service.GetDeliveries(context.Background(), &DeliveryFilter{Status: "delivered"})
+	suite.Len(delivered, 1)
+	suite.Len(suite.received, 3)
+}
+
+func (suite *DispatcherTestSuite) TestDeleteSubscriptionDeletesDeliveries() {
+	// Arrange
+	subscription := suite.subscribe("route.started")
+	suite.Require().NoError(suite.publish(EventRouteStarted))
+
+	// Act
+	err := suite.service.DeleteSubscription(context.Background(), subscription.ID.String())
+	missingErr := suite.service.DeleteSubscription(context.Background(), subscription.ID.String())
+
+	// Assert
+	suite.NoError(err)
+	suite.ErrorIs(missingErr, gorm.ErrRecordNotFound)
+	deliveries, _ := suite.service.GetDeliveries(context.Background(), &DeliveryFilter{})
+	suite.Empty(deliveries)
+}
+
+func (suite *DispatcherTestSuite) TestPublishIgnoresQueuedEvents() {
+	// Arrange
+	suite.subscribe("route.completed")
+	event := Event{ID: uuid.New(), Type: "route.completed", OccurredAt: time.Now()}
+
+	// Act
+	err := suite.service.Publish(context.Background(), event)
+	againErr := suite.service.Publish(context.Background(), event)
+
+	// Assert
+	suite.NoError(err)
+	suite.NoError(againErr)
+	deliveries, _ := suite.service.GetDeliveries(context.Background(), &DeliveryFilter{})
+	suite.Len(deliveries, 1)
+}
+
+func (suite *DispatcherTestSuite) TestSinkPublishesSubscribableEvents() {
+	// Arrange
+	suite.subscribe("route.started", "route_point.status_changed")
+	sink := NewSink(suite.service)
+	started, _ := outbox.NewEvent(outbox.AggregateRoute, uuid.New(), outbox.EventRouteStarted, map[string]string{"name": "Morning"})
+	created, _ := outbox.NewEvent(outbox.AggregateRoute, uuid.New(), outbox.EventRouteCreated, nil)
+
+	// Act
+	startedErr := sink.Publish(context.Background(), &started)
+	createdErr := sink.Publish(context.Background(), &created)
+
+	// Assert
+	suite.NoError(startedErr)
+	suite.NoError(createdErr)
+	deliveries, _ := suite.service.GetDeliveries(context.Background(), &DeliveryFilter{})
+	suite.Require().Len(deliveries, 1)
+	suite.Equal(started.ID, deliveries[0].EventID)
+	suite.Contains(deliveries[0].Payload, `"data":{"name":"Morning"}`)
+}
+
+func TestDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(DispatcherTestSuite))
+}
+
+func TestDispatchersShareDeliveries(t *testing.T) {
+	// Arrange
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "webhook.sqlite")+"?_busy_timeout=5000"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Subscription{}, &Delivery{}))
+	var mu sync.Mutex
+	received := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received[r.Header.Get(DeliveryHeader)]++
+	}))
+	defer server.Close()
+	repository := NewRepository(db)
+	subscription, err := repository.CreateSubscription(context.Background(), &Subscription{
+		URL: server.URL, Secret: "0123456789abcdef", EventTypes: EventTypes{"route.started"}, Active: true,
+	})
+	require.NoError(t, err)
+	deliveries := []*Delivery{}
+	for i := 0; i < 30; i++ {
+		deliveries = append(deliveries, &Delivery{
+			SubscriptionID: subscription.ID,
+			EventID:        uuid.New(),
+			EventType:      "route.started",
+			Payload:        "{}",
+			Status:         DeliveryStatusList[DeliveryStatusPending],
+			NextAttemptAt:  time.Now().Add(-time.Second),
+		})
+	}
+	require.NoError(t, repository.CreateDeliveries(context.Background(), deliveries))
+
+	// Act
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		dispatcher := NewDispatcher(repository, Backoff{Base: time.Minute, Max: time.Hour, MaxAttempts: 2}, time.Second, time.Second, true)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, dispatcher.DispatchDue(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	assert.Len(t, received, 30)
+	for id, count := range received {
+		assert.Equal(t, 1, count, id)
+	}
+	delivered, _ := repository.GetDeliveries(context.Background(), "", DeliveryStatusList[DeliveryStatusDelivered], 100)
+	assert.Len(t, delivered, 30)
+}
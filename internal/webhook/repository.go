@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository stores the webhook subscriptions and their deliveries.
+type Repository interface {
+	GetSubscriptions(ctx context.Context) ([]Subscription, error)
+	GetActiveSubscriptions(ctx context.Context) ([]Subscription, error)
+	GetSubscription(ctx context.Context, id string) (*Subscription, error)
+	CreateSubscription(ctx context.Context, subscription *Subscription) (*Subscription, error)
+	UpdateSubscription(ctx context.Context, subscription *Subscription) error
+	DeleteSubscription(ctx context.Context, id string) error
+	CreateDeliveries(ctx context.Context, deliveries []*Delivery) error
+	HasDeliveries(ctx context.Context, eventID uuid.UUID) (bool, error)
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error)
+	ClaimDelivery(ctx context.Context, delivery *Delivery, now time.Time, until time.Time) (bool, error)
+	GetDeliveries(ctx context.Context, subscriptionID string, status string, limit int) ([]Delivery, error)
+	GetDelivery(ctx context.Context, id string) (*Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func (r *repository) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := r.db.WithContext(ctx).Order("created_at").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *repository) GetActiveSubscriptions(ctx context.Context) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := r.db.WithContext(ctx).Where("active = ?", true).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *repository) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return &Subscription{}, gorm.ErrRecordNotFound
+	}
+	var subscription Subscription
+	err := r.db.WithContext(ctx).First(&subscription, "id = ?", id).Error
+	return &subscription, err
+}
+
+func (r *repository) CreateSubscription(ctx context.Context, subscription *Subscription) (*Subscription, error) {
+	if subscription.ID == uuid.Nil {
+		subscription.ID = uuid.New()
+	}
+	err := r.db.WithContext(ctx).Create(subscription).Error
+	return subscription, err
+}
+
+func (r *repository) UpdateSubscription(ctx context.Context, subscription *Subscription) error {
+	return r.db.WithContext(ctx).Model(&Subscription{}).Where("id = ?", subscription.ID).
+		Updates(map[string]interface{}{
+			"url":         subscription.URL,
+			"secret":      subscription.Secret,
+			"event_types": subscription.EventTypes,
+			"active":      subscription.Active,
+		}).Error
+}
+
+// DeleteSubscription deletes the subscription along with its deliveries.
+func (r *repository) DeleteSubscription(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return gorm.ErrRecordNotFound
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&Delivery{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&Subscription{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *repository) CreateDeliveries(ctx context.Context, deliveries []*Delivery) error {
+	for _, delivery := range deliveries {
+		if delivery.ID == uuid.Nil {
+			delivery.ID = uuid.New()
+		}
+	}
+	return r.db.WithContext(ctx).CreateInBatches(deliveries, 100).Error
+}
+
+// HasDeliveries tells whether the event was already queued.
+func (r *repository) HasDeliveries(ctx context.Context, eventID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Delivery{}).Where("event_id = ?", eventID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetDueDeliveries returns the pending deliveries due by now and not claimed
+// by another instance, oldest first.
+func (r *repository) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error) {
+	var deliveries []Delivery
+	err := r.db.WithContext(ctx).Where("status = ? AND next_attempt_at <= ?", DeliveryStatusList[DeliveryStatusPending], now).
+		Where("locked_until IS NULL OR locked_until <= ?", now).
+		Order("next_attempt_at, created_at").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimDelivery leases the delivery until the given time, unless it was sent,
+// put off or claimed by another instance since it was read, and tells whether
+// it was. UpdateDelivery releases it.
+func (r *repository) ClaimDelivery(ctx context.Context, delivery *Delivery, now time.Time, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Delivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", delivery.ID, DeliveryStatusList[DeliveryStatusPending], now).
+		Where("locked_until IS NULL OR locked_until <= ?", now).
+		Update("locked_until", until)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	delivery.LockedUntil = &until
+	return true, nil
+}
+
+// GetDeliveries returns the most recent deliveries first, optionally only
+// those of a subscription or with a status.
+func (r *repository) GetDeliveries(ctx context.Context, subscriptionID string, status string, limit int) ([]Delivery, error) {
+	var deliveries []Delivery
+	query := r.db.WithContext(ctx).Order("created_at DESC").Limit(limit)
+	if subscriptionID != "" {
+		if _, err := uuid.Parse(subscriptionID); err != nil {
+			return []Delivery{}, nil
+		}
+		query = query.Where("subscription_id = ?", subscriptionID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *repository) GetDelivery(ctx context.Context, id string) (*Delivery, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return &Delivery{}, gorm.ErrRecordNotFound
+	}
+	var delivery Delivery
+	err := r.db.WithContext(ctx).First(&delivery, "id = ?", id).Error
+	return &delivery, err
+}
+
+// UpdateDelivery saves the outcome of an attempt, or a replay.
+func (r *repository) UpdateDelivery(ctx context.Context, delivery *Delivery) error {
+	return r.db.WithContext(ctx).Model(&Delivery{}).Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":           delivery.Status,
+			"attempts":         delivery.Attempts,
+			"next_attempt_at":  delivery.NextAttemptAt,
+			"last_status_code": delivery.LastStatusCode,
+			"last_error":       delivery.LastError,
+			"delivered_at":     delivery.DeliveredAt,
+			"locked_until":     nil,
+		}).Error
+}
+
+// static functions
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
@@ -0,0 +1,166 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Service interface {
+	GetSubscriptions(ctx context.Context) ([]Subscription, error)
+	GetSubscription(ctx context.Context, id string) (*Subscription, error)
+	CreateSubscription(ctx context.Context, create *CreateSubscription) (*Subscription, error)
+	UpdateSubscription(ctx context.Context, id string, update *UpdateSubscription) (*Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	GetDeliveries(ctx context.Context, filter *DeliveryFilter) ([]Delivery, error)
+	ReplayDelivery(ctx context.Context, id string) (*Delivery, error)
+	Publish(ctx context.Context, event Event) error
+}
+
+type service struct {
+	repository        Repository
+	allowPrivateHosts bool
+}
+
+func (s *service) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	return s.repository.GetSubscriptions(ctx)
+}
+
+func (s *service) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	return s.repository.GetSubscription(ctx, id)
+}
+
+func (s *service) CreateSubscription(ctx context.Context, create *CreateSubscription) (*Subscription, error) {
+	if problems := validSubscription(create.URL, create.Secret, create.EventTypes, s.allowPrivateHosts); problems != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSubscription, strings.Join(problems, ", "))
+	}
+	return s.repository.CreateSubscription(ctx, &Subscription{
+		URL:        create.URL,
+		Secret:     create.Secret,
+		EventTypes: create.EventTypes,
+		Active:     true,
+	})
+}
+
+func (s *service) UpdateSubscription(ctx context.Context, id string, update *UpdateSubscription) (*Subscription, error) {
+	subscription, err := s.repository.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if update.URL != nil {
+		subscription.URL = *update.URL
+	}
+	if update.Secret != nil {
+		subscription.Secret = *update.Secret
+	}
+	if update.EventTypes != nil {
+		subscription.EventTypes = *update.EventTypes
+	}
+	if update.Active != nil {
+		subscription.Active = *update.Active
+	}
+	if problems := validSubscription(subscription.URL, subscription.Secret, subscription.EventTypes, s.allowPrivateHosts); problems != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSubscription, strings.Join(problems, ", "))
+	}
+
+	if err := s.repository.UpdateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return s.repository.GetSubscription(ctx, id)
+}
+
+func (s *service) DeleteSubscription(ctx context.Context, id string) error {
+	return s.repository.DeleteSubscription(ctx, id)
+}
+
+// GetDeliveries returns the most recent deliveries first.
+func (s *service) GetDeliveries(ctx context.Context, filter *DeliveryFilter) ([]Delivery, error) {
+	if filter.Status != "" {
+		if _, ok := DeliveryStatusList[DeliveryStatus(filter.Status)]; !ok {
+			return nil, ErrInvalidDeliveryStatus
+		}
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	return s.repository.GetDeliveries(ctx, filter.SubscriptionID, filter.Status, limit)
+}
+
+// ReplayDelivery queues a dead-lettered or delivered event again, with a fresh
+// set of attempts.
+func (s *service) ReplayDelivery(ctx context.Context, id string) (*Delivery, error) {
+	delivery, err := s.repository.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == DeliveryStatusList[DeliveryStatusPending] {
+		return nil, ErrDeliveryPending
+	}
+
+	delivery.Status = DeliveryStatusList[DeliveryStatusPending]
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.LastStatusCode = nil
+	delivery.LastError = ""
+	delivery.DeliveredAt = nil
+	if err := s.repository.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return s.repository.GetDelivery(ctx, id)
+}
+
+// Publish queues the event for every active subscription to its type. The
+// dispatcher delivers it in the background. Events already queued are
+// ignored, so publishing an event again does not deliver it twice.
+func (s *service) Publish(ctx context.Context, event Event) error {
+	queued, err := s.repository.HasDeliveries(ctx, event.ID)
+	if err != nil || queued {
+		return err
+	}
+	subscriptions, err := s.repository.GetActiveSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []*Delivery
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribed(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, &Delivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Status:         DeliveryStatusList[DeliveryStatusPending],
+			NextAttemptAt:  time.Now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for _, delivery := range deliveries {
+		delivery.Payload = string(payload)
+	}
+	return s.repository.CreateDeliveries(ctx, deliveries)
+}
+
+// static functions
+
+// NewService creates the webhook service, which refuses subscriptions to the
+// server's own network unless allowPrivateHosts.
+func NewService(repository Repository, allowPrivateHosts bool) *service {
+	return &service{repository: repository, allowPrivateHosts: allowPrivateHosts}
+}
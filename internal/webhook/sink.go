@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"challenge-fravega/internal/outbox"
+	"context"
+	"encoding/json"
+)
+
+// Sink publishes the outbox events subscribers can subscribe to, ignoring the
+// others.
+type Sink struct {
+	service Service
+}
+
+func (s *Sink) Name() string {
+	return "webhook"
+}
+
+func (s *Sink) Publish(ctx context.Context, event *outbox.Event) error {
+	if _, ok := EventTypeList[EventType(event.Type)]; !ok {
+		return nil
+	}
+	return s.service.Publish(ctx, Event{
+		ID:         event.ID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       json.RawMessage(event.Payload),
+	})
+}
+
+// static functions
+
+func NewSink(service Service) *Sink {
+	return &Sink{service: service}
+}
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultTimeout      = 10 * time.Second
+	DefaultPollInterval = 5 * time.Second
+	DefaultLimit        = 100
+	MaxLimit            = 1000
+	// MinSecretLength is the shortest secret accepted to sign payloads with
+	MinSecretLength = 16
+
+	// Headers sent with every delivery. The signature is the hex encoded
+	// HMAC-SHA256 of the timestamp, a dot and the body, keyed with the secret
+	// of the subscription.
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// DefaultBackoff retries a delivery after 30s, 1m, 2m... up to an hour apart,
+// and dead-letters it after 8 attempts
+var DefaultBackoff = Backoff{Base: 30 * time.Second, Max: time.Hour, MaxAttempts: 8}
+
+var (
+	ErrInvalidSubscription   = errors.New("invalid webhook subscription")
+	ErrInvalidDeliveryStatus = errors.New("invalid webhook delivery status")
+	ErrDeliveryPending       = errors.New("webhook delivery is still pending")
+)
+
+// Subscription sends the events of the given types to a URL. The secret signs
+// the payloads and is never returned.
+type Subscription struct {
+	ID         uuid.UUID  `gorm:"column:id" json:"id"`
+	URL        string     `gorm:"column:url" json:"url"`
+	Secret     string     `gorm:"column:secret" json:"-"`
+	EventTypes EventTypes `gorm:"column:event_types" json:"event_types"`
+	Active     bool       `gorm:"column:active" json:"active"`
+	CreatedAt  time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (Subscription) TableName() string {
+	return "webhook_subscription"
+}
+
+// Subscribed tells whether the subscription wants events of the type.
+func (s *Subscription) Subscribed(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type EventType string
+
+const (
+	EventRouteStarted            EventType = "route.started"
+	EventRouteCompleted          EventType = "route.completed"
+	EventRoutePointStatusChanged EventType = "route_point.status_changed"
+)
+
+var EventTypeList = map[EventType]string{
+	EventRouteStarted:            "route.started",
+	EventRouteCompleted:          "route.completed",
+	EventRoutePointStatusChanged: "route_point.status_changed",
+}
+
+// EventTypes is stored as a JSON array.
+type EventTypes []string
+
+func (e EventTypes) GormDataType() string {
+	return "text"
+}
+
+func (e EventTypes) Value() (driver.Value, error) {
+	b, err := json.Marshal(e)
+	return string(b), err
+}
+
+func (e *EventTypes) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*e = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, e)
+	case string:
+		return json.Unmarshal([]byte(v), e)
+	default:
+		return fmt.Errorf("unsupported webhook event types value %T", value)
+	}
+}
+
+// Event is the payload of a delivery. Its ID is that of the outbox event it
+// was published from, the same for every delivery and replay of the event.
+type Event struct {
+	ID         uuid.UUID   `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Delivery is an event queued for a subscription, retried until the
+// subscriber accepts it or it is dead-lettered. LockedUntil is set while an
+// instance of the server sends it.
+type Delivery struct {
+	ID             uuid.UUID  `gorm:"column:id" json:"id"`
+	SubscriptionID uuid.UUID  `gorm:"column:subscription_id" json:"subscription_id"`
+	EventID        uuid.UUID  `gorm:"column:event_id" json:"event_id"`
+	EventType      string     `gorm:"column:event_type" json:"event_type"`
+	Payload        string     `gorm:"column:payload" json:"payload"`
+	Status         string     `gorm:"column:status" json:"status"`
+	Attempts       int        `gorm:"column:attempts" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"column:next_attempt_at" json:"next_attempt_at"`
+	LastStatusCode *int       `gorm:"column:last_status_code" json:"last_status_code"`
+	LastError      string     `gorm:"column:last_error" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `gorm:"column:delivered_at" json:"delivered_at"`
+	LockedUntil    *time.Time `gorm:"column:locked_until" json:"-"`
+	CreatedAt      time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (Delivery) TableName() string {
+	return "webhook_delivery"
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	// DeliveryStatusDead deliveries ran out of attempts and wait to be replayed
+	DeliveryStatusDead DeliveryStatus = "dead"
+)
+
+var DeliveryStatusList = map[DeliveryStatus]string{
+	DeliveryStatusPending:   "pending",
+	DeliveryStatusDelivered: "delivered",
+	DeliveryStatusDead:      "dead",
+}
+
+type DeliveryFilter struct {
+	SubscriptionID string `form:"subscription_id"`
+	Status         string `form:"status"`
+	Limit          int    `form:"limit"`
+}
+
+// Backoff spaces the attempts of a delivery exponentially.
+type Backoff struct {
+	Base        time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+// Delay returns how long to wait after the given number of failed attempts.
+func (b Backoff) Delay(attempts int) time.Duration {
+	delay := b.Base
+	for i := 1; i < attempts && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// static functions
+
+// Sign computes the signature of a payload sent at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
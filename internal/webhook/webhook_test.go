@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	// Arrange
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"route.started"}`)
+
+	// Act
+	signature := Sign("0123456789abcdef", timestamp, body)
+
+	// Assert
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Len(t, signature, 7+64)
+	assert.Equal(t, signature, Sign("0123456789abcdef", timestamp, body))
+	assert.NotEqual(t, signature, Sign("fedcba9876543210", timestamp, body))
+	assert.NotEqual(t, signature, Sign("0123456789abcdef", timestamp.Add(time.Second), body))
+}
+
+func TestBackoffDelay(t *testing.T) {
+	// Arrange
+	backoff := Backoff{Base: 30 * time.Second, Max: 5 * time.Minute, MaxAttempts: 8}
+
+	// Act & Assert
+	assert.Equal(t, 30*time.Second, backoff.Delay(1))
+	assert.Equal(t, time.Minute, backoff.Delay(2))
+	assert.Equal(t, 4*time.Minute, backoff.Delay(4))
+	assert.Equal(t, 5*time.Minute, backoff.Delay(5))
+	assert.Equal(t, 5*time.Minute, backoff.Delay(20))
+}
+
+func TestValidSubscription(t *testing.T) {
+	// Act
+	valid := validSubscription("https://orders.example.com/hooks", "0123456789abcdef", []string{"route.started"}, false)
+	invalid := validSubscription("orders.example.com", "short", []string{"route.cancelled"}, false)
+	missing := validSubscription("http://localhost:9000", "0123456789abcdef", nil, true)
+	private := validSubscription("http://localhost:9000", "0123456789abcdef", []string{"route.started"}, false)
+
+	// Assert
+	assert.Nil(t, valid)
+	assert.Len(t, invalid, 3)
+	assert.Equal(t, []string{"event_types is required"}, missing)
+	assert.Equal(t, []string{"url must not point to a loopback, link-local or private address"}, private)
+}
+
+func TestPublicHost(t *testing.T) {
+	// Act & Assert
+	assert.True(t, publicHost("orders.example.com"))
+	assert.True(t, publicHost("93.184.216.34"))
+	assert.True(t, publicHost("2606:2800:220:1:248:1893:25c8:1946"))
+	assert.False(t, publicHost("localhost"))
+	assert.False(t, publicHost("api.localhost"))
+	assert.False(t, publicHost("127.0.0.1"))
+	assert.False(t, publicHost("10.1.2.3"))
+	assert.False(t, publicHost("172.16.0.1"))
+	assert.False(t, publicHost("192.168.1.1"))
+	assert.False(t, publicHost("100.64.0.1"))
+	assert.False(t, publicHost("169.254.169.254"))
+	assert.False(t, publicHost("0.0.0.0"))
+	assert.False(t, publicHost("::1"))
+	assert.False(t, publicHost("fe80::1"))
+	assert.False(t, publicHost("fd00::1"))
+	assert.False(t, publicHost("::ffff:127.0.0.1"))
+}
+
+func TestEventTypesScan(t *testing.T) {
+	// Arrange
+	var eventTypes EventTypes
+
+	// Act
+	err := eventTypes.Scan(`["route.started","route.completed"]`)
+	value, valueErr := eventTypes.Value()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, valueErr)
+	assert.Equal(t, EventTypes{"route.started", "route.completed"}, eventTypes)
+	assert.Equal(t, `["route.started","route.completed"]`, value)
+}
@@ -0,0 +1,13 @@
+package zone
+
+import "github.com/google/uuid"
+
+type CreateDepot struct {
+	ZoneID    uuid.UUID `json:"zone_id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	OpensAt   string    `json:"opens_at"`
+	ClosesAt  string    `json:"closes_at"`
+}
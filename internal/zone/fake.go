@@ -0,0 +1,110 @@
+package zone
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FakeRepository is an in-memory Repository for the tests of the services
+// built on zones and their depots.
+type FakeRepository struct {
+	mu     sync.Mutex
+	zones  []Zone
+	depots []Depot
+}
+
+func (f *FakeRepository) CreateZones(ctx context.Context, zones []*Zone) ([]*Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, zone := range zones {
+		if zone.ID == uuid.Nil {
+			zone.ID = uuid.New()
+		}
+		stored := *zone
+		stored.Depots = nil
+		f.zones = append(f.zones, stored)
+	}
+	return zones, nil
+}
+
+func (f *FakeRepository) GetZones(ctx context.Context) ([]Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	zones := make([]Zone, len(f.zones))
+	for i, zone := range f.zones {
+		zones[i] = f.withDepots(zone)
+	}
+	sort.SliceStable(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+	return zones, nil
+}
+
+func (f *FakeRepository) GetZone(ctx context.Context, id uuid.UUID) (*Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, zone := range f.zones {
+		if zone.ID == id {
+			zone = f.withDepots(zone)
+			sort.SliceStable(zone.Depots, func(i, j int) bool {
+				if zone.Depots[i].OpensAt != zone.Depots[j].OpensAt {
+					return zone.Depots[i].OpensAt < zone.Depots[j].OpensAt
+				}
+				return zone.Depots[i].Name < zone.Depots[j].Name
+			})
+			return &zone, nil
+		}
+	}
+	return &Zone{}, gorm.ErrRecordNotFound
+}
+
+func (f *FakeRepository) CreateDepot(ctx context.Context, depot *Depot) (*Depot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if depot.ID == uuid.Nil {
+		depot.ID = uuid.New()
+	}
+	f.depots = append(f.depots, *depot)
+	return depot, nil
+}
+
+func (f *FakeRepository) GetDepot(ctx context.Context, id uuid.UUID) (*Depot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, depot := range f.depots {
+		if depot.ID == id {
+			return &depot, nil
+		}
+	}
+	return &Depot{}, gorm.ErrRecordNotFound
+}
+
+func (f *FakeRepository) GetDepots(ctx context.Context) ([]Depot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	depots := append([]Depot{}, f.depots...)
+	sort.SliceStable(depots, func(i, j int) bool { return depots[i].Name < depots[j].Name })
+	return depots, nil
+}
+
+// withDepots returns the zone along with its depots.
+func (f *FakeRepository) withDepots(zone Zone) Zone {
+	zone.Depots = []Depot{}
+	for _, depot := range f.depots {
+		if depot.ZoneID == zone.ID {
+			zone.Depots = append(zone.Depots, depot)
+		}
+	}
+	return zone
+}
+
+// static functions
+
+// NewFakeRepository creates an in-memory repository holding the zones.
+func NewFakeRepository(zones ...*Zone) *FakeRepository {
+	f := &FakeRepository{}
+	f.CreateZones(context.Background(), zones)
+	return f
+}
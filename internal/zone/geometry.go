@@ -0,0 +1,146 @@
+package zone
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// Position is a GeoJSON [longitude, latitude] position.
+type Position [2]float64
+
+// Ring is a closed line of positions. The first ring of a Polygon is its
+// exterior and any others are holes.
+type Ring []Position
+
+type Polygon []Ring
+
+type MultiPolygon []Polygon
+
+// BoundingBox is the smallest latitude and longitude range containing a shape.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// RawGeometry is a GeoJSON geometry stored as text, and written as is to JSON.
+type RawGeometry string
+
+func (g RawGeometry) MarshalJSON() ([]byte, error) {
+	if g == "" {
+		return []byte("null"), nil
+	}
+	return []byte(g), nil
+}
+
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Contains tells whether a location lies within any of the polygons. Points
+// on an edge may fall either way.
+func (m MultiPolygon) Contains(latitude float64, longitude float64) bool {
+	for _, polygon := range m {
+		if polygon.Contains(latitude, longitude) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains tells whether a location lies within the exterior ring of the
+// polygon and outside all of its holes.
+func (p Polygon) Contains(latitude float64, longitude float64) bool {
+	if len(p) == 0 || !p[0].contains(latitude, longitude) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if hole.contains(latitude, longitude) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains casts a ray from the location towards increasing longitude and
+// counts the edges it crosses, an odd count being inside.
+func (r Ring) contains(latitude float64, longitude float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		longitudeI, latitudeI := r[i][0], r[i][1]
+		longitudeJ, latitudeJ := r[j][0], r[j][1]
+		if (latitudeI > latitude) != (latitudeJ > latitude) &&
+			longitude < (longitudeJ-longitudeI)*(latitude-latitudeI)/(latitudeJ-latitudeI)+longitudeI {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (m MultiPolygon) BoundingBox() BoundingBox {
+	box := BoundingBox{
+		MinLatitude:  math.Inf(1),
+		MinLongitude: math.Inf(1),
+		MaxLatitude:  math.Inf(-1),
+		MaxLongitude: math.Inf(-1),
+	}
+	for _, polygon := range m {
+		// Holes lie within the exterior ring
+		for _, position := range polygon[0] {
+			box.MinLongitude = math.Min(box.MinLongitude, position[0])
+			box.MaxLongitude = math.Max(box.MaxLongitude, position[0])
+			box.MinLatitude = math.Min(box.MinLatitude, position[1])
+			box.MaxLatitude = math.Max(box.MaxLatitude, position[1])
+		}
+	}
+	return box
+}
+
+// static functions
+
+// ParseGeometry reads a GeoJSON Polygon or MultiPolygon geometry. Every ring
+// must have at least four positions within the valid coordinate range.
+func ParseGeometry(data []byte) (MultiPolygon, error) {
+	var g geometry
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, ErrInvalidGeometry
+	}
+
+	var shape MultiPolygon
+	switch g.Type {
+	case "Polygon":
+		var polygon Polygon
+		if err := json.Unmarshal(g.Coordinates, &polygon); err != nil {
+			return nil, ErrInvalidGeometry
+		}
+		shape = MultiPolygon{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &shape); err != nil {
+			return nil, ErrInvalidGeometry
+		}
+	default:
+		return nil, ErrInvalidGeometry
+	}
+
+	if len(shape) == 0 {
+		return nil, ErrInvalidGeometry
+	}
+	for _, polygon := range shape {
+		if len(polygon) == 0 {
+			return nil, ErrInvalidGeometry
+		}
+		for _, ring := range polygon {
+			if len(ring) < 4 {
+				return nil, ErrInvalidGeometry
+			}
+			for _, position := range ring {
+				if position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+					return nil, ErrInvalidGeometry
+				}
+			}
+		}
+	}
+	return shape, nil
+}
@@ -0,0 +1,84 @@
+package zone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A square around Palermo with a hole around Plaza Italia
+const palermo = `{"type":"Polygon","coordinates":[
+	[[-58.44,-34.60],[-58.40,-34.60],[-58.40,-34.56],[-58.44,-34.56],[-58.44,-34.60]],
+	[[-58.425,-34.585],[-58.415,-34.585],[-58.415,-34.575],[-58.425,-34.575],[-58.425,-34.585]]
+]}`
+
+func TestPolygonContains(t *testing.T) {
+	// Arrange
+	shape, err := ParseGeometry([]byte(palermo))
+	assert.NoError(t, err)
+
+	// Act & Assert
+	assert.True(t, shape.Contains(-34.5881, -58.4106))
+	assert.False(t, shape.Contains(-34.5800, -58.4200), "inside the hole")
+	assert.False(t, shape.Contains(-34.6037, -58.3816), "east of the square")
+}
+
+func TestPolygonContainsConcave(t *testing.T) {
+	// Arrange
+	// An L shape, missing its north east quarter
+	shape, err := ParseGeometry([]byte(`{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,1],[1,1],[1,2],[0,2],[0,0]]]}`))
+	assert.NoError(t, err)
+
+	// Act & Assert
+	assert.True(t, shape.Contains(0.5, 1.5))
+	assert.True(t, shape.Contains(1.5, 0.5))
+	assert.False(t, shape.Contains(1.5, 1.5))
+}
+
+func TestMultiPolygonContains(t *testing.T) {
+	// Arrange
+	shape, err := ParseGeometry([]byte(`{"type":"MultiPolygon","coordinates":[
+		[[[0,0],[1,0],[1,1],[0,1],[0,0]]],
+		[[[5,5],[6,5],[6,6],[5,6],[5,5]]]
+	]}`))
+	assert.NoError(t, err)
+
+	// Act & Assert
+	assert.True(t, shape.Contains(0.5, 0.5))
+	assert.True(t, shape.Contains(5.5, 5.5))
+	assert.False(t, shape.Contains(3, 3))
+	assert.Equal(t, BoundingBox{MinLatitude: 0, MinLongitude: 0, MaxLatitude: 6, MaxLongitude: 6}, shape.BoundingBox())
+}
+
+func TestParseGeometryInvalid(t *testing.T) {
+	for name, input := range map[string]string{
+		"not json":       `{`,
+		"point":          `{"type":"Point","coordinates":[0,0]}`,
+		"open ring":      `{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,1]]]}`,
+		"no rings":       `{"type":"Polygon","coordinates":[]}`,
+		"out of range":   `{"type":"Polygon","coordinates":[[[0,0],[200,0],[200,1],[0,0]]]}`,
+		"wrong nesting":  `{"type":"MultiPolygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`,
+		"no coordinates": `{"type":"Polygon"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := ParseGeometry([]byte(input))
+
+			// Assert
+			assert.ErrorIs(t, err, ErrInvalidGeometry)
+		})
+	}
+}
+
+func TestZoneContainsChecksBoundingBoxFirst(t *testing.T) {
+	// Arrange
+	// The geometry is never parsed for locations outside the bounding box
+	zone := &Zone{Geometry: "not json", MinLatitude: -34.60, MinLongitude: -58.44, MaxLatitude: -34.56, MaxLongitude: -58.40}
+
+	// Act
+	inside, err := zone.Contains(-34.6037, -58.3816)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, inside)
+}
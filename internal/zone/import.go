@@ -0,0 +1,73 @@
+package zone
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type feature struct {
+	Type       string                 `json:"type"`
+	Geometry   json.RawMessage        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+// static functions
+
+// ParseGeoJSON reads zones from a GeoJSON FeatureCollection, a single Feature
+// or a bare geometry. Each feature is named after its "name" property.
+func ParseGeoJSON(data []byte) ([]*Zone, error) {
+	var collection featureCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+	}
+
+	var features []feature
+	switch collection.Type {
+	case "FeatureCollection":
+		features = collection.Features
+	case "Feature":
+		var f feature
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+		}
+		features = []feature{f}
+	default:
+		features = []feature{{Geometry: data}}
+	}
+	if len(features) == 0 {
+		return nil, fmt.Errorf("%w: no features", ErrInvalidGeometry)
+	}
+
+	zones := make([]*Zone, len(features))
+	for i, f := range features {
+		zone, err := newZone(f)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i+1, err)
+		}
+		zones[i] = zone
+	}
+	return zones, nil
+}
+
+func newZone(f feature) (*Zone, error) {
+	shape, err := ParseGeometry(f.Geometry)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := f.Properties["name"].(string)
+	box := shape.BoundingBox()
+	return &Zone{
+		Name:         name,
+		Geometry:     RawGeometry(f.Geometry),
+		MinLatitude:  box.MinLatitude,
+		MinLongitude: box.MinLongitude,
+		MaxLatitude:  box.MaxLatitude,
+		MaxLongitude: box.MaxLongitude,
+		shape:        shape,
+	}, nil
+}
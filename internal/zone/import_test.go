@@ -0,0 +1,64 @@
+package zone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGeoJSONFeatureCollection(t *testing.T) {
+	// Arrange
+	input := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"name":"Palermo"},"geometry":` + palermo + `},
+		{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}
+	]}`
+
+	// Act
+	zones, err := ParseGeoJSON([]byte(input))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, zones, 2)
+	assert.Equal(t, "Palermo", zones[0].Name)
+	assert.Equal(t, -34.60, zones[0].MinLatitude)
+	assert.Equal(t, -58.40, zones[0].MaxLongitude)
+	assert.Equal(t, "", zones[1].Name)
+	inside, err := zones[0].Contains(-34.5881, -58.4106)
+	assert.NoError(t, err)
+	assert.True(t, inside)
+}
+
+func TestParseGeoJSONFeatureAndGeometry(t *testing.T) {
+	// Act
+	fromFeature, featureErr := ParseGeoJSON([]byte(`{"type":"Feature","properties":{"name":"Palermo"},"geometry":` + palermo + `}`))
+	fromGeometry, geometryErr := ParseGeoJSON([]byte(palermo))
+
+	// Assert
+	assert.NoError(t, featureErr)
+	assert.NoError(t, geometryErr)
+	assert.Equal(t, "Palermo", fromFeature[0].Name)
+	assert.Equal(t, fromFeature[0].MaxLatitude, fromGeometry[0].MaxLatitude)
+}
+
+func TestParseGeoJSONInvalidFeature(t *testing.T) {
+	// Arrange
+	input := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":` + palermo + `},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]}}
+	]}`
+
+	// Act
+	_, err := ParseGeoJSON([]byte(input))
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidGeometry)
+	assert.ErrorContains(t, err, "feature 2")
+}
+
+func TestParseGeoJSONEmpty(t *testing.T) {
+	// Act
+	_, err := ParseGeoJSON([]byte(`{"type":"FeatureCollection","features":[]}`))
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidGeometry)
+}
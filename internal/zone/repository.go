@@ -0,0 +1,72 @@
+package zone
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository stores the zones and their depots.
+type Repository interface {
+	CreateZones(ctx context.Context, zones []*Zone) ([]*Zone, error)
+	GetZones(ctx context.Context) ([]Zone, error)
+	GetZone(ctx context.Context, id uuid.UUID) (*Zone, error)
+	CreateDepot(ctx context.Context, depot *Depot) (*Depot, error)
+	GetDepot(ctx context.Context, id uuid.UUID) (*Depot, error)
+	GetDepots(ctx context.Context) ([]Depot, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// CreateZones stores all the zones or none of them.
+func (r *repository) CreateZones(ctx context.Context, zones []*Zone) ([]*Zone, error) {
+	for _, zone := range zones {
+		if zone.ID == uuid.Nil {
+			zone.ID = uuid.New()
+		}
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Omit("Depots").Create(zones).Error
+	})
+	return zones, err
+}
+
+func (r *repository) GetZones(ctx context.Context) ([]Zone, error) {
+	var zones []Zone
+	err := r.db.WithContext(ctx).Preload("Depots").Order("name").Find(&zones).Error
+	return zones, err
+}
+
+func (r *repository) GetZone(ctx context.Context, id uuid.UUID) (*Zone, error) {
+	var zone Zone
+	err := r.db.WithContext(ctx).Preload("Depots", func(db *gorm.DB) *gorm.DB {
+		return db.Order("opens_at, name")
+	}).First(&zone, "id = ?", id).Error
+	return &zone, err
+}
+
+func (r *repository) CreateDepot(ctx context.Context, depot *Depot) (*Depot, error) {
+	if depot.ID == uuid.Nil {
+		depot.ID = uuid.New()
+	}
+	return depot, r.db.WithContext(ctx).Create(depot).Error
+}
+
+func (r *repository) GetDepot(ctx context.Context, id uuid.UUID) (*Depot, error) {
+	var depot Depot
+	return &depot, r.db.WithContext(ctx).First(&depot, "id = ?", id).Error
+}
+
+func (r *repository) GetDepots(ctx context.Context) ([]Depot, error) {
+	var depots []Depot
+	return depots, r.db.WithContext(ctx).Order("name").Find(&depots).Error
+}
+
+// static functions
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
@@ -0,0 +1,75 @@
+package zone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type RepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	repository Repository
+}
+
+func (suite *RepositoryTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	err = db.AutoMigrate(&Zone{}, &Depot{})
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	suite.db = db
+	suite.repository = NewRepository(db)
+}
+
+func (suite *RepositoryTestSuite) TestCreateZones() {
+	// Arrange
+	zones, err := ParseGeoJSON([]byte(palermo))
+	suite.Require().NoError(err)
+
+	// Act
+	result, err := suite.repository.CreateZones(context.Background(), zones)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), uuid.Nil, result[0].ID)
+
+	stored, err := suite.repository.GetZone(context.Background(), result[0].ID)
+	assert.NoError(suite.T(), err)
+	assert.JSONEq(suite.T(), palermo, string(stored.Geometry))
+	inside, err := stored.Contains(-34.5881, -58.4106)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), inside)
+}
+
+func (suite *RepositoryTestSuite) TestGetZoneOrdersDepotsByOpening() {
+	// Arrange
+	zone := &Zone{ID: uuid.New(), Name: "Palermo"}
+	suite.db.Create(zone)
+	suite.repository.CreateDepot(context.Background(), &Depot{ZoneID: zone.ID, Name: "Late", OpensAt: "09:00", ClosesAt: "18:00"})
+	suite.repository.CreateDepot(context.Background(), &Depot{ZoneID: zone.ID, Name: "Early", OpensAt: "06:30", ClosesAt: "15:00"})
+	suite.repository.CreateDepot(context.Background(), &Depot{ZoneID: uuid.New(), Name: "Elsewhere", OpensAt: "05:00", ClosesAt: "15:00"})
+
+	// Act
+	result, err := suite.repository.GetZone(context.Background(), zone.ID)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result.Depots, 2)
+	assert.Equal(suite.T(), "Early", result.Depots[0].Name)
+	assert.Equal(suite.T(), "Late", result.Depots[1].Name)
+}
+
+func TestRepositorySuite(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
@@ -0,0 +1,113 @@
+package zone
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Service interface {
+	GetZones(ctx context.Context) ([]Zone, error)
+	GetZone(ctx context.Context, id uuid.UUID) (*Zone, error)
+	ImportZones(ctx context.Context, data []byte) ([]*Zone, error)
+	GetDepots(ctx context.Context) ([]Depot, error)
+	GetDepot(ctx context.Context, id uuid.UUID) (*Depot, error)
+	CreateDepot(ctx context.Context, newDepot *CreateDepot) (*Depot, error)
+	PickDepot(ctx context.Context, zoneID uuid.UUID) (*Depot, error)
+	Contains(ctx context.Context, zoneID uuid.UUID, latitude float64, longitude float64) (bool, error)
+}
+
+type service struct {
+	repository Repository
+}
+
+func (s *service) GetZones(ctx context.Context) ([]Zone, error) {
+	return s.repository.GetZones(ctx)
+}
+
+func (s *service) GetZone(ctx context.Context, id uuid.UUID) (*Zone, error) {
+	return s.repository.GetZone(ctx, id)
+}
+
+// ImportZones stores every zone of a GeoJSON document, or none if any of them
+// is invalid.
+func (s *service) ImportZones(ctx context.Context, data []byte) ([]*Zone, error) {
+	zones, err := ParseGeoJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.CreateZones(ctx, zones)
+}
+
+func (s *service) GetDepots(ctx context.Context) ([]Depot, error) {
+	return s.repository.GetDepots(ctx)
+}
+
+func (s *service) GetDepot(ctx context.Context, id uuid.UUID) (*Depot, error) {
+	return s.repository.GetDepot(ctx, id)
+}
+
+// CreateDepot adds a depot to a zone. The depot must lie within the zone.
+func (s *service) CreateDepot(ctx context.Context, newDepot *CreateDepot) (*Depot, error) {
+	if strings.TrimSpace(newDepot.Name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidDepot)
+	}
+	if newDepot.Latitude < -90 || newDepot.Latitude > 90 || newDepot.Longitude < -180 || newDepot.Longitude > 180 {
+		return nil, fmt.Errorf("%w: coordinates are out of range", ErrInvalidDepot)
+	}
+	if !ValidOperatingHours(newDepot.OpensAt, newDepot.ClosesAt) {
+		return nil, ErrInvalidOperatingHours
+	}
+
+	zone, err := s.repository.GetZone(ctx, newDepot.ZoneID)
+	if err != nil {
+		return nil, err
+	}
+	inside, err := zone.Contains(newDepot.Latitude, newDepot.Longitude)
+	if err != nil {
+		return nil, err
+	}
+	if !inside {
+		return nil, ErrDepotOutsideZone
+	}
+
+	return s.repository.CreateDepot(ctx, &Depot{
+		ZoneID:    newDepot.ZoneID,
+		Name:      newDepot.Name,
+		Address:   newDepot.Address,
+		Latitude:  newDepot.Latitude,
+		Longitude: newDepot.Longitude,
+		OpensAt:   normalizeHour(newDepot.OpensAt),
+		ClosesAt:  normalizeHour(newDepot.ClosesAt),
+	})
+}
+
+// PickDepot returns the depot a new route of the zone starts from: the one
+// that opens earliest, so the route can leave as soon as possible.
+func (s *service) PickDepot(ctx context.Context, zoneID uuid.UUID) (*Depot, error) {
+	zone, err := s.repository.GetZone(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	if len(zone.Depots) == 0 {
+		return nil, ErrNoDepot
+	}
+	// Depots are loaded by opening time
+	return &zone.Depots[0], nil
+}
+
+func (s *service) Contains(ctx context.Context, zoneID uuid.UUID, latitude float64, longitude float64) (bool, error) {
+	zone, err := s.repository.GetZone(ctx, zoneID)
+	if err != nil {
+		return false, err
+	}
+	return zone.Contains(latitude, longitude)
+}
+
+// static functions
+
+func NewService(repository Repository) *service {
+	return &service{repository: repository}
+}
@@ -0,0 +1,156 @@
+package zone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Define a mock repository for testing the service, with only the methods
+// the tests expect
+type MockRepository struct {
+	Repository
+	mock.Mock
+}
+
+func (m *MockRepository) GetZone(ctx context.Context, id uuid.UUID) (*Zone, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Zone), args.Error(1)
+}
+
+func (m *MockRepository) CreateDepot(ctx context.Context, depot *Depot) (*Depot, error) {
+	args := m.Called(ctx, depot)
+	return args.Get(0).(*Depot), args.Error(1)
+}
+
+func palermoZone(t *testing.T) *Zone {
+	zones, err := ParseGeoJSON([]byte(palermo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones[0].ID = uuid.New()
+	return zones[0]
+}
+
+func TestCreateDepot(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+	zone := palermoZone(t)
+
+	mockRepo.On("GetZone", zone.ID).Return(zone, nil)
+	mockRepo.On("CreateDepot", mock.Anything, mock.MatchedBy(func(d *Depot) bool {
+		return d.ZoneID == zone.ID && d.OpensAt == "07:00"
+	})).Return(&Depot{ID: uuid.New(), ZoneID: zone.ID}, nil)
+
+	// Act
+	_, err := service.CreateDepot(context.Background(), &CreateDepot{
+		ZoneID:    zone.ID,
+		Name:      "Palermo",
+		Latitude:  -34.5881,
+		Longitude: -58.4106,
+		OpensAt:   "07:00",
+		ClosesAt:  "19:00",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateDepotOutsideZone(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+	zone := palermoZone(t)
+
+	mockRepo.On("GetZone", zone.ID).Return(zone, nil)
+
+	// Act
+	_, err := service.CreateDepot(context.Background(), &CreateDepot{
+		ZoneID:    zone.ID,
+		Name:      "Microcentro",
+		Latitude:  -34.6037,
+		Longitude: -58.3816,
+		OpensAt:   "07:00",
+		ClosesAt:  "19:00",
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrDepotOutsideZone)
+	mockRepo.AssertNotCalled(t, "CreateDepot")
+}
+
+func TestCreateDepotInvalidOperatingHours(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	// Act
+	_, err := service.CreateDepot(context.Background(), &CreateDepot{Name: "Palermo", OpensAt: "19:00", ClosesAt: "07:00"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidOperatingHours)
+	mockRepo.AssertNotCalled(t, "GetZone")
+}
+
+func TestPickDepot(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+	zone := palermoZone(t)
+	empty := palermoZone(t)
+	zone.Depots = []Depot{{ID: uuid.New(), Name: "Early", OpensAt: "06:00"}, {ID: uuid.New(), Name: "Late", OpensAt: "09:00"}}
+
+	mockRepo.On("GetZone", zone.ID).Return(zone, nil)
+	mockRepo.On("GetZone", empty.ID).Return(empty, nil)
+
+	// Act
+	depot, err := service.PickDepot(context.Background(), zone.ID)
+	_, emptyErr := service.PickDepot(context.Background(), empty.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Early", depot.Name)
+	assert.ErrorIs(t, emptyErr, ErrNoDepot)
+}
+
+func TestValidOperatingHours(t *testing.T) {
+	// Act & Assert
+	assert.True(t, ValidOperatingHours("07:00", "19:30"))
+	assert.False(t, ValidOperatingHours("19:00", "07:00"))
+	assert.False(t, ValidOperatingHours("07:00", "07:00"))
+	assert.False(t, ValidOperatingHours("7am", "19:00"))
+}
+
+func TestPickDepotOpeningFirst(t *testing.T) {
+	// Arrange
+	zone := palermoZone(t)
+	service := NewService(NewFakeRepository(zone))
+	for _, opensAt := range []string{"10:00", "9:00"} {
+		_, err := service.CreateDepot(context.Background(), &CreateDepot{
+			ZoneID:    zone.ID,
+			Name:      "Palermo " + opensAt,
+			Latitude:  -34.5881,
+			Longitude: -58.4106,
+			OpensAt:   opensAt,
+			ClosesAt:  "19:00",
+		})
+		assert.NoError(t, err)
+	}
+
+	// Act
+	depot, err := service.PickDepot(context.Background(), zone.ID)
+	_, noDepotErr := service.PickDepot(context.Background(), uuid.New())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "09:00", depot.OpensAt)
+	assert.Error(t, noDepotErr)
+}
@@ -0,0 +1,96 @@
+package zone
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Zone is an area we deliver to, bounded by a GeoJSON Polygon or MultiPolygon.
+// The bounding box is stored along with it so most locations can be ruled
+// out without looking at the polygon.
+type Zone struct {
+	ID           uuid.UUID   `gorm:"column:id" json:"id"`
+	Name         string      `gorm:"column:name" json:"name"`
+	Geometry     RawGeometry `gorm:"column:geometry" json:"geometry"`
+	MinLatitude  float64     `gorm:"column:min_latitude" json:"min_latitude"`
+	MinLongitude float64     `gorm:"column:min_longitude" json:"min_longitude"`
+	MaxLatitude  float64     `gorm:"column:max_latitude" json:"max_latitude"`
+	MaxLongitude float64     `gorm:"column:max_longitude" json:"max_longitude"`
+	Depots       []Depot     `gorm:"foreignKey:ZoneID" json:"depots"`
+	CreatedAt    time.Time   `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt    time.Time   `gorm:"column:updated_at" json:"updated_at"`
+
+	shape MultiPolygon `gorm:"-"`
+}
+
+// Depot is where the routes of a zone start from. OpensAt and ClosesAt are
+// the daily operating hours, formatted as HourLayout.
+type Depot struct {
+	ID        uuid.UUID `gorm:"column:id" json:"id"`
+	ZoneID    uuid.UUID `gorm:"column:zone_id" json:"zone_id"`
+	Name      string    `gorm:"column:name" json:"name"`
+	Address   string    `gorm:"column:address" json:"address"`
+	Latitude  float64   `gorm:"column:latitude" json:"latitude"`
+	Longitude float64   `gorm:"column:longitude" json:"longitude"`
+	OpensAt   string    `gorm:"column:opens_at" json:"opens_at"`
+	ClosesAt  string    `gorm:"column:closes_at" json:"closes_at"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// HourLayout is the format of the operating hours of a depot
+const HourLayout = "15:04"
+
+var (
+	ErrInvalidGeometry       = errors.New("invalid zone geometry, must be a GeoJSON Polygon or MultiPolygon")
+	ErrInvalidOperatingHours = errors.New("invalid operating hours, must be formatted as HH:MM and close after opening")
+	ErrInvalidDepot          = errors.New("invalid depot")
+	ErrDepotOutsideZone      = errors.New("depot lies outside its zone")
+	ErrDepotNotInZone        = errors.New("depot does not belong to the zone")
+	ErrNoDepot               = errors.New("zone has no depot")
+)
+
+// Contains tells whether a location lies within the zone.
+func (z *Zone) Contains(latitude float64, longitude float64) (bool, error) {
+	if latitude < z.MinLatitude || latitude > z.MaxLatitude ||
+		longitude < z.MinLongitude || longitude > z.MaxLongitude {
+		return false, nil
+	}
+	if z.shape == nil {
+		shape, err := ParseGeometry([]byte(z.Geometry))
+		if err != nil {
+			return false, err
+		}
+		z.shape = shape
+	}
+	return z.shape.Contains(latitude, longitude), nil
+}
+
+// static functions
+
+// ValidOperatingHours tells whether the hours are formatted as HourLayout and
+// the depot closes after it opens.
+func ValidOperatingHours(opensAt string, closesAt string) bool {
+	opens, err := time.Parse(HourLayout, opensAt)
+	if err != nil {
+		return false
+	}
+	closes, err := time.Parse(HourLayout, closesAt)
+	if err != nil {
+		return false
+	}
+	return closes.After(opens)
+}
+
+// normalizeHour formats an hour as HourLayout, zero padded, so that operating
+// hours compare and sort as strings. Hours that do not parse are left as
+// they are.
+func normalizeHour(hour string) string {
+	parsed, err := time.Parse(HourLayout, hour)
+	if err != nil {
+		return hour
+	}
+	return parsed.Format(HourLayout)
+}